@@ -0,0 +1,461 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/heartbeat"
+)
+
+var heartbeatJobAPIFn = callGatewayAPIJSON
+
+// heartbeatJobRequest mirrors the gateway job payload. Only flags given on the
+// command line are sent, so update changes just those fields.
+type heartbeatJobRequest struct {
+	ID                 string            `json:"id,omitempty"`
+	Runtime            *string           `json:"runtime,omitempty"`
+	Agent              *string           `json:"agent,omitempty"`
+	Text               *string           `json:"text,omitempty"`
+	Cron               *string           `json:"cron,omitempty"`
+	Every              *string           `json:"every,omitempty"`
+	Jitter             *string           `json:"jitter,omitempty"`
+	At                 *string           `json:"at,omitempty"`
+	Timezone           *string           `json:"timezone,omitempty"`
+	AgentCronProfiles  map[string]string `json:"agent_cron_profiles,omitempty"`
+	ResetCronOnInbound *bool             `json:"reset_cron_on_inbound,omitempty"`
+
+	// A set but empty list clears quiet hours or blackouts on update.
+	QuietHours     *[]config.HeartbeatQuietHoursConfig `json:"quiet_hours,omitempty"`
+	Blackouts      *[]string                           `json:"blackouts,omitempty"`
+	BlackoutPolicy *string                             `json:"blackout_policy,omitempty"`
+	MisfirePolicy  *string                             `json:"misfire_policy,omitempty"`
+	MisfireLimit   *int                                `json:"misfire_limit,omitempty"`
+	MisfireExpiry  *string                             `json:"misfire_expiry,omitempty"`
+
+	ActivityThreshold *string `json:"activity_threshold,omitempty"`
+	ActivityPolicy    *string `json:"activity_policy,omitempty"`
+
+	BackoffAfter  *int      `json:"backoff_after,omitempty"`
+	BackoffLadder *[]string `json:"backoff_ladder,omitempty"`
+
+	Notify *config.HeartbeatNotifyConfig `json:"notify,omitempty"`
+	Window *config.HeartbeatWindowConfig `json:"window,omitempty"`
+}
+
+type heartbeatJobsResponse struct {
+	Jobs []heartbeat.JobStatus `json:"jobs"`
+	Job  *heartbeat.JobStatus  `json:"job"`
+}
+
+// runHeartbeatJobCommand manages heartbeat jobs through the gateway, which
+// validates and persists them.
+func runHeartbeatJobCommand(ctx context.Context, cfg *config.Config, args []string, out io.Writer, logger *log.Logger) int {
+	if len(args) == 0 {
+		logger.Printf("heartbeat job command requires a subcommand (create, update, delete or list)")
+		return 1
+	}
+	subcmd := strings.ToLower(strings.TrimSpace(args[0]))
+	fs := flag.NewFlagSet("heartbeat job "+subcmd, flag.ContinueOnError)
+	fs.SetOutput(out)
+	var (
+		id                 *string
+		runtimeName        *string
+		agentName          *string
+		text               *string
+		cronExpression     *string
+		every              *string
+		jitter             *string
+		at                 *string
+		window             *string
+		timezone           *string
+		profiles           stringSliceFlag
+		resetCronOnInbound *bool
+		quietHours         stringSliceFlag
+		blackouts          stringSliceFlag
+		blackoutPolicy     *string
+		misfirePolicy      *string
+		misfireLimit       *int
+		misfireExpiry      *string
+		activityThreshold  *string
+		activityPolicy     *string
+		backoffAfter       *int
+		backoffLadder      stringSliceFlag
+		notify             config.HeartbeatNotifyConfig
+	)
+	switch subcmd {
+	case "create", "update":
+		id = fs.String("id", "", "heartbeat job ID")
+		runtimeName = fs.String("runtime", "", "target Agent Runtime")
+		agentName = fs.String("agent", "", "target agent")
+		text = fs.String("text", "", "instruction sent on every run")
+		cronExpression = fs.String("cron", "", "five-field cron expression")
+		every = fs.String("every", "", "run at this interval instead of a cron expression, e.g. 30m")
+		jitter = fs.String("jitter", "", "delay each --every run by up to this much, e.g. 5m")
+		at = fs.String("at", "", `run once at this RFC 3339 or "YYYY-MM-DD HH:MM" time; API jobs are deleted after running`)
+		window = fs.String("window", "", "run once a day at a random time in HH:MM-HH:MM")
+		timezone = fs.String("timezone", "", "IANA timezone for the cron expression")
+		fs.Var(&profiles, "profile", "cron profile as name=expression (repeatable; replaces all profiles on update)")
+		resetCronOnInbound = fs.Bool("reset-cron-on-inbound", false, "restore the default cron after routed inbound messages")
+		fs.Var(&quietHours, "quiet-hours", "quiet hours as HH:MM-HH:MM[@timezone] (repeatable; replaces all windows on update, empty clears)")
+		fs.Var(&blackouts, "blackout", "blackout calendar name (repeatable; replaces all calendars on update, empty clears)")
+		blackoutPolicy = fs.String("blackout-policy", "", "skip or defer firings inside quiet hours and blackouts")
+		misfirePolicy = fs.String("misfire-policy", "", "skip, run-once or run-all firings missed while the gateway was down")
+		misfireLimit = fs.Int("misfire-limit", 0, "most missed firings run-all replays (default 3)")
+		misfireExpiry = fs.String("misfire-expiry", "", "never replay missed firings older than this, e.g. 12h")
+		activityThreshold = fs.String("activity-threshold", "", "hold back firings while the agent was active within this, e.g. 10m")
+		activityPolicy = fs.String("activity-policy", "", "skip or defer firings while the agent is active")
+		backoffAfter = fs.Int("backoff-after", 0, "step to the next backoff profile after this many consecutive HEARTBEAT_OK replies (0 disables)")
+		fs.Var(&backoffLadder, "backoff-ladder", "profile name to back off to, in order (repeatable; replaces the ladder on update, empty clears)")
+		fs.StringVar(&notify.Channel, "notify-channel", "", "channel for failure alerts (notify flags replace the job's target on update; empty removes it)")
+		fs.StringVar(&notify.To, "notify-to", "", "chat ID for failure alerts")
+		fs.IntVar(&notify.FailureThreshold, "notify-failures", 0, "consecutive failed runs before alerting (default 3)")
+		fs.StringVar(&notify.UnreachableAfter, "notify-unreachable-after", "", "also alert when the runtime stays unreachable this long, e.g. 30m")
+		fs.IntVar(&notify.RecoverAfter, "notify-recover-after", 0, "successful runs before the recovery message (default 1)")
+	case "delete":
+		id = fs.String("id", "", "heartbeat job ID")
+	case "list":
+	default:
+		logger.Printf("unknown heartbeat job subcommand: %s", args[0])
+		return 1
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 1
+	}
+
+	if subcmd == "list" {
+		var response heartbeatJobsResponse
+		if err := heartbeatJobAPIFn(ctx, cfg, http.MethodGet, "/api/v1/heartbeat/jobs", nil, &response); err != nil {
+			logger.Printf("failed to list heartbeat jobs: %v", err)
+			return 1
+		}
+		if len(response.Jobs) == 0 {
+			fmt.Fprintln(out, "No heartbeat jobs")
+			return 0
+		}
+		for _, job := range response.Jobs {
+			fmt.Fprintln(out, formatHeartbeatJob(job))
+		}
+		return 0
+	}
+
+	jobID := strings.TrimSpace(*id)
+	if jobID == "" {
+		logger.Printf("--id is required")
+		return 1
+	}
+	path := "/api/v1/heartbeat/jobs/" + url.PathEscape(jobID)
+	if subcmd == "delete" {
+		if err := heartbeatJobAPIFn(ctx, cfg, http.MethodDelete, path, nil, nil); err != nil {
+			logger.Printf("failed to delete heartbeat job: %v", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Heartbeat job %s deleted\n", jobID)
+		return 0
+	}
+
+	request := heartbeatJobRequest{}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["runtime"] {
+		request.Runtime = runtimeName
+	}
+	if set["agent"] {
+		request.Agent = agentName
+	}
+	if set["text"] {
+		request.Text = text
+	}
+	if set["cron"] {
+		request.Cron = cronExpression
+	}
+	if set["every"] {
+		request.Every = every
+	}
+	if set["jitter"] {
+		request.Jitter = jitter
+	}
+	if set["at"] {
+		request.At = at
+	}
+	if set["window"] {
+		start, end, ok := strings.Cut(*window, "-")
+		if !ok {
+			logger.Printf("--window must be HH:MM-HH:MM: %q", *window)
+			return 1
+		}
+		request.Window = &config.HeartbeatWindowConfig{Start: strings.TrimSpace(start), End: strings.TrimSpace(end)}
+	}
+	if set["timezone"] {
+		request.Timezone = timezone
+	}
+	if set["reset-cron-on-inbound"] {
+		request.ResetCronOnInbound = resetCronOnInbound
+	}
+	if len(profiles) > 0 {
+		request.AgentCronProfiles = make(map[string]string, len(profiles))
+		for _, profile := range profiles.trimmed() {
+			name, expression, ok := strings.Cut(profile, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				logger.Printf("--profile must be name=expression: %q", profile)
+				return 1
+			}
+			request.AgentCronProfiles[strings.TrimSpace(name)] = strings.TrimSpace(expression)
+		}
+	}
+
+	if set["quiet-hours"] {
+		windows := []config.HeartbeatQuietHoursConfig{}
+		for _, window := range quietHours.trimmed() {
+			parsed, err := parseQuietHours(window)
+			if err != nil {
+				logger.Printf("%v", err)
+				return 1
+			}
+			windows = append(windows, parsed)
+		}
+		request.QuietHours = &windows
+	}
+	if set["blackout"] {
+		calendars := append([]string{}, blackouts.trimmed()...)
+		request.Blackouts = &calendars
+	}
+	if set["blackout-policy"] {
+		request.BlackoutPolicy = blackoutPolicy
+	}
+	if set["misfire-policy"] {
+		request.MisfirePolicy = misfirePolicy
+	}
+	if set["misfire-limit"] {
+		request.MisfireLimit = misfireLimit
+	}
+	if set["misfire-expiry"] {
+		request.MisfireExpiry = misfireExpiry
+	}
+	if set["activity-threshold"] {
+		request.ActivityThreshold = activityThreshold
+	}
+	if set["activity-policy"] {
+		request.ActivityPolicy = activityPolicy
+	}
+	if set["backoff-after"] {
+		request.BackoffAfter = backoffAfter
+	}
+	if set["backoff-ladder"] {
+		ladder := append([]string{}, backoffLadder.trimmed()...)
+		request.BackoffLadder = &ladder
+	}
+	if set["notify-channel"] || set["notify-to"] || set["notify-failures"] || set["notify-unreachable-after"] || set["notify-recover-after"] {
+		request.Notify = &notify
+	}
+
+	method := http.MethodPut
+	if subcmd == "create" {
+		method = http.MethodPost
+		path = "/api/v1/heartbeat/jobs"
+		request.ID = jobID
+	} else if len(set) == 1 {
+		logger.Printf("heartbeat job update requires at least one field to change")
+		return 1
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		logger.Printf("failed to encode heartbeat job: %v", err)
+		return 1
+	}
+	var response heartbeatJobsResponse
+	if err := heartbeatJobAPIFn(ctx, cfg, method, path, bytes.NewReader(payload), &response); err != nil {
+		logger.Printf("failed to %s heartbeat job: %v", subcmd, err)
+		return 1
+	}
+	verb := "updated"
+	if subcmd == "create" {
+		verb = "created"
+	}
+	fmt.Fprintf(out, "Heartbeat job %s %s\n", jobID, verb)
+	if response.Job != nil {
+		fmt.Fprintln(out, formatHeartbeatJob(*response.Job))
+	}
+	return 0
+}
+
+// parseQuietHours reads HH:MM-HH:MM with an optional @timezone suffix.
+func parseQuietHours(value string) (config.HeartbeatQuietHoursConfig, error) {
+	window, timezone, _ := strings.Cut(value, "@")
+	start, end, ok := strings.Cut(window, "-")
+	if !ok {
+		return config.HeartbeatQuietHoursConfig{}, fmt.Errorf("--quiet-hours must be HH:MM-HH:MM[@timezone]: %q", value)
+	}
+	return config.HeartbeatQuietHoursConfig{
+		Start:    strings.TrimSpace(start),
+		End:      strings.TrimSpace(end),
+		Timezone: strings.TrimSpace(timezone),
+	}, nil
+}
+
+type heartbeatPauseRequest struct {
+	Until    string `json:"until,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// runHeartbeatControlCommand pauses, resumes or immediately runs a job.
+func runHeartbeatControlCommand(ctx context.Context, cfg *config.Config, args []string, out io.Writer, logger *log.Logger) int {
+	action := strings.ToLower(strings.TrimSpace(args[0]))
+	fs := flag.NewFlagSet("heartbeat "+action, flag.ContinueOnError)
+	fs.SetOutput(out)
+	job := fs.String("job", "", "heartbeat job ID")
+	var pause heartbeatPauseRequest
+	if action == "pause" {
+		fs.StringVar(&pause.Duration, "for", "", "pause for this long, e.g. 2h (default: until resumed)")
+		fs.StringVar(&pause.Until, "until", "", "pause until this RFC 3339 time")
+		fs.StringVar(&pause.Reason, "reason", "", "why the job is paused")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 1
+	}
+	jobID := strings.TrimSpace(*job)
+	if jobID == "" {
+		logger.Printf("--job is required")
+		return 1
+	}
+
+	var body io.Reader
+	if action == "pause" {
+		payload, err := json.Marshal(pause)
+		if err != nil {
+			logger.Printf("failed to encode pause request: %v", err)
+			return 1
+		}
+		body = bytes.NewReader(payload)
+	}
+	var response heartbeatJobsResponse
+	path := "/api/v1/heartbeat/jobs/" + url.PathEscape(jobID) + "/" + action
+	if err := heartbeatJobAPIFn(ctx, cfg, http.MethodPost, path, body, &response); err != nil {
+		logger.Printf("failed to %s heartbeat job: %v", action, err)
+		return 1
+	}
+	switch action {
+	case "pause":
+		if response.Job != nil && response.Job.PausedUntil != "" {
+			fmt.Fprintf(out, "Heartbeat job %s paused until %s\n", jobID, response.Job.PausedUntil)
+		} else {
+			fmt.Fprintf(out, "Heartbeat job %s paused until resumed\n", jobID)
+		}
+	case "resume":
+		fmt.Fprintf(out, "Heartbeat job %s resumed\n", jobID)
+	default:
+		fmt.Fprintf(out, "Heartbeat job %s triggered\n", jobID)
+	}
+	return 0
+}
+
+type heartbeatRunsResponse struct {
+	Runs []heartbeat.RunRecord `json:"runs"`
+}
+
+// runHeartbeatHistoryCommand prints a job's recorded runs, newest first.
+func runHeartbeatHistoryCommand(ctx context.Context, cfg *config.Config, args []string, out io.Writer, logger *log.Logger) int {
+	fs := flag.NewFlagSet("heartbeat history", flag.ContinueOnError)
+	fs.SetOutput(out)
+	job := fs.String("job", "", "heartbeat job ID")
+	limit := fs.Int("limit", 20, "number of runs to show (0 for all retained runs)")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	jobID := strings.TrimSpace(*job)
+	if jobID == "" {
+		logger.Printf("--job is required")
+		return 1
+	}
+	if *limit < 0 {
+		logger.Printf("--limit must be >= 0")
+		return 1
+	}
+	var response heartbeatRunsResponse
+	path := fmt.Sprintf("/api/v1/heartbeat/jobs/%s/runs?limit=%d", url.PathEscape(jobID), *limit)
+	if err := heartbeatJobAPIFn(ctx, cfg, http.MethodGet, path, nil, &response); err != nil {
+		logger.Printf("failed to read heartbeat history: %v", err)
+		return 1
+	}
+	if len(response.Runs) == 0 {
+		fmt.Fprintf(out, "No runs recorded for heartbeat job %s\n", jobID)
+		return 0
+	}
+	for _, run := range response.Runs {
+		fmt.Fprintln(out, formatHeartbeatRun(run))
+	}
+	return 0
+}
+
+func formatHeartbeatRun(run heartbeat.RunRecord) string {
+	at := run.StartedAt
+	if at == "" {
+		at = run.ScheduledAt
+	}
+	parts := []string{at, run.Status, run.Trigger}
+	if run.ScheduledAt != "" && run.ScheduledAt != at {
+		parts = append(parts, "scheduled="+run.ScheduledAt)
+	}
+	if run.Attempts > 0 {
+		parts = append(parts, fmt.Sprintf("attempts=%d", run.Attempts), fmt.Sprintf("duration=%dms", run.DurationMS))
+	}
+	if run.Profile != "" {
+		parts = append(parts, "profile="+run.Profile)
+	}
+	if run.EnvelopeID != "" {
+		parts = append(parts, "envelope="+run.EnvelopeID)
+	}
+	if run.Error != "" {
+		parts = append(parts, "error="+run.Error)
+	}
+	return strings.Join(parts, "\t")
+}
+
+func formatHeartbeatJob(job heartbeat.JobStatus) string {
+	schedule := "cron=" + job.EffectiveCron
+	switch {
+	case job.EffectiveCron != "":
+	case job.Every != "":
+		schedule = "every=" + job.Every
+		if job.Jitter != "" {
+			schedule += " jitter=" + job.Jitter
+		}
+	case job.At != "":
+		schedule = "at=" + job.At
+	case job.Window != "":
+		schedule = "window=" + job.Window
+	}
+	parts := []string{job.ID, job.Source, job.Runtime + "/" + job.Agent, schedule, "tz=" + job.Timezone}
+	if job.EffectiveProfile != "" {
+		parts = append(parts, "profile="+job.EffectiveProfile)
+	}
+	if job.Paused {
+		if job.PausedUntil != "" {
+			parts = append(parts, "paused_until="+job.PausedUntil)
+		} else {
+			parts = append(parts, "paused")
+		}
+	}
+	if job.NextRunAt != "" {
+		parts = append(parts, "next="+job.NextRunAt)
+	}
+	if job.LastDispatchStatus != "" {
+		parts = append(parts, "last="+job.LastDispatchStatus)
+	}
+	if job.MissedRuns > 0 {
+		parts = append(parts, fmt.Sprintf("missed=%d", job.MissedRuns))
+	}
+	if job.PendingCatchUp > 0 {
+		parts = append(parts, fmt.Sprintf("catch_up=%d", job.PendingCatchUp))
+	}
+	return strings.Join(parts, "\t")
+}
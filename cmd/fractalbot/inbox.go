@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/inbox"
+)
+
+// runInboxCommand operates on the runtime inbox directories directly, so it
+// also works while the gateway is down.
+func runInboxCommand(cfg *config.Config, args []string, out io.Writer, logger *log.Logger) int {
+	if len(args) == 0 {
+		logger.Printf("inbox command requires a subcommand (list, show, ack, requeue or purge)")
+		return 1
+	}
+	subcmd := strings.ToLower(strings.TrimSpace(args[0]))
+	fs := flag.NewFlagSet("inbox "+subcmd, flag.ContinueOnError)
+	fs.SetOutput(out)
+	runtimeName := fs.String("runtime", "", "runtime whose inbox to use (default: the only configured inbox)")
+	var (
+		state     *string
+		id        *string
+		failed    *bool
+		reason    *string
+		olderThan *time.Duration
+	)
+	switch subcmd {
+	case "list":
+		state = fs.String("state", "", "only list items in this state (pending, claimed, done, failed)")
+	case "show", "requeue":
+		id = fs.String("id", "", "inbox item ID")
+	case "ack":
+		id = fs.String("id", "", "inbox item ID")
+		failed = fs.Bool("failed", false, "mark the item failed instead of done")
+		reason = fs.String("error", "", "failure reason recorded with --failed")
+	case "purge":
+		state = fs.String("state", string(inbox.StateDone), "state to purge (done or failed)")
+		olderThan = fs.Duration("older-than", 0, "only purge items finished longer ago than this")
+	default:
+		logger.Printf("unknown inbox subcommand: %s", args[0])
+		return 1
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 1
+	}
+
+	name, store, err := resolveInboxStore(cfg, *runtimeName)
+	if err != nil {
+		logger.Printf("%v", err)
+		return 1
+	}
+	itemID := ""
+	if id != nil {
+		itemID = strings.TrimSpace(*id)
+		if itemID == "" && fs.NArg() > 0 {
+			itemID = strings.TrimSpace(fs.Arg(0))
+		}
+		if itemID == "" {
+			logger.Printf("--id is required")
+			return 1
+		}
+	}
+
+	switch subcmd {
+	case "list":
+		filter, err := inbox.ParseState(*state)
+		if err != nil {
+			logger.Printf("%v", err)
+			return 1
+		}
+		items, err := store.List(filter)
+		if err != nil {
+			logger.Printf("failed to list %s inbox: %v", name, err)
+			return 1
+		}
+		if len(items) == 0 {
+			fmt.Fprintf(out, "%s inbox is empty\n", name)
+			return 0
+		}
+		for _, item := range items {
+			fmt.Fprintln(out, formatInboxItem(item))
+		}
+		return 0
+
+	case "show":
+		item, payload, err := store.Get(itemID)
+		if err != nil {
+			logger.Printf("failed to show inbox item: %v", err)
+			return 1
+		}
+		fmt.Fprintln(out, formatInboxItem(item))
+		fmt.Fprintf(out, "path: %s\n", item.Path)
+		if item.Error != "" {
+			fmt.Fprintf(out, "error: %s\n", item.Error)
+		}
+		fmt.Fprintf(out, "%s\n", strings.TrimSpace(string(payload)))
+		return 0
+
+	case "ack":
+		item, err := store.Ack(itemID, *failed, *reason)
+		if err != nil {
+			logger.Printf("failed to ack inbox item: %v", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Inbox item %s marked %s\n", item.ID, item.State)
+		return 0
+
+	case "requeue":
+		item, err := store.Requeue(itemID)
+		if err != nil {
+			logger.Printf("failed to requeue inbox item: %v", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Inbox item %s is pending again\n", item.ID)
+		return 0
+
+	default: // purge
+		filter, err := inbox.ParseState(*state)
+		if err != nil {
+			logger.Printf("%v", err)
+			return 1
+		}
+		purged, err := store.Purge(filter, *olderThan)
+		if err != nil {
+			logger.Printf("failed to purge %s inbox: %v", name, err)
+			return 1
+		}
+		fmt.Fprintf(out, "Purged %d %s item(s) from %s inbox\n", purged, filter, name)
+		return 0
+	}
+}
+
+func resolveInboxStore(cfg *config.Config, runtimeName string) (string, *inbox.Store, error) {
+	var inboxes map[string]string
+	if cfg != nil && cfg.Agents != nil {
+		inboxes = cfg.Agents.RuntimeInboxes()
+	}
+	runtimeName = strings.TrimSpace(runtimeName)
+	if runtimeName != "" {
+		path, ok := inboxes[runtimeName]
+		if !ok {
+			return "", nil, fmt.Errorf("no inbox configured for runtime %s", runtimeName)
+		}
+		return runtimeName, inbox.Open(path), nil
+	}
+	switch len(inboxes) {
+	case 0:
+		return "", nil, fmt.Errorf("no runtime inbox is configured")
+	case 1:
+		for name, path := range inboxes {
+			return name, inbox.Open(path), nil
+		}
+	}
+	names := make([]string, 0, len(inboxes))
+	for name := range inboxes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "", nil, fmt.Errorf("--runtime is required (configured inboxes: %s)", strings.Join(names, ", "))
+}
+
+func formatInboxItem(item inbox.Item) string {
+	parts := []string{item.ID, string(item.State), item.QueuedAt.UTC().Format(time.RFC3339)}
+	if item.SelectedAgent != "" {
+		parts = append(parts, "agent="+item.SelectedAgent)
+	}
+	if item.Channel != "" {
+		parts = append(parts, "channel="+item.Channel)
+	}
+	if item.JobID != "" {
+		parts = append(parts, "job="+item.JobID)
+	}
+	if item.Attempts > 0 {
+		parts = append(parts, fmt.Sprintf("attempts=%d", item.Attempts))
+	}
+	if item.Owner != "" {
+		parts = append(parts, "owner="+item.Owner)
+	}
+	if !item.LeaseExpiresAt.IsZero() {
+		parts = append(parts, "lease_expires="+item.LeaseExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(parts, "\t")
+}
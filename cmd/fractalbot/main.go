@@ -27,6 +27,7 @@ var messageSendFn = sendMessageViaGatewayAPI
 var fileDownloadFn = downloadFileViaHTTP
 var heartbeatCronSetFn = setHeartbeatCronViaGatewayAPI
 var heartbeatCronResetFn = resetHeartbeatCronViaGatewayAPI
+var taskCompleteFn = completeTaskViaGatewayAPI
 
 // stringSliceFlag implements flag.Value so a flag can be specified multiple
 // times (e.g. --image a.png --image b.png) and accumulate into a slice.
@@ -181,6 +182,10 @@ func runCommand(ctx context.Context, cfg *config.Config, args []string, out io.W
 		return runFileCommand(ctx, cfg, args[1:], out, logger)
 	case "heartbeat":
 		return runHeartbeatCommand(ctx, cfg, args[1:], out, logger)
+	case "inbox":
+		return runInboxCommand(cfg, args[1:], out, logger)
+	case "task":
+		return runTaskCommand(ctx, cfg, args[1:], out, logger)
 	default:
 		logger.Printf("unknown command: %s", args[0])
 		return 1
@@ -188,8 +193,18 @@ func runCommand(ctx context.Context, cfg *config.Config, args []string, out io.W
 }
 
 func runHeartbeatCommand(ctx context.Context, cfg *config.Config, args []string, out io.Writer, logger *log.Logger) int {
+	if len(args) > 0 {
+		switch strings.ToLower(strings.TrimSpace(args[0])) {
+		case "job":
+			return runHeartbeatJobCommand(ctx, cfg, args[1:], out, logger)
+		case "pause", "resume", "run":
+			return runHeartbeatControlCommand(ctx, cfg, args, out, logger)
+		case "history":
+			return runHeartbeatHistoryCommand(ctx, cfg, args[1:], out, logger)
+		}
+	}
 	if len(args) < 2 || strings.ToLower(strings.TrimSpace(args[0])) != "cron" {
-		logger.Printf("heartbeat command requires a cron subcommand (set or reset)")
+		logger.Printf("heartbeat command requires a subcommand (cron set|reset, job create|update|delete|list, pause, resume, run or history)")
 		return 1
 	}
 
@@ -250,6 +265,37 @@ func runHeartbeatCommand(ctx context.Context, cfg *config.Config, args []string,
 	}
 }
 
+func runTaskCommand(ctx context.Context, cfg *config.Config, args []string, out io.Writer, logger *log.Logger) int {
+	if len(args) == 0 || strings.ToLower(strings.TrimSpace(args[0])) != "complete" {
+		logger.Printf("task command requires a subcommand (complete)")
+		return 1
+	}
+
+	completeFS := flag.NewFlagSet("task complete", flag.ContinueOnError)
+	completeFS.SetOutput(out)
+	id := completeFS.String("id", "", "tracked task ID")
+	summary := completeFS.String("summary", "", "one-line outcome posted back to the chat")
+	failed := completeFS.Bool("failed", false, "report the task as failed")
+	if err := completeFS.Parse(args[1:]); err != nil {
+		return 1
+	}
+	taskID := strings.TrimSpace(*id)
+	if taskID == "" {
+		logger.Printf("--id is required")
+		return 1
+	}
+	if err := taskCompleteFn(ctx, cfg, taskID, *failed, strings.TrimSpace(*summary)); err != nil {
+		logger.Printf("failed to complete task: %v", err)
+		return 1
+	}
+	state := "done"
+	if *failed {
+		state = "failed"
+	}
+	fmt.Fprintf(out, "Task %s reported as %s\n", taskID, state)
+	return 0
+}
+
 func runMessageCommand(ctx context.Context, cfg *config.Config, args []string, out io.Writer, logger *log.Logger) int {
 	if len(args) == 0 {
 		logger.Printf("message command requires a subcommand (send)")
@@ -449,13 +495,39 @@ func resetHeartbeatCronViaGatewayAPI(ctx context.Context, cfg *config.Config, jo
 	return callHeartbeatCronAPI(ctx, cfg, http.MethodDelete, jobID, nil)
 }
 
+type taskCompleteRequest struct {
+	Status  string `json:"status"`
+	Summary string `json:"summary,omitempty"`
+}
+
+func completeTaskViaGatewayAPI(ctx context.Context, cfg *config.Config, taskID string, failed bool, summary string) error {
+	request := taskCompleteRequest{Status: "done", Summary: summary}
+	if failed {
+		request.Status = "failed"
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	return callGatewayAPI(ctx, cfg, http.MethodPost, "/api/v1/tasks/"+url.PathEscape(taskID)+"/complete", bytes.NewReader(payload))
+}
+
 type heartbeatCronRequest struct {
 	Profile string `json:"profile"`
 	Reason  string `json:"reason"`
 }
 
 func callHeartbeatCronAPI(ctx context.Context, cfg *config.Config, method, jobID string, body io.Reader) error {
-	path := "/api/v1/heartbeat/jobs/" + url.PathEscape(strings.TrimSpace(jobID)) + "/cron"
+	return callGatewayAPI(ctx, cfg, method, "/api/v1/heartbeat/jobs/"+url.PathEscape(strings.TrimSpace(jobID))+"/cron", body)
+}
+
+func callGatewayAPI(ctx context.Context, cfg *config.Config, method, path string, body io.Reader) error {
+	return callGatewayAPIJSON(ctx, cfg, method, path, body, nil)
+}
+
+// callGatewayAPIJSON is callGatewayAPI that also decodes a successful
+// response into out when out is non-nil.
+func callGatewayAPIJSON(ctx context.Context, cfg *config.Config, method, path string, body io.Reader, out interface{}) error {
 	endpoint := gatewayAPIEndpoint(cfg, path)
 	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
 	if err != nil {
@@ -485,6 +557,11 @@ func callHeartbeatCronAPI(ctx context.Context, cfg *config.Config, method, jobID
 		}
 		return fmt.Errorf("gateway API error (%d): %s", response.StatusCode, message)
 	}
+	if out != nil {
+		if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +19,7 @@ import (
 	"time"
 
 	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/heartbeat"
 )
 
 func TestRunMissingConfig(t *testing.T) {
@@ -497,6 +500,193 @@ func TestRunHeartbeatCronCommands(t *testing.T) {
 	})
 }
 
+func TestRunHeartbeatJobCommands(t *testing.T) {
+	configPath := writeMinimalConfig(t)
+	original := heartbeatJobAPIFn
+	t.Cleanup(func() { heartbeatJobAPIFn = original })
+
+	type call struct {
+		method string
+		path   string
+		body   map[string]interface{}
+	}
+	var calls []call
+	heartbeatJobAPIFn = func(ctx context.Context, cfg *config.Config, method, path string, body io.Reader, out interface{}) error {
+		observed := call{method: method, path: path}
+		if body != nil {
+			if err := json.NewDecoder(body).Decode(&observed.body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+		}
+		calls = append(calls, observed)
+		if response, ok := out.(*heartbeatJobsResponse); ok {
+			response.Jobs = []heartbeat.JobStatus{{ID: "nightly", Source: "api", Runtime: "codexAppCDP", Agent: "main", EffectiveCron: "0 2 * * *", Timezone: "UTC"}}
+		}
+		return nil
+	}
+	run := func(args ...string) (int, string) {
+		var output bytes.Buffer
+		code := runWithContext(context.Background(), append([]string{"--config", configPath, "heartbeat", "job"}, args...), &output)
+		return code, output.String()
+	}
+
+	if code, output := run("create", "--id", "nightly", "--runtime", "codexAppCDP", "--agent", "main", "--text", "review PRs", "--cron", "0 2 * * *", "--timezone", "UTC", "--profile", "idle=0 */6 * * *"); code != 0 || !strings.Contains(output, "Heartbeat job nightly created") {
+		t.Fatalf("create code=%d output=%q", code, output)
+	}
+	created := calls[0]
+	profiles, _ := created.body["agent_cron_profiles"].(map[string]interface{})
+	if created.method != http.MethodPost || created.path != "/api/v1/heartbeat/jobs" || created.body["id"] != "nightly" || created.body["timezone"] != "UTC" || profiles["idle"] != "0 */6 * * *" {
+		t.Fatalf("unexpected create call: %#v", created)
+	}
+
+	if code, output := run("update", "--id", "nightly", "--cron", "0 4 * * *"); code != 0 || !strings.Contains(output, "updated") {
+		t.Fatalf("update code=%d output=%q", code, output)
+	}
+	updated := calls[1]
+	if updated.method != http.MethodPut || updated.path != "/api/v1/heartbeat/jobs/nightly" || len(updated.body) != 1 || updated.body["cron"] != "0 4 * * *" {
+		t.Fatalf("update should only send changed fields: %#v", updated)
+	}
+
+	if code, output := run("list"); code != 0 || !strings.Contains(output, "nightly\tapi\tcodexAppCDP/main\tcron=0 2 * * *") {
+		t.Fatalf("list code=%d output=%q", code, output)
+	}
+	if code, output := run("delete", "--id", "nightly"); code != 0 || calls[3].method != http.MethodDelete || !strings.Contains(output, "deleted") {
+		t.Fatalf("delete code=%d output=%q calls=%#v", code, output, calls)
+	}
+
+	if code, output := run("update", "--id", "nightly", "--quiet-hours", "22:00-07:00@Europe/Berlin", "--blackout", "", "--blackout-policy", "defer"); code != 0 {
+		t.Fatalf("update blackouts code=%d output=%q", code, output)
+	}
+	quiet := calls[4].body["quiet_hours"].([]interface{})
+	window, _ := quiet[0].(map[string]interface{})
+	blackouts, isList := calls[4].body["blackouts"].([]interface{})
+	if len(quiet) != 1 || window["start"] != "22:00" || window["end"] != "07:00" || window["timezone"] != "Europe/Berlin" || !isList || len(blackouts) != 0 || calls[4].body["blackout_policy"] != "defer" {
+		t.Fatalf("unexpected blackout update: %#v", calls[4].body)
+	}
+
+	for _, args := range [][]string{{"delete"}, {"update", "--id", "nightly"}, {"create", "--id", "x", "--profile", "idle"}, {"create", "--id", "x", "--quiet-hours", "22:00"}} {
+		if code, output := run(args...); code == 0 {
+			t.Fatalf("%v should fail, output=%q", args, output)
+		}
+	}
+}
+
+func TestRunHeartbeatControlCommands(t *testing.T) {
+	configPath := writeMinimalConfig(t)
+	original := heartbeatJobAPIFn
+	t.Cleanup(func() { heartbeatJobAPIFn = original })
+
+	var paths []string
+	var pause heartbeatPauseRequest
+	heartbeatJobAPIFn = func(ctx context.Context, cfg *config.Config, method, path string, body io.Reader, out interface{}) error {
+		if method != http.MethodPost {
+			t.Fatalf("method=%s", method)
+		}
+		paths = append(paths, path)
+		if body != nil {
+			if err := json.NewDecoder(body).Decode(&pause); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+		}
+		return nil
+	}
+	run := func(args ...string) (int, string) {
+		var output bytes.Buffer
+		code := runWithContext(context.Background(), append([]string{"--config", configPath, "heartbeat"}, args...), &output)
+		return code, output.String()
+	}
+
+	if code, output := run("pause", "--job", "cloudbank-main", "--for", "2h", "--reason", "incident"); code != 0 || !strings.Contains(output, "paused") {
+		t.Fatalf("pause code=%d output=%q", code, output)
+	}
+	if pause.Duration != "2h" || pause.Reason != "incident" || pause.Until != "" {
+		t.Fatalf("unexpected pause request: %#v", pause)
+	}
+	if code, output := run("resume", "--job", "cloudbank-main"); code != 0 || !strings.Contains(output, "resumed") {
+		t.Fatalf("resume code=%d output=%q", code, output)
+	}
+	if code, output := run("run", "--job", "cloudbank-main"); code != 0 || !strings.Contains(output, "triggered") {
+		t.Fatalf("run code=%d output=%q", code, output)
+	}
+	want := []string{
+		"/api/v1/heartbeat/jobs/cloudbank-main/pause",
+		"/api/v1/heartbeat/jobs/cloudbank-main/resume",
+		"/api/v1/heartbeat/jobs/cloudbank-main/run",
+	}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("paths=%v", paths)
+	}
+	if code, output := run("run"); code == 0 || !strings.Contains(output, "--job is required") {
+		t.Fatalf("missing job code=%d output=%q", code, output)
+	}
+}
+
+func TestRunHeartbeatHistoryCommand(t *testing.T) {
+	configPath := writeMinimalConfig(t)
+	original := heartbeatJobAPIFn
+	t.Cleanup(func() { heartbeatJobAPIFn = original })
+
+	var gotPath string
+	heartbeatJobAPIFn = func(ctx context.Context, cfg *config.Config, method, path string, body io.Reader, out interface{}) error {
+		if method != http.MethodGet || body != nil {
+			t.Fatalf("method=%s body=%v", method, body)
+		}
+		gotPath = path
+		response := out.(*heartbeatRunsResponse)
+		response.Runs = []heartbeat.RunRecord{
+			{JobID: "cloudbank-main", Trigger: heartbeat.RunTriggerManual, StartedAt: "2026-07-26T00:10:00Z", Status: "queued", Attempts: 1, DurationMS: 20, EnvelopeID: "env-2"},
+			{JobID: "cloudbank-main", Trigger: heartbeat.RunTriggerSchedule, ScheduledAt: "2026-07-26T00:00:00Z", Status: "skipped_overlap"},
+		}
+		return nil
+	}
+	var output bytes.Buffer
+	code := runWithContext(context.Background(), []string{"--config", configPath, "heartbeat", "history", "--job", "cloudbank-main", "--limit", "5"}, &output)
+	if code != 0 {
+		t.Fatalf("history code=%d output=%q", code, output.String())
+	}
+	if gotPath != "/api/v1/heartbeat/jobs/cloudbank-main/runs?limit=5" {
+		t.Fatalf("path=%q", gotPath)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "manual") || !strings.Contains(lines[0], "envelope=env-2") || !strings.Contains(lines[1], "skipped_overlap") {
+		t.Fatalf("unexpected output: %q", output.String())
+	}
+}
+
+func TestRunTaskCompleteCommand(t *testing.T) {
+	configPath := writeMinimalConfig(t)
+	original := taskCompleteFn
+	t.Cleanup(func() { taskCompleteFn = original })
+
+	called := false
+	taskCompleteFn = func(ctx context.Context, cfg *config.Config, taskID string, failed bool, summary string) error {
+		called = true
+		if taskID != "task-1" || !failed || summary != "tests still fail" {
+			t.Fatalf("unexpected arguments: id=%q failed=%t summary=%q", taskID, failed, summary)
+		}
+		return nil
+	}
+	var output bytes.Buffer
+	code := runWithContext(context.Background(), []string{
+		"--config", configPath,
+		"task", "complete",
+		"--id", "task-1",
+		"--failed",
+		"--summary", "tests still fail",
+	}, &output)
+	if code != 0 || !called {
+		t.Fatalf("code=%d called=%t output=%q", code, called, output.String())
+	}
+	if !strings.Contains(output.String(), "Task task-1 reported as failed") {
+		t.Fatalf("unexpected output: %q", output.String())
+	}
+
+	output.Reset()
+	if code := runWithContext(context.Background(), []string{"--config", configPath, "task", "complete"}, &output); code == 0 || !strings.Contains(output.String(), "--id is required") {
+		t.Fatalf("code=%d output=%q", code, output.String())
+	}
+}
+
 func TestHeartbeatCronGatewayAPIRequests(t *testing.T) {
 	type observedRequest struct {
 		method  string
@@ -656,3 +846,61 @@ func containsSignal(signals []os.Signal, target os.Signal) bool {
 	}
 	return false
 }
+
+func TestRunInboxCommands(t *testing.T) {
+	inboxDir := filepath.Join(t.TempDir(), "inbox")
+	if err := os.MkdirAll(inboxDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	envelope := `{"id":"env-1","received_at":"2026-05-01T12:00:00Z","selected_agent":"main","channel":"telegram"}`
+	if err := os.WriteFile(filepath.Join(inboxDir, "item-1.json"), []byte(envelope), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Agents: &config.AgentsConfig{
+		CodexAppCDP: &config.CodexAppCDPConfig{Enabled: true, InboxPath: inboxDir},
+	}}
+
+	run := func(args ...string) (int, string) {
+		var output bytes.Buffer
+		logger := log.New(&output, "", 0)
+		code := runInboxCommand(cfg, args, &output, logger)
+		return code, output.String()
+	}
+
+	if code, output := run("list"); code != 0 || !strings.Contains(output, "item-1\tpending") || !strings.Contains(output, "agent=main") {
+		t.Fatalf("list code=%d output=%q", code, output)
+	}
+	if code, output := run("show", "--id", "item-1"); code != 0 || !strings.Contains(output, `"id":"env-1"`) {
+		t.Fatalf("show code=%d output=%q", code, output)
+	}
+	if code, output := run("ack", "--id", "item-1", "--failed", "--error", "stale"); code != 0 || !strings.Contains(output, "marked failed") {
+		t.Fatalf("ack code=%d output=%q", code, output)
+	}
+	if code, output := run("list", "--state", "pending"); code != 0 || !strings.Contains(output, "inbox is empty") {
+		t.Fatalf("pending list code=%d output=%q", code, output)
+	}
+	if code, output := run("requeue", "item-1"); code != 0 || !strings.Contains(output, "pending again") {
+		t.Fatalf("requeue code=%d output=%q", code, output)
+	}
+	if code, output := run("ack", "--runtime", "codexAppCDP", "--id", "item-1"); code != 0 || !strings.Contains(output, "marked done") {
+		t.Fatalf("ack done code=%d output=%q", code, output)
+	}
+	if code, output := run("purge", "--state", "done"); code != 0 || !strings.Contains(output, "Purged 1 done item(s)") {
+		t.Fatalf("purge code=%d output=%q", code, output)
+	}
+
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"ack"}, want: "--id is required"},
+		{args: []string{"list", "--runtime", "claudeDesktop"}, want: "no inbox configured for runtime claudeDesktop"},
+		{args: []string{"list", "--state", "bogus"}, want: "unknown inbox state"},
+		{args: []string{"show", "--id", "missing"}, want: "inbox item not found"},
+		{args: []string{"drain"}, want: "unknown inbox subcommand"},
+	} {
+		if code, output := run(test.args...); code == 0 || !strings.Contains(output, test.want) {
+			t.Fatalf("args=%v code=%d output=%q want=%q", test.args, code, output, test.want)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+// Agent registry statuses.
+const (
+	AgentStatusRunning     = "running"
+	AgentStatusBusy        = "busy"
+	AgentStatusStopped     = "stopped"
+	AgentStatusReady       = "ready"
+	AgentStatusUnavailable = "unavailable"
+	AgentStatusInbox       = "inbox"
+	AgentStatusUnknown     = "unknown"
+)
+
+// agentRegistryRefreshInterval is used when agents.registry.refreshIntervalSeconds
+// is unset.
+var agentRegistryRefreshInterval = 60 * time.Second
+
+var ohMyCodeListedAgentPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
+
+func agentRegistryInterval(cfg *config.AgentsConfig) time.Duration {
+	if cfg != nil && cfg.Registry.RefreshIntervalSeconds > 0 {
+		return time.Duration(cfg.Registry.RefreshIntervalSeconds) * time.Second
+	}
+	return agentRegistryRefreshInterval
+}
+
+func agentRegistryID(runtimeName, agentName string) string {
+	return runtimeName + ":" + agentName
+}
+
+func (m *Manager) startAgentRegistry(parent context.Context) {
+	m.stopAgentRegistry(context.Background())
+
+	registryCtx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	m.mu.Lock()
+	m.registryCancel = cancel
+	m.registryDone = done
+	m.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(agentRegistryInterval(m.config))
+		defer ticker.Stop()
+		m.RefreshAgents(registryCtx)
+		for {
+			select {
+			case <-registryCtx.Done():
+				return
+			case <-ticker.C:
+				m.RefreshAgents(registryCtx)
+			}
+		}
+	}()
+}
+
+func (m *Manager) stopAgentRegistry(ctx context.Context) {
+	m.mu.Lock()
+	cancel := m.registryCancel
+	done := m.registryDone
+	m.registryCancel = nil
+	m.registryDone = nil
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if done == nil {
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// RefreshAgents asks every enabled runtime for its agents and replaces the
+// registry. A runtime that fails to list keeps its previous entries.
+func (m *Manager) RefreshAgents(ctx context.Context) {
+	listed := make(map[string][]protocol.AgentInfo)
+	failed := make(map[string]bool)
+	for _, rt := range m.Runtimes() {
+		lister, ok := rt.(agentruntime.AgentLister)
+		if !ok || !rt.Enabled() {
+			continue
+		}
+		name := rt.Name()
+		agents, err := lister.ListAgents(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("agent registry: %s: %v", name, err)
+			failed[name] = true
+			continue
+		}
+		listed[name] = agents
+	}
+
+	m.routingMu.RLock()
+	activity := make(map[string]time.Time, len(m.agentActivity))
+	for id, at := range m.agentActivity {
+		activity[id] = at
+	}
+	m.routingMu.RUnlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	agents := make(map[string]protocol.AgentInfo)
+	for id, info := range m.agents {
+		if failed[info.Runtime] {
+			agents[id] = info
+		}
+	}
+	for runtimeName, infos := range listed {
+		for _, info := range infos {
+			info.Runtime = runtimeName
+			info.ID = agentRegistryID(runtimeName, info.Name)
+			if at, ok := activity[info.ID]; ok && (info.LastActivity == nil || at.After(*info.LastActivity)) {
+				at := at
+				info.LastActivity = &at
+			}
+			agents[info.ID] = info
+		}
+	}
+	m.agents = agents
+}
+
+// AgentBusy reports whether the registry last listed agentName as busy.
+// An empty runtimeName matches the agent on every runtime.
+func (m *Manager) AgentBusy(runtimeName, agentName string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if runtimeName != "" {
+		return m.agents[agentRegistryID(runtimeName, agentName)].Status == AgentStatusBusy
+	}
+	for _, info := range m.agents {
+		if info.Name == agentName && info.Status == AgentStatusBusy {
+			return true
+		}
+	}
+	return false
+}
+
+// recordAgentActivity notes that a message was routed to agentName.
+func (m *Manager) recordAgentActivity(runtimeName, agentName string, at time.Time) {
+	if agentName == "" {
+		return
+	}
+	id := agentRegistryID(runtimeName, agentName)
+	m.routingMu.Lock()
+	if m.agentActivity == nil {
+		m.agentActivity = make(map[string]time.Time)
+	}
+	m.agentActivity[id] = at
+	m.routingMu.Unlock()
+
+	m.mu.Lock()
+	if info, ok := m.agents[id]; ok {
+		info.LastActivity = &at
+		m.agents[id] = info
+	}
+	m.mu.Unlock()
+}
+
+// listOhMyCodeAgents runs agent-manager list in the top-level and every named
+// workspace. Configured agents missing from the output are listed with an
+// unknown status.
+func (m *Manager) listOhMyCodeAgents(ctx context.Context) ([]protocol.AgentInfo, error) {
+	cfg := m.config.OhMyCode
+	workspaceNames := m.ohMyCodeWorkspaceNames()
+	if strings.TrimSpace(cfg.Workspace) != "" {
+		workspaceNames = append([]string{""}, workspaceNames...)
+	}
+
+	var agents []protocol.AgentInfo
+	for _, workspaceName := range workspaceNames {
+		ws, err := m.ohMyCodeWorkspace(workspaceName)
+		if err != nil {
+			return nil, err
+		}
+		listCtx, cancel := context.WithTimeout(ctx, defaultOhMyCodeLifecycleTimeout)
+		output, err := runOhMyCodeAgentManager(listCtx, ws.Dir, ws.Script, "", "list")
+		cancel()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		configured := effectiveAgents(ws.DefaultAgent, ws.AllowedAgents)
+		var listed []ohMyCodeListedAgent
+		detail := ""
+		if err != nil {
+			detail = fmt.Sprintf("agent-manager list failed: %v", err)
+		} else {
+			listed = parseOhMyCodeAgentList(output, configured)
+		}
+
+		seen := make(map[string]bool)
+		for _, entry := range listed {
+			seen[entry.Name] = true
+			agents = append(agents, protocol.AgentInfo{Name: ws.qualify(entry.Name), Status: entry.Status})
+		}
+		for _, name := range configured {
+			if !seen[name] {
+				agents = append(agents, protocol.AgentInfo{Name: ws.qualify(name), Status: AgentStatusUnknown, Detail: detail})
+			}
+		}
+	}
+	return agents, nil
+}
+
+type ohMyCodeListedAgent struct {
+	Name   string
+	Status string
+}
+
+// parseOhMyCodeAgentList reads agent-manager list output. Each line naming an
+// agent is expected to carry a status word; lines without one are kept only
+// when they name a configured agent. Lines ending in ":" are headers.
+func parseOhMyCodeAgentList(output string, configured []string) []ohMyCodeListedAgent {
+	var agents []ohMyCodeListedAgent
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasSuffix(strings.TrimSpace(line), ":") {
+			continue
+		}
+		status := ohMyCodeListedStatus(line)
+		for _, field := range strings.Fields(line) {
+			name := strings.Trim(field, ":,;()[]*`'\"")
+			if !ohMyCodeListedAgentPattern.MatchString(name) || isOhMyCodeStatusWord(name) {
+				continue
+			}
+			if status == AgentStatusUnknown && !containsAgent(configured, name) {
+				break
+			}
+			if !seen[name] {
+				seen[name] = true
+				agents = append(agents, ohMyCodeListedAgent{Name: name, Status: status})
+			}
+			break
+		}
+	}
+	return agents
+}
+
+var (
+	ohMyCodeStoppedWords = []string{"not running", "stopped", "inactive", "offline", "exited", "dead"}
+	ohMyCodeRunningWords = []string{"running", "active", "online", "idle", "busy"}
+	ohMyCodeBusyWords    = []string{"busy"}
+)
+
+func ohMyCodeListedStatus(line string) string {
+	lower := strings.ToLower(line)
+	for _, word := range ohMyCodeStoppedWords {
+		if strings.Contains(lower, word) {
+			return AgentStatusStopped
+		}
+	}
+	for _, word := range ohMyCodeBusyWords {
+		if strings.Contains(lower, word) {
+			return AgentStatusBusy
+		}
+	}
+	for _, word := range ohMyCodeRunningWords {
+		if strings.Contains(lower, word) {
+			return AgentStatusRunning
+		}
+	}
+	return AgentStatusUnknown
+}
+
+func isOhMyCodeStatusWord(field string) bool {
+	lower := strings.ToLower(field)
+	return containsAgent(ohMyCodeStoppedWords, lower) || containsAgent(ohMyCodeRunningWords, lower) || lower == "not"
+}
+
+// listCodexAppAgents reports the configured agents with the readiness of the
+// Codex App and the conversation they deliver to.
+func (m *Manager) listCodexAppAgents(ctx context.Context) ([]protocol.AgentInfo, error) {
+	cfg := m.config.CodexAppCDP
+	ctx, cancel := context.WithTimeout(ctx, codexAppDeliveryTimeout(cfg))
+	defer cancel()
+
+	status := AgentStatusInbox
+	if strings.TrimSpace(cfg.CDPEndpoint) != "" {
+		available := false
+		if readiness := m.CodexAppCDPReadinessStatus(); readiness != nil && readiness.WatchRunning && !readiness.LastCheckedAt.IsZero() {
+			available = readiness.Available
+		} else {
+			available, _, _ = checkCodexAppCDPReady(ctx, cfg)
+		}
+		status = AgentStatusUnavailable
+		if available {
+			status = AgentStatusReady
+		}
+	}
+
+	detail := ""
+	var lastActivity *time.Time
+	conversationID, err := m.resolveCodexAppConversation(ctx, cfg)
+	switch {
+	case err != nil:
+		detail = fmt.Sprintf("conversation not resolved: %v", err)
+	case conversationID == "":
+		detail = "active thread"
+	default:
+		detail = "conversation " + conversationID
+		if resolved := m.CodexAppCDPResolvedConversationStatus(); resolved != nil && resolved.ID == conversationID {
+			if resolved.Title != "" {
+				detail = fmt.Sprintf("conversation %q", resolved.Title)
+			}
+			if !resolved.UpdatedAt.IsZero() {
+				updatedAt := resolved.UpdatedAt
+				lastActivity = &updatedAt
+			}
+		}
+	}
+
+	var agents []protocol.AgentInfo
+	for _, name := range effectiveAgents(cfg.DefaultAgent, cfg.AllowedAgents) {
+		agents = append(agents, protocol.AgentInfo{Name: name, Status: status, Detail: detail, LastActivity: lastActivity})
+	}
+	return agents, nil
+}
+
+// listClaudeDesktopAgents reports the configured agents and targets with the
+// readiness of Claude Desktop and the conversation each agent resolves to.
+func (m *Manager) listClaudeDesktopAgents(ctx context.Context) ([]protocol.AgentInfo, error) {
+	cfg := m.config.ClaudeDesktop
+
+	status := AgentStatusInbox
+	if strings.TrimSpace(cfg.CDPEndpoint) != "" {
+		available := false
+		if readiness := m.ClaudeDesktopReadinessStatus(); readiness != nil && readiness.WatchRunning && !readiness.LastCheckedAt.IsZero() {
+			available = readiness.Available
+		} else {
+			checkCtx, cancel := context.WithTimeout(ctx, claudeDesktopDeliveryTimeout(cfg))
+			_, chatTargets, err := checkClaudeDesktopCDPReady(checkCtx, cfg)
+			cancel()
+			available = err == nil && chatTargets > 0
+		}
+		status = AgentStatusUnavailable
+		if available {
+			status = AgentStatusReady
+		}
+	}
+
+	names := effectiveAgents(cfg.DefaultAgent, cfg.AllowedAgents)
+	for name := range cfg.Targets {
+		if !containsAgent(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var agents []protocol.AgentInfo
+	for _, name := range names {
+		target := m.resolveClaudeDesktopTarget(cfg, ClaudeDesktopEnvelope{SelectedAgent: name})
+		detail := "mode " + claudeDesktopConversationMode(cfg)
+		switch {
+		case target.ConversationID != "":
+			detail = "conversation " + target.ConversationID
+		case target.ProjectID != "":
+			detail = "project " + target.ProjectID
+		}
+		agents = append(agents, protocol.AgentInfo{Name: name, Status: status, Detail: detail})
+	}
+	return agents, nil
+}
+
+// effectiveAgents returns the allowlist, or the default agent alone
+// when no allowlist is configured.
+func effectiveAgents(defaultAgent string, allowedAgents []string) []string {
+	var agents []string
+	for _, name := range allowedAgents {
+		if trimmed := strings.TrimSpace(name); trimmed != "" && !containsAgent(agents, trimmed) {
+			agents = append(agents, trimmed)
+		}
+	}
+	if len(agents) == 0 {
+		if trimmed := strings.TrimSpace(defaultAgent); trimmed != "" {
+			agents = append(agents, trimmed)
+		}
+	}
+	return agents
+}
+
+func containsAgent(names []string, target string) bool {
+	for _, name := range names {
+		if name == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp/cdptest"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+const ohMyCodeListStub = `import sys
+
+if sys.argv[1] == "list":
+    print("Agents:")
+    print("  qa-1        running   (tmux: agent-qa-1)")
+    print("  coder-a     stopped")
+    print("  scratch     not running")
+    sys.exit(0)
+
+if sys.argv[1] == "assign":
+    sys.stdin.read()
+    print("assign ok")
+    sys.exit(0)
+
+sys.exit(1)
+`
+
+func registryEntry(t *testing.T, agents []protocol.AgentInfo, id string) protocol.AgentInfo {
+	t.Helper()
+	for _, info := range agents {
+		if info.ID == id {
+			return info
+		}
+	}
+	t.Fatalf("agent %s not in registry: %#v", id, agents)
+	return protocol.AgentInfo{}
+}
+
+func TestRefreshAgentsListsOhMyCodeWorkspaces(t *testing.T) {
+	workspace := t.TempDir()
+	scriptPath := filepath.Join(workspace, "agent_manager_stub.py")
+	if err := os.WriteFile(scriptPath, []byte(ohMyCodeListStub), 0644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	broken := t.TempDir()
+	manager := NewManager(&config.AgentsConfig{
+		OhMyCode: &config.OhMyCodeConfig{
+			Enabled:            true,
+			Workspace:          workspace,
+			AgentManagerScript: scriptPath,
+			DefaultAgent:       "qa-1",
+			AllowedAgents:      []string{"qa-1", "coder-a", "reviewer"},
+			Workspaces: map[string]config.OhMyCodeWorkspaceConfig{
+				"docs": {Workspace: broken, AgentManagerScript: filepath.Join(broken, "missing.py"), DefaultAgent: "writer"},
+			},
+		},
+	})
+
+	manager.RefreshAgents(context.Background())
+	agents := manager.List()
+	var ids []string
+	for _, info := range agents {
+		ids = append(ids, info.ID)
+	}
+	want := []string{"ohMyCode:coder-a", "ohMyCode:docs/writer", "ohMyCode:qa-1", "ohMyCode:reviewer", "ohMyCode:scratch"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("ids=%v want %v", ids, want)
+	}
+	if info := registryEntry(t, agents, "ohMyCode:qa-1"); info.Status != AgentStatusRunning || info.Runtime != "ohMyCode" || info.LastActivity != nil {
+		t.Fatalf("unexpected qa-1 entry: %#v", info)
+	}
+	if info := registryEntry(t, agents, "ohMyCode:coder-a"); info.Status != AgentStatusStopped {
+		t.Fatalf("unexpected coder-a entry: %#v", info)
+	}
+	if info := registryEntry(t, agents, "ohMyCode:scratch"); info.Status != AgentStatusStopped {
+		t.Fatalf("unexpected scratch entry: %#v", info)
+	}
+	if info := registryEntry(t, agents, "ohMyCode:reviewer"); info.Status != AgentStatusUnknown || info.Detail != "" {
+		t.Fatalf("unexpected reviewer entry: %#v", info)
+	}
+	if info := registryEntry(t, agents, "ohMyCode:docs/writer"); info.Status != AgentStatusUnknown || info.Detail == "" {
+		t.Fatalf("failed list should be reported in detail: %#v", info)
+	}
+
+	if _, err := manager.assignOhMyCode(context.Background(), "hello", "coder-a", nil); err != nil {
+		t.Fatalf("assignOhMyCode: %v", err)
+	}
+	if info := registryEntry(t, manager.List(), "ohMyCode:coder-a"); info.LastActivity == nil {
+		t.Fatalf("routing should record activity: %#v", info)
+	}
+	manager.RefreshAgents(context.Background())
+	if info := registryEntry(t, manager.List(), "ohMyCode:coder-a"); info.LastActivity == nil {
+		t.Fatalf("refresh should keep activity: %#v", info)
+	}
+}
+
+func TestRefreshAgentsReportsDesktopRuntimes(t *testing.T) {
+	server := cdptest.NewServer(t)
+	server.AddPage("Codex", "app://codex")
+	fake := &fakeClaudeDesktopCDP{loggedIn: true}
+	claude := httptest.NewServer(fake.handler())
+	defer claude.Close()
+
+	manager := NewManager(&config.AgentsConfig{
+		CodexAppCDP: &config.CodexAppCDPConfig{
+			Enabled:        true,
+			CDPEndpoint:    server.URL,
+			DefaultAgent:   "main",
+			ConversationID: "thread-1",
+		},
+		ClaudeDesktop: &config.ClaudeDesktopConfig{
+			Enabled:      true,
+			CDPEndpoint:  claude.URL,
+			DefaultAgent: "claude",
+			Targets: map[string]config.ClaudeDesktopTargetConfig{
+				"research": {ConversationID: "conv-9"},
+			},
+		},
+	})
+
+	manager.RefreshAgents(context.Background())
+	agents := manager.List()
+	if len(agents) != 3 {
+		t.Fatalf("unexpected registry: %#v", agents)
+	}
+	if info := registryEntry(t, agents, "codexAppCDP:main"); info.Status != AgentStatusReady || info.Detail != "conversation thread-1" {
+		t.Fatalf("unexpected codex entry: %#v", info)
+	}
+	if info := registryEntry(t, agents, "claudeDesktop:research"); info.Status != AgentStatusReady || info.Detail != "conversation conv-9" {
+		t.Fatalf("unexpected claude target entry: %#v", info)
+	}
+
+	server.SetDown(true)
+	fake.set(true, true)
+	manager.RefreshAgents(context.Background())
+	for _, info := range manager.List() {
+		if info.Status != AgentStatusUnavailable {
+			t.Fatalf("expected %s to be unavailable: %#v", info.ID, info)
+		}
+	}
+}
+
+func TestParseOhMyCodeAgentList(t *testing.T) {
+	output := "Running agents:\n- qa-1 [active]\n* coder-b: offline\n- coder-c (busy)\nreviewer\nnoise line\n"
+	got := parseOhMyCodeAgentList(output, []string{"reviewer"})
+	want := []ohMyCodeListedAgent{
+		{Name: "qa-1", Status: AgentStatusRunning},
+		{Name: "coder-b", Status: AgentStatusStopped},
+		{Name: "coder-c", Status: AgentStatusBusy},
+		{Name: "reviewer", Status: AgentStatusUnknown},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v want %#v", got, want)
+	}
+}
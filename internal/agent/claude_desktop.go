@@ -5,16 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
 	"github.com/fractalmind-ai/fractalbot/internal/channels"
 	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/inbox"
 )
 
 const (
@@ -27,14 +25,17 @@ const (
 type ClaudeDesktopEnvelope = InboundAppEnvelope
 
 type claudeDesktopDeliveryResult struct {
-	EnvelopeID string
-	Status     string
-	InboxPath  string
-	Error      error
+	EnvelopeID     string
+	Status         string
+	InboxPath      string
+	ConversationID string
+	Error          error
 }
 
 type claudeDesktopClient interface {
-	Deliver(context.Context, *config.ClaudeDesktopConfig, ClaudeDesktopEnvelope, string) error
+	// Deliver submits prompt to target and returns the conversation it landed
+	// in, or "" when that is unknown.
+	Deliver(context.Context, *config.ClaudeDesktopConfig, claudeDesktopTarget, string) (string, error)
 }
 
 type liveClaudeDesktopClient struct{}
@@ -73,6 +74,9 @@ func (m *Manager) assignClaudeDesktop(ctx context.Context, userText, agentOverri
 		return "", result.Error
 	}
 	m.recordRoutingOutcomeForBackend("claudeDesktop", inboundData, validatedName, result.Status, result.EnvelopeID, result.InboxPath, result.Error)
+	if result.Status == "delivered" {
+		m.startClaudeDesktopResponseRelay(cfg, envelope, result.ConversationID)
+	}
 	return claudeDesktopAssignAckMessage, nil
 }
 
@@ -106,12 +110,15 @@ func (m *Manager) deliverClaudeDesktopEnvelope(ctx context.Context, cfg *config.
 		if client == nil {
 			client = liveClaudeDesktopClient{}
 		}
-		if err := client.Deliver(deliveryCtx, cfg, envelope, prompt); err == nil {
+		target := m.resolveClaudeDesktopTarget(cfg, envelope)
+		conversationID, err := client.Deliver(deliveryCtx, cfg, target, prompt)
+		m.recordClaudeDesktopTarget(cfg, envelope, target, conversationID, err)
+		if err == nil {
 			result.Status = "delivered"
+			result.ConversationID = conversationID
 			return result
-		} else {
-			deliveryErr = err
 		}
+		deliveryErr = err
 	}
 
 	if strings.TrimSpace(cfg.InboxPath) != "" && (endpoint == "" || cfg.FallbackToInbox) {
@@ -206,86 +213,64 @@ func writeClaudeDesktopInboxEnvelope(inboxPath string, envelope ClaudeDesktopEnv
 	if strings.TrimSpace(inboxPath) == "" {
 		return "", errors.New("agents.claudeDesktop.inboxPath is required")
 	}
-	if err := os.MkdirAll(inboxPath, 0700); err != nil {
-		return "", fmt.Errorf("create Claude Desktop inbox: %w", err)
-	}
-	name := appInboxEnvelopeName(envelope)
-	finalPath := filepath.Join(inboxPath, name)
-	tmp, err := os.CreateTemp(inboxPath, "."+name+"-*.tmp")
+	path, err := inbox.Open(inboxPath).Put(appInboxEnvelopeName(envelope), claudeDesktopInboxEnvelope{Envelope: envelope, Prompt: prompt})
 	if err != nil {
-		return "", fmt.Errorf("create Claude Desktop inbox temp file: %w", err)
-	}
-	tmpPath := tmp.Name()
-	defer func() { _ = os.Remove(tmpPath) }()
-	if err := tmp.Chmod(0600); err != nil {
-		_ = tmp.Close()
-		return "", fmt.Errorf("set Claude Desktop inbox permissions: %w", err)
-	}
-	encoder := json.NewEncoder(tmp)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(claudeDesktopInboxEnvelope{Envelope: envelope, Prompt: prompt}); err != nil {
-		_ = tmp.Close()
-		return "", fmt.Errorf("encode Claude Desktop inbox envelope: %w", err)
-	}
-	if err := tmp.Close(); err != nil {
-		return "", fmt.Errorf("close Claude Desktop inbox envelope: %w", err)
+		return "", fmt.Errorf("Claude Desktop %w", err)
 	}
-	if err := os.Rename(tmpPath, finalPath); err != nil {
-		return "", fmt.Errorf("commit Claude Desktop inbox envelope: %w", err)
-	}
-	return finalPath, nil
+	return path, nil
 }
 
-func (liveClaudeDesktopClient) Deliver(ctx context.Context, cfg *config.ClaudeDesktopConfig, _ ClaudeDesktopEnvelope, prompt string) error {
-	target, err := selectClaudeDesktopCDPTarget(ctx, cfg.CDPEndpoint, cfg.TargetSelector)
+func (liveClaudeDesktopClient) Deliver(ctx context.Context, cfg *config.ClaudeDesktopConfig, conversation claudeDesktopTarget, prompt string) (string, error) {
+	target, err := selectClaudeDesktopConversationTarget(ctx, cfg, conversation.ConversationID)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if conversation.Path != "" {
+		// New conversations always start from a fresh page; a pinned one is
+		// only opened when the page shows something else.
+		parsed, _ := url.Parse(target.URL)
+		if conversation.ConversationID == "" || parsed == nil || !strings.HasPrefix(parsed.Path, conversation.Path) {
+			if err := openClaudeDesktopPath(ctx, target.WebSocketDebuggerURL, conversation.Path); err != nil {
+				return "", err
+			}
+		}
 	}
 	value, err := evaluateCDPValue(ctx, target.WebSocketDebuggerURL, buildClaudeDesktopDeliveryScript(prompt))
 	if err != nil {
-		return err
+		return "", err
+	}
+	if err := validateClaudeDesktopDeliveryValue(value); err != nil {
+		return "", err
+	}
+	switch {
+	case conversation.ConversationID != "":
+		return conversation.ConversationID, nil
+	case conversation.Path == "":
+		return claudeDesktopConversationIDFromURL(target.URL), nil
+	default:
+		return waitClaudeDesktopConversationID(ctx, target.WebSocketDebuggerURL), nil
 	}
-	return validateClaudeDesktopDeliveryValue(value)
 }
 
-func selectClaudeDesktopCDPTarget(ctx context.Context, endpoint, selector string) (cdpTarget, error) {
+func selectClaudeDesktopCDPTarget(ctx context.Context, endpoint, selector string) (cdp.Target, error) {
 	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
 	if endpoint == "" {
-		return cdpTarget{}, errors.New("agents.claudeDesktop.cdpEndpoint is required")
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/json/list", nil)
-	if err != nil {
-		return cdpTarget{}, err
+		return cdp.Target{}, errors.New("agents.claudeDesktop.cdpEndpoint is required")
 	}
-	resp, err := http.DefaultClient.Do(req)
+	targets, err := cdp.ListTargets(ctx, endpoint)
 	if err != nil {
-		return cdpTarget{}, fmt.Errorf("query Claude Desktop CDP targets: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return cdpTarget{}, fmt.Errorf("query Claude Desktop CDP targets: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-	var targets []cdpTarget
-	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
-		return cdpTarget{}, fmt.Errorf("decode Claude Desktop CDP targets: %w", err)
+		return cdp.Target{}, fmt.Errorf("query Claude Desktop CDP targets: %w", err)
 	}
-	selector = strings.TrimSpace(selector)
-	for _, target := range targets {
-		if !isClaudeDesktopChatTarget(target) {
-			continue
-		}
-		if selector == "" || strings.Contains(target.Title, selector) || strings.Contains(target.URL, selector) {
-			return target, nil
-		}
+	if target, ok := cdp.SelectTarget(targets, selector, isClaudeDesktopChatTarget); ok {
+		return target, nil
 	}
-	if selector != "" {
-		return cdpTarget{}, fmt.Errorf("no authenticated Claude Desktop CDP target matched %q", selector)
+	if selector = strings.TrimSpace(selector); selector != "" {
+		return cdp.Target{}, fmt.Errorf("no authenticated Claude Desktop CDP target matched %q", selector)
 	}
-	return cdpTarget{}, errors.New("Claude Desktop CDP has no authenticated chat target")
+	return cdp.Target{}, errors.New("Claude Desktop CDP has no authenticated chat target")
 }
 
-func isClaudeDesktopChatTarget(target cdpTarget) bool {
+func isClaudeDesktopChatTarget(target cdp.Target) bool {
 	if target.Type != "page" || target.WebSocketDebuggerURL == "" {
 		return false
 	}
@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// claudeDesktopCapturePollInterval is how often the chat page is read while
+// Claude is generating.
+var claudeDesktopCapturePollInterval = 2 * time.Second
+
+var claudeDesktopReadTurn = readClaudeDesktopTurn
+
+// claudeDesktopTurn is what the chat page shows for the turn an envelope
+// started.
+type claudeDesktopTurn struct {
+	// Found is false until the user message carrying the envelope is rendered.
+	Found     bool   `json:"found"`
+	Streaming bool   `json:"streaming"`
+	Markdown  string `json:"markdown"`
+}
+
+// startClaudeDesktopResponseRelay watches the chat page an envelope was
+// delivered to and relays Claude's reply when responseRelay is enabled.
+func (m *Manager) startClaudeDesktopResponseRelay(cfg *config.ClaudeDesktopConfig, envelope ClaudeDesktopEnvelope, conversationID string) {
+	if cfg == nil || !responseRelayApplies(cfg.ResponseRelay, envelope) {
+		return
+	}
+	m.startResponseRelay("claudeDesktop", cfg.ResponseRelay, envelope, func(ctx context.Context, send func(string) error) error {
+		text, err := m.captureClaudeDesktopTurn(ctx, cfg, envelope, conversationID)
+		if err != nil {
+			return err
+		}
+		return send(text)
+	})
+}
+
+// startClaudeDesktopHeartbeatReply watches the chat page a wakeup was
+// delivered to and reports Claude's answer to the heartbeat reply hook.
+func (m *Manager) startClaudeDesktopHeartbeatReply(cfg *config.ClaudeDesktopConfig, envelope ClaudeDesktopEnvelope, conversationID string, request agentruntime.DispatchRequest) {
+	if cfg == nil {
+		return
+	}
+	m.startHeartbeatReplyCapture("claudeDesktop", cfg.ResponseRelay, request, func(ctx context.Context) (string, error) {
+		return m.captureClaudeDesktopTurn(ctx, cfg, envelope, conversationID)
+	})
+}
+
+// captureClaudeDesktopTurn polls the chat page until the assistant turn that
+// follows envelope's message stops streaming, then returns it as Markdown. A
+// turn counts as finished once it is not streaming and unchanged across two
+// polls, which rides out the gap between tool calls.
+func (m *Manager) captureClaudeDesktopTurn(ctx context.Context, cfg *config.ClaudeDesktopConfig, envelope ClaudeDesktopEnvelope, conversationID string) (string, error) {
+	var previous string
+	var lastErr error
+	for {
+		turn, err := claudeDesktopReadTurn(ctx, cfg, conversationID, envelope.ID)
+		switch {
+		case err != nil:
+			// The page may be reloading or navigating to the new chat URL.
+			lastErr = err
+			previous = ""
+		case !turn.Found || turn.Streaming || strings.TrimSpace(turn.Markdown) == "":
+			lastErr = nil
+			previous = ""
+		case turn.Markdown == previous:
+			return turn.Markdown, nil
+		default:
+			lastErr = nil
+			previous = turn.Markdown
+		}
+		if err := sleepContext(ctx, claudeDesktopCapturePollInterval); err != nil {
+			if lastErr != nil {
+				return "", fmt.Errorf("%w (last read error: %v)", err, lastErr)
+			}
+			return "", err
+		}
+	}
+}
+
+func readClaudeDesktopTurn(ctx context.Context, cfg *config.ClaudeDesktopConfig, conversationID, envelopeID string) (claudeDesktopTurn, error) {
+	readCtx, cancel := context.WithTimeout(ctx, claudeDesktopDeliveryTimeout(cfg))
+	defer cancel()
+	target, err := selectClaudeDesktopConversationTarget(readCtx, cfg, conversationID)
+	if err != nil {
+		return claudeDesktopTurn{}, err
+	}
+	value, err := evaluateCDPValue(readCtx, target.WebSocketDebuggerURL, buildClaudeDesktopCaptureScript("envelope_id: "+envelopeID))
+	if err != nil {
+		return claudeDesktopTurn{}, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return claudeDesktopTurn{}, err
+	}
+	var turn claudeDesktopTurn
+	if err := json.Unmarshal(data, &turn); err != nil {
+		return claudeDesktopTurn{}, fmt.Errorf("Claude Desktop capture returned %T, expected object", value)
+	}
+	return turn, nil
+}
+
+// buildClaudeDesktopCaptureScript finds the last user message containing
+// marker and converts the assistant messages after it to Markdown.
+func buildClaudeDesktopCaptureScript(marker string) string {
+	encoded, _ := json.Marshal(marker)
+	return fmt.Sprintf(`(() => {
+  const marker = %s;
+  const url = String(location.href || "").toLowerCase();
+  if (/\/(login|logout|oauth)(?:[/?#]|$)/.test(url)) {
+    throw new Error("Claude Desktop is not on an authenticated chat page");
+  }
+  const userSelector = "[data-testid=user-message]";
+  const assistantSelector = ".font-claude-message,.font-claude-response,[data-testid=assistant-message]";
+  const messageSelector = userSelector + "," + assistantSelector;
+  const nodes = Array.from(document.querySelectorAll(messageSelector)).filter((node) => !node.parentElement || !node.parentElement.closest(messageSelector));
+  let start = -1;
+  nodes.forEach((node, index) => {
+    if (node.matches(userSelector) && String(node.innerText || node.textContent || "").includes(marker)) start = index;
+  });
+  if (start < 0) return { found: false, streaming: false, markdown: "" };
+  const turns = [];
+  for (let index = start + 1; index < nodes.length && !nodes[index].matches(userSelector); index++) {
+    turns.push(nodes[index]);
+  }
+  const stopButton = Array.from(document.querySelectorAll("button")).some((button) => /stop (response|generating)/i.test([button.getAttribute("aria-label"), button.getAttribute("title")].filter(Boolean).join(" ")));
+  const streaming = stopButton || turns.some((node) => node.closest("[data-is-streaming=true]") || node.querySelector("[data-is-streaming=true]"));
+
+  const fence = "\x60\x60\x60";
+  const tick = "\x60";
+  const block = (text) => "\n\n" + text.trim() + "\n\n";
+  const skip = (element) => element.matches("button,svg,style,script,[aria-hidden=true],.sr-only");
+  const convert = (node) => {
+    if (node.nodeType === Node.TEXT_NODE) {
+      const text = node.textContent || "";
+      return /^\s*\n\s*$/.test(text) ? "" : text.replace(/\s+/g, " ");
+    }
+    if (node.nodeType !== Node.ELEMENT_NODE || skip(node)) return "";
+    const children = () => Array.from(node.childNodes).map(convert).join("");
+    const tag = node.tagName.toLowerCase();
+    switch (tag) {
+      case "pre": {
+        const code = node.querySelector("code") || node;
+        const language = /language-([\w+#.-]+)/.exec(code.className || "");
+        const body = String(code.textContent || "").replace(/\n$/, "");
+        return "\n\n" + fence + (language ? language[1] : "") + "\n" + body + "\n" + fence + "\n\n";
+      }
+      case "code":
+        return tick + (node.textContent || "") + tick;
+      case "strong":
+      case "b":
+        return "**" + children() + "**";
+      case "em":
+      case "i":
+        return "*" + children() + "*";
+      case "del":
+      case "s":
+        return "~~" + children() + "~~";
+      case "a": {
+        const href = node.getAttribute("href") || "";
+        const text = children();
+        return href && href !== text ? "[" + text + "](" + href + ")" : text;
+      }
+      case "br":
+        return "\n";
+      case "hr":
+        return "\n\n---\n\n";
+      case "h1":
+      case "h2":
+      case "h3":
+      case "h4":
+      case "h5":
+      case "h6":
+        return block("#".repeat(Number(tag[1])) + " " + children());
+      case "p":
+        return block(children());
+      case "blockquote":
+        return block(children().trim().split("\n").map((line) => "> " + line).join("\n"));
+      case "ul":
+      case "ol": {
+        const first = Number(node.getAttribute("start") || 1);
+        const items = Array.from(node.children).filter((child) => child.tagName === "LI").map((item, index) => {
+          const bullet = tag === "ol" ? (first + index) + ". " : "- ";
+          const content = Array.from(item.childNodes).map(convert).join("").trim().replace(/\n{2,}/g, "\n");
+          return bullet + content.split("\n").join("\n" + " ".repeat(bullet.length));
+        });
+        return block(items.join("\n"));
+      }
+      case "table": {
+        const rows = Array.from(node.querySelectorAll("tr")).map((row) => "| " + Array.from(row.children).map((cell) => Array.from(cell.childNodes).map(convert).join("").trim().replace(/\|/g, "\\|")).join(" | ") + " |");
+        if (rows.length === 0) return "";
+        const columns = node.querySelector("tr").children.length;
+        rows.splice(1, 0, "|" + " --- |".repeat(columns));
+        return block(rows.join("\n"));
+      }
+      default:
+        return children();
+    }
+  };
+  const markdown = turns.map(convert).join("\n\n").replace(/[ \t]+\n/g, "\n").replace(/\n{3,}/g, "\n\n").trim();
+  return { found: true, streaming, markdown };
+})()`, string(encoded))
+}
@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+type recordingClaudeDesktopClient struct{}
+
+func (recordingClaudeDesktopClient) Deliver(context.Context, *config.ClaudeDesktopConfig, claudeDesktopTarget, string) (string, error) {
+	return "", nil
+}
+
+// stubClaudeDesktopTurns makes each page read return the next turn, repeating
+// the last one once the list is exhausted.
+func stubClaudeDesktopTurns(t *testing.T, turns ...claudeDesktopTurn) {
+	t.Helper()
+	oldRead := claudeDesktopReadTurn
+	oldInterval := claudeDesktopCapturePollInterval
+	t.Cleanup(func() {
+		claudeDesktopReadTurn = oldRead
+		claudeDesktopCapturePollInterval = oldInterval
+	})
+	claudeDesktopCapturePollInterval = 10 * time.Millisecond
+	var mu sync.Mutex
+	claudeDesktopReadTurn = func(ctx context.Context, cfg *config.ClaudeDesktopConfig, conversationID, envelopeID string) (claudeDesktopTurn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		turn := turns[0]
+		if len(turns) > 1 {
+			turns = turns[1:]
+		}
+		return turn, nil
+	}
+}
+
+func waitForMessages(t *testing.T, channel *recordingChannel, count int, timeout time.Duration) []channels.OutboundMessage {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if len(channel.messages()) >= count {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return channel.messages()
+}
+
+func newClaudeDesktopRelayManager(t *testing.T, relay config.ResponseRelayConfig) (*Manager, *recordingChannel) {
+	t.Helper()
+	channel := &recordingChannel{name: "slack"}
+	manager := NewManager(&config.AgentsConfig{
+		Router: "claudeDesktop",
+		ClaudeDesktop: &config.ClaudeDesktopConfig{
+			Enabled:       true,
+			CDPEndpoint:   "http://127.0.0.1:1",
+			DefaultAgent:  "main",
+			ResponseRelay: relay,
+		},
+	})
+	manager.claudeDesktopClient = recordingClaudeDesktopClient{}
+	manager.ChannelManager = channels.NewManager(&config.ChannelsConfig{}, nil)
+	if err := manager.ChannelManager.Register(channel); err != nil {
+		t.Fatalf("register channel: %v", err)
+	}
+	t.Cleanup(func() { manager.Stop(context.Background()) })
+	return manager, channel
+}
+
+func TestCaptureClaudeDesktopTurnWaitsForStableReply(t *testing.T) {
+	stubClaudeDesktopTurns(t,
+		claudeDesktopTurn{},
+		claudeDesktopTurn{Found: true, Streaming: true, Markdown: "Let me"},
+		claudeDesktopTurn{Found: true, Markdown: "Let me check."},
+		claudeDesktopTurn{Found: true, Streaming: true, Markdown: "Let me check.\n\n```go\nfmt.Println()\n```"},
+		claudeDesktopTurn{Found: true, Markdown: "Let me check.\n\n```go\nfmt.Println()\n```"},
+	)
+	manager := NewManager(&config.AgentsConfig{})
+	text, err := manager.captureClaudeDesktopTurn(context.Background(), &config.ClaudeDesktopConfig{}, ClaudeDesktopEnvelope{ID: "env-1"}, "")
+	if err != nil {
+		t.Fatalf("captureClaudeDesktopTurn: %v", err)
+	}
+	if text != "Let me check.\n\n```go\nfmt.Println()\n```" {
+		t.Fatalf("unexpected captured text: %q", text)
+	}
+}
+
+func TestHandleIncomingClaudeDesktopRelaysCapturedReply(t *testing.T) {
+	stubClaudeDesktopTurns(t,
+		claudeDesktopTurn{Found: true, Streaming: true, Markdown: "Wor"},
+		claudeDesktopTurn{Found: true, Markdown: "**Done.**"},
+	)
+	manager, channel := newClaudeDesktopRelayManager(t, config.ResponseRelayConfig{Enabled: true, TimeoutSeconds: 5})
+
+	if _, err := manager.HandleIncoming(context.Background(), &protocol.Message{Data: map[string]interface{}{
+		"channel":   "slack",
+		"chat_id":   "C1",
+		"thread_ts": "171.1",
+		"text":      "summarize",
+	}}); err != nil {
+		t.Fatalf("HandleIncoming: %v", err)
+	}
+	sent := waitForMessages(t, channel, 1, 3*time.Second)
+	if len(sent) != 1 || sent[0].To != "C1" || sent[0].ThreadTS != "171.1" || sent[0].Text != "**Done.**" {
+		t.Fatalf("unexpected relayed messages: %#v", sent)
+	}
+	if status := manager.ResponseRelayStatus("claudeDesktop"); status == nil || status.Relayed != 1 {
+		t.Fatalf("unexpected relay status: %#v", status)
+	}
+}
+
+func TestClaudeDesktopRelayPostsStillWorkingNotice(t *testing.T) {
+	start := time.Now()
+	oldRead := claudeDesktopReadTurn
+	oldInterval := claudeDesktopCapturePollInterval
+	defer func() {
+		claudeDesktopReadTurn = oldRead
+		claudeDesktopCapturePollInterval = oldInterval
+	}()
+	claudeDesktopCapturePollInterval = 10 * time.Millisecond
+	claudeDesktopReadTurn = func(ctx context.Context, cfg *config.ClaudeDesktopConfig, conversationID, envelopeID string) (claudeDesktopTurn, error) {
+		if time.Since(start) < 1500*time.Millisecond {
+			return claudeDesktopTurn{Found: true, Streaming: true, Markdown: "..."}, nil
+		}
+		return claudeDesktopTurn{Found: true, Markdown: "finally"}, nil
+	}
+	manager, channel := newClaudeDesktopRelayManager(t, config.ResponseRelayConfig{Enabled: true, StillWorkingSeconds: 1})
+
+	if _, err := manager.HandleIncoming(context.Background(), &protocol.Message{Data: map[string]interface{}{
+		"channel": "slack",
+		"chat_id": "C1",
+		"text":    "long task",
+	}}); err != nil {
+		t.Fatalf("HandleIncoming: %v", err)
+	}
+	sent := waitForMessages(t, channel, 2, 5*time.Second)
+	if len(sent) != 2 || !strings.Contains(sent[0].Text, "Still working") || sent[1].Text != "finally" {
+		t.Fatalf("unexpected relayed messages: %#v", sent)
+	}
+	if status := manager.ResponseRelayStatus("claudeDesktop"); status == nil || status.Relayed != 1 {
+		t.Fatalf("notice should not count as a relayed reply: %#v", status)
+	}
+}
+
+func TestClaudeDesktopCaptureScriptEmbedsMarker(t *testing.T) {
+	script := buildClaudeDesktopCaptureScript(`envelope_id: a"b`)
+	if !strings.Contains(script, `"envelope_id: a\"b"`) || strings.Contains(script, "%!") {
+		t.Fatalf("unexpected capture script: %s", script)
+	}
+}
@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+const claudeDesktopConversationIDWait = 10 * time.Second
+
+// claudeDesktopPollInterval paces page reads while waiting for navigation or
+// for a new conversation to get its URL.
+var claudeDesktopPollInterval = 250 * time.Millisecond
+
+var claudeDesktopChatPathPattern = regexp.MustCompile(`^/chat/([A-Za-z0-9-]+)`)
+
+// claudeDesktopTarget is the conversation an envelope is delivered to.
+type claudeDesktopTarget struct {
+	// Path is opened before delivery. Empty delivers to whatever chat the
+	// page shows.
+	Path           string
+	ConversationID string
+	ProjectID      string
+	// ThreadKey identifies the chat thread a new conversation is remembered
+	// for in perThread mode.
+	ThreadKey string
+	Source    string
+}
+
+// ClaudeDesktopResolvedConversationStatus is exposed in /status for target
+// observability when delivery resolves an envelope to a Claude conversation.
+type ClaudeDesktopResolvedConversationStatus struct {
+	Mode           string
+	Agent          string
+	ID             string
+	ProjectID      string
+	ThreadKey      string
+	Source         string
+	Threads        int
+	LastResolvedAt time.Time
+	LastError      string
+}
+
+func claudeDesktopConversationMode(cfg *config.ClaudeDesktopConfig) string {
+	if cfg == nil || strings.TrimSpace(cfg.ConversationMode) == "" {
+		return "active"
+	}
+	return strings.TrimSpace(cfg.ConversationMode)
+}
+
+// claudeDesktopThreadKey identifies the chat thread an envelope came from.
+// Heartbeat wakeups are keyed by job so each job keeps its own conversation.
+func claudeDesktopThreadKey(envelope ClaudeDesktopEnvelope) string {
+	if envelope.Source != "" {
+		if envelope.JobID == "" {
+			return ""
+		}
+		return envelope.Source + "/" + envelope.JobID
+	}
+	if envelope.Channel == "" || envelope.ChatID == "" {
+		return ""
+	}
+	key := envelope.Channel + "/" + envelope.ChatID
+	if envelope.ThreadTS != "" {
+		key += "/" + envelope.ThreadTS
+	}
+	return key
+}
+
+// resolveClaudeDesktopTarget picks the conversation for envelope. A pinned
+// agent conversation wins over the global pin, which wins over the mode.
+func (m *Manager) resolveClaudeDesktopTarget(cfg *config.ClaudeDesktopConfig, envelope ClaudeDesktopEnvelope) claudeDesktopTarget {
+	agentTarget := cfg.Targets[envelope.SelectedAgent]
+	projectID := strings.TrimSpace(agentTarget.ProjectID)
+	if id := strings.TrimSpace(agentTarget.ConversationID); id != "" {
+		return claudeDesktopTarget{Path: "/chat/" + id, ConversationID: id, ProjectID: projectID, Source: "agent"}
+	}
+	if id := strings.TrimSpace(cfg.ConversationID); id != "" {
+		return claudeDesktopTarget{Path: "/chat/" + id, ConversationID: id, ProjectID: projectID, Source: "config"}
+	}
+	newPath := "/new"
+	if projectID != "" {
+		newPath = "/project/" + projectID
+	}
+	switch claudeDesktopConversationMode(cfg) {
+	case "perThread":
+		key := claudeDesktopThreadKey(envelope)
+		if key == "" {
+			return claudeDesktopTarget{Path: newPath, ProjectID: projectID, Source: "new"}
+		}
+		m.mu.RLock()
+		id := m.claudeThreads[key]
+		m.mu.RUnlock()
+		if id != "" {
+			return claudeDesktopTarget{Path: "/chat/" + id, ConversationID: id, ProjectID: projectID, ThreadKey: key, Source: "thread"}
+		}
+		return claudeDesktopTarget{Path: newPath, ProjectID: projectID, ThreadKey: key, Source: "thread:new"}
+	case "new":
+		return claudeDesktopTarget{Path: newPath, ProjectID: projectID, Source: "new"}
+	default:
+		return claudeDesktopTarget{Source: "active"}
+	}
+}
+
+// recordClaudeDesktopTarget remembers the conversation a thread was started
+// in and updates the resolved conversation status.
+func (m *Manager) recordClaudeDesktopTarget(cfg *config.ClaudeDesktopConfig, envelope ClaudeDesktopEnvelope, target claudeDesktopTarget, conversationID string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil && target.ThreadKey != "" && conversationID != "" {
+		if m.claudeThreads == nil {
+			m.claudeThreads = make(map[string]string)
+		}
+		m.claudeThreads[target.ThreadKey] = conversationID
+	}
+	status := m.claudeResolvedTarget
+	if status == nil {
+		status = &ClaudeDesktopResolvedConversationStatus{}
+		m.claudeResolvedTarget = status
+	}
+	status.Mode = claudeDesktopConversationMode(cfg)
+	status.Agent = envelope.SelectedAgent
+	status.ID = conversationID
+	status.ProjectID = target.ProjectID
+	status.ThreadKey = target.ThreadKey
+	status.Source = target.Source
+	status.Threads = len(m.claudeThreads)
+	status.LastResolvedAt = time.Now().UTC()
+	status.LastError = errorString(err)
+	if err == nil && conversationID == "" && target.ThreadKey != "" {
+		status.LastError = "new conversation id was not detected; the next message in this thread starts another conversation"
+	}
+}
+
+func (m *Manager) ClaudeDesktopResolvedConversationStatus() *ClaudeDesktopResolvedConversationStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.claudeResolvedTarget == nil {
+		return nil
+	}
+	status := *m.claudeResolvedTarget
+	return &status
+}
+
+// selectClaudeDesktopConversationTarget prefers a page already showing
+// conversationID, then falls back to the configured target selector.
+func selectClaudeDesktopConversationTarget(ctx context.Context, cfg *config.ClaudeDesktopConfig, conversationID string) (cdp.Target, error) {
+	if conversationID != "" {
+		if target, err := selectClaudeDesktopCDPTarget(ctx, cfg.CDPEndpoint, "/chat/"+conversationID); err == nil {
+			return target, nil
+		}
+	}
+	return selectClaudeDesktopCDPTarget(ctx, cfg.CDPEndpoint, cfg.TargetSelector)
+}
+
+func claudeDesktopConversationIDFromURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	if match := claudeDesktopChatPathPattern.FindStringSubmatch(parsed.Path); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// openClaudeDesktopPath navigates the page to path and waits until its
+// compose box is ready.
+func openClaudeDesktopPath(ctx context.Context, wsURL, path string) error {
+	session := cdpSessions.Session(wsURL)
+	// The load event wakes the readiness check early; polling still covers
+	// client-side route changes that never fire it.
+	if err := session.Enable(ctx, "Page"); err != nil {
+		return fmt.Errorf("open Claude Desktop %s: %w", path, err)
+	}
+	loaded, stop := session.Subscribe(cdp.EventLoadFired)
+	defer stop()
+	if _, err := session.Evaluate(ctx, buildClaudeDesktopNavigateScript(path)); err != nil && !cdp.IsContextDestroyed(err) {
+		return fmt.Errorf("open Claude Desktop %s: %w", path, err)
+	}
+	for {
+		timer := time.NewTimer(claudeDesktopPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("open Claude Desktop %s: page did not become ready: %w", path, ctx.Err())
+		case <-loaded:
+			timer.Stop()
+		case <-timer.C:
+		}
+		// Reads fail while the old document is torn down; keep polling.
+		value, err := session.Evaluate(ctx, claudeDesktopPageStateScript)
+		if err != nil {
+			continue
+		}
+		state, _ := value.(map[string]interface{})
+		currentPath, _ := state["path"].(string)
+		ready, _ := state["ready"].(bool)
+		if ready && strings.HasPrefix(currentPath, path) {
+			return nil
+		}
+	}
+}
+
+// waitClaudeDesktopConversationID waits for a new conversation to move to its
+// /chat/<id> URL. It returns "" when the id does not show up in time.
+func waitClaudeDesktopConversationID(ctx context.Context, wsURL string) string {
+	ctx, cancel := context.WithTimeout(ctx, claudeDesktopConversationIDWait)
+	defer cancel()
+	for {
+		if value, err := evaluateCDPValue(ctx, wsURL, claudeDesktopPageStateScript); err == nil {
+			state, _ := value.(map[string]interface{})
+			currentPath, _ := state["path"].(string)
+			if match := claudeDesktopChatPathPattern.FindStringSubmatch(currentPath); match != nil {
+				return match[1]
+			}
+		}
+		if err := sleepContext(ctx, claudeDesktopPollInterval); err != nil {
+			return ""
+		}
+	}
+}
+
+func buildClaudeDesktopNavigateScript(path string) string {
+	encoded, _ := json.Marshal(path)
+	return fmt.Sprintf(`(() => {
+  location.assign(new URL(%s, location.origin).href);
+  return { ok: true };
+})()`, string(encoded))
+}
+
+const claudeDesktopPageStateScript = `(() => ({
+  path: String(location.pathname || ""),
+  ready: document.readyState === "complete" && !!document.querySelector("textarea,[contenteditable=true],div[role=textbox]")
+}))()`
@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+	"github.com/fractalmind-ai/fractalbot/internal/cdp/cdptest"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+func TestResolveClaudeDesktopTarget(t *testing.T) {
+	cfg := &config.ClaudeDesktopConfig{
+		ConversationMode: "perThread",
+		Targets: map[string]config.ClaudeDesktopTargetConfig{
+			"research": {ProjectID: "proj-1"},
+			"pinned":   {ConversationID: "conv-pinned"},
+		},
+	}
+	manager := NewManager(&config.AgentsConfig{})
+	thread := ClaudeDesktopEnvelope{SelectedAgent: "research", Channel: "slack", ChatID: "C1", ThreadTS: "171.1"}
+
+	first := manager.resolveClaudeDesktopTarget(cfg, thread)
+	if first.Path != "/project/proj-1" || first.ThreadKey != "slack/C1/171.1" || first.Source != "thread:new" {
+		t.Fatalf("unexpected new thread target: %#v", first)
+	}
+	manager.recordClaudeDesktopTarget(cfg, thread, first, "conv-1", nil)
+	second := manager.resolveClaudeDesktopTarget(cfg, thread)
+	if second.Path != "/chat/conv-1" || second.ConversationID != "conv-1" || second.Source != "thread" {
+		t.Fatalf("unexpected known thread target: %#v", second)
+	}
+	other := manager.resolveClaudeDesktopTarget(cfg, ClaudeDesktopEnvelope{SelectedAgent: "main", Channel: "slack", ChatID: "C2"})
+	if other.Path != "/new" || other.Source != "thread:new" {
+		t.Fatalf("unexpected other thread target: %#v", other)
+	}
+	pinned := manager.resolveClaudeDesktopTarget(cfg, ClaudeDesktopEnvelope{SelectedAgent: "pinned", Channel: "slack", ChatID: "C1"})
+	if pinned.Path != "/chat/conv-pinned" || pinned.Source != "agent" {
+		t.Fatalf("unexpected pinned target: %#v", pinned)
+	}
+
+	status := manager.ClaudeDesktopResolvedConversationStatus()
+	if status == nil || status.Mode != "perThread" || status.ID != "conv-1" || status.Threads != 1 || status.ProjectID != "proj-1" {
+		t.Fatalf("unexpected resolved status: %#v", status)
+	}
+
+	active := manager.resolveClaudeDesktopTarget(&config.ClaudeDesktopConfig{}, thread)
+	if active.Path != "" || active.Source != "active" {
+		t.Fatalf("unexpected active target: %#v", active)
+	}
+	configured := manager.resolveClaudeDesktopTarget(&config.ClaudeDesktopConfig{ConversationID: "conv-2", ConversationMode: "new"}, thread)
+	if configured.Path != "/chat/conv-2" || configured.Source != "config" {
+		t.Fatalf("unexpected configured target: %#v", configured)
+	}
+}
+
+// fakeClaudeDesktopPage is a CDP endpoint for one Claude page that follows
+// navigation and turns a new chat into /chat/conv-new once submitted.
+type fakeClaudeDesktopPage struct {
+	server *cdptest.Server
+	page   *cdptest.Page
+
+	mu          sync.Mutex
+	path        string
+	expressions []string
+}
+
+func newFakeClaudeDesktopPage(t *testing.T, path string) *fakeClaudeDesktopPage {
+	fake := &fakeClaudeDesktopPage{server: cdptest.NewServer(t), path: path}
+	fake.page = fake.server.AddPage("Claude", "https://claude.ai"+path)
+	fake.page.OnEvaluate(fake.evaluate)
+	return fake
+}
+
+func (p *fakeClaudeDesktopPage) evaluate(expression string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case strings.Contains(expression, "location.assign"):
+		p.expressions = append(p.expressions, "navigate")
+		var path string
+		start := strings.Index(expression, "new URL(") + len("new URL(")
+		_ = json.Unmarshal([]byte(expression[start:strings.Index(expression, ", location.origin")]), &path)
+		p.setPath(path)
+		p.page.Emit(cdp.EventLoadFired, map[string]interface{}{"timestamp": 1})
+		return map[string]interface{}{"ok": true}, nil
+	case strings.Contains(expression, "No visible Claude Desktop input found"):
+		p.expressions = append(p.expressions, "deliver")
+		if p.path == "/new" || strings.HasPrefix(p.path, "/project/") {
+			p.setPath("/chat/conv-new")
+		}
+		return map[string]interface{}{"ok": true, "submitted": true}, nil
+	default:
+		return map[string]interface{}{"path": p.path, "ready": true}, nil
+	}
+}
+
+func (p *fakeClaudeDesktopPage) setPath(path string) {
+	p.path = path
+	p.page.SetURL("https://claude.ai" + path)
+}
+
+func (p *fakeClaudeDesktopPage) steps() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	steps := make([]string, 0, len(p.expressions))
+	for _, step := range p.expressions {
+		if len(steps) == 0 || steps[len(steps)-1] != step {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+func TestHandleIncomingClaudeDesktopStartsConversationPerThread(t *testing.T) {
+	oldInterval := claudeDesktopPollInterval
+	claudeDesktopPollInterval = 10 * time.Millisecond
+	defer func() { claudeDesktopPollInterval = oldInterval }()
+
+	page := newFakeClaudeDesktopPage(t, "/chat/conv-old")
+	manager := NewManager(&config.AgentsConfig{
+		Router: "claudeDesktop",
+		ClaudeDesktop: &config.ClaudeDesktopConfig{
+			Enabled:          true,
+			CDPEndpoint:      page.server.URL,
+			DefaultAgent:     "main",
+			ConversationMode: "perThread",
+			Targets:          map[string]config.ClaudeDesktopTargetConfig{"main": {ProjectID: "proj-1"}},
+		},
+	})
+	message := func() *protocol.Message {
+		return &protocol.Message{Data: map[string]interface{}{"channel": "slack", "chat_id": "C1", "text": "hello"}}
+	}
+
+	if _, err := manager.HandleIncoming(context.Background(), message()); err != nil {
+		t.Fatalf("HandleIncoming: %v", err)
+	}
+	if got := strings.Join(page.steps(), ","); got != "navigate,deliver" {
+		t.Fatalf("unexpected first delivery steps: %s", got)
+	}
+	status := manager.ClaudeDesktopResolvedConversationStatus()
+	if status == nil || status.ID != "conv-new" || status.Source != "thread:new" || status.LastError != "" {
+		t.Fatalf("unexpected resolved status: %#v", status)
+	}
+
+	// The page already shows the thread's conversation, so no navigation.
+	if _, err := manager.HandleIncoming(context.Background(), message()); err != nil {
+		t.Fatalf("HandleIncoming: %v", err)
+	}
+	if got := strings.Join(page.steps(), ","); got != "navigate,deliver" {
+		t.Fatalf("unexpected second delivery steps: %s", got)
+	}
+	if status := manager.ClaudeDesktopResolvedConversationStatus(); status == nil || status.ID != "conv-new" || status.Source != "thread" {
+		t.Fatalf("unexpected resolved status: %#v", status)
+	}
+}
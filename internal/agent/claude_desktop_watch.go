@@ -0,0 +1,251 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+const (
+	defaultClaudeDesktopWatchInterval = 60 * time.Second
+	defaultClaudeDesktopHistorySize   = 20
+	claudeDesktopAlertTimeout         = 10 * time.Second
+)
+
+// ClaudeDesktopReadinessStatus is exposed in /status and /health for Claude
+// Desktop CDP observability.
+type ClaudeDesktopReadinessStatus struct {
+	Enabled         bool
+	Endpoint        string
+	Available       bool
+	TargetCount     int
+	ChatTargetCount int
+	WatchEnabled    bool
+	WatchRunning    bool
+	IntervalSeconds int
+	LastCheckedAt   time.Time
+	LastChangedAt   time.Time
+	LastError       string
+	// History lists readiness changes, oldest first.
+	History []ClaudeDesktopReadinessEvent
+}
+
+// ClaudeDesktopReadinessEvent records one readiness change.
+type ClaudeDesktopReadinessEvent struct {
+	At        time.Time
+	Available bool
+	Error     string
+}
+
+func claudeDesktopWatchEnabled(cfg *config.ClaudeDesktopConfig) bool {
+	if cfg == nil || !cfg.Enabled || strings.TrimSpace(cfg.CDPEndpoint) == "" {
+		return false
+	}
+	if cfg.Watch.Enabled == nil {
+		return true
+	}
+	return *cfg.Watch.Enabled
+}
+
+func claudeDesktopWatchInterval(cfg *config.ClaudeDesktopConfig) time.Duration {
+	if cfg != nil && cfg.Watch.IntervalSeconds > 0 {
+		return time.Duration(cfg.Watch.IntervalSeconds) * time.Second
+	}
+	return defaultClaudeDesktopWatchInterval
+}
+
+func claudeDesktopHistorySize(cfg *config.ClaudeDesktopConfig) int {
+	if cfg != nil && cfg.Watch.HistorySize > 0 {
+		return cfg.Watch.HistorySize
+	}
+	return defaultClaudeDesktopHistorySize
+}
+
+func (m *Manager) startClaudeDesktopWatch(parent context.Context, cfg *config.ClaudeDesktopConfig) {
+	if !claudeDesktopWatchEnabled(cfg) {
+		m.updateClaudeDesktopReadinessStatus(cfg, nil, false)
+		return
+	}
+	m.stopClaudeDesktopWatch(context.Background())
+
+	watchCtx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	m.mu.Lock()
+	m.claudeMonitorCancel = cancel
+	m.claudeMonitorDone = done
+	m.mu.Unlock()
+	m.updateClaudeDesktopReadinessStatus(cfg, nil, true)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(claudeDesktopWatchInterval(cfg))
+		defer ticker.Stop()
+		m.checkClaudeDesktopReadiness(watchCtx, cfg)
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+				m.checkClaudeDesktopReadiness(watchCtx, cfg)
+			}
+		}
+	}()
+}
+
+func (m *Manager) stopClaudeDesktopWatch(ctx context.Context) {
+	m.mu.Lock()
+	cancel := m.claudeMonitorCancel
+	done := m.claudeMonitorDone
+	m.claudeMonitorCancel = nil
+	m.claudeMonitorDone = nil
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if done == nil {
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	m.mu.Lock()
+	if m.claudeReadiness != nil {
+		m.claudeReadiness.WatchRunning = false
+	}
+	m.mu.Unlock()
+}
+
+// checkClaudeDesktopReadiness probes CDP once, records the result, and alerts
+// the admin chat when readiness changed. The first check only alerts when
+// Claude Desktop is unavailable.
+func (m *Manager) checkClaudeDesktopReadiness(ctx context.Context, cfg *config.ClaudeDesktopConfig) {
+	checkCtx, cancel := context.WithTimeout(ctx, claudeDesktopDeliveryTimeout(cfg))
+	targetCount, chatCount, err := checkClaudeDesktopCDPReady(checkCtx, cfg)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	available := err == nil
+	now := time.Now().UTC()
+	changed := false
+	m.updateClaudeDesktopReadinessStatus(cfg, func(status *ClaudeDesktopReadinessStatus) {
+		first := status.LastCheckedAt.IsZero()
+		changed = (first && !available) || (!first && status.Available != available)
+		status.Available = available
+		status.TargetCount = targetCount
+		status.ChatTargetCount = chatCount
+		status.LastCheckedAt = now
+		status.LastError = errorString(err)
+		if first || changed {
+			status.LastChangedAt = now
+			status.History = append(status.History, ClaudeDesktopReadinessEvent{At: now, Available: available, Error: errorString(err)})
+			if limit := claudeDesktopHistorySize(cfg); len(status.History) > limit {
+				status.History = append([]ClaudeDesktopReadinessEvent(nil), status.History[len(status.History)-limit:]...)
+			}
+		}
+	}, false)
+	if changed {
+		if available {
+			log.Printf("Claude Desktop CDP is available (%d chat targets)", chatCount)
+		} else {
+			log.Printf("Claude Desktop CDP is unavailable: %v", err)
+		}
+		m.alertClaudeDesktopReadiness(ctx, cfg, available, chatCount, err)
+	}
+}
+
+func (m *Manager) alertClaudeDesktopReadiness(ctx context.Context, cfg *config.ClaudeDesktopConfig, available bool, chatCount int, checkErr error) {
+	channelName := strings.TrimSpace(cfg.Watch.Alert.Channel)
+	chatID := strings.TrimSpace(cfg.Watch.Alert.ChatID)
+	if channelName == "" || chatID == "" || m.ChannelManager == nil {
+		return
+	}
+	channel := m.ChannelManager.Get(channelName)
+	if channel == nil {
+		log.Printf("Claude Desktop readiness alert: channel %s is not registered", channelName)
+		return
+	}
+	text := fmt.Sprintf("✅ Claude Desktop is available again (%d chat targets).", chatCount)
+	if !available {
+		text = fmt.Sprintf("⚠️ Claude Desktop is unavailable: %v", checkErr)
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, claudeDesktopAlertTimeout)
+	defer cancel()
+	if _, err := channel.Send(sendCtx, channels.OutboundMessage{To: chatID, Text: text}); err != nil {
+		log.Printf("Claude Desktop readiness alert: notify %s chat %s: %v", channelName, chatID, err)
+	}
+}
+
+// checkClaudeDesktopCDPReady returns the number of debuggable targets and of
+// signed-in Claude chat targets.
+func checkClaudeDesktopCDPReady(ctx context.Context, cfg *config.ClaudeDesktopConfig) (int, int, error) {
+	endpoint := strings.TrimRight(strings.TrimSpace(cfg.CDPEndpoint), "/")
+	if endpoint == "" {
+		return 0, 0, errors.New("agents.claudeDesktop.cdpEndpoint is required")
+	}
+	if _, err := cdp.Version(ctx, endpoint); err != nil {
+		return 0, 0, err
+	}
+	targets, err := cdp.ListTargets(ctx, endpoint)
+	if err != nil {
+		return 0, 0, err
+	}
+	count, chatCount := 0, 0
+	for _, target := range targets {
+		if target.WebSocketDebuggerURL == "" {
+			continue
+		}
+		count++
+		if isClaudeDesktopChatTarget(target) {
+			chatCount++
+		}
+	}
+	if count == 0 {
+		return 0, 0, errors.New("Claude Desktop CDP has no debuggable targets")
+	}
+	if chatCount == 0 {
+		return count, 0, errors.New("Claude Desktop CDP has no authenticated chat target")
+	}
+	return count, chatCount, nil
+}
+
+func (m *Manager) updateClaudeDesktopReadinessStatus(cfg *config.ClaudeDesktopConfig, update func(*ClaudeDesktopReadinessStatus), watchRunning bool) {
+	if cfg == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := m.claudeReadiness
+	if status == nil {
+		status = &ClaudeDesktopReadinessStatus{}
+		m.claudeReadiness = status
+	}
+	status.Enabled = cfg.Enabled
+	status.Endpoint = strings.TrimSpace(cfg.CDPEndpoint)
+	status.WatchEnabled = claudeDesktopWatchEnabled(cfg)
+	status.WatchRunning = watchRunning || (m.claudeMonitorCancel != nil)
+	status.IntervalSeconds = int(claudeDesktopWatchInterval(cfg) / time.Second)
+	if update != nil {
+		update(status)
+	}
+}
+
+func (m *Manager) ClaudeDesktopReadinessStatus() *ClaudeDesktopReadinessStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.claudeReadiness == nil {
+		return nil
+	}
+	status := *m.claudeReadiness
+	status.History = append([]ClaudeDesktopReadinessEvent(nil), m.claudeReadiness.History...)
+	return &status
+}
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// fakeClaudeDesktopCDP serves /json/list with a chat page that can be signed
+// out or taken down.
+type fakeClaudeDesktopCDP struct {
+	mu       sync.Mutex
+	down     bool
+	loggedIn bool
+}
+
+func (f *fakeClaudeDesktopCDP) set(down, loggedIn bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+	f.loggedIn = loggedIn
+}
+
+func (f *fakeClaudeDesktopCDP) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		down, loggedIn := f.down, f.loggedIn
+		f.mu.Unlock()
+		if down {
+			http.Error(w, "gone", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/json/version" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"Browser": "Claude"})
+			return
+		}
+		url := "https://claude.ai/login"
+		if loggedIn {
+			url = "https://claude.ai/new"
+		}
+		_ = json.NewEncoder(w).Encode([]cdp.Target{{Type: "page", Title: "Claude", URL: url, WebSocketDebuggerURL: "ws://127.0.0.1:1/devtools/page/1"}})
+	})
+	return mux
+}
+
+func TestCheckClaudeDesktopReadinessRecordsHistoryAndAlerts(t *testing.T) {
+	fake := &fakeClaudeDesktopCDP{loggedIn: true}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	cfg := &config.ClaudeDesktopConfig{
+		Enabled:     true,
+		CDPEndpoint: server.URL,
+		Watch: config.ClaudeDesktopWatchConfig{
+			HistorySize: 2,
+			Alert:       config.ReadinessAlertConfig{Channel: "telegram", ChatID: "admin"},
+		},
+	}
+	channel := &recordingChannel{name: "telegram"}
+	manager := NewManager(&config.AgentsConfig{ClaudeDesktop: cfg})
+	manager.ChannelManager = channels.NewManager(&config.ChannelsConfig{}, nil)
+	if err := manager.ChannelManager.Register(channel); err != nil {
+		t.Fatalf("register channel: %v", err)
+	}
+
+	manager.checkClaudeDesktopReadiness(context.Background(), cfg)
+	manager.checkClaudeDesktopReadiness(context.Background(), cfg)
+	status := manager.ClaudeDesktopReadinessStatus()
+	if status == nil || !status.Available || status.ChatTargetCount != 1 || len(status.History) != 1 {
+		t.Fatalf("unexpected status after healthy checks: %#v", status)
+	}
+	if sent := channel.messages(); len(sent) != 0 {
+		t.Fatalf("healthy startup should not alert: %#v", sent)
+	}
+
+	fake.set(false, false)
+	manager.checkClaudeDesktopReadiness(context.Background(), cfg)
+	status = manager.ClaudeDesktopReadinessStatus()
+	if status.Available || status.TargetCount != 1 || !strings.Contains(status.LastError, "no authenticated chat target") {
+		t.Fatalf("unexpected status after sign-out: %#v", status)
+	}
+
+	fake.set(true, true)
+	manager.checkClaudeDesktopReadiness(context.Background(), cfg)
+	fake.set(false, true)
+	manager.checkClaudeDesktopReadiness(context.Background(), cfg)
+
+	status = manager.ClaudeDesktopReadinessStatus()
+	if !status.Available || len(status.History) != 2 || status.History[0].Available || !status.History[1].Available {
+		t.Fatalf("unexpected history: %#v", status.History)
+	}
+	sent := channel.messages()
+	if len(sent) != 2 || sent[0].To != "admin" || !strings.Contains(sent[0].Text, "unavailable") || !strings.Contains(sent[1].Text, "available again") {
+		t.Fatalf("unexpected alerts: %#v", sent)
+	}
+}
+
+func TestStartRunsClaudeDesktopWatch(t *testing.T) {
+	server := httptest.NewServer((&fakeClaudeDesktopCDP{down: true}).handler())
+	defer server.Close()
+
+	manager := NewManager(&config.AgentsConfig{ClaudeDesktop: &config.ClaudeDesktopConfig{
+		Enabled:     true,
+		CDPEndpoint: server.URL,
+		Watch:       config.ClaudeDesktopWatchConfig{IntervalSeconds: 1},
+	}})
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := manager.ClaudeDesktopReadinessStatus(); status != nil && !status.LastCheckedAt.IsZero() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	status := manager.ClaudeDesktopReadinessStatus()
+	if status == nil || !status.WatchRunning || status.Available || status.IntervalSeconds != 1 || !strings.Contains(status.LastError, "HTTP 503") {
+		t.Fatalf("unexpected readiness: %#v", status)
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if status := manager.ClaudeDesktopReadinessStatus(); status.WatchRunning {
+		t.Fatalf("watch still running after Stop: %#v", status)
+	}
+}
@@ -8,8 +8,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
@@ -19,10 +17,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
 	"github.com/fractalmind-ai/fractalbot/internal/channels"
 	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/inbox"
 	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
-	"github.com/gorilla/websocket"
 )
 
 const (
@@ -169,6 +168,9 @@ func (m *Manager) assignCodexAppCDP(ctx context.Context, userText, agentOverride
 	}
 
 	m.recordRoutingOutcomeForBackend("codexAppCDP", inboundData, validatedName, result.Status, result.EnvelopeID, result.InboxPath, result.Error)
+	if result.CDPDelivered {
+		m.startCodexAppResponseRelay(cfg, envelope, result.ConversationID)
+	}
 	return codexAppAssignAckMessage, nil
 }
 
@@ -193,41 +195,18 @@ func (m *Manager) deliverCodexAppEnvelope(ctx context.Context, cfg *config.Codex
 
 	endpoint := strings.TrimSpace(cfg.CDPEndpoint)
 	if endpoint != "" {
-		deliveryCfg := *cfg
-		deliveryCtx := ctx
-		if timeout := codexAppDeliveryTimeout(cfg); timeout > 0 {
-			var cancel context.CancelFunc
-			deliveryCtx, cancel = context.WithTimeout(ctx, timeout)
-			defer cancel()
-		}
-		if codexAppCheckOnIncoming(cfg) {
-			deliveryErr = m.ensureCodexAppCDPReady(deliveryCtx, cfg, "incoming")
-		}
-		if deliveryErr == nil {
-			if conversationID, err := m.resolveCodexAppConversation(deliveryCtx, cfg); err != nil {
-				deliveryErr = err
-			} else if strings.TrimSpace(conversationID) != "" {
-				deliveryCfg.ConversationID = conversationID
-			}
-		}
-		if deliveryErr == nil {
-			client := m.codexAppCDPClient
-			if client == nil {
-				client = liveCodexAppCDPClient{}
-			}
-			if err := client.Deliver(deliveryCtx, &deliveryCfg, envelope, prompt); err == nil {
-				result.Status = "delivered"
-				result.CDPDelivered = true
-				result.ConversationID = strings.TrimSpace(deliveryCfg.ConversationID)
-				return result
-			} else {
-				deliveryErr = err
-			}
+		conversationID, err := m.deliverCodexAppCDP(ctx, cfg, envelope, prompt)
+		if err == nil {
+			result.Status = "delivered"
+			result.CDPDelivered = true
+			result.ConversationID = conversationID
+			return result
 		}
+		deliveryErr = err
 	}
 
 	if strings.TrimSpace(cfg.InboxPath) != "" && (endpoint == "" || cfg.FallbackToInbox) {
-		path, err := writeCodexAppInboxEnvelope(cfg.InboxPath, envelope)
+		path, err := writeCodexAppInboxEnvelope(cfg.InboxPath, envelope, prompt)
 		result.InboxPath = path
 		if err != nil {
 			if deliveryErr != nil {
@@ -254,6 +233,37 @@ func (m *Manager) deliverCodexAppEnvelope(ctx context.Context, cfg *config.Codex
 	return result
 }
 
+// deliverCodexAppCDP delivers one envelope over CDP and returns the
+// conversation it was delivered to.
+func (m *Manager) deliverCodexAppCDP(ctx context.Context, cfg *config.CodexAppCDPConfig, envelope CodexAppEnvelope, prompt string) (string, error) {
+	deliveryCfg := *cfg
+	if timeout := codexAppDeliveryTimeout(cfg); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if codexAppCheckOnIncoming(cfg) {
+		if err := m.ensureCodexAppCDPReady(ctx, cfg, "incoming"); err != nil {
+			return "", err
+		}
+	}
+	conversationID, err := m.resolveCodexAppConversation(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(conversationID) != "" {
+		deliveryCfg.ConversationID = conversationID
+	}
+	client := m.codexAppCDPClient
+	if client == nil {
+		client = liveCodexAppCDPClient{}
+	}
+	if err := client.Deliver(ctx, &deliveryCfg, envelope, prompt); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(deliveryCfg.ConversationID), nil
+}
+
 func codexAppDeliveryTimeout(cfg *config.CodexAppCDPConfig) time.Duration {
 	if cfg != nil && cfg.DeliveryTimeoutSeconds > 0 {
 		return time.Duration(cfg.DeliveryTimeoutSeconds) * time.Second
@@ -318,10 +328,25 @@ func (m *Manager) startCodexAppCDPWatch(parent context.Context, cfg *config.Code
 	m.mu.Unlock()
 	m.updateCodexAppCDPReadinessStatus(cfg, nil, true)
 
+	redelivery, redeliver := m.codexAppInboxRedelivery(cfg)
 	go func() {
 		defer close(done)
 		interval := codexAppWatchInterval(cfg)
-		m.ensureCodexAppCDPReadyWithTimeout(watchCtx, cfg, "watch")
+		// ready starts false so envelopes queued before startup are drained on
+		// the first successful check.
+		ready := false
+		check := func() {
+			err := m.ensureCodexAppCDPReadyWithTimeout(watchCtx, cfg, "watch")
+			recovered := err == nil && !ready
+			ready = err == nil
+			if recovered && redeliver {
+				// Retry on the next tick if an envelope could not be delivered.
+				if err := m.redeliverInbox(watchCtx, redelivery); err != nil {
+					ready = false
+				}
+			}
+		}
+		check()
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for {
@@ -329,7 +354,7 @@ func (m *Manager) startCodexAppCDPWatch(parent context.Context, cfg *config.Code
 			case <-watchCtx.Done():
 				return
 			case <-ticker.C:
-				m.ensureCodexAppCDPReadyWithTimeout(watchCtx, cfg, "watch")
+				check()
 			}
 		}
 	}()
@@ -450,7 +475,7 @@ func (m *Manager) ensureCodexAppCDPReady(ctx context.Context, cfg *config.CodexA
 		return checkErr
 	}
 
-	repairErr := repairCodexAppCDP(ctx, cfg, policy)
+	repairErr := codexAppRepair(ctx, cfg, policy)
 	m.updateCodexAppCDPReadinessStatus(cfg, func(status *CodexAppCDPReadinessStatus) {
 		status.LastRepairAt = time.Now().UTC()
 		status.LastRepairAction = policy
@@ -494,11 +519,11 @@ func checkCodexAppCDPReady(ctx context.Context, cfg *config.CodexAppCDPConfig) (
 	if endpoint == "" {
 		return false, 0, errors.New("agents.codexAppCDP.cdpEndpoint is required")
 	}
-	if err := getCodexAppCDPJSON(ctx, endpoint+"/json/version", nil); err != nil {
+	if _, err := cdp.Version(ctx, endpoint); err != nil {
 		return false, 0, err
 	}
-	var targets []cdpTarget
-	if err := getCodexAppCDPJSON(ctx, endpoint+"/json/list", &targets); err != nil {
+	targets, err := cdp.ListTargets(ctx, endpoint)
+	if err != nil {
 		return false, 0, err
 	}
 	count := 0
@@ -534,29 +559,11 @@ func waitCodexAppCDPReady(ctx context.Context, cfg *config.CodexAppCDPConfig) (b
 	}
 }
 
-func getCodexAppCDPJSON(ctx context.Context, endpoint string, dest interface{}) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return err
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("query CDP endpoint %s: %w", endpoint, err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return fmt.Errorf("query CDP endpoint %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-	if dest == nil {
-		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 512))
-		return nil
-	}
-	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
-		return fmt.Errorf("decode CDP endpoint %s: %w", endpoint, err)
-	}
-	return nil
-}
+// codexAppRepair and codexAppQuit are replaced in tests.
+var (
+	codexAppRepair = repairCodexAppCDP
+	codexAppQuit   = quitCodexApp
+)
 
 func repairCodexAppCDP(ctx context.Context, cfg *config.CodexAppCDPConfig, policy string) error {
 	if runtime.GOOS != "darwin" {
@@ -570,7 +577,7 @@ func repairCodexAppCDP(ctx context.Context, cfg *config.CodexAppCDPConfig, polic
 	case codexAppCDPRepairNewInstance:
 		return openCodexAppWithCDP(ctx, port)
 	case codexAppCDPRepairRelaunch:
-		_ = exec.CommandContext(ctx, "osascript", "-e", `tell application "Codex" to quit`).Run()
+		_ = quitCodexApp(ctx)
 		time.Sleep(2 * time.Second)
 		return openCodexAppWithCDP(ctx, port)
 	default:
@@ -578,6 +585,16 @@ func repairCodexAppCDP(ctx context.Context, cfg *config.CodexAppCDPConfig, polic
 	}
 }
 
+func quitCodexApp(ctx context.Context) error {
+	if runtime.GOOS != "darwin" {
+		return errors.New("quitting Codex App is only supported on macOS")
+	}
+	if output, err := exec.CommandContext(ctx, "osascript", "-e", `tell application "Codex" to quit`).CombinedOutput(); err != nil {
+		return fmt.Errorf("quit Codex App: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func openCodexAppWithCDP(ctx context.Context, port string) error {
 	cmd := exec.CommandContext(ctx, "open", "-na", defaultCodexAppPath, "--args", "--remote-debugging-port="+port)
 	if output, err := cmd.CombinedOutput(); err != nil {
@@ -962,35 +979,22 @@ func newEnvelopeID() string {
 	return hex.EncodeToString(buf)
 }
 
-func writeCodexAppInboxEnvelope(inboxPath string, envelope CodexAppEnvelope) (string, error) {
+// codexAppInboxEnvelope keeps the envelope fields at the top level for
+// existing inbox consumers and adds the prompt used for redelivery.
+type codexAppInboxEnvelope struct {
+	CodexAppEnvelope
+	Prompt string `json:"prompt,omitempty"`
+}
+
+func writeCodexAppInboxEnvelope(inboxPath string, envelope CodexAppEnvelope, prompt string) (string, error) {
 	if strings.TrimSpace(inboxPath) == "" {
 		return "", errors.New("agents.codexAppCDP.inboxPath is required")
 	}
-	if err := os.MkdirAll(inboxPath, 0700); err != nil {
-		return "", fmt.Errorf("create Codex App inbox: %w", err)
-	}
-	name := appInboxEnvelopeName(envelope)
-	finalPath := filepath.Join(inboxPath, name)
-	tmp, err := os.CreateTemp(inboxPath, "."+name+"-*.tmp")
+	path, err := inbox.Open(inboxPath).Put(appInboxEnvelopeName(envelope), codexAppInboxEnvelope{CodexAppEnvelope: envelope, Prompt: prompt})
 	if err != nil {
-		return "", fmt.Errorf("create Codex App inbox temp file: %w", err)
-	}
-	tmpPath := tmp.Name()
-	defer func() { _ = os.Remove(tmpPath) }()
-
-	encoder := json.NewEncoder(tmp)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(envelope); err != nil {
-		_ = tmp.Close()
-		return "", fmt.Errorf("encode Codex App envelope: %w", err)
-	}
-	if err := tmp.Close(); err != nil {
-		return "", fmt.Errorf("close Codex App inbox temp file: %w", err)
+		return "", fmt.Errorf("Codex App %w", err)
 	}
-	if err := os.Rename(tmpPath, finalPath); err != nil {
-		return "", fmt.Errorf("commit Codex App inbox envelope: %w", err)
-	}
-	return finalPath, nil
+	return path, nil
 }
 
 func appInboxEnvelopeName(envelope InboundAppEnvelope) string {
@@ -1001,13 +1005,6 @@ func appInboxEnvelopeName(envelope InboundAppEnvelope) string {
 	return fmt.Sprintf("%s-%s.json", time.Now().UTC().Format("20060102T150405.000000000Z"), envelope.ID)
 }
 
-type cdpTarget struct {
-	Type                 string `json:"type"`
-	Title                string `json:"title"`
-	URL                  string `json:"url"`
-	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
-}
-
 func (liveCodexAppCDPClient) Deliver(ctx context.Context, cfg *config.CodexAppCDPConfig, envelope CodexAppEnvelope, prompt string) error {
 	target, err := selectCodexAppCDPTarget(ctx, cfg.CDPEndpoint, cfg.TargetSelector)
 	if err != nil {
@@ -1026,49 +1023,35 @@ func (liveCodexAppCDPClient) Deliver(ctx context.Context, cfg *config.CodexAppCD
 	}
 }
 
-func selectCodexAppCDPTarget(ctx context.Context, endpoint, selector string) (cdpTarget, error) {
+func selectCodexAppCDPTarget(ctx context.Context, endpoint, selector string) (cdp.Target, error) {
 	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
 	if endpoint == "" {
-		return cdpTarget{}, errors.New("agents.codexAppCDP.cdpEndpoint is required")
-	}
-	listURL := endpoint + "/json/list"
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
-	if err != nil {
-		return cdpTarget{}, err
+		return cdp.Target{}, errors.New("agents.codexAppCDP.cdpEndpoint is required")
 	}
-	resp, err := http.DefaultClient.Do(req)
+	targets, err := cdp.ListTargets(ctx, endpoint)
 	if err != nil {
-		return cdpTarget{}, fmt.Errorf("query CDP targets: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return cdpTarget{}, fmt.Errorf("query CDP targets: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-	var targets []cdpTarget
-	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
-		return cdpTarget{}, fmt.Errorf("decode CDP targets: %w", err)
+		return cdp.Target{}, fmt.Errorf("query CDP targets: %w", err)
 	}
 	selector = strings.TrimSpace(selector)
-	candidates := make([]cdpTarget, 0, len(targets))
-	for _, target := range targets {
-		if target.WebSocketDebuggerURL == "" {
-			continue
-		}
-		if selector != "" && (strings.Contains(target.Title, selector) || strings.Contains(target.URL, selector)) {
+	if selector != "" {
+		if target, ok := cdp.SelectTarget(targets, selector, nil); ok {
 			return target, nil
 		}
-		candidates = append(candidates, target)
-	}
-	if selector == "" {
+	} else {
+		candidates := make([]cdp.Target, 0, len(targets))
+		for _, target := range targets {
+			if target.WebSocketDebuggerURL != "" {
+				candidates = append(candidates, target)
+			}
+		}
 		if target, ok := preferredCodexAppCDPTarget(candidates); ok {
 			return target, nil
 		}
 	}
-	return cdpTarget{}, fmt.Errorf("no Codex App CDP target matched %q", selector)
+	return cdp.Target{}, fmt.Errorf("no Codex App CDP target matched %q", selector)
 }
 
-func preferredCodexAppCDPTarget(targets []cdpTarget) (cdpTarget, bool) {
+func preferredCodexAppCDPTarget(targets []cdp.Target) (cdp.Target, bool) {
 	for _, target := range targets {
 		if target.Type == "page" && target.URL == "app://-/index.html" {
 			return target, true
@@ -1089,7 +1072,7 @@ func preferredCodexAppCDPTarget(targets []cdpTarget) (cdpTarget, bool) {
 			return target, true
 		}
 	}
-	return cdpTarget{}, false
+	return cdp.Target{}, false
 }
 
 func evaluateCDPExpression(ctx context.Context, wsURL, expression, expectedConversationID string) error {
@@ -1100,46 +1083,12 @@ func evaluateCDPExpression(ctx context.Context, wsURL, expression, expectedConve
 	return validateCodexAppDeliveryValue(value, expectedConversationID)
 }
 
-func evaluateCDPValue(ctx context.Context, wsURL, expression string) (interface{}, error) {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("connect CDP websocket: %w", err)
-	}
-	defer conn.Close()
+// cdpSessions holds one CDP session per desktop app target, shared by
+// deliveries, readiness probes and response capture.
+var cdpSessions = cdp.NewPool()
 
-	deadline, ok := ctx.Deadline()
-	if ok {
-		_ = conn.SetReadDeadline(deadline)
-		_ = conn.SetWriteDeadline(deadline)
-	}
-	request := map[string]interface{}{
-		"id":     1,
-		"method": "Runtime.evaluate",
-		"params": map[string]interface{}{
-			"expression":    expression,
-			"awaitPromise":  true,
-			"returnByValue": true,
-		},
-	}
-	if err := conn.WriteJSON(request); err != nil {
-		return nil, fmt.Errorf("send CDP Runtime.evaluate: %w", err)
-	}
-	for {
-		var response cdpEvaluateResponse
-		if err := conn.ReadJSON(&response); err != nil {
-			return nil, fmt.Errorf("read CDP Runtime.evaluate response: %w", err)
-		}
-		if response.ID != 1 {
-			continue
-		}
-		if response.Error != nil {
-			return nil, fmt.Errorf("CDP Runtime.evaluate failed: %s", response.Error.Message)
-		}
-		if response.Result.ExceptionDetails != nil {
-			return nil, fmt.Errorf("Codex App delivery script failed: %s", response.Result.ExceptionDetails.Text)
-		}
-		return response.Result.Result.Value, nil
-	}
+func evaluateCDPValue(ctx context.Context, wsURL, expression string) (interface{}, error) {
+	return cdpSessions.Session(wsURL).Evaluate(ctx, expression)
 }
 
 func validateCodexAppDeliveryValue(value interface{}, expectedConversationID string) error {
@@ -1228,22 +1177,6 @@ func codexAppBridgeErrorDetail(value interface{}) string {
 	return "empty result"
 }
 
-type cdpEvaluateResponse struct {
-	ID    int `json:"id"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
-	Result struct {
-		Result struct {
-			Type  string      `json:"type"`
-			Value interface{} `json:"value,omitempty"`
-		} `json:"result"`
-		ExceptionDetails *struct {
-			Text string `json:"text"`
-		} `json:"exceptionDetails,omitempty"`
-	} `json:"result"`
-}
-
 func buildCodexAppDeliveryScript(cfg *config.CodexAppCDPConfig, envelope CodexAppEnvelope, prompt string) string {
 	hostID := defaultCodexAppHostID
 	conversationID := ""
@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// codexAppRelayPollInterval is how often the conversation rollout is re-read
+// while a turn is in progress.
+var codexAppRelayPollInterval = time.Second
+
+var codexAppRolloutPath = queryCodexAppRolloutPath
+
+var codexAppConversationIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// codexAppRolloutEvent is one line of a Codex conversation rollout file.
+type codexAppRolloutEvent struct {
+	Type    string `json:"type"`
+	Payload struct {
+		Type             string `json:"type"`
+		Message          string `json:"message"`
+		LastAgentMessage string `json:"last_agent_message"`
+	} `json:"payload"`
+}
+
+// startCodexAppResponseRelay follows the conversation an envelope was
+// delivered to and relays the reply when responseRelay is enabled.
+func (m *Manager) startCodexAppResponseRelay(cfg *config.CodexAppCDPConfig, envelope CodexAppEnvelope, conversationID string) {
+	if cfg == nil || !responseRelayApplies(cfg.ResponseRelay, envelope) {
+		return
+	}
+	m.startResponseRelay("codexAppCDP", cfg.ResponseRelay, envelope, func(ctx context.Context, send func(string) error) error {
+		if strings.TrimSpace(conversationID) == "" {
+			return errors.New("Codex App conversation is unknown; configure targetProject or conversationId to relay replies")
+		}
+		stream := cfg.ResponseRelay.Stream
+		lastSent := ""
+		return m.followCodexAppTurn(ctx, cfg, envelope, conversationID, func(text string, final bool) error {
+			text = strings.TrimSpace(text)
+			if text == "" || (final && text == lastSent) || (!final && !stream) {
+				return nil
+			}
+			lastSent = text
+			return send(text)
+		})
+	})
+}
+
+// startCodexAppHeartbeatReply follows the conversation a wakeup was
+// delivered to and reports the agent's final answer to the heartbeat reply
+// hook.
+func (m *Manager) startCodexAppHeartbeatReply(cfg *config.CodexAppCDPConfig, envelope CodexAppEnvelope, conversationID string, request agentruntime.DispatchRequest) {
+	if cfg == nil || strings.TrimSpace(conversationID) == "" {
+		return
+	}
+	m.startHeartbeatReplyCapture("codexAppCDP", cfg.ResponseRelay, request, func(ctx context.Context) (string, error) {
+		var reply string
+		err := m.followCodexAppTurn(ctx, cfg, envelope, conversationID, func(text string, final bool) error {
+			if final {
+				reply = text
+			}
+			return nil
+		})
+		return reply, err
+	})
+}
+
+// followCodexAppTurn reads the conversation rollout until the turn started by
+// envelope finishes. emit receives each assistant message and then the final
+// one.
+func (m *Manager) followCodexAppTurn(ctx context.Context, cfg *config.CodexAppCDPConfig, envelope CodexAppEnvelope, conversationID string, emit func(text string, final bool) error) error {
+	path, err := waitCodexAppRolloutPath(ctx, cfg, conversationID)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open Codex App rollout: %w", err)
+	}
+	defer file.Close()
+
+	marker := "envelope_id: " + envelope.ID
+	matched := false
+	lastMessage := ""
+	reader := bufio.NewReader(file)
+	var pending []byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			pending = append(pending, line...)
+		}
+		if errors.Is(err, io.EOF) {
+			// Wait for the rest of a partially written line.
+			if err := sleepContext(ctx, codexAppRelayPollInterval); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read Codex App rollout: %w", err)
+		}
+
+		var event codexAppRolloutEvent
+		decodeErr := json.Unmarshal(pending, &event)
+		pending = pending[:0]
+		if decodeErr != nil || event.Type != "event_msg" {
+			continue
+		}
+		switch event.Payload.Type {
+		case "user_message":
+			if !matched {
+				matched = strings.Contains(event.Payload.Message, marker)
+			}
+		case "agent_message":
+			if matched {
+				lastMessage = event.Payload.Message
+				if err := emit(lastMessage, false); err != nil {
+					return err
+				}
+			}
+		case "task_complete":
+			if matched {
+				final := event.Payload.LastAgentMessage
+				if strings.TrimSpace(final) == "" {
+					final = lastMessage
+				}
+				return emit(final, true)
+			}
+		case "turn_aborted":
+			if matched {
+				return errors.New("Codex App turn was aborted")
+			}
+		}
+	}
+}
+
+// waitCodexAppRolloutPath retries until the state DB knows the conversation's
+// rollout file; a new conversation may not be indexed yet.
+func waitCodexAppRolloutPath(ctx context.Context, cfg *config.CodexAppCDPConfig, conversationID string) (string, error) {
+	for {
+		path, err := codexAppRolloutPath(ctx, cfg, conversationID)
+		if err == nil && strings.TrimSpace(path) != "" {
+			return path, nil
+		}
+		if sleepErr := sleepContext(ctx, codexAppRelayPollInterval); sleepErr != nil {
+			if err != nil {
+				return "", err
+			}
+			return "", sleepErr
+		}
+	}
+}
+
+func queryCodexAppRolloutPath(ctx context.Context, cfg *config.CodexAppCDPConfig, conversationID string) (string, error) {
+	if !codexAppConversationIDPattern.MatchString(conversationID) {
+		return "", fmt.Errorf("invalid Codex App conversation id %q", conversationID)
+	}
+	dbPath, err := codexAppStateDBPath(cfg)
+	if err != nil {
+		return "", err
+	}
+	query := fmt.Sprintf(`select rollout_path from threads where id='%s' limit 1;`, conversationID)
+	output, err := exec.CommandContext(ctx, "sqlite3", "-json", dbPath, query).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("query Codex App state DB %s: %w: %s", dbPath, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("query Codex App state DB %s: %w", dbPath, err)
+	}
+	var rows []struct {
+		RolloutPath string `json:"rollout_path"`
+	}
+	if len(strings.TrimSpace(string(output))) == 0 {
+		return "", nil
+	}
+	if err := json.Unmarshal(output, &rows); err != nil {
+		return "", fmt.Errorf("decode Codex App rollout path: %w", err)
+	}
+	if len(rows) == 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(rows[0].RolloutPath), nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+func rolloutLine(t *testing.T, payload map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"type": "event_msg", "payload": payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+func stubCodexAppRollout(t *testing.T, path string) {
+	t.Helper()
+	oldPath := codexAppRolloutPath
+	oldInterval := codexAppRelayPollInterval
+	t.Cleanup(func() {
+		codexAppRolloutPath = oldPath
+		codexAppRelayPollInterval = oldInterval
+	})
+	codexAppRelayPollInterval = 10 * time.Millisecond
+	codexAppRolloutPath = func(ctx context.Context, cfg *config.CodexAppCDPConfig, conversationID string) (string, error) {
+		if conversationID != "thread-1" {
+			t.Errorf("conversationID=%q", conversationID)
+		}
+		return path, nil
+	}
+}
+
+func TestFollowCodexAppTurnSkipsEarlierTurns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	content := rolloutLine(t, map[string]string{"type": "user_message", "message": "- envelope_id: other"}) +
+		rolloutLine(t, map[string]string{"type": "agent_message", "message": "old answer"}) +
+		rolloutLine(t, map[string]string{"type": "task_complete", "last_agent_message": "old answer"}) +
+		rolloutLine(t, map[string]string{"type": "user_message", "message": "- envelope_id: env-1\nhello"}) +
+		rolloutLine(t, map[string]string{"type": "agent_message", "message": "thinking"}) +
+		rolloutLine(t, map[string]string{"type": "task_complete", "last_agent_message": "final answer"})
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stubCodexAppRollout(t, path)
+
+	var emitted []string
+	manager := NewManager(&config.AgentsConfig{})
+	err := manager.followCodexAppTurn(context.Background(), &config.CodexAppCDPConfig{}, CodexAppEnvelope{ID: "env-1"}, "thread-1", func(text string, final bool) error {
+		emitted = append(emitted, text+"|"+map[bool]string{true: "final", false: "partial"}[final])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("followCodexAppTurn: %v", err)
+	}
+	if strings.Join(emitted, ",") != "thinking|partial,final answer|final" {
+		t.Fatalf("unexpected emitted messages: %v", emitted)
+	}
+}
+
+func TestHandleIncomingCodexAppCDPRelaysReplyToChat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stubCodexAppRollout(t, path)
+
+	channel := &recordingChannel{name: "telegram"}
+	manager := NewManager(&config.AgentsConfig{
+		Router: "codexAppCDP",
+		CodexAppCDP: &config.CodexAppCDPConfig{
+			Enabled:        true,
+			CDPEndpoint:    "http://127.0.0.1:1",
+			ConversationID: "thread-1",
+			DefaultAgent:   "main",
+			RepairPolicy:   "off",
+			ResponseRelay:  config.ResponseRelayConfig{Enabled: true, TimeoutSeconds: 5},
+		},
+	})
+	manager.codexAppCDPClient = &recordingCodexAppCDPClient{}
+	manager.ChannelManager = channels.NewManager(&config.ChannelsConfig{}, nil)
+	if err := manager.ChannelManager.Register(channel); err != nil {
+		t.Fatalf("register channel: %v", err)
+	}
+	defer manager.Stop(context.Background())
+
+	if _, err := manager.HandleIncoming(context.Background(), &protocol.Message{
+		Data: map[string]interface{}{
+			"channel": "telegram",
+			"chat_id": "42",
+			"text":    "what is the status?",
+		},
+	}); err != nil {
+		t.Fatalf("HandleIncoming: %v", err)
+	}
+	outcome := manager.LastRoutingOutcome()
+	if outcome == nil || outcome.Status != "delivered" {
+		t.Fatalf("unexpected outcome: %#v", outcome)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(rolloutLine(t, map[string]string{"type": "user_message", "message": "- envelope_id: " + outcome.EnvelopeID}))
+	_, _ = file.WriteString(rolloutLine(t, map[string]string{"type": "agent_message", "message": "checking"}))
+	_, _ = file.WriteString(rolloutLine(t, map[string]string{"type": "task_complete", "last_agent_message": "All green."}))
+	_ = file.Close()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(channel.messages()) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sent := channel.messages()
+	if len(sent) != 1 || sent[0].To != "42" || sent[0].Text != "All green." {
+		t.Fatalf("unexpected relayed messages: %#v", sent)
+	}
+	if status := manager.ResponseRelayStatus("codexAppCDP"); status == nil || status.Relayed != 1 {
+		t.Fatalf("unexpected relay status: %#v", status)
+	}
+}
+
+func TestResponseRelayAppliesOnlyToConfiguredChatRoutes(t *testing.T) {
+	chat := InboundAppEnvelope{Channel: "slack", ChatID: "C1"}
+	if responseRelayApplies(config.ResponseRelayConfig{}, chat) {
+		t.Fatal("expected disabled relay to skip")
+	}
+	if !responseRelayApplies(config.ResponseRelayConfig{Enabled: true}, chat) {
+		t.Fatal("expected relay for any channel")
+	}
+	if responseRelayApplies(config.ResponseRelayConfig{Enabled: true, Channels: []string{"telegram"}}, chat) {
+		t.Fatal("expected channel filter to skip slack")
+	}
+	if responseRelayApplies(config.ResponseRelayConfig{Enabled: true}, InboundAppEnvelope{Source: "heartbeat", Channel: "slack", ChatID: "C1"}) {
+		t.Fatal("expected heartbeat wakeups to skip")
+	}
+}
@@ -0,0 +1,301 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/inbox"
+)
+
+const (
+	inboxRedeliveryOwner           = "fractalbot-redelivery"
+	inboxRedeliveryLease           = 2 * time.Minute
+	defaultInboxRedeliveryInterval = 5 * time.Second
+	lateDeliveryNotifyTimeout      = 10 * time.Second
+)
+
+// InboxRedeliveryStatus is exposed in /status for each runtime that drains
+// its inbox after recovering.
+type InboxRedeliveryStatus struct {
+	Running        bool
+	LastStartedAt  time.Time
+	LastFinishedAt time.Time
+	Delivered      int
+	Skipped        int
+	LastError      string
+}
+
+// inboxRedelivery describes how to drain one runtime inbox.
+type inboxRedelivery struct {
+	runtime     string
+	displayName string
+	store       *inbox.Store
+	interval    time.Duration
+	notifyChat  bool
+
+	// prompt rebuilds the delivery prompt for envelopes queued without one.
+	prompt  func(envelope InboundAppEnvelope) string
+	deliver func(ctx context.Context, envelope InboundAppEnvelope, prompt string) error
+}
+
+type appInboxPayload struct {
+	Envelope *InboundAppEnvelope `json:"envelope"`
+	Prompt   string              `json:"prompt"`
+}
+
+// decodeAppInboxPayload accepts both inbox layouts: the Codex App layout with
+// envelope fields at the top level, and the Claude Desktop layout that nests
+// them under "envelope".
+func decodeAppInboxPayload(data []byte) (InboundAppEnvelope, string, error) {
+	var payload appInboxPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return InboundAppEnvelope{}, "", err
+	}
+	if payload.Envelope != nil {
+		return *payload.Envelope, payload.Prompt, nil
+	}
+	var envelope InboundAppEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return InboundAppEnvelope{}, "", err
+	}
+	return envelope, payload.Prompt, nil
+}
+
+// redeliverInbox delivers pending envelopes oldest first, one per interval.
+// It stops at the first delivery failure and leaves that envelope pending for
+// the next recovery. Envelopes whose ID was already delivered are marked done
+// without being delivered again.
+func (m *Manager) redeliverInbox(ctx context.Context, r inboxRedelivery) error {
+	if !m.beginInboxRedelivery(r.runtime) {
+		return nil
+	}
+	var delivered, skipped int
+	err := func() error {
+		if _, err := r.store.ReapExpired(); err != nil {
+			return err
+		}
+		seen, err := deliveredEnvelopeIDs(r.store)
+		if err != nil {
+			return err
+		}
+		for {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			item, err := r.store.Claim(inboxRedeliveryOwner, inboxRedeliveryLease+r.interval)
+			if errors.Is(err, inbox.ErrNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			// Pace deliveries, not skipped envelopes.
+			if delivered > 0 && r.interval > 0 {
+				timer := time.NewTimer(r.interval)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					_, _ = r.store.Requeue(item.ID)
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+			wasDelivered, err := m.redeliverInboxItem(ctx, r, item, seen)
+			if err != nil {
+				return err
+			}
+			if wasDelivered {
+				delivered++
+			} else {
+				skipped++
+			}
+		}
+	}()
+	if errors.Is(err, context.Canceled) {
+		err = nil
+	}
+	m.finishInboxRedelivery(r.runtime, delivered, skipped, err)
+	if delivered > 0 || err != nil {
+		log.Printf("%s inbox redelivery: delivered=%d skipped=%d error=%s", r.displayName, delivered, skipped, errorString(err))
+	}
+	return err
+}
+
+func (m *Manager) redeliverInboxItem(ctx context.Context, r inboxRedelivery, item inbox.Item, seen map[string]bool) (bool, error) {
+	_, data, err := r.store.Get(item.ID)
+	if err != nil {
+		return false, err
+	}
+	envelope, prompt, err := decodeAppInboxPayload(data)
+	if err != nil {
+		_, ackErr := r.store.Ack(item.ID, true, "invalid envelope: "+err.Error())
+		return false, ackErr
+	}
+	if envelope.ID != "" && seen[envelope.ID] {
+		_, ackErr := r.store.Ack(item.ID, false, "duplicate of delivered envelope "+envelope.ID)
+		return false, ackErr
+	}
+	if expiresAt, err := time.Parse(time.RFC3339Nano, envelope.ExpiresAt); err == nil && time.Now().After(expiresAt) {
+		_, ackErr := r.store.Ack(item.ID, true, "expired before redelivery")
+		return false, ackErr
+	}
+	if strings.TrimSpace(prompt) == "" && r.prompt != nil {
+		prompt = r.prompt(envelope)
+	}
+
+	if err := r.deliver(ctx, envelope, prompt); err != nil {
+		if _, requeueErr := r.store.Requeue(item.ID); requeueErr != nil {
+			return false, fmt.Errorf("redeliver %s: %v; requeue failed: %w", item.ID, err, requeueErr)
+		}
+		return false, fmt.Errorf("redeliver %s: %w", item.ID, err)
+	}
+	if _, err := r.store.Ack(item.ID, false, ""); err != nil {
+		return true, err
+	}
+	if envelope.ID != "" {
+		seen[envelope.ID] = true
+	}
+	if r.notifyChat {
+		m.notifyLateDelivery(ctx, r.displayName, envelope)
+	}
+	return true, nil
+}
+
+func deliveredEnvelopeIDs(store *inbox.Store) (map[string]bool, error) {
+	done, err := store.List(inbox.StateDone)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(done))
+	for _, item := range done {
+		if item.EnvelopeID != "" {
+			seen[item.EnvelopeID] = true
+		}
+	}
+	return seen, nil
+}
+
+// notifyLateDelivery tells the chat a queued message came from that it has
+// now reached the agent. Heartbeat wakeups have no originating chat.
+func (m *Manager) notifyLateDelivery(ctx context.Context, displayName string, envelope InboundAppEnvelope) {
+	channelName := strings.TrimSpace(envelope.Channel)
+	chatID := strings.TrimSpace(envelope.ChatID)
+	if envelope.Source != "" || channelName == "" || chatID == "" || m.ChannelManager == nil {
+		return
+	}
+	channel := m.ChannelManager.Get(channelName)
+	if channel == nil {
+		return
+	}
+	queuedAt := envelope.ReceivedAt
+	if parsed, err := time.Parse(time.RFC3339Nano, envelope.ReceivedAt); err == nil {
+		queuedAt = parsed.UTC().Format("2006-01-02 15:04 MST")
+	}
+	text := fmt.Sprintf("📬 Your message from %s was delivered late to %s after %s became available again.", queuedAt, defaultPromptContextValue(envelope.SelectedAgent), displayName)
+
+	sendCtx, cancel := context.WithTimeout(ctx, lateDeliveryNotifyTimeout)
+	defer cancel()
+	if _, err := channel.Send(sendCtx, channels.OutboundMessage{To: chatID, Text: text, ThreadTS: envelope.ThreadTS}); err != nil {
+		log.Printf("%s inbox redelivery: notify %s chat %s: %v", displayName, channelName, chatID, err)
+	}
+}
+
+func (m *Manager) beginInboxRedelivery(runtimeName string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.redelivery == nil {
+		m.redelivery = make(map[string]*InboxRedeliveryStatus)
+	}
+	status := m.redelivery[runtimeName]
+	if status == nil {
+		status = &InboxRedeliveryStatus{}
+		m.redelivery[runtimeName] = status
+	}
+	if status.Running {
+		return false
+	}
+	status.Running = true
+	status.LastStartedAt = time.Now().UTC()
+	return true
+}
+
+func (m *Manager) finishInboxRedelivery(runtimeName string, delivered, skipped int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := m.redelivery[runtimeName]
+	if status == nil {
+		return
+	}
+	status.Running = false
+	status.LastFinishedAt = time.Now().UTC()
+	status.Delivered += delivered
+	status.Skipped += skipped
+	status.LastError = errorString(err)
+}
+
+// InboxRedeliveryStatus returns the redelivery status of a runtime, or nil if
+// its inbox has never been drained.
+func (m *Manager) InboxRedeliveryStatus(runtimeName string) *InboxRedeliveryStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status := m.redelivery[runtimeName]
+	if status == nil {
+		return nil
+	}
+	copied := *status
+	return &copied
+}
+
+func inboxRedeliveryEnabled(cfg config.InboxRedeliveryConfig, inboxPath string, fallbackToInbox bool) bool {
+	if strings.TrimSpace(inboxPath) == "" {
+		return false
+	}
+	if cfg.Enabled != nil {
+		return *cfg.Enabled
+	}
+	return fallbackToInbox
+}
+
+func inboxRedeliveryInterval(cfg config.InboxRedeliveryConfig) time.Duration {
+	if cfg.IntervalSeconds > 0 {
+		return time.Duration(cfg.IntervalSeconds) * time.Second
+	}
+	return defaultInboxRedeliveryInterval
+}
+
+func inboxRedeliveryNotifyChat(cfg config.InboxRedeliveryConfig) bool {
+	return cfg.NotifyChat == nil || *cfg.NotifyChat
+}
+
+// codexAppInboxRedelivery returns the redelivery settings for the Codex App
+// inbox, or false when redelivery is off.
+func (m *Manager) codexAppInboxRedelivery(cfg *config.CodexAppCDPConfig) (inboxRedelivery, bool) {
+	if cfg == nil || !inboxRedeliveryEnabled(cfg.Redelivery, cfg.InboxPath, cfg.FallbackToInbox) {
+		return inboxRedelivery{}, false
+	}
+	return inboxRedelivery{
+		runtime:     "codexAppCDP",
+		displayName: "Codex App",
+		store:       inbox.Open(cfg.InboxPath),
+		interval:    inboxRedeliveryInterval(cfg.Redelivery),
+		notifyChat:  inboxRedeliveryNotifyChat(cfg.Redelivery),
+		prompt: func(envelope InboundAppEnvelope) string {
+			return buildCodexAppPrompt(envelope, nil)
+		},
+		deliver: func(ctx context.Context, envelope InboundAppEnvelope, prompt string) error {
+			conversationID, err := m.deliverCodexAppCDP(ctx, cfg, envelope, prompt)
+			if err != nil {
+				return err
+			}
+			m.startCodexAppResponseRelay(cfg, envelope, conversationID)
+			return nil
+		},
+	}, true
+}
@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/inbox"
+)
+
+type recordingChannel struct {
+	name string
+
+	mu   sync.Mutex
+	sent []channels.OutboundMessage
+}
+
+func (c *recordingChannel) Name() string                    { return c.name }
+func (c *recordingChannel) Start(ctx context.Context) error { return nil }
+func (c *recordingChannel) Stop(ctx context.Context) error  { return nil }
+func (c *recordingChannel) IsRunning() bool                 { return true }
+func (c *recordingChannel) IsAllowed(senderID string) bool  { return true }
+
+func (c *recordingChannel) Send(ctx context.Context, msg channels.OutboundMessage) (*channels.SendResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, msg)
+	return &channels.SendResult{}, nil
+}
+
+func (c *recordingChannel) messages() []channels.OutboundMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]channels.OutboundMessage(nil), c.sent...)
+}
+
+func queueTestEnvelope(t *testing.T, inboxPath string, envelope InboundAppEnvelope, prompt string) {
+	t.Helper()
+	if _, err := writeCodexAppInboxEnvelope(inboxPath, envelope, prompt); err != nil {
+		t.Fatalf("queue envelope: %v", err)
+	}
+}
+
+func TestRedeliverInboxDeliversInOrderAndDedupes(t *testing.T) {
+	inboxPath := filepath.Join(t.TempDir(), "inbox")
+	queueTestEnvelope(t, inboxPath, InboundAppEnvelope{ID: "env-1", ReceivedAt: "2026-05-01T12:00:00Z", Channel: "telegram", ChatID: "42", SelectedAgent: "main", Text: "first"}, "prompt-1")
+	queueTestEnvelope(t, inboxPath, InboundAppEnvelope{ID: "env-1", ReceivedAt: "2026-05-01T12:00:01Z", Channel: "telegram", ChatID: "42", SelectedAgent: "main", Text: "first again"}, "prompt-1")
+	queueTestEnvelope(t, inboxPath, InboundAppEnvelope{ID: "env-2", ReceivedAt: "2026-05-01T12:00:02Z", Source: "heartbeat", JobID: "job", CoalesceKey: "job", ExpiresAt: "2026-05-01T12:05:00Z", SelectedAgent: "main", Text: "stale wakeup"}, "heartbeat")
+	queueTestEnvelope(t, inboxPath, InboundAppEnvelope{ID: "env-3", ReceivedAt: "2026-05-01T12:00:03Z", Channel: "telegram", ChatID: "42", SelectedAgent: "main", Text: "second"}, "")
+
+	channel := &recordingChannel{name: "telegram"}
+	manager := NewManager(&config.AgentsConfig{})
+	manager.ChannelManager = channels.NewManager(&config.ChannelsConfig{}, nil)
+	if err := manager.ChannelManager.Register(channel); err != nil {
+		t.Fatalf("register channel: %v", err)
+	}
+
+	var delivered []string
+	store := inbox.Open(inboxPath)
+	err := manager.redeliverInbox(context.Background(), inboxRedelivery{
+		runtime:     "codexAppCDP",
+		displayName: "Codex App",
+		store:       store,
+		notifyChat:  true,
+		prompt:      func(envelope InboundAppEnvelope) string { return "rebuilt:" + envelope.Text },
+		deliver: func(ctx context.Context, envelope InboundAppEnvelope, prompt string) error {
+			delivered = append(delivered, envelope.ID+"="+prompt)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("redeliverInbox: %v", err)
+	}
+	if strings.Join(delivered, ",") != "env-1=prompt-1,env-3=rebuilt:second" {
+		t.Fatalf("unexpected deliveries: %v", delivered)
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Pending != 0 || stats.Done != 3 || stats.Failed != 1 {
+		t.Fatalf("unexpected inbox stats: %#v", stats)
+	}
+
+	sent := channel.messages()
+	if len(sent) != 2 || sent[0].To != "42" || !strings.Contains(sent[0].Text, "delivered late to main after Codex App became available again") {
+		t.Fatalf("unexpected notifications: %#v", sent)
+	}
+
+	status := manager.InboxRedeliveryStatus("codexAppCDP")
+	if status == nil || status.Running || status.Delivered != 2 || status.Skipped != 2 || status.LastError != "" {
+		t.Fatalf("unexpected redelivery status: %#v", status)
+	}
+}
+
+func TestRedeliverInboxStopsAtFirstFailure(t *testing.T) {
+	inboxPath := filepath.Join(t.TempDir(), "inbox")
+	queueTestEnvelope(t, inboxPath, InboundAppEnvelope{ID: "env-1", ReceivedAt: "2026-05-01T12:00:00Z", Text: "first"}, "p")
+	queueTestEnvelope(t, inboxPath, InboundAppEnvelope{ID: "env-2", ReceivedAt: "2026-05-01T12:00:01Z", Text: "second"}, "p")
+
+	manager := NewManager(&config.AgentsConfig{})
+	calls := 0
+	store := inbox.Open(inboxPath)
+	err := manager.redeliverInbox(context.Background(), inboxRedelivery{
+		runtime: "codexAppCDP",
+		store:   store,
+		deliver: func(ctx context.Context, envelope InboundAppEnvelope, prompt string) error {
+			calls++
+			return errors.New("composer missing")
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "composer missing") || calls != 1 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Pending != 2 || stats.Claimed != 0 {
+		t.Fatalf("expected both envelopes to stay pending, got %#v", stats)
+	}
+}
+
+func TestCodexAppCDPWatchRedeliversQueuedEnvelopes(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/json/version", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"Browser": "Codex"})
+	})
+	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]cdp.Target{{Type: "page", Title: "Codex", WebSocketDebuggerURL: "ws://127.0.0.1:1/devtools/page/codex"}})
+	})
+
+	inboxPath := filepath.Join(t.TempDir(), "inbox")
+	queueTestEnvelope(t, inboxPath, InboundAppEnvelope{ID: "env-1", ReceivedAt: time.Now().UTC().Format(time.RFC3339Nano), Text: "queued"}, "p")
+
+	client := &recordingCodexAppCDPClient{}
+	manager := NewManager(&config.AgentsConfig{
+		CodexAppCDP: &config.CodexAppCDPConfig{
+			Enabled:         true,
+			CDPEndpoint:     server.URL,
+			InboxPath:       inboxPath,
+			FallbackToInbox: true,
+			RepairPolicy:    "status-only",
+			Watch:           config.CodexAppCDPWatchConfig{IntervalSeconds: 1},
+		},
+	})
+	manager.codexAppCDPClient = client
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := manager.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer manager.Stop(context.Background())
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := manager.InboxRedeliveryStatus("codexAppCDP"); status != nil && status.Delivered == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := manager.InboxRedeliveryStatus("codexAppCDP"); status == nil || status.Delivered != 1 {
+		t.Fatalf("expected one redelivered envelope, got %#v", status)
+	}
+	if atomic.LoadInt32(&client.calls) != 1 {
+		t.Fatalf("expected one CDP delivery, got %d", client.calls)
+	}
+	items, err := inbox.Open(inboxPath).List(inbox.StateDone)
+	if err != nil || len(items) != 1 || items[0].EnvelopeID != "env-1" {
+		t.Fatalf("items=%#v err=%v", items, err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,26 +34,45 @@ const (
 	maxOhMyCodeMonitorLines         = 200
 
 	gatewayToolCommandsUnavailableMessage = "⚠️ /tool and /tools are not available in gateway mode."
-	markerHeartbeatOK                     = "HEARTBEAT_OK"
+	markerHeartbeatOK                     = agentruntime.HeartbeatOK
 	markerNoReply                         = "NO_REPLY"
 )
 
 // Manager is a minimal stub for agent lifecycle management.
 type Manager struct {
-	config              *config.AgentsConfig
-	ChannelManager      *channels.Manager
-	mu                  sync.RWMutex
-	agents              map[string]protocol.AgentInfo
-	routingMu           sync.RWMutex
-	lastRouting         *RoutingOutcome
-	codexAppCDPClient   codexAppCDPClient
-	claudeDesktopClient claudeDesktopClient
-	cdpMonitorCancel    context.CancelFunc
-	cdpMonitorDone      chan struct{}
-	cdpReadiness        *CodexAppCDPReadinessStatus
-	cdpResolvedTarget   *CodexAppCDPResolvedConversationStatus
-	inboundHookMu       sync.RWMutex
-	inboundRoutedHook   func(runtimeName, agentName string)
+	config               *config.AgentsConfig
+	ChannelManager       *channels.Manager
+	mu                   sync.RWMutex
+	agents               map[string]protocol.AgentInfo
+	routingMu            sync.RWMutex
+	lastRouting          *RoutingOutcome
+	agentActivity        map[string]time.Time
+	registryCancel       context.CancelFunc
+	registryDone         chan struct{}
+	codexAppCDPClient    codexAppCDPClient
+	claudeDesktopClient  claudeDesktopClient
+	cdpMonitorCancel     context.CancelFunc
+	cdpMonitorDone       chan struct{}
+	cdpReadiness         *CodexAppCDPReadinessStatus
+	cdpResolvedTarget    *CodexAppCDPResolvedConversationStatus
+	claudeResolvedTarget *ClaudeDesktopResolvedConversationStatus
+	claudeThreads        map[string]string
+	claudeMonitorCancel  context.CancelFunc
+	claudeMonitorDone    chan struct{}
+	claudeReadiness      *ClaudeDesktopReadinessStatus
+	redelivery           map[string]*InboxRedeliveryStatus
+	relays               map[string]*ResponseRelayStatus
+	ohMyCodeTasks        map[string]*ohMyCodeTask
+	ohMyCodeTaskHistory  []OhMyCodeTaskStatus
+	relayCtx             context.Context
+	relayCancel          context.CancelFunc
+	relayWG              sync.WaitGroup
+	inboundHookMu        sync.RWMutex
+	inboundRoutedHook    func(runtimeName, agentName string)
+	replyRelayedHook     func(runtimeName, agentName string)
+	heartbeatReplyHook   func(jobID, runID, reply string)
+	runtimesMu           sync.RWMutex
+	runtimes             []runtimeEntry
 }
 
 type RoutingOutcome struct {
@@ -73,10 +93,17 @@ type OhMyCodeRoutingOutcome = RoutingOutcome
 
 // NewManager creates a new agent manager.
 func NewManager(cfg *config.AgentsConfig) *Manager {
-	return &Manager{
+	m := &Manager{
 		config: cfg,
 		agents: make(map[string]protocol.AgentInfo),
 	}
+	m.initRuntimes()
+	return m
+}
+
+// Config returns the agents configuration the manager was created with.
+func (m *Manager) Config() *config.AgentsConfig {
+	return m.config
 }
 
 // Start initializes resources required by the manager.
@@ -84,16 +111,23 @@ func (m *Manager) Start(ctx context.Context) error {
 	if m.config != nil && m.config.CodexAppCDP != nil && m.config.CodexAppCDP.Enabled {
 		m.startCodexAppCDPWatch(ctx, m.config.CodexAppCDP)
 	}
+	if m.config != nil && m.config.ClaudeDesktop != nil && m.config.ClaudeDesktop.Enabled {
+		m.startClaudeDesktopWatch(ctx, m.config.ClaudeDesktop)
+	}
+	m.startAgentRegistry(ctx)
 	return nil
 }
 
 // Stop releases resources held by the manager.
 func (m *Manager) Stop(ctx context.Context) error {
 	m.stopCodexAppCDPWatch(ctx)
+	m.stopAgentRegistry(ctx)
+	m.stopClaudeDesktopWatch(ctx)
+	m.stopResponseRelays(ctx)
 	return nil
 }
 
-// List returns known agents.
+// List returns the agents discovered by the registry, ordered by ID.
 func (m *Manager) List() []protocol.AgentInfo {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -102,6 +136,7 @@ func (m *Manager) List() []protocol.AgentInfo {
 	for _, info := range m.agents {
 		agents = append(agents, info)
 	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
 	return agents
 }
 
@@ -127,7 +162,7 @@ func (m *Manager) HandleIncoming(ctx context.Context, msg *protocol.Message) (st
 	}
 
 	channel, _ := data["channel"].(string)
-	if channel != "telegram" && channel != "feishu" && channel != "slack" && channel != "discord" && channel != "imessage" {
+	if !m.isRoutableChannel(channel) {
 		return "", nil
 	}
 
@@ -152,47 +187,13 @@ func (m *Manager) HandleIncoming(ctx context.Context, msg *protocol.Message) (st
 		return gatewayToolCommandsUnavailableMessage, nil
 	}
 
-	if m.activeRouter() == "codexAppCDP" && m.isCodexAppCDPEnabled() {
-		agentName, _ := data["agent"].(string)
-		out, err := m.assignCodexAppCDP(ctx, text, agentName, data)
-		if err != nil {
-			return "", err
-		}
-		m.notifyInboundRouted(agentruntime.CodexAppCDP, agentName)
-		out = normalizeUserReply(out)
-		if out == "" {
-			return "", nil
-		}
-		if channel == "telegram" {
-			return channels.TruncateTelegramReply(out), nil
-		}
-		return out, nil
-	}
-
-	if m.activeRouter() == "claudeDesktop" && m.isClaudeDesktopEnabled() {
+	if rt := m.routingRuntime(); rt != nil {
 		agentName, _ := data["agent"].(string)
-		out, err := m.assignClaudeDesktop(ctx, text, agentName, data)
+		out, err := rt.Route(ctx, agentruntime.RouteRequest{Text: text, Agent: agentName, Data: data})
 		if err != nil {
 			return "", err
 		}
-		m.notifyInboundRouted(agentruntime.ClaudeDesktop, agentName)
-		out = normalizeUserReply(out)
-		if out == "" {
-			return "", nil
-		}
-		if channel == "telegram" {
-			return channels.TruncateTelegramReply(out), nil
-		}
-		return out, nil
-	}
-
-	if m.isOhMyCodeEnabled() {
-		agentName, _ := data["agent"].(string)
-		out, err := m.assignOhMyCode(ctx, text, agentName, data)
-		if err != nil {
-			return "", err
-		}
-		m.notifyInboundRouted(agentruntime.OhMyCode, agentName)
+		m.notifyInboundRouted(rt.Name(), agentName)
 		out = normalizeUserReply(out)
 		if out == "" {
 			return "", nil
@@ -214,22 +215,37 @@ func (m *Manager) SetInboundRoutedHook(hook func(runtimeName, agentName string))
 	m.inboundHookMu.Unlock()
 }
 
+// SetReplyRelayedHook registers a callback invoked after an agent reply is
+// relayed to the chat the message came from.
+func (m *Manager) SetReplyRelayedHook(hook func(runtimeName, agentName string)) {
+	m.inboundHookMu.Lock()
+	m.replyRelayedHook = hook
+	m.inboundHookMu.Unlock()
+}
+
+// SetHeartbeatReplyHook registers a callback invoked with the agent's answer
+// to a wakeup dispatched with WantReply by a runtime that captures it after
+// the dispatch returns.
+func (m *Manager) SetHeartbeatReplyHook(hook func(jobID, runID, reply string)) {
+	m.inboundHookMu.Lock()
+	m.heartbeatReplyHook = hook
+	m.inboundHookMu.Unlock()
+}
+
+func (m *Manager) notifyHeartbeatReply(request agentruntime.DispatchRequest, reply string) {
+	m.inboundHookMu.RLock()
+	hook := m.heartbeatReplyHook
+	m.inboundHookMu.RUnlock()
+	if hook != nil {
+		hook(strings.TrimSpace(request.JobID), strings.TrimSpace(request.RunID), strings.TrimSpace(reply))
+	}
+}
+
 func (m *Manager) notifyInboundRouted(runtimeName, agentName string) {
 	agentName = strings.TrimSpace(agentName)
-	if agentName == "" && m.config != nil {
-		switch runtimeName {
-		case agentruntime.OhMyCode:
-			if m.config.OhMyCode != nil {
-				agentName = strings.TrimSpace(m.config.OhMyCode.DefaultAgent)
-			}
-		case agentruntime.CodexAppCDP:
-			if m.config.CodexAppCDP != nil {
-				agentName = strings.TrimSpace(m.config.CodexAppCDP.DefaultAgent)
-			}
-		case agentruntime.ClaudeDesktop:
-			if m.config.ClaudeDesktop != nil {
-				agentName = strings.TrimSpace(m.config.ClaudeDesktop.DefaultAgent)
-			}
+	if agentName == "" {
+		if rt := m.Runtime(runtimeName); rt != nil {
+			agentName = rt.DefaultAgent()
 		}
 	}
 	m.inboundHookMu.RLock()
@@ -241,23 +257,20 @@ func (m *Manager) notifyInboundRouted(runtimeName, agentName string) {
 }
 
 func (m *Manager) activeRouter() string {
-	if m.config == nil {
-		return ""
-	}
-	router := strings.TrimSpace(m.config.Router)
-	if router != "" {
-		return router
-	}
-	if m.config.OhMyCode != nil && m.config.OhMyCode.Enabled {
-		return "ohMyCode"
-	}
-	if m.config.CodexAppCDP != nil && m.config.CodexAppCDP.Enabled {
-		return "codexAppCDP"
-	}
-	if m.config.ClaudeDesktop != nil && m.config.ClaudeDesktop.Enabled {
-		return "claudeDesktop"
+	return m.config.ActiveRouter()
+}
+
+// isRoutableChannel reports whether messages from channel are routed to an
+// Agent Runtime. Channels registered outside the built-in set are routable
+// once the channel manager knows them.
+func (m *Manager) isRoutableChannel(channel string) bool {
+	switch channel {
+	case "telegram", "feishu", "slack", "discord", "imessage":
+		return true
+	case "", "demail":
+		return false
 	}
-	return ""
+	return m.ChannelManager != nil && m.ChannelManager.Get(channel) != nil
 }
 
 func isRuntimeToolInvocation(text string) bool {
@@ -300,12 +313,12 @@ func (m *Manager) isOhMyCodeEnabled() bool {
 	if !m.config.OhMyCode.Enabled {
 		return false
 	}
-	return strings.TrimSpace(m.config.OhMyCode.Workspace) != ""
+	return strings.TrimSpace(m.config.OhMyCode.Workspace) != "" || len(m.config.OhMyCode.Workspaces) > 0
 }
 
 func (m *Manager) assignOhMyCode(ctx context.Context, userText, agentOverride string, inboundData map[string]interface{}) (string, error) {
-	workspace, script, err := m.resolveOhMyCodeWorkspaceAndScript()
-	if err != nil {
+	if m.config == nil || m.config.OhMyCode == nil {
+		err := errors.New("agents.ohMyCode is not configured")
 		m.recordRoutingOutcome(inboundData, "", "error", err)
 		return "", err
 	}
@@ -317,43 +330,40 @@ func (m *Manager) assignOhMyCode(ctx context.Context, userText, agentOverride st
 			agentName = defaultOhMyCodeDefaultAgent
 		}
 	}
-	validatedName, err := m.validateOhMyCodeAgent(agentName)
+	ws, name, err := m.resolveOhMyCodeAgent(agentName)
 	if err != nil {
 		m.recordRoutingOutcome(inboundData, agentName, "error", err)
 		return "", err
 	}
-	name := validatedName
-
-	timeout := defaultOhMyCodeAssignTimeout
-	if m.config.OhMyCode.AssignTimeoutSeconds > 0 {
-		timeout = time.Duration(m.config.OhMyCode.AssignTimeoutSeconds) * time.Second
-	}
 
 	assignCtx := ctx
-	if timeout > 0 {
+	if ws.AssignTimeout > 0 {
 		var cancel context.CancelFunc
-		assignCtx, cancel = context.WithTimeout(ctx, timeout)
+		assignCtx, cancel = context.WithTimeout(ctx, ws.AssignTimeout)
 		defer cancel()
 	}
 
 	prompt := buildOhMyCodeTaskPrompt(userText, name, inboundData)
-	if _, err := runOhMyCodeAgentManager(assignCtx, workspace, script, prompt, "assign", name); err != nil {
-		m.recordRoutingOutcome(inboundData, name, "error", err)
+	var taskID string
+	if m.ohMyCodeTaskTrackingApplies(inboundData) {
+		taskID = newEnvelopeID()
+		prompt += buildOhMyCodeTaskTrackingPrompt(taskID)
+	}
+	if _, err := runOhMyCodeAgentManager(assignCtx, ws.Dir, ws.Script, prompt, "assign", name); err != nil {
+		m.recordRoutingOutcome(inboundData, ws.qualify(name), "error", err)
 		return "", err
 	}
 
-	m.recordRoutingOutcome(inboundData, name, "assigned", nil)
+	m.recordRoutingOutcome(inboundData, ws.qualify(name), "assigned", nil)
+	if taskID != "" {
+		m.startOhMyCodeTask(ws, taskID, name, inboundData)
+	}
 	return ohMyCodeAssignAckMessage, nil
 }
 
-// MonitorAgent returns the latest agent-manager monitor output.
-func (m *Manager) MonitorAgent(ctx context.Context, agentName string, lines int) (string, error) {
-	workspace, script, err := m.resolveOhMyCodeWorkspaceAndScript()
-	if err != nil {
-		return "", err
-	}
-
-	name, err := m.validateOhMyCodeAgent(agentName)
+// monitorOhMyCodeAgent returns the latest agent-manager monitor output.
+func (m *Manager) monitorOhMyCodeAgent(ctx context.Context, agentName string, lines int) (string, error) {
+	ws, name, err := m.resolveOhMyCodeAgent(agentName)
 	if err != nil {
 		return "", err
 	}
@@ -368,17 +378,12 @@ func (m *Manager) MonitorAgent(ctx context.Context, agentName string, lines int)
 	monitorCtx, cancel := context.WithTimeout(ctx, defaultOhMyCodeMonitorTimeout)
 	defer cancel()
 
-	return runOhMyCodeAgentManager(monitorCtx, workspace, script, "", "monitor", name, "--lines", strconv.Itoa(lines))
+	return runOhMyCodeAgentManager(monitorCtx, ws.Dir, ws.Script, "", "monitor", name, "--lines", strconv.Itoa(lines))
 }
 
-// StartAgent starts a configured agent-manager session.
-func (m *Manager) StartAgent(ctx context.Context, agentName string) (string, error) {
-	workspace, script, err := m.resolveOhMyCodeWorkspaceAndScript()
-	if err != nil {
-		return "", err
-	}
-
-	name, err := m.validateOhMyCodeAgent(agentName)
+// startOhMyCodeAgent starts a configured agent-manager session.
+func (m *Manager) startOhMyCodeAgent(ctx context.Context, agentName string) (string, error) {
+	ws, name, err := m.resolveOhMyCodeAgent(agentName)
 	if err != nil {
 		return "", err
 	}
@@ -386,17 +391,12 @@ func (m *Manager) StartAgent(ctx context.Context, agentName string) (string, err
 	lifecycleCtx, cancel := context.WithTimeout(ctx, defaultOhMyCodeLifecycleTimeout)
 	defer cancel()
 
-	return runOhMyCodeAgentManager(lifecycleCtx, workspace, script, "", "start", name)
+	return runOhMyCodeAgentManager(lifecycleCtx, ws.Dir, ws.Script, "", "start", name)
 }
 
-// StopAgent stops a running agent-manager session.
-func (m *Manager) StopAgent(ctx context.Context, agentName string) (string, error) {
-	workspace, script, err := m.resolveOhMyCodeWorkspaceAndScript()
-	if err != nil {
-		return "", err
-	}
-
-	name, err := m.validateOhMyCodeAgent(agentName)
+// stopOhMyCodeAgent stops a running agent-manager session.
+func (m *Manager) stopOhMyCodeAgent(ctx context.Context, agentName string) (string, error) {
+	ws, name, err := m.resolveOhMyCodeAgent(agentName)
 	if err != nil {
 		return "", err
 	}
@@ -404,12 +404,13 @@ func (m *Manager) StopAgent(ctx context.Context, agentName string) (string, erro
 	lifecycleCtx, cancel := context.WithTimeout(ctx, defaultOhMyCodeLifecycleTimeout)
 	defer cancel()
 
-	return runOhMyCodeAgentManager(lifecycleCtx, workspace, script, "", "stop", name)
+	return runOhMyCodeAgentManager(lifecycleCtx, ws.Dir, ws.Script, "", "stop", name)
 }
 
-// DoctorAgentManager runs a diagnostic check for agent-manager.
-func (m *Manager) Doctor(ctx context.Context) (string, error) {
-	workspace, script, err := m.resolveOhMyCodeWorkspaceAndScript()
+// doctorOhMyCode runs a diagnostic check for agent-manager in workspace. An
+// empty workspace checks the top-level agents.ohMyCode.workspace.
+func (m *Manager) doctorOhMyCode(ctx context.Context, workspace string) (string, error) {
+	ws, err := m.ohMyCodeWorkspace(strings.TrimSpace(workspace))
 	if err != nil {
 		return "", err
 	}
@@ -417,46 +418,124 @@ func (m *Manager) Doctor(ctx context.Context) (string, error) {
 	lifecycleCtx, cancel := context.WithTimeout(ctx, defaultOhMyCodeLifecycleTimeout)
 	defer cancel()
 
-	return runOhMyCodeAgentManager(lifecycleCtx, workspace, script, "", "doctor")
+	return runOhMyCodeAgentManager(lifecycleCtx, ws.Dir, ws.Script, "", "doctor")
 }
 
-func (m *Manager) resolveOhMyCodeWorkspaceAndScript() (string, string, error) {
+// ohMyCodeWorkspace is a resolved oh-my-code repository. Name is empty for
+// the top-level agents.ohMyCode.workspace.
+type ohMyCodeWorkspace struct {
+	Name          string
+	Dir           string
+	Script        string
+	DefaultAgent  string
+	AllowedAgents []string
+	AssignTimeout time.Duration
+}
+
+// qualify returns the chat-facing name of agent in this workspace.
+func (ws ohMyCodeWorkspace) qualify(agent string) string {
+	if ws.Name == "" {
+		return agent
+	}
+	return ws.Name + "/" + agent
+}
+
+func (ws ohMyCodeWorkspace) configPath() string {
+	if ws.Name == "" {
+		return "agents.ohMyCode"
+	}
+	return "agents.ohMyCode.workspaces." + ws.Name
+}
+
+// ohMyCodeWorkspace resolves a named workspace, or the top-level workspace
+// when name is empty.
+func (m *Manager) ohMyCodeWorkspace(name string) (ohMyCodeWorkspace, error) {
 	if m.config == nil || m.config.OhMyCode == nil {
-		return "", "", errors.New("agents.ohMyCode is not configured")
+		return ohMyCodeWorkspace{}, errors.New("agents.ohMyCode is not configured")
 	}
-	if !m.config.OhMyCode.Enabled {
-		return "", "", errors.New("agents.ohMyCode is disabled")
+	cfg := m.config.OhMyCode
+	if !cfg.Enabled {
+		return ohMyCodeWorkspace{}, errors.New("agents.ohMyCode is disabled")
 	}
 
-	workspace := strings.TrimSpace(m.config.OhMyCode.Workspace)
-	if workspace == "" {
-		return "", "", errors.New("agents.ohMyCode.workspace is required")
+	ws := ohMyCodeWorkspace{
+		Dir:           strings.TrimSpace(cfg.Workspace),
+		Script:        strings.TrimSpace(cfg.AgentManagerScript),
+		DefaultAgent:  strings.TrimSpace(cfg.DefaultAgent),
+		AllowedAgents: cfg.AllowedAgents,
+		AssignTimeout: defaultOhMyCodeAssignTimeout,
+	}
+	if cfg.AssignTimeoutSeconds > 0 {
+		ws.AssignTimeout = time.Duration(cfg.AssignTimeoutSeconds) * time.Second
+	}
+	if name != "" {
+		named, ok := cfg.Workspaces[name]
+		if !ok {
+			return ohMyCodeWorkspace{}, fmt.Errorf("unknown oh-my-code workspace %q", name)
+		}
+		ws.Name = name
+		ws.Dir = strings.TrimSpace(named.Workspace)
+		if script := strings.TrimSpace(named.AgentManagerScript); script != "" {
+			ws.Script = script
+		}
+		ws.DefaultAgent = strings.TrimSpace(named.DefaultAgent)
+		ws.AllowedAgents = named.AllowedAgents
+		if named.AssignTimeoutSeconds > 0 {
+			ws.AssignTimeout = time.Duration(named.AssignTimeoutSeconds) * time.Second
+		}
+	}
+	if ws.Dir == "" {
+		if name == "" && len(cfg.Workspaces) > 0 {
+			return ohMyCodeWorkspace{}, fmt.Errorf("a workspace is required (available: %s)", strings.Join(m.ohMyCodeWorkspaceNames(), ", "))
+		}
+		return ohMyCodeWorkspace{}, fmt.Errorf("%s.workspace is required", ws.configPath())
 	}
 
-	script := strings.TrimSpace(m.config.OhMyCode.AgentManagerScript)
-	if script == "" {
-		script = defaultOhMyCodeAgentManagerScript
+	if ws.Script == "" {
+		ws.Script = defaultOhMyCodeAgentManagerScript
 	}
-	if !filepath.IsAbs(script) {
-		script = filepath.Join(workspace, script)
+	if !filepath.IsAbs(ws.Script) {
+		ws.Script = filepath.Join(ws.Dir, ws.Script)
 	}
+	return ws, nil
+}
 
-	return workspace, script, nil
+func (m *Manager) ohMyCodeWorkspaceNames() []string {
+	names := make([]string, 0, len(m.config.OhMyCode.Workspaces))
+	for name := range m.config.OhMyCode.Workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
-func (m *Manager) validateOhMyCodeAgent(agentName string) (string, error) {
+// resolveOhMyCodeAgent splits "<workspace>/<agent>" and checks the agent
+// against that workspace's allowlist. It returns the unqualified agent name
+// that agent-manager knows.
+func (m *Manager) resolveOhMyCodeAgent(agentName string) (ohMyCodeWorkspace, string, error) {
 	name := strings.TrimSpace(agentName)
 	if name == "" {
-		return "", errors.New("agent name is required")
+		return ohMyCodeWorkspace{}, "", errors.New("agent name is required")
 	}
 	if err := channels.ValidateAgentName(name); err != nil {
-		return "", err
+		return ohMyCodeWorkspace{}, "", err
 	}
-	allowlist := channels.NewAgentAllowlist(m.config.OhMyCode.AllowedAgents)
-	if err := allowlist.Validate(name, m.config.OhMyCode.DefaultAgent); err != nil {
-		return "", m.agentAllowedError(err)
+	workspaceName, agent, qualified := strings.Cut(name, "/")
+	if !qualified {
+		workspaceName, agent = "", name
 	}
-	return name, nil
+	ws, err := m.ohMyCodeWorkspace(workspaceName)
+	if err != nil {
+		return ohMyCodeWorkspace{}, "", err
+	}
+	allowlist := channels.NewAgentAllowlist(ws.AllowedAgents)
+	if err := allowlist.Validate(agent, ws.DefaultAgent); err != nil {
+		if ws.Name != "" {
+			return ohMyCodeWorkspace{}, "", fmt.Errorf("%s (Tip: run /agents or configure %s.allowedAgents)", err, ws.configPath())
+		}
+		return ohMyCodeWorkspace{}, "", m.agentAllowedError(err)
+	}
+	return ws, agent, nil
 }
 
 func (m *Manager) agentAllowedError(err error) error {
@@ -629,6 +708,9 @@ func (m *Manager) recordRoutingOutcomeForBackend(backend string, inboundData map
 	m.routingMu.Lock()
 	m.lastRouting = outcome
 	m.routingMu.Unlock()
+	if err == nil {
+		m.recordAgentActivity(outcome.Backend, outcome.SelectedAgent, outcome.RecordedAt)
+	}
 }
 
 func extractRecentMessages(inboundData map[string]interface{}) []map[string]interface{} {
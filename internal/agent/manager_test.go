@@ -12,10 +12,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+	"github.com/fractalmind-ai/fractalbot/internal/cdp/cdptest"
 	"github.com/fractalmind-ai/fractalbot/internal/channels"
 	"github.com/fractalmind-ai/fractalbot/internal/config"
 	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
-	"github.com/gorilla/websocket"
 )
 
 type recordingCodexAppCDPClient struct {
@@ -33,7 +34,7 @@ func boolPtr(value bool) *bool {
 	return &value
 }
 
-func TestValidateOhMyCodeAgentDefaultOnly(t *testing.T) {
+func TestResolveOhMyCodeAgentDefaultOnly(t *testing.T) {
 	manager := NewManager(&config.AgentsConfig{
 		OhMyCode: &config.OhMyCodeConfig{
 			Enabled:      true,
@@ -42,15 +43,15 @@ func TestValidateOhMyCodeAgentDefaultOnly(t *testing.T) {
 		},
 	})
 
-	if _, err := manager.validateOhMyCodeAgent("qa-1"); err != nil {
+	if _, _, err := manager.resolveOhMyCodeAgent("qa-1"); err != nil {
 		t.Fatalf("expected default agent allowed: %v", err)
 	}
-	if _, err := manager.validateOhMyCodeAgent("coder-a"); err == nil {
+	if _, _, err := manager.resolveOhMyCodeAgent("coder-a"); err == nil {
 		t.Fatal("expected non-default agent to be rejected")
 	}
 }
 
-func TestValidateOhMyCodeAgentAllowlist(t *testing.T) {
+func TestResolveOhMyCodeAgentAllowlist(t *testing.T) {
 	manager := NewManager(&config.AgentsConfig{
 		OhMyCode: &config.OhMyCodeConfig{
 			Enabled:       true,
@@ -60,15 +61,15 @@ func TestValidateOhMyCodeAgentAllowlist(t *testing.T) {
 		},
 	})
 
-	if _, err := manager.validateOhMyCodeAgent("coder-a"); err != nil {
+	if _, _, err := manager.resolveOhMyCodeAgent("coder-a"); err != nil {
 		t.Fatalf("expected allowlisted agent allowed: %v", err)
 	}
-	if _, err := manager.validateOhMyCodeAgent("coder-b"); err == nil {
+	if _, _, err := manager.resolveOhMyCodeAgent("coder-b"); err == nil {
 		t.Fatal("expected non-allowlisted agent rejected")
 	}
 }
 
-func TestValidateOhMyCodeAgentInvalidName(t *testing.T) {
+func TestResolveOhMyCodeAgentInvalidName(t *testing.T) {
 	manager := NewManager(&config.AgentsConfig{
 		OhMyCode: &config.OhMyCodeConfig{
 			Enabled:      true,
@@ -77,10 +78,10 @@ func TestValidateOhMyCodeAgentInvalidName(t *testing.T) {
 		},
 	})
 
-	if _, err := manager.validateOhMyCodeAgent(""); err == nil {
+	if _, _, err := manager.resolveOhMyCodeAgent(""); err == nil {
 		t.Fatal("expected empty name rejected")
 	}
-	if _, err := manager.validateOhMyCodeAgent("bad name"); err == nil {
+	if _, _, err := manager.resolveOhMyCodeAgent("bad name"); err == nil {
 		t.Fatal("expected invalid name rejected")
 	} else if !strings.Contains(err.Error(), "invalid") {
 		t.Fatalf("expected invalid name error, got %v", err)
@@ -727,48 +728,10 @@ func TestHandleIncomingClaudeDesktopWritesInboxEnvelope(t *testing.T) {
 }
 
 func TestHandleIncomingClaudeDesktopDeliversViaCDP(t *testing.T) {
-	var evaluated string
-	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
-	mux := http.NewServeMux()
-	server := httptest.NewServer(mux)
-	defer server.Close()
-	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/devtools/page/1"
-	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
-		_ = json.NewEncoder(w).Encode([]cdpTarget{{
-			Type:                 "page",
-			Title:                "Claude",
-			URL:                  "https://claude.ai/new",
-			WebSocketDebuggerURL: wsURL,
-		}})
-	})
-	mux.HandleFunc("/devtools/page/1", func(w http.ResponseWriter, r *http.Request) {
-		conn, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			t.Fatalf("upgrade: %v", err)
-		}
-		defer conn.Close()
-		var req struct {
-			ID     int    `json:"id"`
-			Method string `json:"method"`
-			Params struct {
-				Expression string `json:"expression"`
-			} `json:"params"`
-		}
-		if err := conn.ReadJSON(&req); err != nil {
-			t.Fatalf("read CDP request: %v", err)
-		}
-		evaluated = req.Params.Expression
-		if req.Method != "Runtime.evaluate" {
-			t.Fatalf("method=%q", req.Method)
-		}
-		if err := conn.WriteJSON(map[string]interface{}{
-			"id": req.ID,
-			"result": map[string]interface{}{
-				"result": map[string]interface{}{"type": "object", "value": map[string]interface{}{"ok": true, "submitted": true}},
-			},
-		}); err != nil {
-			t.Fatalf("write CDP response: %v", err)
-		}
+	server := cdptest.NewServer(t)
+	page := server.AddPage("Claude", "https://claude.ai/new")
+	page.OnEvaluate(func(string) (interface{}, error) {
+		return map[string]interface{}{"ok": true, "submitted": true}, nil
 	})
 
 	manager := NewManager(&config.AgentsConfig{
@@ -792,7 +755,11 @@ func TestHandleIncomingClaudeDesktopDeliversViaCDP(t *testing.T) {
 	if reply != claudeDesktopAssignAckMessage {
 		t.Fatalf("reply=%q", reply)
 	}
-	if !strings.Contains(evaluated, "deliver to Claude") || !strings.Contains(evaluated, "No visible Claude Desktop input found") {
+	expressions := page.Expressions()
+	if len(expressions) != 1 {
+		t.Fatalf("expected one evaluation, got %d", len(expressions))
+	}
+	if evaluated := expressions[0]; !strings.Contains(evaluated, "deliver to Claude") || !strings.Contains(evaluated, "No visible Claude Desktop input found") {
 		t.Fatalf("unexpected evaluated script: %s", evaluated)
 	}
 	telemetry := manager.LastRoutingOutcome()
@@ -806,7 +773,7 @@ func TestHandleIncomingClaudeDesktopLoginFallsBackToInbox(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
-		_ = json.NewEncoder(w).Encode([]cdpTarget{{
+		_ = json.NewEncoder(w).Encode([]cdp.Target{{
 			Type:                 "page",
 			Title:                "Sign in",
 			URL:                  "https://claude.ai/login",
@@ -851,27 +818,27 @@ func TestHandleIncomingClaudeDesktopLoginFallsBackToInbox(t *testing.T) {
 func TestIsClaudeDesktopChatTarget(t *testing.T) {
 	for _, test := range []struct {
 		name   string
-		target cdpTarget
+		target cdp.Target
 		want   bool
 	}{
 		{
 			name:   "authenticated Claude chat",
-			target: cdpTarget{Type: "page", URL: "https://claude.ai/new", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
+			target: cdp.Target{Type: "page", URL: "https://claude.ai/new", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
 			want:   true,
 		},
 		{
 			name:   "login page",
-			target: cdpTarget{Type: "page", Title: "Sign in", URL: "https://claude.ai/new", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
+			target: cdp.Target{Type: "page", Title: "Sign in", URL: "https://claude.ai/new", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
 			want:   false,
 		},
 		{
 			name:   "non Claude host",
-			target: cdpTarget{Type: "page", URL: "https://example.com", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
+			target: cdp.Target{Type: "page", URL: "https://example.com", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
 			want:   false,
 		},
 		{
 			name:   "non-page target",
-			target: cdpTarget{Type: "worker", URL: "https://claude.ai/new", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
+			target: cdp.Target{Type: "worker", URL: "https://claude.ai/new", WebSocketDebuggerURL: "ws://127.0.0.1/page/1"},
 			want:   false,
 		},
 	} {
@@ -884,51 +851,10 @@ func TestIsClaudeDesktopChatTarget(t *testing.T) {
 }
 
 func TestHandleIncomingCodexAppCDPDeliversViaCDP(t *testing.T) {
-	var evaluated string
-	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
-	mux := http.NewServeMux()
-	server := httptest.NewServer(mux)
-	defer server.Close()
-	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/devtools/page/1"
-	mux.HandleFunc("/json/version", func(w http.ResponseWriter, r *http.Request) {
-		_ = json.NewEncoder(w).Encode(map[string]string{"Browser": "Codex"})
-	})
-	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
-		_ = json.NewEncoder(w).Encode([]cdpTarget{{
-			Type:                 "page",
-			Title:                "Codex",
-			URL:                  "http://codex.local/local/thread-123",
-			WebSocketDebuggerURL: wsURL,
-		}})
-	})
-	mux.HandleFunc("/devtools/page/1", func(w http.ResponseWriter, r *http.Request) {
-		conn, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			t.Fatalf("upgrade: %v", err)
-		}
-		defer conn.Close()
-		var req struct {
-			ID     int    `json:"id"`
-			Method string `json:"method"`
-			Params struct {
-				Expression string `json:"expression"`
-			} `json:"params"`
-		}
-		if err := conn.ReadJSON(&req); err != nil {
-			t.Fatalf("read cdp request: %v", err)
-		}
-		evaluated = req.Params.Expression
-		if req.Method != "Runtime.evaluate" {
-			t.Fatalf("method=%q", req.Method)
-		}
-		if err := conn.WriteJSON(map[string]interface{}{
-			"id": req.ID,
-			"result": map[string]interface{}{
-				"result": map[string]interface{}{"type": "object", "value": map[string]interface{}{"ok": true, "conversationId": "thread-123"}},
-			},
-		}); err != nil {
-			t.Fatalf("write cdp response: %v", err)
-		}
+	server := cdptest.NewServer(t)
+	page := server.AddPage("Codex", "http://codex.local/local/thread-123")
+	page.OnEvaluate(func(string) (interface{}, error) {
+		return map[string]interface{}{"ok": true, "conversationId": "thread-123"}, nil
 	})
 
 	manager := NewManager(&config.AgentsConfig{
@@ -957,7 +883,11 @@ func TestHandleIncomingCodexAppCDPDeliversViaCDP(t *testing.T) {
 	if reply != codexAppAssignAckMessage {
 		t.Fatalf("reply=%q", reply)
 	}
-	if !strings.Contains(evaluated, "start-turn-for-host") || !strings.Contains(evaluated, "deliver to app") {
+	expressions := page.Expressions()
+	if len(expressions) != 1 {
+		t.Fatalf("expected one evaluation, got %d", len(expressions))
+	}
+	if evaluated := expressions[0]; !strings.Contains(evaluated, "start-turn-for-host") || !strings.Contains(evaluated, "deliver to app") {
 		t.Fatalf("unexpected evaluated script: %s", evaluated)
 	}
 	telemetry := manager.LastRoutingOutcome()
@@ -1003,50 +933,18 @@ func TestBuildCodexAppDeliveryScriptUsesArrayLikeSafeURLCollection(t *testing.T)
 }
 
 func TestHandleIncomingCodexAppCDPBridgeRejectionFallsBackToInbox(t *testing.T) {
-	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
-	mux := http.NewServeMux()
-	server := httptest.NewServer(mux)
-	defer server.Close()
-	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/devtools/page/1"
-	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
-		_ = json.NewEncoder(w).Encode([]cdpTarget{{
-			Type:                 "page",
-			Title:                "Codex",
-			URL:                  "app://-/index.html",
-			WebSocketDebuggerURL: wsURL,
-		}})
-	})
-	mux.HandleFunc("/devtools/page/1", func(w http.ResponseWriter, r *http.Request) {
-		conn, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			t.Fatalf("upgrade: %v", err)
-		}
-		defer conn.Close()
-		var req struct {
-			ID int `json:"id"`
-		}
-		if err := conn.ReadJSON(&req); err != nil {
-			t.Fatalf("read cdp request: %v", err)
-		}
-		if err := conn.WriteJSON(map[string]interface{}{
-			"id": req.ID,
+	server := cdptest.NewServer(t)
+	page := server.AddPage("Codex", "app://-/index.html")
+	page.OnEvaluate(func(string) (interface{}, error) {
+		return map[string]interface{}{
+			"ok":             true,
+			"conversationId": "thread-123",
 			"result": map[string]interface{}{
-				"result": map[string]interface{}{
-					"type": "object",
-					"value": map[string]interface{}{
-						"ok":             true,
-						"conversationId": "thread-123",
-						"result": map[string]interface{}{
-							"error": map[string]interface{}{
-								"message": "conversation has an active turn",
-							},
-						},
-					},
+				"error": map[string]interface{}{
+					"message": "conversation has an active turn",
 				},
 			},
-		}); err != nil {
-			t.Fatalf("write cdp response: %v", err)
-		}
+		}, nil
 	})
 
 	inbox := filepath.Join(t.TempDir(), "inbox")
@@ -1090,42 +988,16 @@ func TestHandleIncomingCodexAppCDPBridgeRejectionFallsBackToInbox(t *testing.T)
 }
 
 func TestHandleIncomingCodexAppCDPBridgeRejectionUsesVisibleComposerFallback(t *testing.T) {
-	var expressions []string
-	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
-	mux := http.NewServeMux()
-	server := httptest.NewServer(mux)
-	defer server.Close()
-	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/devtools/page/1"
-	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
-		_ = json.NewEncoder(w).Encode([]cdpTarget{{
-			Type:                 "page",
-			Title:                "Codex",
-			URL:                  "app://-/index.html",
-			WebSocketDebuggerURL: wsURL,
-		}})
-	})
-	mux.HandleFunc("/devtools/page/1", func(w http.ResponseWriter, r *http.Request) {
-		conn, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			t.Fatalf("upgrade: %v", err)
-		}
-		defer conn.Close()
-		var req struct {
-			ID     int    `json:"id"`
-			Method string `json:"method"`
-			Params struct {
-				Expression string `json:"expression"`
-			} `json:"params"`
-		}
-		if err := conn.ReadJSON(&req); err != nil {
-			t.Fatalf("read cdp request: %v", err)
-		}
-		expressions = append(expressions, req.Params.Expression)
+	server := cdptest.NewServer(t)
+	page := server.AddPage("Codex", "app://-/index.html")
+	evaluations := 0
+	page.OnEvaluate(func(string) (interface{}, error) {
+		evaluations++
 		value := map[string]interface{}{
 			"ok":             true,
 			"conversationId": "thread-123",
 		}
-		if len(expressions) == 1 {
+		if evaluations == 1 {
 			value["result"] = map[string]interface{}{
 				"error": map[string]interface{}{"message": "start-turn-for-host not implemented"},
 			}
@@ -1133,14 +1005,7 @@ func TestHandleIncomingCodexAppCDPBridgeRejectionUsesVisibleComposerFallback(t *
 			value["fallback"] = "visible-composer-button"
 			value["verified"] = "target-thread-readback"
 		}
-		if err := conn.WriteJSON(map[string]interface{}{
-			"id": req.ID,
-			"result": map[string]interface{}{
-				"result": map[string]interface{}{"type": "object", "value": value},
-			},
-		}); err != nil {
-			t.Fatalf("write cdp response: %v", err)
-		}
+		return value, nil
 	})
 
 	manager := NewManager(&config.AgentsConfig{
@@ -1169,6 +1034,7 @@ func TestHandleIncomingCodexAppCDPBridgeRejectionUsesVisibleComposerFallback(t *
 	if reply != codexAppAssignAckMessage {
 		t.Fatalf("reply=%q", reply)
 	}
+	expressions := page.Expressions()
 	if len(expressions) != 2 {
 		t.Fatalf("expected bridge and composer evaluations, got %d", len(expressions))
 	}
@@ -1536,7 +1402,7 @@ func TestCodexAppCDPWatchUpdatesReadinessAndStops(t *testing.T) {
 		case checked <- struct{}{}:
 		default:
 		}
-		_ = json.NewEncoder(w).Encode([]cdpTarget{{
+		_ = json.NewEncoder(w).Encode([]cdp.Target{{
 			Type:                 "page",
 			Title:                "Codex",
 			URL:                  "app://-/index.html",
@@ -1592,7 +1458,7 @@ func TestSelectCodexAppCDPTargetPrefersCodexPageOverBlankWebview(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
-		_ = json.NewEncoder(w).Encode([]cdpTarget{
+		_ = json.NewEncoder(w).Encode([]cdp.Target{
 			{
 				Type:                 "webview",
 				Title:                "about:blank",
@@ -1638,3 +1504,76 @@ func TestBuildCodexAppPromptIncludesUseFractalbotReplyHint(t *testing.T) {
 		}
 	}
 }
+
+func TestOhMyCodeNamedWorkspaceRoutesQualifiedAgents(t *testing.T) {
+	script := `import pathlib
+import sys
+
+log_path = pathlib.Path(sys.argv[0]).with_name("calls.log")
+with log_path.open("a", encoding="utf-8") as f:
+    f.write(" ".join(sys.argv[1:]) + "\n")
+print("ok")
+`
+	newWorkspace := func() string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "agent_manager_stub.py"), []byte(script), 0644); err != nil {
+			t.Fatalf("write script: %v", err)
+		}
+		return dir
+	}
+	readCalls := func(dir string) string {
+		raw, err := os.ReadFile(filepath.Join(dir, "calls.log"))
+		if err != nil {
+			t.Fatalf("read calls log: %v", err)
+		}
+		return strings.TrimSpace(string(raw))
+	}
+	frontend := newWorkspace()
+	backend := newWorkspace()
+
+	manager := NewManager(&config.AgentsConfig{
+		OhMyCode: &config.OhMyCodeConfig{
+			Enabled:            true,
+			Workspace:          frontend,
+			AgentManagerScript: "agent_manager_stub.py",
+			DefaultAgent:       "qa-1",
+			Workspaces: map[string]config.OhMyCodeWorkspaceConfig{
+				"backend": {
+					Workspace:     backend,
+					DefaultAgent:  "api-1",
+					AllowedAgents: []string{"api-1", "api-2"},
+				},
+			},
+		},
+	})
+
+	if _, err := manager.assignOhMyCode(context.Background(), "fix the handler", "backend/api-2", nil); err != nil {
+		t.Fatalf("assignOhMyCode: %v", err)
+	}
+	if calls := readCalls(backend); calls != "assign api-2" {
+		t.Fatalf("backend calls=%q", calls)
+	}
+	if telemetry := manager.LastRoutingOutcome(); telemetry == nil || telemetry.SelectedAgent != "backend/api-2" {
+		t.Fatalf("unexpected routing telemetry: %#v", telemetry)
+	}
+
+	if _, err := manager.StartAgent(context.Background(), "backend/qa-1"); err == nil || !strings.Contains(err.Error(), "agents.ohMyCode.workspaces.backend.allowedAgents") {
+		t.Fatalf("expected workspace allowlist error, got %v", err)
+	}
+	if _, err := manager.StopAgent(context.Background(), "qa-1"); err != nil {
+		t.Fatalf("StopAgent: %v", err)
+	}
+	if calls := readCalls(frontend); calls != "stop qa-1" {
+		t.Fatalf("frontend calls=%q", calls)
+	}
+
+	if _, err := manager.Doctor(context.Background(), "backend"); err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	if calls := readCalls(backend); !strings.HasSuffix(calls, "\ndoctor") {
+		t.Fatalf("backend calls=%q", calls)
+	}
+	if _, err := manager.Doctor(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "unknown oh-my-code workspace") {
+		t.Fatalf("expected unknown workspace error, got %v", err)
+	}
+}
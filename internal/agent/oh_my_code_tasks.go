@@ -0,0 +1,324 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+)
+
+const (
+	defaultOhMyCodeTaskTimeout     = time.Hour
+	defaultOhMyCodeTaskHistorySize = 20
+	// ohMyCodeTaskMonitorFailures is how many monitor reads in a row may fail
+	// before the agent session is treated as gone.
+	ohMyCodeTaskMonitorFailures = 3
+
+	ohMyCodeTaskDoneMarker   = "FRACTALBOT_TASK_DONE"
+	ohMyCodeTaskFailedMarker = "FRACTALBOT_TASK_FAILED"
+)
+
+// Tracked task states.
+const (
+	OhMyCodeTaskRunning  = "running"
+	OhMyCodeTaskDone     = "done"
+	OhMyCodeTaskFailed   = "failed"
+	OhMyCodeTaskTimedOut = "timeout"
+)
+
+// ErrOhMyCodeTaskNotFound is returned when completing a task that is not
+// running.
+var ErrOhMyCodeTaskNotFound = errors.New("oh-my-code task not found")
+
+// ohMyCodeTaskPollInterval is used when tasks.pollIntervalSeconds is unset.
+var ohMyCodeTaskPollInterval = 15 * time.Second
+
+// OhMyCodeTaskStatus is exposed in /status for tracked oh-my-code
+// assignments.
+type OhMyCodeTaskStatus struct {
+	ID string
+	// Agent is the chat-facing name, "<workspace>/<agent>" for agents of
+	// named workspaces.
+	Agent      string
+	Channel    string
+	ChatID     string
+	ThreadTS   string
+	State      string
+	Summary    string
+	Error      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+type ohMyCodeTask struct {
+	status    OhMyCodeTaskStatus
+	workspace ohMyCodeWorkspace
+	// agent is the name agent-manager knows the agent by.
+	agent    string
+	complete chan ohMyCodeTaskResult
+}
+
+type ohMyCodeTaskResult struct {
+	State   string
+	Summary string
+}
+
+// ohMyCodeTaskTrackingApplies reports whether an assignment from inboundData
+// is tracked. Only chat messages with a known origin can receive the outcome.
+func (m *Manager) ohMyCodeTaskTrackingApplies(inboundData map[string]interface{}) bool {
+	if m.config == nil || m.config.OhMyCode == nil || !m.config.OhMyCode.Tasks.Enabled {
+		return false
+	}
+	return promptContextValue(inboundData, "channel") != "" && firstContextValue(inboundData, "chat_id", "chatID") != ""
+}
+
+// buildOhMyCodeTaskTrackingPrompt tells the agent how to report completion.
+// The marker lines use a placeholder so the echoed prompt never matches.
+func buildOhMyCodeTaskTrackingPrompt(taskID string) string {
+	var sb strings.Builder
+	sb.WriteString("\nTask tracking:\n")
+	sb.WriteString(fmt.Sprintf("- task_id: %s\n", taskID))
+	sb.WriteString("- FractalBot posts the outcome of this task back to the user when you report it.\n")
+	sb.WriteString(fmt.Sprintf("- When you finish, print one line: %s <task_id>: <one-line summary>\n", ohMyCodeTaskDoneMarker))
+	sb.WriteString(fmt.Sprintf("- If you cannot finish, print one line: %s <task_id>: <reason>\n", ohMyCodeTaskFailedMarker))
+	sb.WriteString("- Alternatively run `fractalbot task complete --id <task_id> --summary \"...\"` (add `--failed` when it failed).\n")
+	return sb.String()
+}
+
+// buildOhMyCodeHeartbeatTrackingPrompt tells the agent how to report its
+// answer to a wakeup.
+func buildOhMyCodeHeartbeatTrackingPrompt(taskID string) string {
+	var sb strings.Builder
+	sb.WriteString("\nTask tracking:\n")
+	sb.WriteString(fmt.Sprintf("- task_id: %s\n", taskID))
+	sb.WriteString(fmt.Sprintf("- When you finish, print one line: %s <task_id>: <one-line summary>\n", ohMyCodeTaskDoneMarker))
+	sb.WriteString(fmt.Sprintf("- If there was no actionable work, the summary is exactly %s.\n", agentruntime.HeartbeatOK))
+	return sb.String()
+}
+
+// startOhMyCodeTask registers an assignment from chat and posts its outcome
+// to the chat once it finishes.
+func (m *Manager) startOhMyCodeTask(ws ohMyCodeWorkspace, taskID, agentName string, inboundData map[string]interface{}) {
+	status := OhMyCodeTaskStatus{
+		ID:       taskID,
+		Agent:    ws.qualify(agentName),
+		Channel:  promptContextValue(inboundData, "channel"),
+		ChatID:   firstContextValue(inboundData, "chat_id", "chatID"),
+		ThreadTS: promptContextValue(inboundData, "thread_ts"),
+	}
+	m.trackOhMyCodeTask(ws, agentName, status, func(status OhMyCodeTaskStatus, timeout time.Duration) {
+		sendCtx, sendCancel := context.WithTimeout(context.Background(), responseRelaySendTimeout)
+		defer sendCancel()
+		envelope := InboundAppEnvelope{Channel: status.Channel, ChatID: status.ChatID, ThreadTS: status.ThreadTS}
+		if err := m.sendToOrigin(sendCtx, envelope, formatOhMyCodeTaskOutcome(status, timeout)); err != nil {
+			log.Printf("oh-my-code task %s: %v", taskID, err)
+		}
+	})
+}
+
+// startOhMyCodeHeartbeatTask registers a wakeup dispatched with WantReply and
+// reports the agent's summary to the heartbeat reply hook once it is done.
+func (m *Manager) startOhMyCodeHeartbeatTask(ws ohMyCodeWorkspace, taskID, agentName string, request agentruntime.DispatchRequest) {
+	status := OhMyCodeTaskStatus{ID: taskID, Agent: ws.qualify(agentName)}
+	m.trackOhMyCodeTask(ws, agentName, status, func(status OhMyCodeTaskStatus, _ time.Duration) {
+		if status.State == OhMyCodeTaskDone {
+			m.notifyHeartbeatReply(request, status.Summary)
+		}
+	})
+}
+
+// trackOhMyCodeTask follows a task in the background until the agent reports
+// completion, the task times out, or the manager stops. done receives the
+// finished task unless the manager stopped.
+func (m *Manager) trackOhMyCodeTask(ws ohMyCodeWorkspace, agentName string, status OhMyCodeTaskStatus, done func(status OhMyCodeTaskStatus, timeout time.Duration)) {
+	cfg := m.config.OhMyCode.Tasks
+	status.State = OhMyCodeTaskRunning
+	status.StartedAt = time.Now().UTC()
+	task := &ohMyCodeTask{
+		status:    status,
+		workspace: ws,
+		agent:     agentName,
+		complete:  make(chan ohMyCodeTaskResult, 1),
+	}
+
+	m.mu.Lock()
+	if m.relayCtx == nil {
+		m.relayCtx, m.relayCancel = context.WithCancel(context.Background())
+	}
+	parent := m.relayCtx
+	if m.ohMyCodeTasks == nil {
+		m.ohMyCodeTasks = make(map[string]*ohMyCodeTask)
+	}
+	m.ohMyCodeTasks[status.ID] = task
+	m.relayWG.Add(1)
+	m.mu.Unlock()
+
+	timeout := defaultOhMyCodeTaskTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	interval := ohMyCodeTaskPollInterval
+	if cfg.PollIntervalSeconds > 0 {
+		interval = time.Duration(cfg.PollIntervalSeconds) * time.Second
+	}
+
+	go func() {
+		defer m.relayWG.Done()
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+
+		result, err := followOhMyCodeTask(ctx, task, interval)
+		if errors.Is(err, context.DeadlineExceeded) {
+			result = ohMyCodeTaskResult{State: OhMyCodeTaskTimedOut}
+			err = fmt.Errorf("no completion reported within %s", timeout)
+		} else if err != nil && parent.Err() != nil {
+			// The manager stopped; the task may still be running.
+			m.finishOhMyCodeTask(task, ohMyCodeTaskResult{State: OhMyCodeTaskFailed}, errors.New("gateway stopped before the task finished"))
+			return
+		}
+		done(m.finishOhMyCodeTask(task, result, err), timeout)
+	}()
+}
+
+// followOhMyCodeTask waits for a completion callback or a completion marker
+// in the agent's monitor output.
+func followOhMyCodeTask(ctx context.Context, task *ohMyCodeTask, interval time.Duration) (ohMyCodeTaskResult, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ohMyCodeTaskResult{}, ctx.Err()
+		case result := <-task.complete:
+			return result, nil
+		case <-ticker.C:
+		}
+		output, err := runOhMyCodeAgentManager(ctx, task.workspace.Dir, task.workspace.Script, "", "monitor", task.agent, "--lines", strconv.Itoa(maxOhMyCodeMonitorLines))
+		if err != nil {
+			if ctx.Err() != nil {
+				return ohMyCodeTaskResult{}, ctx.Err()
+			}
+			failures++
+			if failures >= ohMyCodeTaskMonitorFailures {
+				return ohMyCodeTaskResult{State: OhMyCodeTaskFailed}, fmt.Errorf("agent monitor failed: %w", err)
+			}
+			continue
+		}
+		failures = 0
+		if result, ok := findOhMyCodeTaskMarker(output, task.status.ID); ok {
+			return result, nil
+		}
+	}
+}
+
+// findOhMyCodeTaskMarker returns the last completion marker for taskID in
+// output.
+func findOhMyCodeTaskMarker(output, taskID string) (ohMyCodeTaskResult, bool) {
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		for _, marker := range []struct {
+			prefix string
+			state  string
+		}{
+			{ohMyCodeTaskDoneMarker, OhMyCodeTaskDone},
+			{ohMyCodeTaskFailedMarker, OhMyCodeTaskFailed},
+		} {
+			idx := strings.Index(lines[i], marker.prefix+" "+taskID)
+			if idx < 0 {
+				continue
+			}
+			rest := lines[i][idx+len(marker.prefix)+1+len(taskID):]
+			if rest != "" && rest[0] != ':' && rest[0] != ' ' {
+				continue
+			}
+			summary := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ":"))
+			return ohMyCodeTaskResult{State: marker.state, Summary: summary}, true
+		}
+	}
+	return ohMyCodeTaskResult{}, false
+}
+
+// CompleteOhMyCodeTask records a completion callback for a running task.
+func (m *Manager) CompleteOhMyCodeTask(taskID string, failed bool, summary string) error {
+	m.mu.RLock()
+	task := m.ohMyCodeTasks[strings.TrimSpace(taskID)]
+	m.mu.RUnlock()
+	if task == nil {
+		return ErrOhMyCodeTaskNotFound
+	}
+	result := ohMyCodeTaskResult{State: OhMyCodeTaskDone, Summary: strings.TrimSpace(summary)}
+	if failed {
+		result.State = OhMyCodeTaskFailed
+	}
+	select {
+	case task.complete <- result:
+		return nil
+	default:
+		return fmt.Errorf("oh-my-code task %s is already completing", taskID)
+	}
+}
+
+func (m *Manager) finishOhMyCodeTask(task *ohMyCodeTask, result ohMyCodeTaskResult, err error) OhMyCodeTaskStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.ohMyCodeTasks, task.status.ID)
+	status := task.status
+	status.State = result.State
+	status.Summary = result.Summary
+	status.Error = errorString(err)
+	if result.State == OhMyCodeTaskFailed && status.Error == "" {
+		status.Error = result.Summary
+	}
+	status.FinishedAt = time.Now().UTC()
+
+	historySize := defaultOhMyCodeTaskHistorySize
+	if size := m.config.OhMyCode.Tasks.HistorySize; size > 0 {
+		historySize = size
+	}
+	m.ohMyCodeTaskHistory = append(m.ohMyCodeTaskHistory, status)
+	if extra := len(m.ohMyCodeTaskHistory) - historySize; extra > 0 {
+		m.ohMyCodeTaskHistory = append([]OhMyCodeTaskStatus(nil), m.ohMyCodeTaskHistory[extra:]...)
+	}
+	return status
+}
+
+func formatOhMyCodeTaskOutcome(status OhMyCodeTaskStatus, timeout time.Duration) string {
+	switch status.State {
+	case OhMyCodeTaskDone:
+		if status.Summary == "" {
+			return fmt.Sprintf("✅ %s finished the task.", status.Agent)
+		}
+		return fmt.Sprintf("✅ %s finished the task: %s", status.Agent, status.Summary)
+	case OhMyCodeTaskTimedOut:
+		return fmt.Sprintf("⌛ %s has not reported completion after %s. Use /monitor %s to check on it.", status.Agent, timeout, status.Agent)
+	default:
+		reason := status.Error
+		if reason == "" {
+			reason = "no reason given"
+		}
+		return fmt.Sprintf("❌ %s could not finish the task: %s", status.Agent, reason)
+	}
+}
+
+// OhMyCodeTasks returns running tasks, oldest first, followed by recently
+// finished tasks, newest first.
+func (m *Manager) OhMyCodeTasks() []OhMyCodeTaskStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tasks := make([]OhMyCodeTaskStatus, 0, len(m.ohMyCodeTasks)+len(m.ohMyCodeTaskHistory))
+	for _, task := range m.ohMyCodeTasks {
+		tasks = append(tasks, task.status)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].StartedAt.Before(tasks[j].StartedAt) })
+	for i := len(m.ohMyCodeTaskHistory) - 1; i >= 0; i-- {
+		tasks = append(tasks, m.ohMyCodeTaskHistory[i])
+	}
+	return tasks
+}
@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// ohMyCodeTaskStub records the task id from the assign prompt and prints the
+// completion marker from monitor once the "finished" file exists.
+const ohMyCodeTaskStub = `import pathlib
+import re
+import sys
+
+here = pathlib.Path(sys.argv[0]).parent
+if sys.argv[1] == "assign":
+    match = re.search(r"- task_id: (\w+)", sys.stdin.read())
+    (here / "task_id").write_text(match.group(1) if match else "")
+    print("assign ok")
+    sys.exit(0)
+
+if sys.argv[1] == "monitor":
+    print("working...")
+    finished = here / "finished"
+    if finished.exists():
+        print(finished.read_text().replace("<id>", (here / "task_id").read_text()))
+    sys.exit(0)
+
+sys.exit(1)
+`
+
+func newOhMyCodeTaskManager(t *testing.T, tasks config.OhMyCodeTaskTrackingConfig) (*Manager, *recordingChannel, string) {
+	t.Helper()
+	oldInterval := ohMyCodeTaskPollInterval
+	ohMyCodeTaskPollInterval = 20 * time.Millisecond
+	t.Cleanup(func() { ohMyCodeTaskPollInterval = oldInterval })
+
+	workspace := t.TempDir()
+	scriptPath := filepath.Join(workspace, "agent_manager_stub.py")
+	if err := os.WriteFile(scriptPath, []byte(ohMyCodeTaskStub), 0644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	tasks.Enabled = true
+	manager := NewManager(&config.AgentsConfig{
+		OhMyCode: &config.OhMyCodeConfig{
+			Enabled:            true,
+			Workspace:          workspace,
+			AgentManagerScript: scriptPath,
+			DefaultAgent:       "qa-1",
+			Tasks:              tasks,
+		},
+	})
+	channel := &recordingChannel{name: "slack"}
+	manager.ChannelManager = channels.NewManager(&config.ChannelsConfig{}, nil)
+	if err := manager.ChannelManager.Register(channel); err != nil {
+		t.Fatalf("register channel: %v", err)
+	}
+	t.Cleanup(func() { manager.Stop(context.Background()) })
+	return manager, channel, workspace
+}
+
+func waitForOhMyCodeTaskState(t *testing.T, manager *Manager, state string) OhMyCodeTaskStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if tasks := manager.OhMyCodeTasks(); len(tasks) == 1 && tasks[0].State == state {
+			return tasks[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("task never reached %s: %#v", state, manager.OhMyCodeTasks())
+	return OhMyCodeTaskStatus{}
+}
+
+func TestAssignOhMyCodeTracksTaskUntilMonitorMarker(t *testing.T) {
+	manager, channel, workspace := newOhMyCodeTaskManager(t, config.OhMyCodeTaskTrackingConfig{})
+	inbound := map[string]interface{}{"channel": "slack", "chat_id": "C1", "thread_ts": "171.1"}
+
+	reply, err := manager.assignOhMyCode(context.Background(), "fix the build", "", inbound)
+	if err != nil {
+		t.Fatalf("assignOhMyCode: %v", err)
+	}
+	if reply != ohMyCodeAssignAckMessage {
+		t.Fatalf("reply=%q", reply)
+	}
+	running := waitForOhMyCodeTaskState(t, manager, OhMyCodeTaskRunning)
+	if running.Agent != "qa-1" || running.Channel != "slack" || running.ChatID != "C1" {
+		t.Fatalf("unexpected running task: %#v", running)
+	}
+
+	marker := "FRACTALBOT_TASK_DONE <id>: build is green again"
+	if err := os.WriteFile(filepath.Join(workspace, "finished"), []byte(marker), 0644); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	done := waitForOhMyCodeTaskState(t, manager, OhMyCodeTaskDone)
+	if done.Summary != "build is green again" || done.FinishedAt.IsZero() {
+		t.Fatalf("unexpected finished task: %#v", done)
+	}
+	messages := waitForMessages(t, channel, 1, 2*time.Second)
+	if len(messages) != 1 {
+		t.Fatalf("expected one outcome message, got %#v", messages)
+	}
+	if messages[0].To != "C1" || messages[0].ThreadTS != "171.1" || messages[0].Text != "✅ qa-1 finished the task: build is green again" {
+		t.Fatalf("unexpected outcome message: %#v", messages[0])
+	}
+}
+
+func TestDispatchRuntimeReportsOhMyCodeHeartbeatReply(t *testing.T) {
+	manager, channel, workspace := newOhMyCodeTaskManager(t, config.OhMyCodeTaskTrackingConfig{})
+	replies := make(chan string, 1)
+	manager.SetHeartbeatReplyHook(func(jobID, runID, reply string) {
+		replies <- jobID + "|" + runID + "|" + reply
+	})
+
+	request := runtimeTestRequest(agentruntime.OhMyCode, "run-1")
+	request.Agent = "qa-1"
+	request.WantReply = true
+	if result := manager.DispatchRuntime(context.Background(), request); result.Status != "assigned" {
+		t.Fatalf("unexpected dispatch result: %#v", result)
+	}
+	waitForOhMyCodeTaskState(t, manager, OhMyCodeTaskRunning)
+
+	marker := "FRACTALBOT_TASK_DONE <id>: HEARTBEAT_OK"
+	if err := os.WriteFile(filepath.Join(workspace, "finished"), []byte(marker), 0644); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	select {
+	case reply := <-replies:
+		if reply != "job-1|run-1|HEARTBEAT_OK" {
+			t.Fatalf("unexpected heartbeat reply: %q", reply)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat reply was not reported")
+	}
+	if messages := channel.messages(); len(messages) != 0 {
+		t.Fatalf("heartbeat outcome was posted to chat: %#v", messages)
+	}
+}
+
+func TestCompleteOhMyCodeTaskPostsFailure(t *testing.T) {
+	manager, channel, _ := newOhMyCodeTaskManager(t, config.OhMyCodeTaskTrackingConfig{})
+	if _, err := manager.assignOhMyCode(context.Background(), "deploy", "", map[string]interface{}{"channel": "slack", "chat_id": "C1"}); err != nil {
+		t.Fatalf("assignOhMyCode: %v", err)
+	}
+	running := waitForOhMyCodeTaskState(t, manager, OhMyCodeTaskRunning)
+
+	if err := manager.CompleteOhMyCodeTask("missing", false, ""); !errors.Is(err, ErrOhMyCodeTaskNotFound) {
+		t.Fatalf("expected ErrOhMyCodeTaskNotFound, got %v", err)
+	}
+	if err := manager.CompleteOhMyCodeTask(running.ID, true, "staging is down"); err != nil {
+		t.Fatalf("CompleteOhMyCodeTask: %v", err)
+	}
+	failed := waitForOhMyCodeTaskState(t, manager, OhMyCodeTaskFailed)
+	if failed.Error != "staging is down" {
+		t.Fatalf("unexpected failed task: %#v", failed)
+	}
+	messages := waitForMessages(t, channel, 1, 2*time.Second)
+	if len(messages) != 1 || messages[0].Text != "❌ qa-1 could not finish the task: staging is down" {
+		t.Fatalf("unexpected outcome messages: %#v", messages)
+	}
+}
+
+func TestAssignOhMyCodeSkipsTrackingWithoutOrigin(t *testing.T) {
+	manager, _, workspace := newOhMyCodeTaskManager(t, config.OhMyCodeTaskTrackingConfig{})
+	if _, err := manager.assignOhMyCode(context.Background(), "hello", "", nil); err != nil {
+		t.Fatalf("assignOhMyCode: %v", err)
+	}
+	if tasks := manager.OhMyCodeTasks(); len(tasks) != 0 {
+		t.Fatalf("expected no tracked tasks, got %#v", tasks)
+	}
+	taskID, err := os.ReadFile(filepath.Join(workspace, "task_id"))
+	if err != nil {
+		t.Fatalf("read task id: %v", err)
+	}
+	if len(taskID) != 0 {
+		t.Fatalf("prompt should not carry a task id, got %q", taskID)
+	}
+}
+
+func TestFindOhMyCodeTaskMarker(t *testing.T) {
+	output := strings.Join([]string{
+		"- When you finish, print one line: FRACTALBOT_TASK_DONE <task_id>: <one-line summary>",
+		"FRACTALBOT_TASK_DONE abc1: wrong task",
+		"FRACTALBOT_TASK_FAILED abc: could not reach the database",
+	}, "\n")
+	result, ok := findOhMyCodeTaskMarker(output, "abc")
+	if !ok || result.State != OhMyCodeTaskFailed || result.Summary != "could not reach the database" {
+		t.Fatalf("unexpected marker result: %#v ok=%t", result, ok)
+	}
+	if _, ok := findOhMyCodeTaskMarker("FRACTALBOT_TASK_DONE abc1: other", "abc"); ok {
+		t.Fatal("marker for another task must not match")
+	}
+}
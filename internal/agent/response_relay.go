@@ -0,0 +1,238 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+const (
+	defaultResponseRelayTimeout = 15 * time.Minute
+	responseRelaySendTimeout    = 15 * time.Second
+)
+
+// ResponseRelayStatus is exposed in /status for runtimes that relay agent
+// replies back to chat.
+type ResponseRelayStatus struct {
+	Active        int
+	Relayed       int
+	LastRelayedAt time.Time
+	LastError     string
+}
+
+// responseRelayApplies reports whether a reply to envelope should be relayed
+// under cfg. Only chat messages with a known origin are relayed.
+func responseRelayApplies(cfg config.ResponseRelayConfig, envelope InboundAppEnvelope) bool {
+	if !cfg.Enabled || envelope.Source != "" {
+		return false
+	}
+	channel := strings.TrimSpace(envelope.Channel)
+	if channel == "" || strings.TrimSpace(envelope.ChatID) == "" {
+		return false
+	}
+	if len(cfg.Channels) == 0 {
+		return true
+	}
+	for _, allowed := range cfg.Channels {
+		if strings.EqualFold(strings.TrimSpace(allowed), channel) {
+			return true
+		}
+	}
+	return false
+}
+
+func responseRelayTimeout(cfg config.ResponseRelayConfig) time.Duration {
+	if cfg.TimeoutSeconds > 0 {
+		return time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return defaultResponseRelayTimeout
+}
+
+// responseRelayStillWorkingNotice is posted once when a turn runs longer than
+// stillWorkingSeconds without a relayed reply.
+const responseRelayStillWorkingNotice = "⏳ Still working…"
+
+// startResponseRelay runs follow in the background until it returns, the
+// relay timeout passes, or the manager stops. follow posts replies through
+// send, which routes them to the chat envelope came from.
+func (m *Manager) startResponseRelay(runtimeName string, cfg config.ResponseRelayConfig, envelope InboundAppEnvelope, follow func(ctx context.Context, send func(text string) error) error) {
+	m.mu.Lock()
+	if m.relayCtx == nil {
+		m.relayCtx, m.relayCancel = context.WithCancel(context.Background())
+	}
+	parent := m.relayCtx
+	if m.relays == nil {
+		m.relays = make(map[string]*ResponseRelayStatus)
+	}
+	status := m.relays[runtimeName]
+	if status == nil {
+		status = &ResponseRelayStatus{}
+		m.relays[runtimeName] = status
+	}
+	status.Active++
+	m.relayWG.Add(1)
+	m.mu.Unlock()
+
+	go func() {
+		defer m.relayWG.Done()
+		ctx, cancel := context.WithTimeout(parent, responseRelayTimeout(cfg))
+		defer cancel()
+
+		var replied int32
+		if cfg.StillWorkingSeconds > 0 {
+			notice := time.AfterFunc(time.Duration(cfg.StillWorkingSeconds)*time.Second, func() {
+				if atomic.LoadInt32(&replied) != 0 {
+					return
+				}
+				if err := m.sendToOrigin(ctx, envelope, responseRelayStillWorkingNotice); err != nil && ctx.Err() == nil {
+					log.Printf("%s response relay: %v", runtimeName, err)
+				}
+			})
+			defer notice.Stop()
+		}
+		err := follow(ctx, func(text string) error {
+			if strings.TrimSpace(text) == "" {
+				return nil
+			}
+			atomic.StoreInt32(&replied, 1)
+			return m.sendRelayedResponse(ctx, runtimeName, envelope, text)
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("no reply within %s", responseRelayTimeout(cfg))
+		}
+		if errors.Is(err, context.Canceled) {
+			err = nil
+		}
+		if err != nil {
+			log.Printf("%s response relay: %v", runtimeName, err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		status.Active--
+		if err != nil {
+			status.LastError = err.Error()
+		}
+	}()
+}
+
+// startHeartbeatReplyCapture runs follow in the background, like a response
+// relay, and hands the agent's final answer to a wakeup to the heartbeat
+// reply hook instead of a chat.
+func (m *Manager) startHeartbeatReplyCapture(runtimeName string, cfg config.ResponseRelayConfig, request agentruntime.DispatchRequest, follow func(ctx context.Context) (string, error)) {
+	m.mu.Lock()
+	if m.relayCtx == nil {
+		m.relayCtx, m.relayCancel = context.WithCancel(context.Background())
+	}
+	parent := m.relayCtx
+	m.relayWG.Add(1)
+	m.mu.Unlock()
+
+	go func() {
+		defer m.relayWG.Done()
+		ctx, cancel := context.WithTimeout(parent, responseRelayTimeout(cfg))
+		defer cancel()
+		reply, err := follow(ctx)
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("no reply within %s", responseRelayTimeout(cfg))
+		}
+		if err != nil {
+			log.Printf("%s heartbeat reply of job %s: %v", runtimeName, request.JobID, err)
+			return
+		}
+		m.notifyHeartbeatReply(request, reply)
+	}()
+}
+
+// sendRelayedResponse posts text to the chat and thread envelope came from.
+func (m *Manager) sendRelayedResponse(ctx context.Context, runtimeName string, envelope InboundAppEnvelope, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if err := m.sendToOrigin(ctx, envelope, text); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if status := m.relays[runtimeName]; status != nil {
+		status.Relayed++
+		status.LastRelayedAt = time.Now().UTC()
+		status.LastError = ""
+	}
+	m.mu.Unlock()
+
+	m.inboundHookMu.RLock()
+	hook := m.replyRelayedHook
+	m.inboundHookMu.RUnlock()
+	if hook != nil {
+		hook(runtimeName, strings.TrimSpace(envelope.SelectedAgent))
+	}
+	return nil
+}
+
+func (m *Manager) sendToOrigin(ctx context.Context, envelope InboundAppEnvelope, text string) error {
+	channelName := strings.TrimSpace(envelope.Channel)
+	if m.ChannelManager == nil {
+		return errors.New("channel manager is not configured")
+	}
+	channel := m.ChannelManager.Get(channelName)
+	if channel == nil {
+		return fmt.Errorf("channel %s is not registered", channelName)
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, responseRelaySendTimeout)
+	defer cancel()
+	if _, err := channel.Send(sendCtx, channels.OutboundMessage{
+		To:       strings.TrimSpace(envelope.ChatID),
+		Text:     text,
+		ThreadTS: strings.TrimSpace(envelope.ThreadTS),
+	}); err != nil {
+		return fmt.Errorf("relay reply to %s: %w", channelName, err)
+	}
+	return nil
+}
+
+func (m *Manager) stopResponseRelays(ctx context.Context) {
+	m.mu.Lock()
+	cancel := m.relayCancel
+	m.relayCtx = nil
+	m.relayCancel = nil
+	m.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		m.relayWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// ResponseRelayStatus returns the relay status of a runtime, or nil if it has
+// never relayed a reply.
+func (m *Manager) ResponseRelayStatus(runtimeName string) *ResponseRelayStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status := m.relays[runtimeName]
+	if status == nil {
+		return nil
+	}
+	copied := *status
+	return &copied
+}
@@ -24,46 +24,42 @@ func (m *Manager) DispatchRuntime(ctx context.Context, request agentruntime.Disp
 		return result
 	}
 
-	switch request.Runtime {
-	case agentruntime.OhMyCode:
-		return m.dispatchOhMyCodeRuntime(ctx, request)
-	case agentruntime.CodexAppCDP:
-		return m.dispatchCodexAppRuntime(ctx, request)
-	case agentruntime.ClaudeDesktop:
-		return m.dispatchClaudeDesktopRuntime(ctx, request)
-	default:
+	rt := m.Runtime(request.Runtime)
+	if rt == nil {
 		result.Status = "error"
 		result.Error = fmt.Sprintf("unsupported Agent Runtime %q", request.Runtime)
 		return result
 	}
+	return rt.DispatchRuntime(ctx, request)
 }
 
 func (m *Manager) dispatchOhMyCodeRuntime(ctx context.Context, request agentruntime.DispatchRequest) agentruntime.DispatchResult {
 	result := agentruntime.DispatchResult{Runtime: request.Runtime, Agent: request.Agent}
-	workspace, script, err := m.resolveOhMyCodeWorkspaceAndScript()
+	ws, name, err := m.resolveOhMyCodeAgent(request.Agent)
 	if err != nil {
 		return runtimeDispatchError(result, err)
 	}
-	name, err := m.validateOhMyCodeAgent(request.Agent)
-	if err != nil {
-		return runtimeDispatchError(result, err)
-	}
-	result.Agent = name
+	result.Agent = ws.qualify(name)
 
-	timeout := defaultOhMyCodeAssignTimeout
-	if m.config.OhMyCode.AssignTimeoutSeconds > 0 {
-		timeout = time.Duration(m.config.OhMyCode.AssignTimeoutSeconds) * time.Second
-	}
 	dispatchCtx := ctx
-	if timeout > 0 {
+	if ws.AssignTimeout > 0 {
 		var cancel context.CancelFunc
-		dispatchCtx, cancel = context.WithTimeout(ctx, timeout)
+		dispatchCtx, cancel = context.WithTimeout(ctx, ws.AssignTimeout)
 		defer cancel()
 	}
-	if _, err := runOhMyCodeAgentManager(dispatchCtx, workspace, script, buildRuntimePrompt(request), "assign", name); err != nil {
+	prompt := buildRuntimePrompt(request, "")
+	var taskID string
+	if request.WantReply {
+		taskID = newEnvelopeID()
+		prompt += buildOhMyCodeHeartbeatTrackingPrompt(taskID)
+	}
+	if _, err := runOhMyCodeAgentManager(dispatchCtx, ws.Dir, ws.Script, prompt, "assign", name); err != nil {
 		return runtimeDispatchError(result, err)
 	}
 	result.Status = "assigned"
+	if taskID != "" {
+		m.startOhMyCodeHeartbeatTask(ws, taskID, name, request)
+	}
 	return result
 }
 
@@ -77,14 +73,18 @@ func (m *Manager) dispatchCodexAppRuntime(ctx context.Context, request agentrunt
 		return runtimeDispatchError(result, err)
 	}
 	result.Agent = name
+	cfg := m.config.CodexAppCDP
 	envelope := buildRuntimeAppEnvelope(request, name)
-	delivery := m.deliverCodexAppEnvelope(ctx, m.config.CodexAppCDP, envelope, buildRuntimePrompt(request))
+	delivery := m.deliverCodexAppEnvelope(ctx, cfg, envelope, buildRuntimePrompt(request, envelope.ID))
 	result.Status = delivery.Status
 	result.EnvelopeID = delivery.EnvelopeID
 	result.InboxPath = delivery.InboxPath
 	if delivery.Error != nil {
 		result.Error = delivery.Error.Error()
 	}
+	if delivery.CDPDelivered && request.WantReply {
+		m.startCodexAppHeartbeatReply(cfg, envelope, delivery.ConversationID, request)
+	}
 	return result
 }
 
@@ -98,14 +98,18 @@ func (m *Manager) dispatchClaudeDesktopRuntime(ctx context.Context, request agen
 		return runtimeDispatchError(result, err)
 	}
 	result.Agent = name
+	cfg := m.config.ClaudeDesktop
 	envelope := buildRuntimeAppEnvelope(request, name)
-	delivery := m.deliverClaudeDesktopEnvelope(ctx, m.config.ClaudeDesktop, envelope, buildRuntimePrompt(request))
+	delivery := m.deliverClaudeDesktopEnvelope(ctx, cfg, envelope, buildRuntimePrompt(request, envelope.ID))
 	result.Status = delivery.Status
 	result.EnvelopeID = delivery.EnvelopeID
 	result.InboxPath = delivery.InboxPath
 	if delivery.Error != nil {
 		result.Error = delivery.Error.Error()
 	}
+	if delivery.Status == "delivered" && request.WantReply {
+		m.startClaudeDesktopHeartbeatReply(cfg, envelope, delivery.ConversationID, request)
+	}
 	return result
 }
 
@@ -137,7 +141,9 @@ func buildRuntimeAppEnvelope(request agentruntime.DispatchRequest, agentName str
 	}
 }
 
-func buildRuntimePrompt(request agentruntime.DispatchRequest) string {
+// buildRuntimePrompt describes a wakeup to the agent. envelopeID, when set,
+// lets the runtime find the agent's turn to capture its reply.
+func buildRuntimePrompt(request agentruntime.DispatchRequest, envelopeID string) string {
 	profiles := append([]string(nil), request.CronProfiles...)
 	sort.Strings(profiles)
 	var builder strings.Builder
@@ -146,6 +152,9 @@ func buildRuntimePrompt(request agentruntime.DispatchRequest) string {
 	builder.WriteString(fmt.Sprintf("- run_id: %s\n", strings.TrimSpace(request.RunID)))
 	builder.WriteString(fmt.Sprintf("- runtime: %s\n", strings.TrimSpace(request.Runtime)))
 	builder.WriteString(fmt.Sprintf("- agent: %s\n", strings.TrimSpace(request.Agent)))
+	if envelopeID = strings.TrimSpace(envelopeID); envelopeID != "" {
+		builder.WriteString(fmt.Sprintf("- envelope_id: %s\n", envelopeID))
+	}
 	if !request.ScheduledAt.IsZero() {
 		builder.WriteString(fmt.Sprintf("- scheduled_at: %s\n", request.ScheduledAt.UTC().Format(time.RFC3339Nano)))
 	}
@@ -156,7 +165,9 @@ func buildRuntimePrompt(request agentruntime.DispatchRequest) string {
 	builder.WriteString("Instruction:\n")
 	builder.WriteString(strings.TrimSpace(request.Text))
 	builder.WriteString("\n")
-	if len(profiles) > 0 {
+	if request.WantReply {
+		builder.WriteString(fmt.Sprintf("\nIf and only if there is no actionable work, answer with exactly %s and nothing else. FractalBot reduces this heartbeat frequency after repeated %s answers.\n", agentruntime.HeartbeatOK, agentruntime.HeartbeatOK))
+	} else if len(profiles) > 0 {
 		builder.WriteString("\nIf and only if there is no actionable work, you may reduce this heartbeat frequency with:\n")
 		builder.WriteString(fmt.Sprintf("fractalbot heartbeat cron set --job %s --profile <profile> --reason \"no actionable tasks\"\n", strings.TrimSpace(request.JobID)))
 		builder.WriteString("Allowed profiles: ")
@@ -129,8 +129,9 @@ func TestDispatchRuntimeRejectsInvalidTargetAndText(t *testing.T) {
 func TestBuildRuntimePromptOnlyOffersConfiguredProfiles(t *testing.T) {
 	request := runtimeTestRequest(agentruntime.CodexAppCDP, "run-1")
 	request.CronProfiles = []string{"deep-idle", "idle"}
-	prompt := buildRuntimePrompt(request)
+	prompt := buildRuntimePrompt(request, "env-1")
 	for _, expected := range []string{
+		"- envelope_id: env-1",
 		"fractalbot heartbeat cron set --job job-1 --profile <profile>",
 		"Allowed profiles: deep-idle, idle",
 		"If and only if there is no actionable work",
@@ -142,6 +143,60 @@ func TestBuildRuntimePromptOnlyOffersConfiguredProfiles(t *testing.T) {
 	if strings.Contains(prompt, "channel:") || strings.Contains(prompt, "chat_id:") {
 		t.Fatalf("runtime prompt contains channel identity: %s", prompt)
 	}
+
+	request.WantReply = true
+	prompt = buildRuntimePrompt(request, "")
+	if !strings.Contains(prompt, "answer with exactly HEARTBEAT_OK") || strings.Contains(prompt, "cron set") || strings.Contains(prompt, "envelope_id:") {
+		t.Fatalf("reply prompt should ask for HEARTBEAT_OK instead of cron set: %s", prompt)
+	}
+}
+
+func TestDispatchRuntimeReportsCodexAppHeartbeatReply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stubCodexAppRollout(t, path)
+
+	manager := NewManager(&config.AgentsConfig{CodexAppCDP: &config.CodexAppCDPConfig{
+		Enabled:        true,
+		CDPEndpoint:    "http://127.0.0.1:1",
+		ConversationID: "thread-1",
+		DefaultAgent:   "main",
+		AllowedAgents:  []string{"main"},
+		RepairPolicy:   "off",
+	}})
+	manager.codexAppCDPClient = &recordingCodexAppCDPClient{}
+	defer manager.Stop(context.Background())
+	replies := make(chan string, 1)
+	manager.SetHeartbeatReplyHook(func(jobID, runID, reply string) {
+		replies <- jobID + "|" + runID + "|" + reply
+	})
+
+	request := runtimeTestRequest(agentruntime.CodexAppCDP, "run-1")
+	request.WantReply = true
+	result := manager.DispatchRuntime(context.Background(), request)
+	if result.Status != "delivered" || result.Reply != "" {
+		t.Fatalf("unexpected dispatch result: %#v", result)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(rolloutLine(t, map[string]string{"type": "user_message", "message": "- envelope_id: " + result.EnvelopeID}))
+	_, _ = file.WriteString(rolloutLine(t, map[string]string{"type": "agent_message", "message": "checking the queue"}))
+	_, _ = file.WriteString(rolloutLine(t, map[string]string{"type": "task_complete", "last_agent_message": "HEARTBEAT_OK"}))
+	_ = file.Close()
+
+	select {
+	case reply := <-replies:
+		if reply != "job-1|run-1|HEARTBEAT_OK" {
+			t.Fatalf("unexpected heartbeat reply: %q", reply)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("heartbeat reply was not reported")
+	}
 }
 
 func runtimeTestRequest(runtimeName, runID string) agentruntime.DispatchRequest {
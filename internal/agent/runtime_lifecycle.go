@@ -0,0 +1,309 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+)
+
+// lifecycleRuntime returns the runtime that serves the agent lifecycle
+// commands: the runtime inbound messages are routed to.
+func (m *Manager) lifecycleRuntime() (agentruntime.Lifecycle, error) {
+	rt := m.routingRuntime()
+	if rt == nil {
+		// Keeps the agents.ohMyCode configuration errors when nothing is
+		// enabled.
+		rt = m.Runtime(agentruntime.OhMyCode)
+	}
+	if rt == nil {
+		return nil, errors.New("no agent runtime is enabled")
+	}
+	lifecycle, ok := rt.(agentruntime.Lifecycle)
+	if !ok {
+		return nil, fmt.Errorf("%s runtime does not support agent lifecycle commands", rt.Name())
+	}
+	return lifecycle, nil
+}
+
+// MonitorAgent shows recent output of agentName in the active runtime.
+func (m *Manager) MonitorAgent(ctx context.Context, agentName string, lines int) (string, error) {
+	lifecycle, err := m.lifecycleRuntime()
+	if err != nil {
+		return "", err
+	}
+	return lifecycle.MonitorAgent(ctx, agentName, lines)
+}
+
+// StartAgent starts agentName in the active runtime.
+func (m *Manager) StartAgent(ctx context.Context, agentName string) (string, error) {
+	lifecycle, err := m.lifecycleRuntime()
+	if err != nil {
+		return "", err
+	}
+	return lifecycle.StartAgent(ctx, agentName)
+}
+
+// StopAgent stops agentName in the active runtime.
+func (m *Manager) StopAgent(ctx context.Context, agentName string) (string, error) {
+	lifecycle, err := m.lifecycleRuntime()
+	if err != nil {
+		return "", err
+	}
+	return lifecycle.StopAgent(ctx, agentName)
+}
+
+// Doctor checks the active runtime. target is an oh-my-code workspace name;
+// other runtimes take none.
+func (m *Manager) Doctor(ctx context.Context, target string) (string, error) {
+	lifecycle, err := m.lifecycleRuntime()
+	if err != nil {
+		return "", err
+	}
+	return lifecycle.Doctor(ctx, target)
+}
+
+func unsupportedLifecycleAction(command, runtimeName, reason string) string {
+	return fmt.Sprintf("⚠️ %s is not supported by the %s runtime: %s.", command, runtimeName, reason)
+}
+
+// monitorCodexApp shows the last turns of the resolved Codex conversation.
+// The /monitor count limits turns, one per user or assistant message.
+func (m *Manager) monitorCodexApp(ctx context.Context, agentName string, limit int) (string, error) {
+	if _, err := m.validateCodexAppAgent(agentName); err != nil {
+		return "", err
+	}
+	if limit <= 0 {
+		limit = defaultOhMyCodeMonitorLines
+	}
+	if limit > maxOhMyCodeMonitorLines {
+		limit = maxOhMyCodeMonitorLines
+	}
+	cfg := m.config.CodexAppCDP
+	ctx, cancel := context.WithTimeout(ctx, defaultOhMyCodeMonitorTimeout)
+	defer cancel()
+
+	conversationID, err := m.resolveCodexAppConversation(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	if conversationID == "" {
+		return "No Codex App conversation is resolved; configure agents.codexAppCDP.targetProject or conversationId.", nil
+	}
+	path, err := codexAppRolloutPath(ctx, cfg, conversationID)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(path) == "" {
+		return fmt.Sprintf("Codex App conversation %s has no rollout yet.", conversationID), nil
+	}
+	turns, err := readCodexAppRolloutTurns(path)
+	if err != nil {
+		return "", err
+	}
+	if len(turns) > limit {
+		turns = turns[len(turns)-limit:]
+	}
+	header := fmt.Sprintf("Codex App conversation %s", conversationID)
+	if len(turns) == 0 {
+		return header + ": no messages yet.", nil
+	}
+	return header + "\n" + strings.Join(turns, "\n"), nil
+}
+
+// readCodexAppRolloutTurns returns the user and assistant messages of a
+// rollout file, one turn per message, as "user: ..." and "assistant: ...".
+func readCodexAppRolloutTurns(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open Codex App rollout: %w", err)
+	}
+	defer file.Close()
+
+	var turns []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event codexAppRolloutEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Type != "event_msg" {
+			continue
+		}
+		role := ""
+		switch event.Payload.Type {
+		case "user_message":
+			role = "user"
+		case "agent_message":
+			role = "assistant"
+		default:
+			continue
+		}
+		turns = append(turns, role+": "+strings.TrimSpace(event.Payload.Message))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read Codex App rollout: %w", err)
+	}
+	return turns, nil
+}
+
+// startCodexApp launches Codex App with the configured repair policy when
+// CDP is not reachable.
+func (m *Manager) startCodexApp(ctx context.Context, agentName string) (string, error) {
+	if _, err := m.validateCodexAppAgent(agentName); err != nil {
+		return "", err
+	}
+	cfg := m.config.CodexAppCDP
+	policy := codexAppRepairPolicy(cfg)
+	if policy != codexAppCDPRepairRelaunch && policy != codexAppCDPRepairNewInstance {
+		return unsupportedLifecycleAction("/startagent", agentruntime.CodexAppCDP, fmt.Sprintf("agents.codexAppCDP.repairPolicy is %q", policy)), nil
+	}
+	if strings.TrimSpace(cfg.CDPEndpoint) == "" {
+		return unsupportedLifecycleAction("/startagent", agentruntime.CodexAppCDP, "agents.codexAppCDP.cdpEndpoint is not set"), nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, codexAppDeliveryTimeout(cfg))
+	defer cancel()
+
+	if _, targetCount, err := checkCodexAppCDPReady(ctx, cfg); err == nil {
+		return fmt.Sprintf("✅ Codex App is already running (%d CDP targets).", targetCount), nil
+	}
+	repairErr := codexAppRepair(ctx, cfg, policy)
+	m.updateCodexAppCDPReadinessStatus(cfg, func(status *CodexAppCDPReadinessStatus) {
+		status.LastRepairAt = time.Now().UTC()
+		status.LastRepairAction = policy
+		status.LastRepairError = errorString(repairErr)
+	}, false)
+	if repairErr != nil {
+		return "", repairErr
+	}
+	available, targetCount, err := waitCodexAppCDPReady(ctx, cfg)
+	m.updateCodexAppCDPReadinessStatus(cfg, func(status *CodexAppCDPReadinessStatus) {
+		status.Available = available
+		status.TargetCount = targetCount
+		status.LastCheckedAt = time.Now().UTC()
+		status.LastError = errorString(err)
+	}, false)
+	if err != nil {
+		return "", fmt.Errorf("Codex App did not become ready after %s: %w", policy, err)
+	}
+	return fmt.Sprintf("✅ Started Codex App (%s, %d CDP targets).", policy, targetCount), nil
+}
+
+// stopCodexApp quits Codex App. Only the relaunch policy owns the app
+// process; a new-instance launch may share it with other users.
+func (m *Manager) stopCodexApp(ctx context.Context, agentName string) (string, error) {
+	if _, err := m.validateCodexAppAgent(agentName); err != nil {
+		return "", err
+	}
+	policy := codexAppRepairPolicy(m.config.CodexAppCDP)
+	if policy != codexAppCDPRepairRelaunch {
+		return unsupportedLifecycleAction("/stopagent", agentruntime.CodexAppCDP, fmt.Sprintf("agents.codexAppCDP.repairPolicy is %q", policy)), nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultOhMyCodeLifecycleTimeout)
+	defer cancel()
+	if err := codexAppQuit(ctx); err != nil {
+		return "", err
+	}
+	return "✅ Quit Codex App. Use /startagent to relaunch it.", nil
+}
+
+// doctorCodexApp runs the CDP readiness check without repairing and reports
+// the resolved conversation.
+func (m *Manager) doctorCodexApp(ctx context.Context, target string) (string, error) {
+	if strings.TrimSpace(target) != "" {
+		return "", fmt.Errorf("%s runtime has no doctor targets", agentruntime.CodexAppCDP)
+	}
+	cfg := m.config.CodexAppCDP
+	ctx, cancel := context.WithTimeout(ctx, codexAppDeliveryTimeout(cfg))
+	defer cancel()
+
+	var sb strings.Builder
+	sb.WriteString("Codex App doctor\n")
+	endpoint := strings.TrimSpace(cfg.CDPEndpoint)
+	if endpoint == "" {
+		sb.WriteString("- CDP: not configured\n")
+	} else {
+		available, targetCount, err := checkCodexAppCDPReady(ctx, cfg)
+		m.updateCodexAppCDPReadinessStatus(cfg, func(status *CodexAppCDPReadinessStatus) {
+			status.Available = available
+			status.TargetCount = targetCount
+			status.LastCheckedAt = time.Now().UTC()
+			status.LastError = errorString(err)
+		}, false)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("- CDP %s: ❌ %v\n", endpoint, err))
+		} else {
+			sb.WriteString(fmt.Sprintf("- CDP %s: ✅ %d targets\n", endpoint, targetCount))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("- repair policy: %s\n", codexAppRepairPolicy(cfg)))
+
+	conversationID, err := m.resolveCodexAppConversation(ctx, cfg)
+	switch {
+	case err != nil:
+		sb.WriteString(fmt.Sprintf("- conversation: ❌ %v\n", err))
+	case conversationID == "":
+		sb.WriteString("- conversation: not configured (delivers to the active thread)\n")
+	default:
+		line := fmt.Sprintf("- conversation: %s", conversationID)
+		if status := m.CodexAppCDPResolvedConversationStatus(); status != nil {
+			if status.Title != "" {
+				line += fmt.Sprintf(" %q", status.Title)
+			}
+			if status.Source != "" {
+				line += fmt.Sprintf(" (%s)", status.Source)
+			}
+		}
+		sb.WriteString(line + "\n")
+	}
+	if inbox := strings.TrimSpace(cfg.InboxPath); inbox != "" {
+		sb.WriteString(fmt.Sprintf("- inbox: %s\n", inbox))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// doctorClaudeDesktop runs the readiness check and reports the conversation
+// the default agent resolves to.
+func (m *Manager) doctorClaudeDesktop(ctx context.Context, target string) (string, error) {
+	if strings.TrimSpace(target) != "" {
+		return "", fmt.Errorf("%s runtime has no doctor targets", agentruntime.ClaudeDesktop)
+	}
+	cfg := m.config.ClaudeDesktop
+
+	var sb strings.Builder
+	sb.WriteString("Claude Desktop doctor\n")
+	endpoint := strings.TrimSpace(cfg.CDPEndpoint)
+	if endpoint == "" {
+		sb.WriteString("- CDP: not configured\n")
+	} else {
+		m.checkClaudeDesktopReadiness(ctx, cfg)
+		if status := m.ClaudeDesktopReadinessStatus(); status != nil {
+			if status.Available {
+				sb.WriteString(fmt.Sprintf("- CDP %s: ✅ %d chat targets\n", endpoint, status.ChatTargetCount))
+			} else {
+				sb.WriteString(fmt.Sprintf("- CDP %s: ❌ %s\n", endpoint, status.LastError))
+			}
+		}
+	}
+
+	resolved := m.resolveClaudeDesktopTarget(cfg, ClaudeDesktopEnvelope{SelectedAgent: strings.TrimSpace(cfg.DefaultAgent)})
+	line := fmt.Sprintf("- conversation: mode %s, %s", claudeDesktopConversationMode(cfg), resolved.Source)
+	if resolved.ConversationID != "" {
+		line += " " + resolved.ConversationID
+	}
+	if resolved.ProjectID != "" {
+		line += fmt.Sprintf(" (project %s)", resolved.ProjectID)
+	}
+	sb.WriteString(line + "\n")
+	if status := m.ClaudeDesktopResolvedConversationStatus(); status != nil && status.ID != "" {
+		sb.WriteString(fmt.Sprintf("- last delivery: %s for %s at %s\n", status.ID, status.Agent, status.LastResolvedAt.Format(time.RFC3339)))
+	}
+	if inbox := strings.TrimSpace(cfg.InboxPath); inbox != "" {
+		sb.WriteString(fmt.Sprintf("- inbox: %s\n", inbox))
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp/cdptest"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+func newCodexAppLifecycleManager(cfg *config.CodexAppCDPConfig) *Manager {
+	cfg.Enabled = true
+	cfg.DefaultAgent = "main"
+	return NewManager(&config.AgentsConfig{Router: "codexAppCDP", CodexAppCDP: cfg})
+}
+
+func TestMonitorAgentShowsCodexAppConversationTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	content := rolloutLine(t, map[string]string{"type": "user_message", "message": "first question"}) +
+		rolloutLine(t, map[string]string{"type": "agent_message", "message": "first answer"}) +
+		rolloutLine(t, map[string]string{"type": "task_complete", "last_agent_message": "first answer"}) +
+		rolloutLine(t, map[string]string{"type": "user_message", "message": "second question"}) +
+		rolloutLine(t, map[string]string{"type": "agent_message", "message": "line one\nline two"})
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stubCodexAppRollout(t, path)
+	manager := newCodexAppLifecycleManager(&config.CodexAppCDPConfig{ConversationID: "thread-1"})
+
+	out, err := manager.MonitorAgent(context.Background(), "main", 2)
+	if err != nil {
+		t.Fatalf("MonitorAgent: %v", err)
+	}
+	// The count is of turns: a multi-line message is one turn.
+	want := "Codex App conversation thread-1\nuser: second question\nassistant: line one\nline two"
+	if out != want {
+		t.Fatalf("unexpected monitor output:\n%s", out)
+	}
+	if _, err := manager.MonitorAgent(context.Background(), "other", 3); err == nil {
+		t.Fatal("expected agent outside the allowlist to be rejected")
+	}
+}
+
+func TestStartAgentRepairsCodexAppWithPolicy(t *testing.T) {
+	server := cdptest.NewServer(t)
+	server.AddPage("Codex", "app://codex")
+	server.SetDown(true)
+
+	var repaired []string
+	oldRepair := codexAppRepair
+	codexAppRepair = func(ctx context.Context, cfg *config.CodexAppCDPConfig, policy string) error {
+		repaired = append(repaired, policy)
+		server.SetDown(false)
+		return nil
+	}
+	t.Cleanup(func() { codexAppRepair = oldRepair })
+
+	manager := newCodexAppLifecycleManager(&config.CodexAppCDPConfig{CDPEndpoint: server.URL, RepairPolicy: "new-instance"})
+	out, err := manager.StartAgent(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("StartAgent: %v", err)
+	}
+	if strings.Join(repaired, ",") != "new-instance" || !strings.Contains(out, "Started Codex App (new-instance, 1 CDP targets)") {
+		t.Fatalf("repaired=%v out=%q", repaired, out)
+	}
+	if status := manager.CodexAppCDPReadinessStatus(); status == nil || !status.Available || status.LastRepairAction != "new-instance" {
+		t.Fatalf("unexpected readiness status: %#v", status)
+	}
+
+	out, err = manager.StartAgent(context.Background(), "main")
+	if err != nil || !strings.Contains(out, "already running") || len(repaired) != 1 {
+		t.Fatalf("second start: out=%q err=%v repaired=%v", out, err, repaired)
+	}
+
+	out, err = manager.StopAgent(context.Background(), "main")
+	if err != nil || !strings.Contains(out, "/stopagent is not supported") || !strings.Contains(out, `"new-instance"`) {
+		t.Fatalf("stop with new-instance: out=%q err=%v", out, err)
+	}
+}
+
+func TestStopAgentQuitsCodexAppOnRelaunchPolicy(t *testing.T) {
+	quits := 0
+	oldQuit := codexAppQuit
+	codexAppQuit = func(ctx context.Context) error {
+		quits++
+		return nil
+	}
+	t.Cleanup(func() { codexAppQuit = oldQuit })
+
+	manager := newCodexAppLifecycleManager(&config.CodexAppCDPConfig{CDPEndpoint: "http://127.0.0.1:1", RepairPolicy: "relaunch"})
+	out, err := manager.StopAgent(context.Background(), "main")
+	if err != nil || quits != 1 || !strings.Contains(out, "Quit Codex App") {
+		t.Fatalf("out=%q err=%v quits=%d", out, err, quits)
+	}
+
+	manager = newCodexAppLifecycleManager(&config.CodexAppCDPConfig{CDPEndpoint: "http://127.0.0.1:1", RepairPolicy: "status-only"})
+	out, err = manager.StartAgent(context.Background(), "main")
+	if err != nil || !strings.Contains(out, "/startagent is not supported by the codexAppCDP runtime") {
+		t.Fatalf("start with status-only: out=%q err=%v", out, err)
+	}
+}
+
+func TestDoctorReportsCodexAppReadinessAndConversation(t *testing.T) {
+	server := cdptest.NewServer(t)
+	server.AddPage("Codex", "app://codex")
+	manager := newCodexAppLifecycleManager(&config.CodexAppCDPConfig{CDPEndpoint: server.URL, ConversationID: "thread-1"})
+
+	out, err := manager.Doctor(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	for _, want := range []string{"✅ 1 targets", "repair policy: relaunch", "conversation: thread-1 (config)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("doctor output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestClaudeDesktopLifecycle(t *testing.T) {
+	fake := &fakeClaudeDesktopCDP{loggedIn: true}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	manager := NewManager(&config.AgentsConfig{
+		Router: "claudeDesktop",
+		ClaudeDesktop: &config.ClaudeDesktopConfig{
+			Enabled:          true,
+			CDPEndpoint:      server.URL,
+			ConversationMode: "perThread",
+			DefaultAgent:     "claude",
+			Targets:          map[string]config.ClaudeDesktopTargetConfig{"claude": {ProjectID: "proj-1"}},
+		},
+	})
+
+	out, err := manager.Doctor(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	for _, want := range []string{"✅ 1 chat targets", "mode perThread, new (project proj-1)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("doctor output missing %q:\n%s", want, out)
+		}
+	}
+	if status := manager.ClaudeDesktopReadinessStatus(); status == nil || !status.Available {
+		t.Fatalf("doctor should record readiness: %#v", status)
+	}
+
+	fake.set(true, true)
+	out, err = manager.Doctor(context.Background(), "")
+	if err != nil || !strings.Contains(out, "❌") {
+		t.Fatalf("doctor while down: out=%q err=%v", out, err)
+	}
+
+	out, err = manager.StartAgent(context.Background(), "claude")
+	if err != nil || !strings.Contains(out, "/startagent is not supported by the claudeDesktop runtime") {
+		t.Fatalf("StartAgent: out=%q err=%v", out, err)
+	}
+	if _, err := manager.Doctor(context.Background(), "backend"); err == nil {
+		t.Fatal("expected doctor target to be rejected")
+	}
+}
@@ -0,0 +1,304 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+// RuntimeDefinition registers an Agent Runtime adapter factory. New is called
+// once per Manager.
+type RuntimeDefinition struct {
+	Name string
+
+	// Fallback marks the runtime that receives inbound messages when the
+	// active router is unavailable.
+	Fallback bool
+
+	New func(m *Manager) (agentruntime.Runtime, error)
+}
+
+type runtimeEntry struct {
+	runtime  agentruntime.Runtime
+	fallback bool
+}
+
+var (
+	runtimeDefinitionsMu sync.RWMutex
+	runtimeDefinitions   []RuntimeDefinition
+)
+
+// RegisterRuntime adds a process-wide runtime definition. Managers created
+// afterwards instantiate it.
+func RegisterRuntime(definition RuntimeDefinition) error {
+	definition.Name = strings.TrimSpace(definition.Name)
+	if definition.Name == "" {
+		return errors.New("runtime name is required")
+	}
+	if definition.New == nil {
+		return fmt.Errorf("runtime %q: factory is required", definition.Name)
+	}
+	runtimeDefinitionsMu.Lock()
+	defer runtimeDefinitionsMu.Unlock()
+	for _, existing := range runtimeDefinitions {
+		if existing.Name == definition.Name {
+			return fmt.Errorf("runtime %q already registered", definition.Name)
+		}
+	}
+	runtimeDefinitions = append(runtimeDefinitions, definition)
+	return nil
+}
+
+func init() {
+	builtins := []RuntimeDefinition{
+		{
+			Name:     agentruntime.OhMyCode,
+			Fallback: true,
+			New: func(m *Manager) (agentruntime.Runtime, error) {
+				return ohMyCodeRuntime{m: m}, nil
+			},
+		},
+		{
+			Name: agentruntime.CodexAppCDP,
+			New: func(m *Manager) (agentruntime.Runtime, error) {
+				return codexAppCDPRuntime{m: m}, nil
+			},
+		},
+		{
+			Name: agentruntime.ClaudeDesktop,
+			New: func(m *Manager) (agentruntime.Runtime, error) {
+				return claudeDesktopRuntime{m: m}, nil
+			},
+		},
+	}
+	for _, definition := range builtins {
+		if err := RegisterRuntime(definition); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (m *Manager) initRuntimes() {
+	runtimeDefinitionsMu.RLock()
+	definitions := append([]RuntimeDefinition(nil), runtimeDefinitions...)
+	runtimeDefinitionsMu.RUnlock()
+
+	for _, definition := range definitions {
+		rt, err := definition.New(m)
+		if err != nil {
+			log.Printf("agent runtime %s: %v", definition.Name, err)
+			continue
+		}
+		if err := m.addRuntime(rt, definition.Fallback); err != nil {
+			log.Printf("agent runtime %s: %v", definition.Name, err)
+		}
+	}
+}
+
+// RegisterRuntime adds a runtime adapter to this manager only.
+func (m *Manager) RegisterRuntime(rt agentruntime.Runtime) error {
+	return m.addRuntime(rt, false)
+}
+
+func (m *Manager) addRuntime(rt agentruntime.Runtime, fallback bool) error {
+	if rt == nil {
+		return errors.New("runtime is nil")
+	}
+	name := strings.TrimSpace(rt.Name())
+	if name == "" {
+		return errors.New("runtime name is required")
+	}
+	m.runtimesMu.Lock()
+	defer m.runtimesMu.Unlock()
+	for _, existing := range m.runtimes {
+		if existing.runtime.Name() == name {
+			return fmt.Errorf("runtime %q already registered", name)
+		}
+	}
+	m.runtimes = append(m.runtimes, runtimeEntry{runtime: rt, fallback: fallback})
+	return nil
+}
+
+// Runtime returns the runtime adapter registered under name.
+func (m *Manager) Runtime(name string) agentruntime.Runtime {
+	name = strings.TrimSpace(name)
+	m.runtimesMu.RLock()
+	defer m.runtimesMu.RUnlock()
+	for _, entry := range m.runtimes {
+		if entry.runtime.Name() == name {
+			return entry.runtime
+		}
+	}
+	return nil
+}
+
+// Runtimes returns registered runtime adapters in registration order.
+func (m *Manager) Runtimes() []agentruntime.Runtime {
+	m.runtimesMu.RLock()
+	defer m.runtimesMu.RUnlock()
+	out := make([]agentruntime.Runtime, 0, len(m.runtimes))
+	for _, entry := range m.runtimes {
+		out = append(out, entry.runtime)
+	}
+	return out
+}
+
+// routingRuntime returns the runtime for an inbound message: the active router
+// when enabled, otherwise the first enabled fallback runtime.
+func (m *Manager) routingRuntime() agentruntime.Runtime {
+	if rt := m.Runtime(m.activeRouter()); rt != nil && rt.Enabled() {
+		return rt
+	}
+	m.runtimesMu.RLock()
+	defer m.runtimesMu.RUnlock()
+	for _, entry := range m.runtimes {
+		if entry.fallback && entry.runtime.Enabled() {
+			return entry.runtime
+		}
+	}
+	return nil
+}
+
+type ohMyCodeRuntime struct {
+	m *Manager
+}
+
+func (r ohMyCodeRuntime) Name() string { return agentruntime.OhMyCode }
+
+func (r ohMyCodeRuntime) Enabled() bool { return r.m.isOhMyCodeEnabled() }
+
+func (r ohMyCodeRuntime) DefaultAgent() string {
+	if r.m.config == nil || r.m.config.OhMyCode == nil {
+		return ""
+	}
+	return strings.TrimSpace(r.m.config.OhMyCode.DefaultAgent)
+}
+
+func (r ohMyCodeRuntime) Route(ctx context.Context, request agentruntime.RouteRequest) (string, error) {
+	return r.m.assignOhMyCode(ctx, request.Text, request.Agent, request.Data)
+}
+
+func (r ohMyCodeRuntime) DispatchRuntime(ctx context.Context, request agentruntime.DispatchRequest) agentruntime.DispatchResult {
+	return r.m.dispatchOhMyCodeRuntime(ctx, request)
+}
+
+func (r ohMyCodeRuntime) MonitorAgent(ctx context.Context, agent string, lines int) (string, error) {
+	return r.m.monitorOhMyCodeAgent(ctx, agent, lines)
+}
+
+func (r ohMyCodeRuntime) StartAgent(ctx context.Context, agent string) (string, error) {
+	return r.m.startOhMyCodeAgent(ctx, agent)
+}
+
+func (r ohMyCodeRuntime) StopAgent(ctx context.Context, agent string) (string, error) {
+	return r.m.stopOhMyCodeAgent(ctx, agent)
+}
+
+func (r ohMyCodeRuntime) Doctor(ctx context.Context, target string) (string, error) {
+	return r.m.doctorOhMyCode(ctx, target)
+}
+
+func (r ohMyCodeRuntime) ListAgents(ctx context.Context) ([]protocol.AgentInfo, error) {
+	return r.m.listOhMyCodeAgents(ctx)
+}
+
+type codexAppCDPRuntime struct {
+	m *Manager
+}
+
+func (r codexAppCDPRuntime) Name() string { return agentruntime.CodexAppCDP }
+
+func (r codexAppCDPRuntime) Enabled() bool { return r.m.isCodexAppCDPEnabled() }
+
+func (r codexAppCDPRuntime) DefaultAgent() string {
+	if r.m.config == nil || r.m.config.CodexAppCDP == nil {
+		return ""
+	}
+	return strings.TrimSpace(r.m.config.CodexAppCDP.DefaultAgent)
+}
+
+func (r codexAppCDPRuntime) Route(ctx context.Context, request agentruntime.RouteRequest) (string, error) {
+	return r.m.assignCodexAppCDP(ctx, request.Text, request.Agent, request.Data)
+}
+
+func (r codexAppCDPRuntime) DispatchRuntime(ctx context.Context, request agentruntime.DispatchRequest) agentruntime.DispatchResult {
+	return r.m.dispatchCodexAppRuntime(ctx, request)
+}
+
+func (r codexAppCDPRuntime) MonitorAgent(ctx context.Context, agent string, lines int) (string, error) {
+	return r.m.monitorCodexApp(ctx, agent, lines)
+}
+
+func (r codexAppCDPRuntime) StartAgent(ctx context.Context, agent string) (string, error) {
+	return r.m.startCodexApp(ctx, agent)
+}
+
+func (r codexAppCDPRuntime) StopAgent(ctx context.Context, agent string) (string, error) {
+	return r.m.stopCodexApp(ctx, agent)
+}
+
+func (r codexAppCDPRuntime) Doctor(ctx context.Context, target string) (string, error) {
+	return r.m.doctorCodexApp(ctx, target)
+}
+
+func (r codexAppCDPRuntime) ListAgents(ctx context.Context) ([]protocol.AgentInfo, error) {
+	return r.m.listCodexAppAgents(ctx)
+}
+
+type claudeDesktopRuntime struct {
+	m *Manager
+}
+
+func (r claudeDesktopRuntime) Name() string { return agentruntime.ClaudeDesktop }
+
+func (r claudeDesktopRuntime) Enabled() bool { return r.m.isClaudeDesktopEnabled() }
+
+func (r claudeDesktopRuntime) DefaultAgent() string {
+	if r.m.config == nil || r.m.config.ClaudeDesktop == nil {
+		return ""
+	}
+	return strings.TrimSpace(r.m.config.ClaudeDesktop.DefaultAgent)
+}
+
+func (r claudeDesktopRuntime) Route(ctx context.Context, request agentruntime.RouteRequest) (string, error) {
+	return r.m.assignClaudeDesktop(ctx, request.Text, request.Agent, request.Data)
+}
+
+func (r claudeDesktopRuntime) DispatchRuntime(ctx context.Context, request agentruntime.DispatchRequest) agentruntime.DispatchResult {
+	return r.m.dispatchClaudeDesktopRuntime(ctx, request)
+}
+
+func (r claudeDesktopRuntime) MonitorAgent(ctx context.Context, agent string, lines int) (string, error) {
+	if _, err := r.m.validateClaudeDesktopAgent(agent); err != nil {
+		return "", err
+	}
+	return unsupportedLifecycleAction("/monitor", r.Name(), "Claude Desktop replies are relayed to chat; use /doctor to check the resolved conversation"), nil
+}
+
+func (r claudeDesktopRuntime) StartAgent(ctx context.Context, agent string) (string, error) {
+	if _, err := r.m.validateClaudeDesktopAgent(agent); err != nil {
+		return "", err
+	}
+	return unsupportedLifecycleAction("/startagent", r.Name(), "Claude Desktop has no repair policy; start it with --remote-debugging-port"), nil
+}
+
+func (r claudeDesktopRuntime) StopAgent(ctx context.Context, agent string) (string, error) {
+	if _, err := r.m.validateClaudeDesktopAgent(agent); err != nil {
+		return "", err
+	}
+	return unsupportedLifecycleAction("/stopagent", r.Name(), "Claude Desktop has no repair policy"), nil
+}
+
+func (r claudeDesktopRuntime) Doctor(ctx context.Context, target string) (string, error) {
+	return r.m.doctorClaudeDesktop(ctx, target)
+}
+
+func (r claudeDesktopRuntime) ListAgents(ctx context.Context) ([]protocol.AgentInfo, error) {
+	return r.m.listClaudeDesktopAgents(ctx)
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+type fakeRuntime struct {
+	name       string
+	enabled    bool
+	routed     []agentruntime.RouteRequest
+	dispatched []agentruntime.DispatchRequest
+}
+
+func (f *fakeRuntime) Name() string         { return f.name }
+func (f *fakeRuntime) Enabled() bool        { return f.enabled }
+func (f *fakeRuntime) DefaultAgent() string { return "fake-default" }
+
+func (f *fakeRuntime) Route(ctx context.Context, request agentruntime.RouteRequest) (string, error) {
+	f.routed = append(f.routed, request)
+	return "routed: " + request.Text, nil
+}
+
+func (f *fakeRuntime) DispatchRuntime(ctx context.Context, request agentruntime.DispatchRequest) agentruntime.DispatchResult {
+	f.dispatched = append(f.dispatched, request)
+	return agentruntime.DispatchResult{Runtime: f.name, Agent: request.Agent, Status: "delivered"}
+}
+
+func TestManagerRoutesToRegisteredRuntime(t *testing.T) {
+	manager := NewManager(&config.AgentsConfig{Router: "fake"})
+	rt := &fakeRuntime{name: "fake", enabled: true}
+	if err := manager.RegisterRuntime(rt); err != nil {
+		t.Fatalf("register runtime: %v", err)
+	}
+	var hookRuntime, hookAgent string
+	manager.SetInboundRoutedHook(func(runtimeName, agentName string) {
+		hookRuntime, hookAgent = runtimeName, agentName
+	})
+
+	reply, err := manager.HandleIncoming(context.Background(), &protocol.Message{Data: map[string]interface{}{
+		"channel": "slack",
+		"text":    "hello",
+	}})
+	if err != nil {
+		t.Fatalf("handle incoming: %v", err)
+	}
+	if reply != "routed: hello" || len(rt.routed) != 1 {
+		t.Fatalf("unexpected reply %q routed=%d", reply, len(rt.routed))
+	}
+	if hookRuntime != "fake" || hookAgent != "fake-default" {
+		t.Fatalf("unexpected hook runtime=%q agent=%q", hookRuntime, hookAgent)
+	}
+
+	result := manager.DispatchRuntime(context.Background(), agentruntime.DispatchRequest{Runtime: "fake", Agent: "a", Text: "wake"})
+	if result.Status != "delivered" || len(rt.dispatched) != 1 {
+		t.Fatalf("unexpected dispatch result %#v", result)
+	}
+}
+
+func TestManagerSkipsDisabledRegisteredRuntime(t *testing.T) {
+	manager := NewManager(&config.AgentsConfig{Router: "fake"})
+	if err := manager.RegisterRuntime(&fakeRuntime{name: "fake"}); err != nil {
+		t.Fatalf("register runtime: %v", err)
+	}
+	reply, err := manager.HandleIncoming(context.Background(), &protocol.Message{Data: map[string]interface{}{
+		"channel": "telegram",
+		"text":    "hello",
+	}})
+	if err != nil {
+		t.Fatalf("handle incoming: %v", err)
+	}
+	if reply != "echo: hello" {
+		t.Fatalf("expected echo fallback, got %q", reply)
+	}
+}
+
+func TestManagerRejectsDuplicateRuntime(t *testing.T) {
+	manager := NewManager(nil)
+	if err := manager.RegisterRuntime(&fakeRuntime{name: agentruntime.OhMyCode}); err == nil {
+		t.Fatal("expected duplicate runtime error")
+	}
+	if manager.Runtime(agentruntime.ClaudeDesktop) == nil {
+		t.Fatal("expected built-in claudeDesktop runtime")
+	}
+}
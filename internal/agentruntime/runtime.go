@@ -5,6 +5,8 @@ package agentruntime
 import (
 	"context"
 	"time"
+
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
 )
 
 const (
@@ -13,6 +15,9 @@ const (
 	ClaudeDesktop = "claudeDesktop"
 )
 
+// HeartbeatOK is the reply an agent gives when a wakeup found no work.
+const HeartbeatOK = "HEARTBEAT_OK"
+
 // DispatchRequest is an internal agent wakeup that is not associated with a
 // messaging channel.
 type DispatchRequest struct {
@@ -26,6 +31,10 @@ type DispatchRequest struct {
 	ExpiresAt    time.Time
 	CoalesceKey  string
 	CronProfiles []string
+	// WantReply asks the runtime for the agent's answer. Runtimes that only
+	// queue the wakeup follow the agent's turn in the background and report
+	// the answer through the Agent Manager's heartbeat reply hook.
+	WantReply bool
 }
 
 // DispatchResult reports whether a runtime accepted, queued, or rejected a
@@ -37,9 +46,62 @@ type DispatchResult struct {
 	EnvelopeID string
 	InboxPath  string
 	Error      string
+	// Reply is the agent's answer when the runtime waits for one, such as
+	// HeartbeatOK. Runtimes that only queue the wakeup leave it empty.
+	Reply string
 }
 
 // Dispatcher routes a request to an explicitly selected Agent Runtime.
 type Dispatcher interface {
 	DispatchRuntime(ctx context.Context, request DispatchRequest) DispatchResult
 }
+
+// RouteRequest is an inbound channel message bound for an Agent Runtime.
+type RouteRequest struct {
+	Text  string
+	Agent string
+	// Data is the inbound message context, such as channel, chat_id and
+	// user_id.
+	Data map[string]interface{}
+}
+
+// Runtime is an Agent Runtime adapter registered with the Agent Manager.
+type Runtime interface {
+	Dispatcher
+
+	// Name returns the runtime identifier used by agents.router.
+	Name() string
+
+	// Enabled reports whether the runtime accepts inbound routing.
+	Enabled() bool
+
+	// DefaultAgent returns the agent used when a message selects none.
+	DefaultAgent() string
+
+	// Route delivers a channel message and returns the reply for the sender.
+	Route(ctx context.Context, request RouteRequest) (string, error)
+}
+
+// Lifecycle is implemented by runtimes that support the /monitor,
+// /startagent, /stopagent, and /doctor channel commands. Actions a runtime
+// cannot perform return an explanation instead of an error.
+type Lifecycle interface {
+	MonitorAgent(ctx context.Context, agent string, lines int) (string, error)
+	StartAgent(ctx context.Context, agent string) (string, error)
+	StopAgent(ctx context.Context, agent string) (string, error)
+	// Doctor checks the runtime. target narrows the check, such as an
+	// oh-my-code workspace; empty checks the default.
+	Doctor(ctx context.Context, target string) (string, error)
+}
+
+// AgentLister is implemented by runtimes that can report their agents for
+// the agent registry. Entries need Name, Status, and optionally Detail and
+// LastActivity; the registry fills in ID and Runtime.
+type AgentLister interface {
+	ListAgents(ctx context.Context) ([]protocol.AgentInfo, error)
+}
+
+// StatusProvider is implemented by runtimes that report extra status fields.
+type StatusProvider interface {
+	RuntimeStatus() interface{}
+}
@@ -150,12 +150,22 @@ func (b *MessageBus) StopAgent(ctx context.Context, agentName string) (string, e
 }
 
 // Doctor forwards lifecycle doctor requests when available.
-func (b *MessageBus) Doctor(ctx context.Context) (string, error) {
+func (b *MessageBus) Doctor(ctx context.Context, workspace string) (string, error) {
 	lifecycle, ok := b.handler.(channels.AgentLifecycle)
 	if !ok || lifecycle == nil {
 		return "", errors.New("agent-manager is not available")
 	}
-	return lifecycle.Doctor(ctx)
+	return lifecycle.Doctor(ctx, workspace)
+}
+
+// List forwards agent registry requests when the underlying handler keeps
+// one.
+func (b *MessageBus) List() []protocol.AgentInfo {
+	directory, ok := b.handler.(channels.AgentDirectory)
+	if !ok || directory == nil {
+		return nil
+	}
+	return directory.List()
 }
 
 // PublishOutbound sends an outbound message through the bus.
@@ -243,6 +253,7 @@ func extractChannelName(msg *protocol.Message) string {
 // Ensure MessageBus satisfies IncomingMessageHandler at compile time.
 var _ channels.IncomingMessageHandler = (*MessageBus)(nil)
 var _ channels.AgentLifecycle = (*MessageBus)(nil)
+var _ channels.AgentDirectory = (*MessageBus)(nil)
 
 // String returns a human-readable description for debugging.
 func (b *MessageBus) String() string {
@@ -63,7 +63,7 @@ func (h *fakeLifecycleHandler) StopAgent(ctx context.Context, agentName string)
 	return h.stopReply, h.stopErr
 }
 
-func (h *fakeLifecycleHandler) Doctor(ctx context.Context) (string, error) {
+func (h *fakeLifecycleHandler) Doctor(ctx context.Context, workspace string) (string, error) {
 	return h.doctorReply, h.doctorErr
 }
 
@@ -532,7 +532,7 @@ func TestLifecycleDelegatesToUnderlyingHandler(t *testing.T) {
 	if got, err := b.StopAgent(context.Background(), "main"); err != nil || got != "stop ok" {
 		t.Fatalf("StopAgent() = %q, %v", got, err)
 	}
-	if got, err := b.Doctor(context.Background()); err != nil || got != "doctor ok" {
+	if got, err := b.Doctor(context.Background(), ""); err != nil || got != "doctor ok" {
 		t.Fatalf("Doctor() = %q, %v", got, err)
 	}
 }
@@ -568,7 +568,7 @@ func TestLifecycleReturnsNotAvailableWithoutUnderlyingLifecycle(t *testing.T) {
 		{
 			name: "doctor",
 			call: func() error {
-				_, err := b.Doctor(context.Background())
+				_, err := b.Doctor(context.Background(), "")
 				return err
 			},
 		},
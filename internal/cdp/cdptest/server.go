@@ -0,0 +1,350 @@
+// Package cdptest provides an in-process fake CDP endpoint for tests.
+//
+// A Server serves /json/version and /json/list for its pages. Each Page
+// accepts any number of WebSocket connections, answers Runtime.evaluate
+// through a test callback, acknowledges domain enable/disable commands, and
+// can emit events or drop its connections to simulate a reload.
+package cdptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+)
+
+// EvaluateFunc answers Runtime.evaluate. A returned error is reported to the
+// client as a script exception.
+type EvaluateFunc func(expression string) (interface{}, error)
+
+// HandlerFunc answers an arbitrary command. A returned error is reported as a
+// protocol error.
+type HandlerFunc func(params json.RawMessage) (interface{}, error)
+
+// Call records one command received by a page.
+type Call struct {
+	Method     string
+	Params     json.RawMessage
+	Expression string
+}
+
+// Server is a fake CDP HTTP endpoint.
+type Server struct {
+	URL string
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex
+	pages []*Page
+	down  bool
+}
+
+// NewServer starts a server that is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/version", s.handleVersion)
+	mux.HandleFunc("/json/list", s.handleList)
+	mux.HandleFunc("/devtools/page/", s.handlePage)
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close stops the server and drops every page connection.
+func (s *Server) Close() {
+	s.mu.Lock()
+	pages := append([]*Page(nil), s.pages...)
+	s.mu.Unlock()
+	for _, page := range pages {
+		page.Disconnect()
+	}
+	s.server.Close()
+}
+
+// SetDown makes the HTTP endpoints answer 503, as if the app had quit.
+func (s *Server) SetDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+// AddPage adds a page target.
+func (s *Server) AddPage(title, url string) *Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page := &Page{
+		id:         fmt.Sprintf("page-%d", len(s.pages)+1),
+		targetType: "page",
+		title:      title,
+		url:        url,
+		handlers:   make(map[string]HandlerFunc),
+		conns:      make(map[*websocket.Conn]*sync.Mutex),
+	}
+	page.wsURL = "ws" + strings.TrimPrefix(s.server.URL, "http") + "/devtools/page/" + page.id
+	s.pages = append(s.pages, page)
+	return page
+}
+
+func (s *Server) isDown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.down
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	if s.isDown() {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"Browser": "cdptest", "Protocol-Version": "1.3"})
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	if s.isDown() {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	s.mu.Lock()
+	pages := append([]*Page(nil), s.pages...)
+	s.mu.Unlock()
+	targets := make([]cdp.Target, 0, len(pages))
+	for _, page := range pages {
+		targets = append(targets, page.Target())
+	}
+	_ = json.NewEncoder(w).Encode(targets)
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/devtools/page/")
+	s.mu.Lock()
+	var page *Page
+	for _, candidate := range s.pages {
+		if candidate.id == id {
+			page = candidate
+		}
+	}
+	s.mu.Unlock()
+	if page == nil {
+		http.NotFound(w, r)
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	page.serve(conn)
+}
+
+// Page is a fake page target.
+type Page struct {
+	id    string
+	wsURL string
+
+	mu          sync.Mutex
+	targetType  string
+	title       string
+	url         string
+	evaluate    EvaluateFunc
+	handlers    map[string]HandlerFunc
+	conns       map[*websocket.Conn]*sync.Mutex
+	connections int
+	calls       []Call
+}
+
+// Target returns the page as listed by /json/list.
+func (p *Page) Target() cdp.Target {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return cdp.Target{ID: p.id, Type: p.targetType, Title: p.title, URL: p.url, WebSocketDebuggerURL: p.wsURL}
+}
+
+// WebSocketURL returns the page's debugger URL.
+func (p *Page) WebSocketURL() string {
+	return p.wsURL
+}
+
+// SetType changes the target type, for example to "webview".
+func (p *Page) SetType(targetType string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targetType = targetType
+}
+
+// SetURL changes the URL reported by /json/list.
+func (p *Page) SetURL(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.url = url
+}
+
+// URL returns the URL reported by /json/list.
+func (p *Page) URL() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.url
+}
+
+// SetTitle changes the title reported by /json/list.
+func (p *Page) SetTitle(title string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.title = title
+}
+
+// OnEvaluate sets the Runtime.evaluate callback. Without one, evaluations
+// return undefined.
+func (p *Page) OnEvaluate(fn EvaluateFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evaluate = fn
+}
+
+// Handle answers method with fn.
+func (p *Page) Handle(method string, fn HandlerFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[method] = fn
+}
+
+// Calls returns every command received so far.
+func (p *Page) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// Expressions returns the evaluated expressions in order.
+func (p *Page) Expressions() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var expressions []string
+	for _, call := range p.calls {
+		if call.Method == "Runtime.evaluate" {
+			expressions = append(expressions, call.Expression)
+		}
+	}
+	return expressions
+}
+
+// Connections returns how many WebSocket connections the page has accepted.
+func (p *Page) Connections() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connections
+}
+
+// Emit sends an event to every open connection.
+func (p *Page) Emit(method string, params interface{}) {
+	p.mu.Lock()
+	conns := make(map[*websocket.Conn]*sync.Mutex, len(p.conns))
+	for conn, writeMu := range p.conns {
+		conns[conn] = writeMu
+	}
+	p.mu.Unlock()
+	for conn, writeMu := range conns {
+		writeMu.Lock()
+		_ = conn.WriteJSON(map[string]interface{}{"method": method, "params": params})
+		writeMu.Unlock()
+	}
+}
+
+// Disconnect drops every open connection, as a renderer swap or crash does.
+func (p *Page) Disconnect() {
+	p.mu.Lock()
+	conns := p.conns
+	p.conns = make(map[*websocket.Conn]*sync.Mutex)
+	p.mu.Unlock()
+	for conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+func (p *Page) serve(conn *websocket.Conn) {
+	writeMu := &sync.Mutex{}
+	p.mu.Lock()
+	p.conns[conn] = writeMu
+	p.connections++
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.conns, conn)
+		p.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	for {
+		var request struct {
+			ID     int64           `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+		response := map[string]interface{}{"id": request.ID}
+		result, protocolErr := p.dispatch(request.Method, request.Params)
+		if protocolErr != nil {
+			response["error"] = map[string]interface{}{"code": -32000, "message": protocolErr.Error()}
+		} else {
+			response["result"] = result
+		}
+		writeMu.Lock()
+		err := conn.WriteJSON(response)
+		writeMu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (p *Page) dispatch(method string, params json.RawMessage) (interface{}, error) {
+	call := Call{Method: method, Params: params}
+	if method == "Runtime.evaluate" {
+		var evaluate struct {
+			Expression string `json:"expression"`
+		}
+		_ = json.Unmarshal(params, &evaluate)
+		call.Expression = evaluate.Expression
+	}
+	p.mu.Lock()
+	p.calls = append(p.calls, call)
+	handler := p.handlers[method]
+	evaluate := p.evaluate
+	p.mu.Unlock()
+
+	switch {
+	case handler != nil:
+		result, err := handler(params)
+		if result == nil {
+			result = map[string]interface{}{}
+		}
+		return result, err
+	case method == "Runtime.evaluate":
+		if evaluate == nil {
+			return map[string]interface{}{"result": map[string]interface{}{"type": "undefined"}}, nil
+		}
+		value, err := evaluate(call.Expression)
+		if err != nil {
+			return map[string]interface{}{
+				"result":           map[string]interface{}{"type": "object", "subtype": "error"},
+				"exceptionDetails": map[string]interface{}{"text": err.Error()},
+			}, nil
+		}
+		return map[string]interface{}{"result": map[string]interface{}{"type": "object", "value": value}}, nil
+	case strings.HasSuffix(method, ".enable") || strings.HasSuffix(method, ".disable"):
+		return map[string]interface{}{}, nil
+	default:
+		return nil, fmt.Errorf("'%s' wasn't found", method)
+	}
+}
@@ -0,0 +1,457 @@
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Events the runtimes subscribe to. Their domain must be enabled first.
+const (
+	EventConsoleAPICalled         = "Runtime.consoleAPICalled"
+	EventExecutionContextsCleared = "Runtime.executionContextsCleared"
+	EventFrameNavigated           = "Page.frameNavigated"
+	EventLoadFired                = "Page.loadEventFired"
+	EventDocumentUpdated          = "DOM.documentUpdated"
+	EventChildNodeInserted        = "DOM.childNodeInserted"
+	EventChildNodeRemoved         = "DOM.childNodeRemoved"
+	EventCharacterDataModified    = "DOM.characterDataModified"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it.
+const subscriberBuffer = 64
+
+// ErrDisconnected is returned for commands that were in flight when the
+// target closed the connection. The command may or may not have run.
+var ErrDisconnected = errors.New("CDP connection closed")
+
+// ErrClosed is returned after Close.
+var ErrClosed = errors.New("CDP session closed")
+
+// ProtocolError is an error response to a command.
+type ProtocolError struct {
+	Method  string
+	Code    int
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("CDP %s failed: %s", e.Method, e.Message)
+}
+
+// EvaluateError is a script exception reported by Runtime.evaluate.
+type EvaluateError struct {
+	Text        string
+	Description string
+}
+
+func (e *EvaluateError) Error() string {
+	if e.Description != "" && e.Description != e.Text {
+		return fmt.Sprintf("CDP script failed: %s: %s", e.Text, e.Description)
+	}
+	return "CDP script failed: " + e.Text
+}
+
+// Event is a CDP notification.
+type Event struct {
+	Method string
+	Params json.RawMessage
+}
+
+type message struct {
+	ID     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Pool keeps one Session per target WebSocket URL.
+type Pool struct {
+	dialer *websocket.Dialer
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewPool creates an empty pool.
+func NewPool() *Pool {
+	return &Pool{dialer: websocket.DefaultDialer, sessions: make(map[string]*Session)}
+}
+
+// Session returns the pooled session for wsURL. It connects lazily on the
+// first command.
+func (p *Pool) Session(wsURL string) *Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	session := p.sessions[wsURL]
+	if session == nil || session.isClosed() {
+		session = newSession(wsURL, p.dialer)
+		p.sessions[wsURL] = session
+	}
+	return session
+}
+
+// Close closes every pooled session.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	sessions := p.sessions
+	p.sessions = make(map[string]*Session)
+	p.mu.Unlock()
+	for _, session := range sessions {
+		session.Close()
+	}
+}
+
+// Session is a multiplexed connection to one target. It is safe for
+// concurrent use. When the target drops the connection, in-flight commands
+// fail with ErrDisconnected and the next command redials and re-enables the
+// domains enabled through Enable. Subscriptions survive reconnects.
+type Session struct {
+	url    string
+	dialer *websocket.Dialer
+
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	conn        *websocket.Conn
+	nextID      int64
+	pending     map[int64]chan message
+	subscribers map[string]map[chan Event]struct{}
+	domains     []string
+	closed      bool
+}
+
+func newSession(wsURL string, dialer *websocket.Dialer) *Session {
+	return &Session{
+		url:         wsURL,
+		dialer:      dialer,
+		pending:     make(map[int64]chan message),
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Dial returns a session for wsURL outside any pool.
+func Dial(ctx context.Context, wsURL string) (*Session, error) {
+	session := newSession(wsURL, websocket.DefaultDialer)
+	if _, err := session.connect(ctx); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// URL returns the target WebSocket URL.
+func (s *Session) URL() string {
+	return s.url
+}
+
+// Call sends a command and decodes its result into result, which may be nil.
+func (s *Session) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.conn != conn {
+		// The connection dropped after connect returned it.
+		s.mu.Unlock()
+		return fmt.Errorf("CDP %s: %w", method, ErrDisconnected)
+	}
+	s.nextID++
+	id := s.nextID
+	reply := make(chan message, 1)
+	s.pending[id] = reply
+	s.mu.Unlock()
+
+	request := map[string]interface{}{"id": id, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	s.writeMu.Lock()
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetWriteDeadline(deadline)
+	err = conn.WriteJSON(request)
+	s.writeMu.Unlock()
+	if err != nil {
+		s.dropPending(id)
+		s.disconnect(conn)
+		return fmt.Errorf("send CDP %s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		s.dropPending(id)
+		return ctx.Err()
+	case response, ok := <-reply:
+		if !ok {
+			return fmt.Errorf("CDP %s: %w", method, ErrDisconnected)
+		}
+		if response.Error != nil {
+			return &ProtocolError{Method: method, Code: response.Error.Code, Message: response.Error.Message}
+		}
+		if result == nil || len(response.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("decode CDP %s result: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// Evaluate runs expression with Runtime.evaluate, awaiting promises, and
+// returns the result by value. Script exceptions are returned as
+// *EvaluateError.
+func (s *Session) Evaluate(ctx context.Context, expression string) (interface{}, error) {
+	var result struct {
+		Result struct {
+			Type  string      `json:"type"`
+			Value interface{} `json:"value,omitempty"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception *struct {
+				Description string `json:"description"`
+			} `json:"exception,omitempty"`
+		} `json:"exceptionDetails,omitempty"`
+	}
+	err := s.Call(ctx, "Runtime.evaluate", map[string]interface{}{
+		"expression":    expression,
+		"awaitPromise":  true,
+		"returnByValue": true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if details := result.ExceptionDetails; details != nil {
+		evalErr := &EvaluateError{Text: details.Text}
+		if details.Exception != nil {
+			evalErr.Description = details.Exception.Description
+		}
+		return nil, evalErr
+	}
+	return result.Result.Value, nil
+}
+
+// Enable enables a domain such as "Page", "Runtime" or "DOM" and re-enables
+// it after every reconnect.
+func (s *Session) Enable(ctx context.Context, domain string) error {
+	if err := s.Call(ctx, domain+".enable", nil, nil); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, enabled := range s.domains {
+		if enabled == domain {
+			return nil
+		}
+	}
+	s.domains = append(s.domains, domain)
+	return nil
+}
+
+// Subscribe delivers events named method until cancel is called. Events are
+// dropped while the channel is full.
+func (s *Session) Subscribe(method string) (<-chan Event, func()) {
+	events := make(chan Event, subscriberBuffer)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		close(events)
+		return events, func() {}
+	}
+	if s.subscribers[method] == nil {
+		s.subscribers[method] = make(map[chan Event]struct{})
+	}
+	s.subscribers[method][events] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if _, ok := s.subscribers[method][events]; ok {
+				delete(s.subscribers[method], events)
+				close(events)
+			}
+		})
+	}
+}
+
+// WaitEvent waits for the next event named method, for example a load
+// after navigation. Subscribe first when the event may fire before WaitEvent
+// is called.
+func (s *Session) WaitEvent(ctx context.Context, method string) (Event, error) {
+	events, cancel := s.Subscribe(method)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		return Event{}, ctx.Err()
+	case event, ok := <-events:
+		if !ok {
+			return Event{}, ErrClosed
+		}
+		return event, nil
+	}
+}
+
+// Close closes the connection, fails in-flight commands and ends all
+// subscriptions.
+func (s *Session) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	conn := s.conn
+	s.conn = nil
+	for method, subscribers := range s.subscribers {
+		for events := range subscribers {
+			close(events)
+		}
+		delete(s.subscribers, method)
+	}
+	s.mu.Unlock()
+	if conn != nil {
+		_ = conn.Close()
+	}
+	s.failPending()
+}
+
+func (s *Session) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// connect returns the live connection, dialing and re-enabling domains when
+// there is none.
+func (s *Session) connect(ctx context.Context) (*websocket.Conn, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if s.conn != nil {
+		conn := s.conn
+		s.mu.Unlock()
+		return conn, nil
+	}
+	s.mu.Unlock()
+
+	conn, _, err := s.dialer.DialContext(ctx, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect CDP websocket: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return nil, ErrClosed
+	}
+	if s.conn != nil {
+		// Another caller connected first.
+		existing := s.conn
+		s.mu.Unlock()
+		_ = conn.Close()
+		return existing, nil
+	}
+	s.conn = conn
+	domains := append([]string(nil), s.domains...)
+	s.mu.Unlock()
+
+	go s.readLoop(conn)
+	for _, domain := range domains {
+		if err := s.Call(ctx, domain+".enable", nil, nil); err != nil {
+			return nil, fmt.Errorf("re-enable CDP %s domain: %w", domain, err)
+		}
+	}
+	return conn, nil
+}
+
+func (s *Session) readLoop(conn *websocket.Conn) {
+	defer s.disconnect(conn)
+	for {
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		if msg.ID != 0 {
+			s.mu.Lock()
+			reply := s.pending[msg.ID]
+			delete(s.pending, msg.ID)
+			s.mu.Unlock()
+			if reply != nil {
+				reply <- msg
+			}
+			continue
+		}
+		if msg.Method == "" {
+			continue
+		}
+		event := Event{Method: msg.Method, Params: msg.Params}
+		s.mu.Lock()
+		for events := range s.subscribers[msg.Method] {
+			select {
+			case events <- event:
+			default:
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// disconnect forgets conn if it is still current and fails the commands
+// waiting on it.
+func (s *Session) disconnect(conn *websocket.Conn) {
+	s.mu.Lock()
+	current := s.conn == conn
+	if current {
+		s.conn = nil
+	}
+	s.mu.Unlock()
+	_ = conn.Close()
+	if current {
+		s.failPending()
+	}
+}
+
+func (s *Session) failPending() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[int64]chan message)
+	s.mu.Unlock()
+	for _, reply := range pending {
+		close(reply)
+	}
+}
+
+func (s *Session) dropPending(id int64) {
+	s.mu.Lock()
+	delete(s.pending, id)
+	s.mu.Unlock()
+}
+
+// IsContextDestroyed reports whether err means the page navigated or the
+// connection dropped while a command was running.
+func IsContextDestroyed(err error) bool {
+	var protocolErr *ProtocolError
+	if errors.As(err, &protocolErr) {
+		message := strings.ToLower(protocolErr.Message)
+		return strings.Contains(message, "context was destroyed") || strings.Contains(message, "cannot find default execution context") || strings.Contains(message, "target navigated or closed")
+	}
+	return errors.Is(err, ErrDisconnected)
+}
@@ -0,0 +1,181 @@
+package cdp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/cdp"
+	"github.com/fractalmind-ai/fractalbot/internal/cdp/cdptest"
+)
+
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestListAndSelectTargets(t *testing.T) {
+	server := cdptest.NewServer(t)
+	server.AddPage("Login", "https://claude.ai/login")
+	chat := server.AddPage("Claude", "https://claude.ai/chat/abc")
+	ctx := testContext(t)
+
+	if _, err := cdp.Version(ctx, server.URL); err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	targets, err := cdp.ListTargets(ctx, server.URL+"/")
+	if err != nil || len(targets) != 2 {
+		t.Fatalf("targets=%#v err=%v", targets, err)
+	}
+	target, ok := cdp.SelectTarget(targets, "/chat/", nil)
+	if !ok || target.WebSocketDebuggerURL != chat.WebSocketURL() {
+		t.Fatalf("unexpected selected target: %#v", target)
+	}
+	if _, ok := cdp.SelectTarget(targets, "", func(target cdp.Target) bool { return target.Type == "webview" }); ok {
+		t.Fatal("expected no webview target")
+	}
+
+	server.SetDown(true)
+	if _, err := cdp.ListTargets(ctx, server.URL); err == nil || !strings.Contains(err.Error(), "HTTP 503") {
+		t.Fatalf("expected HTTP 503, got %v", err)
+	}
+}
+
+func TestSessionEvaluatesOverOneConnection(t *testing.T) {
+	server := cdptest.NewServer(t)
+	page := server.AddPage("App", "app://-/index.html")
+	page.OnEvaluate(func(expression string) (interface{}, error) {
+		if expression == "throw" {
+			return nil, errors.New("Uncaught Error: boom")
+		}
+		return map[string]interface{}{"echo": expression}, nil
+	})
+	pool := cdp.NewPool()
+	defer pool.Close()
+	ctx := testContext(t)
+
+	session := pool.Session(page.WebSocketURL())
+	for _, expression := range []string{"1", "2"} {
+		value, err := session.Evaluate(ctx, expression)
+		if err != nil {
+			t.Fatalf("Evaluate: %v", err)
+		}
+		if value.(map[string]interface{})["echo"] != expression {
+			t.Fatalf("unexpected value: %#v", value)
+		}
+	}
+	if pool.Session(page.WebSocketURL()) != session {
+		t.Fatal("expected pool to reuse the session")
+	}
+	if page.Connections() != 1 {
+		t.Fatalf("expected one connection, got %d", page.Connections())
+	}
+
+	var evalErr *cdp.EvaluateError
+	if _, err := session.Evaluate(ctx, "throw"); !errors.As(err, &evalErr) || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected EvaluateError, got %v", err)
+	}
+	var protocolErr *cdp.ProtocolError
+	if err := session.Call(ctx, "Nope.missing", nil, nil); !errors.As(err, &protocolErr) || protocolErr.Method != "Nope.missing" {
+		t.Fatalf("expected ProtocolError, got %v", err)
+	}
+}
+
+func TestSessionReconnectsAndKeepsSubscriptions(t *testing.T) {
+	server := cdptest.NewServer(t)
+	page := server.AddPage("App", "app://-/index.html")
+	pool := cdp.NewPool()
+	defer pool.Close()
+	ctx := testContext(t)
+
+	session := pool.Session(page.WebSocketURL())
+	if err := session.Enable(ctx, "Runtime"); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	events, cancel := session.Subscribe(cdp.EventConsoleAPICalled)
+	defer cancel()
+
+	page.Emit(cdp.EventConsoleAPICalled, map[string]string{"type": "log"})
+	waitEvent(t, events)
+
+	page.Disconnect()
+	// The first command after the drop may race the close; the next one must
+	// reconnect.
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := session.Evaluate(ctx, "1"); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("Evaluate after reconnect: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if page.Connections() != 2 {
+		t.Fatalf("expected a second connection, got %d", page.Connections())
+	}
+	enables := 0
+	for _, call := range page.Calls() {
+		if call.Method == "Runtime.enable" {
+			enables++
+		}
+	}
+	if enables != 2 {
+		t.Fatalf("expected Runtime to be re-enabled, got %d enables", enables)
+	}
+
+	page.Emit(cdp.EventConsoleAPICalled, map[string]string{"type": "warning"})
+	if event := waitEvent(t, events); !strings.Contains(string(event.Params), "warning") {
+		t.Fatalf("unexpected event: %#v", event)
+	}
+}
+
+func TestWaitEventAndClose(t *testing.T) {
+	server := cdptest.NewServer(t)
+	page := server.AddPage("App", "app://-/index.html")
+	session, err := cdp.Dial(testContext(t), page.WebSocketURL())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := session.WaitEvent(testContext(t), cdp.EventLoadFired)
+		done <- err
+	}()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		page.Emit(cdp.EventLoadFired, map[string]float64{"timestamp": 1})
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("WaitEvent: %v", err)
+			}
+		case <-time.After(20 * time.Millisecond):
+			if time.Now().Before(deadline) {
+				continue
+			}
+			t.Fatal("timed out waiting for load event")
+		}
+		break
+	}
+
+	session.Close()
+	if _, err := session.Evaluate(testContext(t), "1"); !errors.Is(err, cdp.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func waitEvent(t *testing.T, events <-chan cdp.Event) cdp.Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return cdp.Event{}
+	}
+}
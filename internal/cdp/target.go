@@ -0,0 +1,83 @@
+// Package cdp is a small Chrome DevTools Protocol client for the desktop app
+// runtimes.
+//
+// Targets are discovered over the HTTP endpoints (/json/version and
+// /json/list). Each target's WebSocket is held open by a pooled Session that
+// multiplexes commands, delivers events to subscribers, and redials after the
+// target drops the connection, for example when its renderer is replaced.
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Target is one entry of the /json/list endpoint.
+type Target struct {
+	ID                   string `json:"id,omitempty"`
+	Type                 string `json:"type"`
+	Title                string `json:"title"`
+	URL                  string `json:"url"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+// Version queries /json/version and returns the browser metadata.
+func Version(ctx context.Context, endpoint string) (map[string]interface{}, error) {
+	var version map[string]interface{}
+	if err := getJSON(ctx, strings.TrimRight(strings.TrimSpace(endpoint), "/")+"/json/version", &version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// ListTargets queries /json/list.
+func ListTargets(ctx context.Context, endpoint string) ([]Target, error) {
+	var targets []Target
+	if err := getJSON(ctx, strings.TrimRight(strings.TrimSpace(endpoint), "/")+"/json/list", &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// SelectTarget returns the first debuggable target accepted by match whose
+// title or URL contains selector. An empty selector or nil match accepts
+// every target.
+func SelectTarget(targets []Target, selector string, match func(Target) bool) (Target, bool) {
+	selector = strings.TrimSpace(selector)
+	for _, target := range targets {
+		if target.WebSocketDebuggerURL == "" {
+			continue
+		}
+		if match != nil && !match(target) {
+			continue
+		}
+		if selector == "" || strings.Contains(target.Title, selector) || strings.Contains(target.URL, selector) {
+			return target, true
+		}
+	}
+	return Target{}, false
+}
+
+func getJSON(ctx context.Context, endpoint string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("query CDP endpoint %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("query CDP endpoint %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("decode CDP endpoint %s: %w", endpoint, err)
+	}
+	return nil
+}
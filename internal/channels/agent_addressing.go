@@ -0,0 +1,203 @@
+package channels
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// agentMentionPlaceholder marks where the agent name goes in a mention
+// syntax, for example "@{agent}".
+const agentMentionPlaceholder = "{agent}"
+
+// DefaultAgentMentionSyntaxes are used when agents.addressing.mentions is
+// unset.
+var DefaultAgentMentionSyntaxes = []string{"@" + agentMentionPlaceholder}
+
+// AgentAddressing recognises agent mentions and aliases in chat text.
+type AgentAddressing struct {
+	mentions []*regexp.Regexp
+	aliases  map[string]string
+}
+
+// AgentAddressingAware is implemented by channels that accept addressing
+// settings from agents.addressing.
+type AgentAddressingAware interface {
+	SetAgentAddressing(addressing AgentAddressing)
+}
+
+// NewAgentAddressing compiles mention syntaxes, each containing "{agent}"
+// once, and alias → agent mappings.
+func NewAgentAddressing(syntaxes []string, aliases map[string]string) (AgentAddressing, error) {
+	addressing := AgentAddressing{aliases: make(map[string]string)}
+	for _, syntax := range syntaxes {
+		pattern, err := compileAgentMentionSyntax(syntax)
+		if err != nil {
+			return AgentAddressing{}, err
+		}
+		addressing.mentions = append(addressing.mentions, pattern)
+	}
+	for alias, agent := range aliases {
+		alias = strings.TrimSpace(alias)
+		agent = strings.TrimSpace(agent)
+		if err := ValidateAgentName(alias); err != nil {
+			return AgentAddressing{}, fmt.Errorf("alias: %w", err)
+		}
+		if err := ValidateAgentName(agent); err != nil {
+			return AgentAddressing{}, fmt.Errorf("alias %q: %w", alias, err)
+		}
+		if _, ok := addressing.aliases[alias]; ok {
+			return AgentAddressing{}, fmt.Errorf("alias %q: duplicate", alias)
+		}
+		addressing.aliases[alias] = agent
+	}
+	return addressing, nil
+}
+
+// DefaultAgentAddressing recognises "@<agent>" mentions without aliases.
+func DefaultAgentAddressing() AgentAddressing {
+	addressing, err := NewAgentAddressing(DefaultAgentMentionSyntaxes, nil)
+	if err != nil {
+		panic(err)
+	}
+	return addressing
+}
+
+// ValidateAgentMentionSyntax checks a configured mention syntax.
+func ValidateAgentMentionSyntax(syntax string) error {
+	_, err := compileAgentMentionSyntax(syntax)
+	return err
+}
+
+func compileAgentMentionSyntax(syntax string) (*regexp.Regexp, error) {
+	trimmed := strings.TrimSpace(syntax)
+	if strings.Count(trimmed, agentMentionPlaceholder) != 1 {
+		return nil, fmt.Errorf("mention syntax %q must contain %s once", syntax, agentMentionPlaceholder)
+	}
+	prefix, suffix, _ := strings.Cut(trimmed, agentMentionPlaceholder)
+	if prefix == "" && suffix == "" {
+		return nil, fmt.Errorf("mention syntax %q needs text around %s", syntax, agentMentionPlaceholder)
+	}
+	// The name may not end in "-" or "/" so trailing punctuation is not
+	// swallowed. findMentions checks the character after the match.
+	pattern := `(?:^|[\s(,;])` + regexp.QuoteMeta(prefix) +
+		`([a-zA-Z0-9_](?:[a-zA-Z0-9_/-]*[a-zA-Z0-9_])?)` +
+		regexp.QuoteMeta(suffix)
+	return regexp.Compile(pattern)
+}
+
+// canonical maps an alias to its agent name.
+func (a AgentAddressing) canonical(name string) string {
+	if agent, ok := a.aliases[name]; ok {
+		return agent
+	}
+	return name
+}
+
+func (a AgentAddressing) isAlias(name string) bool {
+	_, ok := a.aliases[name]
+	return ok
+}
+
+// findMentions returns the mentioned names in order of appearance, aliases
+// resolved and duplicates removed.
+func (a AgentAddressing) findMentions(text string) []agentMention {
+	type located struct {
+		at      int
+		mention agentMention
+	}
+	var found []located
+	for _, pattern := range a.mentions {
+		for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+			if match[1] < len(text) && isAgentNameByte(text[match[1]]) {
+				continue
+			}
+			name := text[match[2]:match[3]]
+			found = append(found, located{at: match[2], mention: agentMention{Agent: a.canonical(name), Alias: a.isAlias(name)}})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].at < found[j].at })
+	var mentions []agentMention
+	seen := make(map[string]bool)
+	for _, entry := range found {
+		if seen[entry.mention.Agent] {
+			continue
+		}
+		seen[entry.mention.Agent] = true
+		mentions = append(mentions, entry.mention)
+	}
+	return mentions
+}
+
+// stripMentions removes the mentions of agents from text, with a comma or
+// colon right after a mention and the spaces that follow it, so each
+// addressed agent gets only the task. Text that is nothing but mentions is
+// returned unchanged.
+func (a AgentAddressing) stripMentions(text string, agents []string) string {
+	targets := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		targets[agent] = true
+	}
+	var spans [][2]int
+	for _, pattern := range a.mentions {
+		for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+			if match[1] < len(text) && isAgentNameByte(text[match[1]]) {
+				continue
+			}
+			if !targets[a.canonical(text[match[2]:match[3]])] {
+				continue
+			}
+			start := match[0]
+			if start > 0 {
+				// Keep the separator the pattern matched before the mention.
+				start++
+			}
+			spans = append(spans, [2]int{start, match[1]})
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var out strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span[0] < last {
+			continue
+		}
+		out.WriteString(text[last:span[0]])
+		end := span[1]
+		if end < len(text) && (text[end] == ',' || text[end] == ':') {
+			end++
+		}
+		spaced := end
+		for spaced < len(text) && (text[spaced] == ' ' || text[spaced] == '\t') {
+			spaced++
+		}
+		if spaced == end {
+			trimmed := strings.TrimRight(out.String(), " \t")
+			out.Reset()
+			out.WriteString(trimmed)
+		}
+		last = spaced
+	}
+	out.WriteString(text[last:])
+	if stripped := strings.TrimSpace(out.String()); stripped != "" {
+		return stripped
+	}
+	return text
+}
+
+func isAgentNameByte(c byte) bool {
+	return c == '_' || c == '-' || c == '/' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// agentMention is one addressed agent. Alias mentions are deliberate, so they
+// must be allowed; plain mentions of unknown names are treated as mentions of
+// people and ignored.
+type agentMention struct {
+	Agent string
+	Alias bool
+}
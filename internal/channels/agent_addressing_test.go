@@ -0,0 +1,133 @@
+package channels
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+func TestParseAgentSelectionMentions(t *testing.T) {
+	addressing, err := NewAgentAddressing([]string{"@{agent}", "{agent}:"}, map[string]string{"qa": "qa-1", "api": "backend/api-2"})
+	if err != nil {
+		t.Fatalf("NewAgentAddressing: %v", err)
+	}
+	tests := []struct {
+		input string
+		want  []agentMention
+	}{
+		{"@qa-1 please rerun the tests", []agentMention{{Agent: "qa-1"}}},
+		{"@qa, @coder-a and (@api) take a look", []agentMention{{Agent: "qa-1", Alias: true}, {Agent: "coder-a"}, {Agent: "backend/api-2", Alias: true}}},
+		{"coder-a: ship it, @coder-a too", []agentMention{{Agent: "coder-a"}}},
+		{"@backend/api-1. thanks", []agentMention{{Agent: "backend/api-1"}}},
+		{"mail bob@qa-1.example.com", nil},
+		{"no mentions here", nil},
+	}
+	for _, tt := range tests {
+		selection, err := ParseAgentSelection(tt.input, addressing)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(selection.mentions, tt.want) || selection.Task != tt.input {
+			t.Fatalf("%q: mentions=%#v task=%q", tt.input, selection.mentions, selection.Task)
+		}
+	}
+
+	selection, err := ParseAgentSelection("/agent qa run it", addressing)
+	if err != nil || selection.Agent != "qa-1" || !selection.Specified {
+		t.Fatalf("alias in /agent: %#v err=%v", selection, err)
+	}
+
+	for _, syntax := range []string{"@agent", "{agent}", "@{agent}{agent}"} {
+		if _, err := NewAgentAddressing([]string{syntax}, nil); err == nil {
+			t.Fatalf("expected syntax %q to be rejected", syntax)
+		}
+	}
+}
+
+func TestResolveAgentSelectionMentions(t *testing.T) {
+	addressing, _ := NewAgentAddressing(DefaultAgentMentionSyntaxes, map[string]string{"qa": "qa-1", "ops": "ops-1"})
+	allowlist := NewAgentAllowlist([]string{"qa-1", "coder-a"})
+	resolve := func(text string) (AgentSelection, error) {
+		selection, err := ParseAgentSelection(text, addressing)
+		if err != nil {
+			t.Fatalf("ParseAgentSelection: %v", err)
+		}
+		return ResolveAgentSelection(selection, "", "qa-1", allowlist)
+	}
+
+	selection, err := resolve("@coder-a and @qa please pair, cc @alice")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if selection.Agent != "coder-a" || !reflect.DeepEqual(selection.Targets(), []string{"coder-a", "qa-1"}) || selection.Task != "and please pair, cc @alice" {
+		t.Fatalf("unexpected selection: %#v", selection)
+	}
+
+	for input, want := range map[string]string{
+		"@qa-1 @coder-a please rerun the tests": "please rerun the tests",
+		"@qa, @coder-a: fix the build":          "fix the build",
+		"please rerun the tests @qa":            "please rerun the tests",
+		"@qa-1\nline one\n  indented":           "line one\n  indented",
+		"@qa-1 @coder-a":                        "@qa-1 @coder-a",
+	} {
+		selection, err := resolve(input)
+		if err != nil {
+			t.Fatalf("resolve %q: %v", input, err)
+		}
+		if selection.Task != want {
+			t.Fatalf("task of %q = %q, want %q", input, selection.Task, want)
+		}
+	}
+
+	selection, err = resolve("@alice can you check?")
+	if err != nil || selection.Agent != "qa-1" || len(selection.Agents) != 0 {
+		t.Fatalf("mentions of people should fall back to the default: %#v err=%v", selection, err)
+	}
+
+	if _, err := resolve("@ops restart"); !isAgentNotAllowedError(err) {
+		t.Fatalf("expected alias to a disallowed agent to fail, got %v", err)
+	}
+}
+
+type fanOutHandler struct {
+	agents []string
+	texts  []string
+}
+
+func (h *fanOutHandler) HandleIncoming(ctx context.Context, msg *protocol.Message) (string, error) {
+	data, _ := msg.Data.(map[string]interface{})
+	agent, _ := data["agent"].(string)
+	text, _ := data["text"].(string)
+	h.agents = append(h.agents, agent)
+	h.texts = append(h.texts, text)
+	return "queued", nil
+}
+
+func TestTelegramMentionsFanOut(t *testing.T) {
+	bot, err := NewTelegramBot("token", []int64{123}, 0, "qa-1", []string{"qa-1", "coder-a"})
+	if err != nil {
+		t.Fatalf("NewTelegramBot: %v", err)
+	}
+	var payload sendMessagePayload
+	bot.httpClient = captureHTTPClient(t, &payload)
+	handler := &fanOutHandler{}
+	bot.SetHandler(handler)
+
+	bot.handleIncomingMessage(&TelegramMessage{
+		Text: "@qa-1 @coder-a please rerun the tests",
+		From: &TelegramUser{ID: 123},
+		Chat: &TelegramChat{ID: 99, Type: "private"},
+	})
+	if strings.Join(handler.agents, ",") != "qa-1,coder-a" {
+		t.Fatalf("routed agents=%v", handler.agents)
+	}
+	if strings.Join(handler.texts, "|") != "please rerun the tests|please rerun the tests" {
+		t.Fatalf("routed tasks=%q", handler.texts)
+	}
+	if payload.Text != "qa-1: queued\ncoder-a: queued" {
+		t.Fatalf("unexpected reply %q", payload.Text)
+	}
+}
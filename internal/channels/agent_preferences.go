@@ -0,0 +1,193 @@
+package channels
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	agentPreferencesVersion         = 1
+	defaultAgentPreferencesFilename = "agent-preferences.json"
+)
+
+var errAgentPreferencesUnavailable = errors.New("/use is not available: agent preferences are not configured")
+
+// AgentPreferenceKey identifies one user in one chat of one channel.
+type AgentPreferenceKey struct {
+	Channel string
+	ChatID  string
+	UserID  string
+}
+
+func (k AgentPreferenceKey) String() string {
+	return k.Channel + "/" + k.ChatID + "/" + k.UserID
+}
+
+// AgentPreference is the agent a user picked with /use.
+type AgentPreference struct {
+	Agent     string    `json:"agent"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type persistedAgentPreferences struct {
+	Version     int                        `json:"version"`
+	Preferences map[string]AgentPreference `json:"preferences"`
+}
+
+// AgentPreferences stores /use selections. With an empty path the
+// selections live in memory only.
+type AgentPreferences struct {
+	mu          sync.RWMutex
+	path        string
+	preferences map[string]AgentPreference
+}
+
+// AgentPreferencesAware is implemented by channels that support /use.
+type AgentPreferencesAware interface {
+	SetAgentPreferences(preferences *AgentPreferences)
+}
+
+// NewAgentPreferences loads selections from path.
+func NewAgentPreferences(path string) (*AgentPreferences, error) {
+	p := &AgentPreferences{
+		path:        strings.TrimSpace(path),
+		preferences: make(map[string]AgentPreference),
+	}
+	if p.path == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("read agent preferences: %w", err)
+	}
+	var state persistedAgentPreferences
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&state); err != nil {
+		return p, fmt.Errorf("decode agent preferences: %w", err)
+	}
+	if state.Version != agentPreferencesVersion {
+		return p, fmt.Errorf("unsupported agent preferences version %d", state.Version)
+	}
+	for key, preference := range state.Preferences {
+		p.preferences[key] = preference
+	}
+	return p, nil
+}
+
+// resolveAgentPreferencesPath returns channels.agentPreferencesPath, or
+// agent-preferences.json in the agents workspace.
+func resolveAgentPreferencesPath(configuredPath, workspace string) string {
+	if path := strings.TrimSpace(configuredPath); path != "" {
+		return filepath.Clean(path)
+	}
+	if strings.TrimSpace(workspace) == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Clean(workspace), defaultAgentPreferencesFilename)
+}
+
+// Get returns the agent selected for key, or an empty string.
+func (p *AgentPreferences) Get(key AgentPreferenceKey) string {
+	if p == nil {
+		return ""
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.preferences[key.String()].Agent
+}
+
+// Set records agent for key. An empty agent clears the selection.
+func (p *AgentPreferences) Set(key AgentPreferenceKey, agent string) error {
+	if p == nil {
+		return errAgentPreferencesUnavailable
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if agent == "" {
+		delete(p.preferences, key.String())
+	} else {
+		p.preferences[key.String()] = AgentPreference{Agent: agent, UpdatedAt: time.Now().UTC()}
+	}
+	return p.saveLocked()
+}
+
+func (p *AgentPreferences) saveLocked() error {
+	if p.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(persistedAgentPreferences{Version: agentPreferencesVersion, Preferences: p.preferences}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode agent preferences: %w", err)
+	}
+	data = append(data, '\n')
+	directory := filepath.Dir(p.path)
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return fmt.Errorf("create agent preferences directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(directory, ".agent-preferences-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create agent preferences temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write agent preferences: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close agent preferences: %w", err)
+	}
+	if err := os.Rename(tmpPath, p.path); err != nil {
+		return fmt.Errorf("commit agent preferences: %w", err)
+	}
+	return nil
+}
+
+// useCommandReply implements /use [agent|default] for every channel. Aliases
+// are resolved through addressing, so the agent name is stored.
+func useCommandReply(preferences *AgentPreferences, key AgentPreferenceKey, args []string, defaultAgent string, allowlist AgentAllowlist, addressing AgentAddressing) (string, error) {
+	if len(args) > 1 {
+		return "", errors.New("usage: /use [name|default]")
+	}
+	defaultName := strings.TrimSpace(defaultAgent)
+	if len(args) == 0 {
+		current := preferences.Get(key)
+		if current != "" && allowlist.Validate(current, defaultName) == nil {
+			return fmt.Sprintf("Current agent: %s (set with /use)\nUse /use default to go back to the default agent.", current), nil
+		}
+		if defaultName == "" {
+			return "No agent selected and no default agent configured.\nUse /use <name> to pick one; /agents lists them.", nil
+		}
+		return fmt.Sprintf("Current agent: %s (default)\nUse /use <name> to pick another; /agents lists them.", defaultName), nil
+	}
+
+	name := strings.TrimSpace(args[0])
+	if name == "default" && allowlist.Validate(name, defaultName) != nil {
+		if err := preferences.Set(key, ""); err != nil {
+			return "", err
+		}
+		if defaultName == "" {
+			return "✅ Agent selection cleared.", nil
+		}
+		return fmt.Sprintf("✅ Your messages in this chat go to the default agent %s again.", defaultName), nil
+	}
+	name = addressing.canonical(name)
+	if err := validateAgentCommandName(name, defaultName, allowlist); err != nil {
+		return "", err
+	}
+	if err := preferences.Set(key, name); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("✅ Your messages in this chat now go to %s.\nUse /agent <name> <task> for one-off messages, or /use default to go back.", name), nil
+}
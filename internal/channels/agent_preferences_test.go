@@ -0,0 +1,115 @@
+package channels
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+type agentRecordingHandler struct {
+	agents []string
+}
+
+func (h *agentRecordingHandler) HandleIncoming(ctx context.Context, msg *protocol.Message) (string, error) {
+	data, _ := msg.Data.(map[string]interface{})
+	agent, _ := data["agent"].(string)
+	h.agents = append(h.agents, agent)
+	return "", nil
+}
+
+func TestAgentPreferencesPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "agent-preferences.json")
+	preferences, err := NewAgentPreferences(path)
+	if err != nil {
+		t.Fatalf("NewAgentPreferences: %v", err)
+	}
+	key := AgentPreferenceKey{Channel: "slack", ChatID: "D1", UserID: "U1"}
+	if err := preferences.Set(key, "coder-a"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	reloaded, err := NewAgentPreferences(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := reloaded.Get(key); got != "coder-a" {
+		t.Fatalf("reloaded preference=%q", got)
+	}
+	if got := reloaded.Get(AgentPreferenceKey{Channel: "slack", ChatID: "D2", UserID: "U1"}); got != "" {
+		t.Fatalf("preference leaked to another chat: %q", got)
+	}
+	if err := reloaded.Set(key, ""); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if cleared, _ := NewAgentPreferences(path); cleared.Get(key) != "" {
+		t.Fatal("cleared preference should be persisted")
+	}
+
+	var missing *AgentPreferences
+	if err := missing.Set(key, "coder-a"); err == nil {
+		t.Fatal("expected error without a store")
+	}
+}
+
+func TestUseCommandReply(t *testing.T) {
+	preferences, _ := NewAgentPreferences("")
+	key := AgentPreferenceKey{Channel: "discord", ChatID: "C1", UserID: "U1"}
+	allowlist := NewAgentAllowlist([]string{"qa-1", "coder-a"})
+
+	reply, err := useCommandReply(preferences, key, nil, "qa-1", allowlist, DefaultAgentAddressing())
+	if err != nil || !strings.Contains(reply, "Current agent: qa-1 (default)") {
+		t.Fatalf("reply=%q err=%v", reply, err)
+	}
+	if _, err := useCommandReply(preferences, key, []string{"coder-x"}, "qa-1", allowlist, DefaultAgentAddressing()); !isAgentNotAllowedError(err) {
+		t.Fatalf("expected not allowed error, got %v", err)
+	}
+	if _, err := useCommandReply(preferences, key, []string{"coder-a"}, "qa-1", allowlist, DefaultAgentAddressing()); err != nil {
+		t.Fatalf("use coder-a: %v", err)
+	}
+	reply, _ = useCommandReply(preferences, key, nil, "qa-1", allowlist, DefaultAgentAddressing())
+	if !strings.Contains(reply, "Current agent: coder-a (set with /use)") {
+		t.Fatalf("reply=%q", reply)
+	}
+	if _, err := useCommandReply(preferences, key, []string{"default"}, "qa-1", allowlist, DefaultAgentAddressing()); err != nil || preferences.Get(key) != "" {
+		t.Fatalf("use default: err=%v preference=%q", err, preferences.Get(key))
+	}
+
+	addressing, err := NewAgentAddressing(DefaultAgentMentionSyntaxes, map[string]string{"coder": "coder-a"})
+	if err != nil {
+		t.Fatalf("NewAgentAddressing: %v", err)
+	}
+	reply, err = useCommandReply(preferences, key, []string{"coder"}, "qa-1", allowlist, addressing)
+	if err != nil || preferences.Get(key) != "coder-a" || !strings.Contains(reply, "now go to coder-a") {
+		t.Fatalf("use alias: reply=%q err=%v preference=%q", reply, err, preferences.Get(key))
+	}
+}
+
+func TestTelegramUseRoutesLaterMessages(t *testing.T) {
+	bot, err := NewTelegramBot("token", []int64{123, 456}, 0, "qa-1", []string{"qa-1", "coder-a"})
+	if err != nil {
+		t.Fatalf("NewTelegramBot: %v", err)
+	}
+	var payload sendMessagePayload
+	bot.httpClient = captureHTTPClient(t, &payload)
+	handler := &agentRecordingHandler{}
+	bot.SetHandler(handler)
+	preferences, _ := NewAgentPreferences("")
+	bot.SetAgentPreferences(preferences)
+
+	send := func(userID int64, text string) {
+		bot.handleIncomingMessage(&TelegramMessage{Text: text, From: &TelegramUser{ID: userID}, Chat: &TelegramChat{ID: 99, Type: "private"}})
+	}
+	send(123, "/use coder-a")
+	if !strings.Contains(payload.Text, "now go to coder-a") {
+		t.Fatalf("unexpected /use reply %q", payload.Text)
+	}
+	send(123, "run the tests")
+	send(456, "hello")
+	send(123, "/agent qa-1 one-off")
+	if strings.Join(handler.agents, ",") != "coder-a,qa-1,qa-1" {
+		t.Fatalf("routed agents=%v", handler.agents)
+	}
+}
@@ -8,16 +8,32 @@ import (
 	"strings"
 )
 
-var agentNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
+// agentNamePattern accepts an optional "<workspace>/" prefix for agents in
+// named oh-my-code workspaces.
+var agentNamePattern = regexp.MustCompile(`^([a-zA-Z0-9_][a-zA-Z0-9_-]*/)?[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
 
 var errDefaultAgentMissing = errors.New("default agent is not configured")
 var noAgentsConfiguredMessage = "⚠️ No agents configured.\nSet agents.ohMyCode.defaultAgent or agents.ohMyCode.allowedAgents.\nTry: /agent <name> <task> (or /to <name> <task>)."
 
 // AgentSelection describes the resolved target agent and task text.
 type AgentSelection struct {
-	Agent     string
+	Agent string
+	// Agents lists every addressed agent when a message mentions several;
+	// Agent is the first of them.
+	Agents    []string
 	Task      string
 	Specified bool
+
+	mentions   []agentMention
+	addressing AgentAddressing
+}
+
+// Targets returns the agents the message goes to.
+func (s AgentSelection) Targets() []string {
+	if len(s.Agents) > 0 {
+		return s.Agents
+	}
+	return []string{s.Agent}
 }
 
 // AgentAllowlist enforces allowed agent names.
@@ -40,8 +56,10 @@ func NewAgentAllowlist(names []string) AgentAllowlist {
 }
 
 // ParseAgentSelection extracts a target agent and task from chat text.
-// Supported syntax: /agent <name> <task...> or /to <name> <task...>
-func ParseAgentSelection(text string) (AgentSelection, error) {
+// Supported syntax: /agent <name> <task...>, /to <name> <task...>, and the
+// mention syntaxes of addressing anywhere in the text. Aliases are resolved
+// to agent names.
+func ParseAgentSelection(text string, addressing AgentAddressing) (AgentSelection, error) {
 	trimmed := strings.TrimSpace(text)
 	if trimmed == "" {
 		return AgentSelection{Task: ""}, nil
@@ -65,20 +83,52 @@ func ParseAgentSelection(text string) (AgentSelection, error) {
 			return AgentSelection{}, fmt.Errorf("usage: %s <name> <task>", command)
 		}
 		return AgentSelection{
-			Agent:     fields[1],
+			Agent:     addressing.canonical(fields[1]),
 			Task:      strings.Join(fields[2:], " "),
 			Specified: true,
 		}, nil
 	}
 
-	return AgentSelection{Task: trimmed}, nil
+	return AgentSelection{Task: trimmed, mentions: addressing.findMentions(trimmed), addressing: addressing}, nil
 }
 
-// ResolveAgentSelection applies defaults and allowlist validation.
-func ResolveAgentSelection(selection AgentSelection, defaultAgent string, allowlist AgentAllowlist) (AgentSelection, error) {
+// ResolveAgentSelection applies defaults and allowlist validation. Messages
+// that mention allowed agents go to each of them, with those mentions removed
+// from the task; mentions of names that are not allowed agents are ignored,
+// except aliases, which must be allowed.
+// Other messages go to preferredAgent, the sender's /use choice, while it is
+// still allowed, and to defaultAgent otherwise.
+func ResolveAgentSelection(selection AgentSelection, preferredAgent, defaultAgent string, allowlist AgentAllowlist) (AgentSelection, error) {
+	if !selection.Specified && len(selection.mentions) > 0 {
+		var agents []string
+		for _, mention := range selection.mentions {
+			err := ValidateAgentName(mention.Agent)
+			if err == nil {
+				err = allowlist.Validate(mention.Agent, defaultAgent)
+			}
+			if err != nil {
+				if mention.Alias {
+					return AgentSelection{}, err
+				}
+				continue
+			}
+			agents = append(agents, mention.Agent)
+		}
+		if len(agents) > 0 {
+			selection.Agent = agents[0]
+			selection.Agents = agents
+			selection.Task = selection.addressing.stripMentions(selection.Task, agents)
+			selection.Specified = true
+			return selection, nil
+		}
+	}
+
 	agent := strings.TrimSpace(selection.Agent)
 	if !selection.Specified {
 		agent = strings.TrimSpace(defaultAgent)
+		if preferred := strings.TrimSpace(preferredAgent); preferred != "" && ValidateAgentName(preferred) == nil && allowlist.Validate(preferred, defaultAgent) == nil {
+			agent = preferred
+		}
 		if agent == "" {
 			return AgentSelection{}, errDefaultAgentMissing
 		}
@@ -96,6 +146,24 @@ func ResolveAgentSelection(selection AgentSelection, defaultAgent string, allowl
 	return selection, nil
 }
 
+// fanOutAgentSelection calls handle once per target agent and joins the
+// replies, labelling each with its agent when the message went to several.
+func fanOutAgentSelection(selection AgentSelection, handle func(agent string) string) string {
+	targets := selection.Targets()
+	replies := make([]string, 0, len(targets))
+	for _, agent := range targets {
+		reply := strings.TrimSpace(handle(agent))
+		if reply == "" {
+			continue
+		}
+		if len(targets) > 1 {
+			reply = agent + ": " + reply
+		}
+		replies = append(replies, reply)
+	}
+	return strings.Join(replies, "\n")
+}
+
 func agentCommandName(text string) string {
 	trimmed := strings.TrimSpace(text)
 	if trimmed == "" {
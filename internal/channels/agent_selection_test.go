@@ -72,7 +72,7 @@ func TestParseAgentSelection(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			selection, err := ParseAgentSelection(tt.input)
+			selection, err := ParseAgentSelection(tt.input, DefaultAgentAddressing())
 			if tt.expectErr {
 				if err == nil {
 					t.Fatalf("expected error")
@@ -102,7 +102,7 @@ func TestResolveAgentSelection(t *testing.T) {
 	allowlist := NewAgentAllowlist([]string{"coder-a", "coder_b"})
 
 	selection := AgentSelection{Task: "do it"}
-	resolved, err := ResolveAgentSelection(selection, "coder-a", allowlist)
+	resolved, err := ResolveAgentSelection(selection, "", "coder-a", allowlist)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -111,7 +111,7 @@ func TestResolveAgentSelection(t *testing.T) {
 	}
 
 	selection = AgentSelection{Agent: "coder_b", Task: "go", Specified: true}
-	resolved, err = ResolveAgentSelection(selection, "coder-a", allowlist)
+	resolved, err = ResolveAgentSelection(selection, "", "coder-a", allowlist)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -120,19 +120,29 @@ func TestResolveAgentSelection(t *testing.T) {
 	}
 
 	selection = AgentSelection{Agent: "coder-x", Task: "go", Specified: true}
-	if _, err := ResolveAgentSelection(selection, "coder-a", allowlist); err == nil {
+	if _, err := ResolveAgentSelection(selection, "", "coder-a", allowlist); err == nil {
 		t.Fatalf("expected allowlist error")
 	}
 
 	selection = AgentSelection{Task: "go"}
-	if _, err := ResolveAgentSelection(selection, "", NewAgentAllowlist(nil)); err == nil {
+	if _, err := ResolveAgentSelection(selection, "", "", NewAgentAllowlist(nil)); err == nil {
 		t.Fatalf("expected default agent error")
 	} else if !errors.Is(err, errDefaultAgentMissing) {
 		t.Fatalf("expected default agent missing error, got %v", err)
 	}
 
+	selection = AgentSelection{Task: "go"}
+	resolved, err = ResolveAgentSelection(selection, "coder_b", "coder-a", allowlist)
+	if err != nil || resolved.Agent != "coder_b" {
+		t.Fatalf("expected preferred agent, got %q err=%v", resolved.Agent, err)
+	}
+	resolved, err = ResolveAgentSelection(selection, "coder-x", "coder-a", allowlist)
+	if err != nil || resolved.Agent != "coder-a" {
+		t.Fatalf("expected default for a preference that is no longer allowed, got %q err=%v", resolved.Agent, err)
+	}
+
 	selection = AgentSelection{Agent: "-bad", Task: "go", Specified: true}
-	if _, err := ResolveAgentSelection(selection, "coder-a", allowlist); err == nil {
+	if _, err := ResolveAgentSelection(selection, "", "coder-a", allowlist); err == nil {
 		t.Fatalf("expected name validation error")
 	}
 }
@@ -22,7 +22,9 @@ type DiscordBot struct {
 	defaultAgent string
 	agentAllow   AgentAllowlist
 
-	handler IncomingMessageHandler
+	handler         IncomingMessageHandler
+	agentPrefs      *AgentPreferences
+	agentAddressing AgentAddressing
 
 	session *discordgo.Session
 
@@ -48,11 +50,12 @@ func NewDiscordBot(token string, allowedUsers []string, defaultAgent string, all
 	}
 
 	return &DiscordBot{
-		token:        trimmed,
-		allowlist:    NewDiscordAllowlist(allowedUsers),
-		defaultAgent: strings.TrimSpace(defaultAgent),
-		agentAllow:   NewAgentAllowlist(allowedAgents),
-		ctx:          context.Background(),
+		token:           trimmed,
+		allowlist:       NewDiscordAllowlist(allowedUsers),
+		defaultAgent:    strings.TrimSpace(defaultAgent),
+		agentAllow:      NewAgentAllowlist(allowedAgents),
+		agentAddressing: DefaultAgentAddressing(),
+		ctx:             context.Background(),
 	}, nil
 }
 
@@ -64,6 +67,21 @@ func (b *DiscordBot) SetHandler(handler IncomingMessageHandler) {
 	b.handler = handler
 }
 
+// SetAgentPreferences sets the store behind /use.
+func (b *DiscordBot) SetAgentPreferences(preferences *AgentPreferences) {
+	b.agentPrefs = preferences
+}
+
+// SetAgentAddressing sets the mention syntaxes and aliases recognised in
+// messages.
+func (b *DiscordBot) SetAgentAddressing(addressing AgentAddressing) {
+	b.agentAddressing = addressing
+}
+
+func (b *DiscordBot) agentPreferenceKey(msg *discordInboundMessage) AgentPreferenceKey {
+	return AgentPreferenceKey{Channel: "discord", ChatID: msg.channelID, UserID: msg.userID}
+}
+
 func (b *DiscordBot) IsRunning() bool {
 	b.runningMu.RLock()
 	defer b.runningMu.RUnlock()
@@ -254,7 +272,7 @@ func (b *DiscordBot) handleMessageEvent(ctx context.Context, msg *discordInbound
 		return
 	}
 
-	selection, err := ParseAgentSelection(msg.text)
+	selection, err := ParseAgentSelection(msg.text, b.agentAddressing)
 	if err != nil {
 		reply := fmt.Sprintf("❌ %v", err)
 		if isAgentNotAllowedError(err) {
@@ -270,9 +288,10 @@ func (b *DiscordBot) handleMessageEvent(ctx context.Context, msg *discordInbound
 		return
 	}
 
-	enforceSelection := selection.Specified || b.defaultAgent != "" || b.agentAllow.configured
+	preferredAgent := b.agentPrefs.Get(b.agentPreferenceKey(msg))
+	enforceSelection := selection.Specified || preferredAgent != "" || b.defaultAgent != "" || b.agentAllow.configured
 	if enforceSelection {
-		selection, err = ResolveAgentSelection(selection, b.defaultAgent, b.agentAllow)
+		selection, err = ResolveAgentSelection(selection, preferredAgent, b.defaultAgent, b.agentAllow)
 		if err != nil {
 			reply := fmt.Sprintf("❌ %v", err)
 			if isDefaultAgentMissingError(err) && !selection.Specified && b.agentAllow.configured {
@@ -288,11 +307,14 @@ func (b *DiscordBot) handleMessageEvent(ctx context.Context, msg *discordInbound
 	}
 
 	if b.handler != nil {
-		replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, selection.Agent))
-		if err != nil {
-			log.Printf("discord handler error: %v", err)
-			replyText = "❌ Something went wrong. Please try again."
-		}
+		replyText := fanOutAgentSelection(selection, func(agent string) string {
+			replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, agent))
+			if err != nil {
+				log.Printf("discord handler error: %v", err)
+				replyText = "❌ Something went wrong. Please try again."
+			}
+			return replyText
+		})
 		if strings.TrimSpace(replyText) != "" {
 			_ = b.reply(ctx, msg, replyText)
 		}
@@ -353,6 +375,12 @@ func (b *DiscordBot) handleCommand(ctx context.Context, msg *discordInboundMessa
 			return true, nil
 		}
 		return true, b.reply(ctx, msg, replyText)
+	case "/use":
+		reply, err := useCommandReply(b.agentPrefs, b.agentPreferenceKey(msg), fields[1:], b.defaultAgent, b.agentAllow, b.agentAddressing)
+		if err != nil {
+			return true, err
+		}
+		return true, b.reply(ctx, msg, reply)
 	case "/agents":
 		names := b.agentAllow.Names()
 		defaultName := strings.TrimSpace(b.defaultAgent)
@@ -375,6 +403,9 @@ func (b *DiscordBot) handleCommand(ctx context.Context, msg *discordInboundMessa
 		for _, name := range names {
 			sb.WriteString(fmt.Sprintf("  - %s\n", name))
 		}
+		if registry := agentDirectoryText(b.handler); registry != "" {
+			sb.WriteString("\n" + registry)
+		}
 		return true, b.reply(ctx, msg, strings.TrimSpace(sb.String()))
 	case "/monitor":
 		agentName, lines, err := parseMonitorArgs(fields)
@@ -437,11 +468,18 @@ func (b *DiscordBot) handleCommand(ctx context.Context, msg *discordInboundMessa
 		}
 		return true, b.reply(ctx, msg, out)
 	case "/doctor":
+		if len(fields) > 2 {
+			return true, fmt.Errorf("usage: /doctor [workspace]")
+		}
+		workspace := ""
+		if len(fields) == 2 {
+			workspace = fields[1]
+		}
 		lifecycle, ok := b.handler.(AgentLifecycle)
 		if !ok || lifecycle == nil {
 			return true, errors.New("agent-manager is not available (set agents.ohMyCode.enabled)")
 		}
-		out, err := lifecycle.Doctor(b.ctx)
+		out, err := lifecycle.Doctor(b.ctx, workspace)
 		if err != nil {
 			return true, b.sanitizeLifecycleError(command, err)
 		}
@@ -477,6 +515,7 @@ func (b *DiscordBot) helpText() string {
 		"Agent routing:",
 		"  /agent <name> <task...>",
 		"  /to <name> <task...> (alias of /agent)",
+		"  /use [name|default] - show or set your agent in this chat",
 		"  /agents - see available agents",
 		"  Note: if an allowlist is configured, only allowlisted agents can be used.",
 		"",
@@ -58,7 +58,7 @@ func (f *fakeDiscordLifecycle) StopAgent(ctx context.Context, agentName string)
 	return "", nil
 }
 
-func (f *fakeDiscordLifecycle) Doctor(ctx context.Context) (string, error) {
+func (f *fakeDiscordLifecycle) Doctor(ctx context.Context, workspace string) (string, error) {
 	_ = ctx
 	return "", nil
 }
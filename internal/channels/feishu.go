@@ -35,7 +35,9 @@ type FeishuBot struct {
 	defaultAgent string
 	agentAllow   AgentAllowlist
 
-	handler IncomingMessageHandler
+	handler         IncomingMessageHandler
+	agentPrefs      *AgentPreferences
+	agentAddressing AgentAddressing
 
 	apiClient *lark.Client
 	wsClient  *ws.Client
@@ -77,15 +79,16 @@ func NewFeishuBot(appID, appSecret, domain string, allowedUsers []string, defaul
 	}
 
 	return &FeishuBot{
-		appID:        trimmedID,
-		appSecret:    trimmedSecret,
-		domain:       resolvedDomain,
-		allowlist:    NewFeishuAllowlist(allowedUsers),
-		defaultAgent: strings.TrimSpace(defaultAgent),
-		agentAllow:   NewAgentAllowlist(allowedAgents),
-		ctx:          context.Background(),
-		seenMsg:      make(map[string]time.Time),
-		seenContent:  make(map[string]time.Time),
+		appID:           trimmedID,
+		appSecret:       trimmedSecret,
+		domain:          resolvedDomain,
+		allowlist:       NewFeishuAllowlist(allowedUsers),
+		defaultAgent:    strings.TrimSpace(defaultAgent),
+		agentAllow:      NewAgentAllowlist(allowedAgents),
+		agentAddressing: DefaultAgentAddressing(),
+		ctx:             context.Background(),
+		seenMsg:         make(map[string]time.Time),
+		seenContent:     make(map[string]time.Time),
 	}, nil
 }
 
@@ -97,6 +100,27 @@ func (b *FeishuBot) SetHandler(handler IncomingMessageHandler) {
 	b.handler = handler
 }
 
+// SetAgentPreferences sets the store behind /use.
+func (b *FeishuBot) SetAgentPreferences(preferences *AgentPreferences) {
+	b.agentPrefs = preferences
+}
+
+// SetAgentAddressing sets the mention syntaxes and aliases recognised in
+// messages.
+func (b *FeishuBot) SetAgentAddressing(addressing AgentAddressing) {
+	b.agentAddressing = addressing
+}
+
+// agentPreferenceKey identifies the sender for /use. Feishu users are keyed
+// by open_id when present.
+func (b *FeishuBot) agentPreferenceKey(msg *feishuInboundMessage) AgentPreferenceKey {
+	userID := strings.TrimSpace(msg.openID)
+	if userID == "" {
+		userID = strings.TrimSpace(msg.userID)
+	}
+	return AgentPreferenceKey{Channel: "feishu", ChatID: msg.chatID, UserID: userID}
+}
+
 func (b *FeishuBot) IsRunning() bool {
 	b.runningMu.RLock()
 	defer b.runningMu.RUnlock()
@@ -441,7 +465,7 @@ func (b *FeishuBot) handleMessageEvent(ctx context.Context, event *larkim.P2Mess
 		return nil
 	}
 
-	selection, err := ParseAgentSelection(msg.text)
+	selection, err := ParseAgentSelection(msg.text, b.agentAddressing)
 	if err != nil {
 		reply := fmt.Sprintf("❌ %v", err)
 		if isAgentNotAllowedError(err) {
@@ -456,9 +480,10 @@ func (b *FeishuBot) handleMessageEvent(ctx context.Context, event *larkim.P2Mess
 		return nil
 	}
 
-	enforceSelection := selection.Specified || b.defaultAgent != "" || b.agentAllow.configured
+	preferredAgent := b.agentPrefs.Get(b.agentPreferenceKey(msg))
+	enforceSelection := selection.Specified || preferredAgent != "" || b.defaultAgent != "" || b.agentAllow.configured
 	if enforceSelection {
-		selection, err = ResolveAgentSelection(selection, b.defaultAgent, b.agentAllow)
+		selection, err = ResolveAgentSelection(selection, preferredAgent, b.defaultAgent, b.agentAllow)
 		if err != nil {
 			reply := fmt.Sprintf("❌ %v", err)
 			if !selection.Specified && (isDefaultAgentMissingError(err) || isInvalidAgentNameError(err)) {
@@ -474,12 +499,15 @@ func (b *FeishuBot) handleMessageEvent(ctx context.Context, event *larkim.P2Mess
 	}
 
 	if b.handler != nil {
-		replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, selection.Agent))
-		if err != nil {
-			b.markError()
-			log.Printf("feishu handler error: %v", err)
-			replyText = "❌ Something went wrong. Please try again."
-		}
+		replyText := fanOutAgentSelection(selection, func(agent string) string {
+			replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, agent))
+			if err != nil {
+				b.markError()
+				log.Printf("feishu handler error: %v", err)
+				replyText = "❌ Something went wrong. Please try again."
+			}
+			return replyText
+		})
 		if strings.TrimSpace(replyText) != "" {
 			_ = b.reply(ctx, msg, replyText)
 		}
@@ -514,6 +542,12 @@ func (b *FeishuBot) handleCommand(ctx context.Context, msg *feishuInboundMessage
 		return true, b.reply(ctx, msg, b.helpText())
 	case "/status":
 		return true, b.reply(ctx, msg, b.statusText())
+	case "/use":
+		reply, err := useCommandReply(b.agentPrefs, b.agentPreferenceKey(msg), parts[1:], b.defaultAgent, b.agentAllow, b.agentAddressing)
+		if err != nil {
+			return true, err
+		}
+		return true, b.reply(ctx, msg, reply)
 	case "/agents":
 		names := b.agentAllow.Names()
 		defaultName := strings.TrimSpace(b.defaultAgent)
@@ -531,6 +565,9 @@ func (b *FeishuBot) handleCommand(ctx context.Context, msg *feishuInboundMessage
 		for _, name := range names {
 			sb.WriteString(fmt.Sprintf("  - %s\n", name))
 		}
+		if registry := agentDirectoryText(b.handler); registry != "" {
+			sb.WriteString("\n" + registry)
+		}
 		return true, b.reply(ctx, msg, strings.TrimSpace(sb.String()))
 	case "/whoami":
 		reply := fmt.Sprintf("open_id: %s\nuser_id: %s\nchat_id: %s", msg.openID, msg.userID, msg.chatID)
@@ -553,6 +590,7 @@ func (b *FeishuBot) helpText() string {
 		"Agent routing:",
 		"  /agent <name> <task...>",
 		"  /to <name> <task...> (alias of /agent)",
+		"  /use [name|default] - show or set your agent in this chat",
 		"  /agents - see available agents",
 		"  Note: if an allowlist is configured, only allowlisted agents can be used.",
 	}
@@ -2,6 +2,9 @@ package channels
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
 )
@@ -17,5 +20,45 @@ type AgentLifecycle interface {
 	MonitorAgent(ctx context.Context, agentName string, lines int) (string, error)
 	StartAgent(ctx context.Context, agentName string) (string, error)
 	StopAgent(ctx context.Context, agentName string) (string, error)
-	Doctor(ctx context.Context) (string, error)
+	// Doctor checks agent-manager in workspace; empty means the default
+	// workspace.
+	Doctor(ctx context.Context, workspace string) (string, error)
+}
+
+// AgentDirectory exposes the agent registry to /agents commands.
+type AgentDirectory interface {
+	List() []protocol.AgentInfo
+}
+
+// agentDirectoryText renders the agent registry for /agents. It returns an
+// empty string when handler has no registry or the registry is empty.
+func agentDirectoryText(handler IncomingMessageHandler) string {
+	directory, ok := handler.(AgentDirectory)
+	if !ok || directory == nil {
+		return ""
+	}
+	agents := directory.List()
+	if len(agents) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Agent status:\n")
+	for _, info := range agents {
+		line := fmt.Sprintf("  - %s: %s", info.Name, info.Status)
+		var notes []string
+		if info.Runtime != "" {
+			notes = append(notes, info.Runtime)
+		}
+		if info.Detail != "" {
+			notes = append(notes, info.Detail)
+		}
+		if info.LastActivity != nil && !info.LastActivity.IsZero() {
+			notes = append(notes, "last active "+info.LastActivity.UTC().Format(time.RFC3339))
+		}
+		if len(notes) > 0 {
+			line += " (" + strings.Join(notes, ", ") + ")"
+		}
+		sb.WriteString(line + "\n")
+	}
+	return strings.TrimSpace(sb.String())
 }
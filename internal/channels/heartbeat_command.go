@@ -0,0 +1,114 @@
+package channels
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const heartbeatCommandUsage = "usage: /heartbeat [pause <job> [duration|RFC3339 time] [reason...] | resume <job> | run <job>]"
+
+// HeartbeatControl pauses, resumes and triggers heartbeat jobs for admin chat
+// commands.
+type HeartbeatControl interface {
+	HeartbeatJobs() []HeartbeatJobState
+	PauseHeartbeat(jobID string, until time.Time, reason, pausedBy string) (HeartbeatJobState, error)
+	ResumeHeartbeat(jobID string) (HeartbeatJobState, error)
+	RunHeartbeat(jobID string) (HeartbeatJobState, error)
+}
+
+// HeartbeatControlAware is implemented by channels with a /heartbeat command.
+type HeartbeatControlAware interface {
+	SetHeartbeatControl(control HeartbeatControl)
+}
+
+// HeartbeatJobState is the part of a heartbeat job shown in chat. Times are
+// RFC 3339 strings and empty when unset.
+type HeartbeatJobState struct {
+	ID          string
+	Runtime     string
+	Agent       string
+	NextRunAt   string
+	Paused      bool
+	PausedUntil string
+	PauseReason string
+}
+
+// heartbeatCommandReply implements /heartbeat for admins.
+func heartbeatCommandReply(control HeartbeatControl, args []string, requestedBy string) (string, error) {
+	if control == nil {
+		return "", errors.New("heartbeat scheduler is not available")
+	}
+	if len(args) == 0 {
+		jobs := control.HeartbeatJobs()
+		if len(jobs) == 0 {
+			return "No heartbeat jobs.", nil
+		}
+		var sb strings.Builder
+		sb.WriteString("Heartbeat jobs:\n")
+		for _, job := range jobs {
+			sb.WriteString("  - " + formatHeartbeatJobState(job) + "\n")
+		}
+		return strings.TrimSpace(sb.String()), nil
+	}
+	if len(args) < 2 {
+		return "", errors.New(heartbeatCommandUsage)
+	}
+	action := strings.ToLower(strings.TrimSpace(args[0]))
+	jobID := strings.TrimSpace(args[1])
+	switch action {
+	case "pause":
+		var until time.Time
+		rest := args[2:]
+		if len(rest) > 0 {
+			if duration, err := time.ParseDuration(rest[0]); err == nil && duration > 0 {
+				until = time.Now().Add(duration)
+				rest = rest[1:]
+			} else if parsed, err := time.Parse(time.RFC3339, rest[0]); err == nil {
+				until = parsed
+				rest = rest[1:]
+			}
+		}
+		job, err := control.PauseHeartbeat(jobID, until, strings.Join(rest, " "), requestedBy)
+		if err != nil {
+			return "", err
+		}
+		return "⏸ " + formatHeartbeatJobState(job), nil
+	case "resume":
+		if len(args) != 2 {
+			return "", errors.New(heartbeatCommandUsage)
+		}
+		job, err := control.ResumeHeartbeat(jobID)
+		if err != nil {
+			return "", err
+		}
+		return "▶️ " + formatHeartbeatJobState(job), nil
+	case "run":
+		if len(args) != 2 {
+			return "", errors.New(heartbeatCommandUsage)
+		}
+		if _, err := control.RunHeartbeat(jobID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✅ Heartbeat %s triggered", jobID), nil
+	default:
+		return "", errors.New(heartbeatCommandUsage)
+	}
+}
+
+func formatHeartbeatJobState(job HeartbeatJobState) string {
+	text := fmt.Sprintf("%s (%s/%s)", job.ID, job.Runtime, job.Agent)
+	switch {
+	case job.Paused && job.PausedUntil != "":
+		text += " paused until " + job.PausedUntil
+	case job.Paused:
+		text += " paused"
+	case job.NextRunAt != "":
+		text += " next run " + job.NextRunAt
+	}
+	if job.Paused && job.PauseReason != "" {
+		text += ": " + job.PauseReason
+	}
+	return text
+}
@@ -0,0 +1,90 @@
+package channels
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeHeartbeatControl struct {
+	jobs        []HeartbeatJobState
+	pausedJob   string
+	pausedUntil time.Time
+	pauseReason string
+	pausedBy    string
+	resumed     string
+	ran         string
+}
+
+func (f *fakeHeartbeatControl) HeartbeatJobs() []HeartbeatJobState {
+	return f.jobs
+}
+
+func (f *fakeHeartbeatControl) PauseHeartbeat(jobID string, until time.Time, reason, pausedBy string) (HeartbeatJobState, error) {
+	f.pausedJob, f.pausedUntil, f.pauseReason, f.pausedBy = jobID, until, reason, pausedBy
+	return HeartbeatJobState{ID: jobID, Runtime: "codexAppCDP", Agent: "main", Paused: true, PauseReason: reason}, nil
+}
+
+func (f *fakeHeartbeatControl) ResumeHeartbeat(jobID string) (HeartbeatJobState, error) {
+	f.resumed = jobID
+	return HeartbeatJobState{ID: jobID, Runtime: "codexAppCDP", Agent: "main", NextRunAt: "2026-07-26T02:00:00Z"}, nil
+}
+
+func (f *fakeHeartbeatControl) RunHeartbeat(jobID string) (HeartbeatJobState, error) {
+	f.ran = jobID
+	return HeartbeatJobState{ID: jobID}, nil
+}
+
+func TestTelegramHeartbeatCommand(t *testing.T) {
+	bot, err := NewTelegramBot("token", []int64{111}, 123, "qa-1", []string{"qa-1"})
+	if err != nil {
+		t.Fatalf("NewTelegramBot: %v", err)
+	}
+	var payload sendMessagePayload
+	bot.httpClient = captureHTTPClient(t, &payload)
+	control := &fakeHeartbeatControl{jobs: []HeartbeatJobState{
+		{ID: "cloudbank-main", Runtime: "codexAppCDP", Agent: "main", Paused: true, PausedUntil: "2026-07-26T02:00:00Z", PauseReason: "incident"},
+	}}
+	bot.SetHeartbeatControl(control)
+	command := func(userID int64, text string) error {
+		_, err := bot.handleCommand(&TelegramMessage{Text: text, From: &TelegramUser{ID: userID}, Chat: &TelegramChat{ID: 1}})
+		return err
+	}
+
+	if err := command(111, "/heartbeat"); err == nil || !strings.Contains(err.Error(), "admin only") {
+		t.Fatalf("expected admin check, got %v", err)
+	}
+	if err := command(123, "/heartbeat"); err != nil {
+		t.Fatalf("/heartbeat: %v", err)
+	}
+	if !strings.Contains(payload.Text, "cloudbank-main (codexAppCDP/main) paused until 2026-07-26T02:00:00Z: incident") {
+		t.Fatalf("unexpected list reply %q", payload.Text)
+	}
+
+	before := time.Now()
+	if err := command(123, "/heartbeat pause cloudbank-main 2h deploy in progress"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if control.pausedJob != "cloudbank-main" || control.pauseReason != "deploy in progress" || control.pausedBy != "telegram:123" {
+		t.Fatalf("unexpected pause call: %#v", control)
+	}
+	if control.pausedUntil.Before(before.Add(2*time.Hour)) || control.pausedUntil.After(time.Now().Add(2*time.Hour)) {
+		t.Fatalf("pause end=%s", control.pausedUntil)
+	}
+	if err := command(123, "/heartbeat pause cloudbank-main investigating"); err != nil || !control.pausedUntil.IsZero() || control.pauseReason != "investigating" {
+		t.Fatalf("indefinite pause: err=%v control=%#v", err, control)
+	}
+
+	if err := command(123, "/heartbeat resume cloudbank-main"); err != nil || control.resumed != "cloudbank-main" {
+		t.Fatalf("resume: err=%v control=%#v", err, control)
+	}
+	if err := command(123, "/heartbeat run cloudbank-main"); err != nil || control.ran != "cloudbank-main" {
+		t.Fatalf("run: err=%v control=%#v", err, control)
+	}
+	if !strings.Contains(payload.Text, "triggered") {
+		t.Fatalf("unexpected run reply %q", payload.Text)
+	}
+	if err := command(123, "/heartbeat stop cloudbank-main"); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
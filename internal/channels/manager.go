@@ -8,7 +8,6 @@ import (
 	"sort"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/fractalmind-ai/fractalbot/internal/config"
 )
@@ -18,6 +17,11 @@ type Manager struct {
 	cfg       *config.ChannelsConfig
 	agentsCfg *config.AgentsConfig
 	handler   IncomingMessageHandler
+	registry  *ChannelRegistry
+
+	agentPrefs      *AgentPreferences
+	agentAddressing AgentAddressing
+	heartbeat       HeartbeatControl
 
 	channels map[string]Channel
 	workers  map[string]*channelWorker
@@ -28,13 +32,39 @@ type Manager struct {
 
 // NewManager creates a new channel manager.
 func NewManager(cfg *config.ChannelsConfig, agentsCfg *config.AgentsConfig) *Manager {
+	var preferencesPath, workspace string
+	if cfg != nil {
+		preferencesPath = cfg.AgentPreferencesPath
+	}
+	if agentsCfg != nil {
+		workspace = agentsCfg.Workspace
+	}
+	agentPrefs, err := NewAgentPreferences(resolveAgentPreferencesPath(preferencesPath, workspace))
+	if err != nil {
+		log.Printf("agent preferences: %v", err)
+	}
+	agentAddressing := DefaultAgentAddressing()
+	if agentsCfg != nil && agentsCfg.Addressing != nil {
+		mentions := agentsCfg.Addressing.Mentions
+		if len(mentions) == 0 {
+			mentions = DefaultAgentMentionSyntaxes
+		}
+		if addressing, err := NewAgentAddressing(mentions, agentsCfg.Addressing.Aliases); err != nil {
+			log.Printf("agent addressing: %v", err)
+		} else {
+			agentAddressing = addressing
+		}
+	}
 	return &Manager{
 		cfg:       cfg,
 		agentsCfg: agentsCfg,
+		registry:  defaultChannelRegistry.clone(),
 		channels:  make(map[string]Channel),
 		workers:   make(map[string]*channelWorker),
 
-		startCancels: make(map[string]context.CancelFunc),
+		agentPrefs:      agentPrefs,
+		agentAddressing: agentAddressing,
+		startCancels:    make(map[string]context.CancelFunc),
 	}
 }
 
@@ -48,6 +78,16 @@ func (m *Manager) SetHandler(handler IncomingMessageHandler) {
 	}
 }
 
+// SetHeartbeatControl enables /heartbeat on channels that support it.
+func (m *Manager) SetHeartbeatControl(control HeartbeatControl) {
+	m.heartbeat = control
+	for _, channel := range m.channels {
+		if heartbeatAware, ok := channel.(HeartbeatControlAware); ok {
+			heartbeatAware.SetHeartbeatControl(control)
+		}
+	}
+}
+
 // Register adds a channel to the manager.
 func (m *Manager) Register(channel Channel) error {
 	if channel == nil {
@@ -65,6 +105,17 @@ func (m *Manager) Register(channel Channel) error {
 			handlerAware.SetHandler(m.handler)
 		}
 	}
+	if preferencesAware, ok := channel.(AgentPreferencesAware); ok {
+		preferencesAware.SetAgentPreferences(m.agentPrefs)
+	}
+	if addressingAware, ok := channel.(AgentAddressingAware); ok {
+		addressingAware.SetAgentAddressing(m.agentAddressing)
+	}
+	if m.heartbeat != nil {
+		if heartbeatAware, ok := channel.(HeartbeatControlAware); ok {
+			heartbeatAware.SetHeartbeatControl(m.heartbeat)
+		}
+	}
 	m.channels[name] = channel
 	return nil
 }
@@ -212,208 +263,92 @@ func (m *Manager) registerConfiguredChannels() error {
 		return nil
 	}
 
-	if m.cfg.Telegram != nil && m.cfg.Telegram.Enabled {
-		if m.Get("telegram") != nil {
-			return nil
-		}
-		if m.cfg.Telegram.BotToken == "" {
-			return errors.New("channels.telegram.botToken is required when telegram is enabled")
-		}
-
-		defaultAgent, allowedAgents, agentConfigName := activeChannelAgentConfig(m.agentsCfg)
-		if err := validateOhMyCodeAgentConfig(defaultAgent, allowedAgents); err != nil {
-			return fmt.Errorf("invalid %s config: %w", agentConfigName, err)
-		}
-
-		bot, err := NewTelegramBot(m.cfg.Telegram.BotToken, m.cfg.Telegram.AllowedUsers, m.cfg.Telegram.AdminID, defaultAgent, allowedAgents)
-		if err != nil {
-			return fmt.Errorf("failed to init telegram bot: %w", err)
-		}
-		bot.setAllowedChats(m.cfg.Telegram.AllowedChats)
-
-		bot.ConfigureMode(m.cfg.Telegram.Mode)
-		bot.ConfigurePolling(
-			m.cfg.Telegram.PollingTimeoutSeconds,
-			m.cfg.Telegram.PollingLimit,
-			m.cfg.Telegram.PollingOffsetFile,
-		)
-		bot.ConfigureWebhook(
-			m.cfg.Telegram.WebhookListenAddr,
-			m.cfg.Telegram.WebhookPath,
-			m.cfg.Telegram.WebhookPublicURL,
-			m.cfg.Telegram.WebhookSecretToken,
-		)
-		bot.ConfigureWebhookLifecycle(
-			m.cfg.Telegram.WebhookRegisterOnStart,
-			m.cfg.Telegram.WebhookDeleteOnStop,
-		)
-
-		if err := m.Register(bot); err != nil {
-			return fmt.Errorf("failed to register telegram bot: %w", err)
-		}
-	}
-
-	if m.cfg.Feishu != nil && m.cfg.Feishu.Enabled {
-		if m.Get("feishu") != nil {
-			return nil
-		}
-		if strings.TrimSpace(m.cfg.Feishu.AppID) == "" || strings.TrimSpace(m.cfg.Feishu.AppSecret) == "" {
-			return errors.New("channels.feishu.appId and channels.feishu.appSecret are required when feishu is enabled")
-		}
-
-		defaultAgent, allowedAgents, agentConfigName := activeChannelAgentConfig(m.agentsCfg)
-		if err := validateOhMyCodeAgentConfig(defaultAgent, allowedAgents); err != nil {
-			return fmt.Errorf("invalid %s config: %w", agentConfigName, err)
-		}
-
-		bot, err := NewFeishuBot(
-			m.cfg.Feishu.AppID,
-			m.cfg.Feishu.AppSecret,
-			m.cfg.Feishu.Domain,
-			m.cfg.Feishu.AllowedUsers,
-			defaultAgent,
-			allowedAgents,
-		)
+	for _, definition := range m.registry.Definitions() {
+		settings, ok, err := definition.Decode(m.cfg)
 		if err != nil {
-			return fmt.Errorf("failed to init feishu bot: %w", err)
-		}
-
-		if err := m.Register(bot); err != nil {
-			return fmt.Errorf("failed to register feishu bot: %w", err)
+			return err
 		}
-	}
-
-	if m.cfg.Slack != nil && m.cfg.Slack.Enabled {
-		if m.Get("slack") != nil {
-			return nil
+		if !ok || !settings.Enabled {
+			continue
 		}
-		if strings.TrimSpace(m.cfg.Slack.BotToken) == "" || strings.TrimSpace(m.cfg.Slack.AppToken) == "" {
-			return errors.New("channels.slack.botToken and channels.slack.appToken are required when slack is enabled")
+		if m.Get(definition.Name) != nil {
+			continue
 		}
-
-		defaultAgent, allowedAgents, agentConfigName := activeChannelAgentConfig(m.agentsCfg)
-		if err := validateOhMyCodeAgentConfig(defaultAgent, allowedAgents); err != nil {
-			return fmt.Errorf("invalid %s config: %w", agentConfigName, err)
+		if definition.Validate != nil {
+			if err := definition.Validate(settings); err != nil {
+				return err
+			}
 		}
 
-		bot, err := NewSlackBot(
-			m.cfg.Slack.BotToken,
-			m.cfg.Slack.AppToken,
-			m.cfg.Slack.AllowedUsers,
-			m.cfg.Slack.AllowedChannels,
-			defaultAgent,
-			allowedAgents,
-		)
+		channel, err := definition.New(settings, m.agentsCfg)
 		if err != nil {
-			return fmt.Errorf("failed to init slack bot: %w", err)
+			return err
 		}
-
-		if err := m.Register(bot); err != nil {
-			return fmt.Errorf("failed to register slack bot: %w", err)
+		if err := m.Register(channel); err != nil {
+			return fmt.Errorf("failed to register %s channel: %w", definition.Name, err)
 		}
 	}
 
-	if m.cfg.Discord != nil && m.cfg.Discord.Enabled {
-		if m.Get("discord") != nil {
-			return nil
-		}
-		if strings.TrimSpace(m.cfg.Discord.Token) == "" {
-			return errors.New("channels.discord.token is required when discord is enabled")
-		}
-
-		defaultAgent, allowedAgents, agentConfigName := activeChannelAgentConfig(m.agentsCfg)
-		if err := validateOhMyCodeAgentConfig(defaultAgent, allowedAgents); err != nil {
-			return fmt.Errorf("invalid %s config: %w", agentConfigName, err)
-		}
-
-		bot, err := NewDiscordBot(
-			m.cfg.Discord.Token,
-			m.cfg.Discord.AllowedUsers,
-			defaultAgent,
-			allowedAgents,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to init discord bot: %w", err)
-		}
+	return nil
+}
 
-		if err := m.Register(bot); err != nil {
-			return fmt.Errorf("failed to register discord bot: %w", err)
-		}
-	}
+// Registry returns the channel definitions used by this manager.
+func (m *Manager) Registry() *ChannelRegistry {
+	return m.registry
+}
 
-	if m.cfg.IMessage != nil && m.cfg.IMessage.Enabled {
-		if m.Get("imessage") != nil {
-			return nil
+// ConfiguredStatus reports adapter-specific status for every configured
+// channel section, in registration order.
+func (m *Manager) ConfiguredStatus() []ConfiguredChannel {
+	var out []ConfiguredChannel
+	for _, definition := range m.registry.Definitions() {
+		settings, ok, err := definition.Decode(m.cfg)
+		if err != nil || !ok {
+			continue
 		}
-		if strings.TrimSpace(m.cfg.IMessage.Recipient) == "" {
-			return errors.New("channels.imessage.recipient is required when imessage is enabled")
+		entry := ConfiguredChannel{Name: definition.Name, Enabled: settings.Enabled}
+		channel := m.Get(definition.Name)
+		if channel != nil {
+			entry.Running = channel.IsRunning()
 		}
-
-		bot, err := NewIMessageBot(
-			m.cfg.IMessage.Recipient,
-			m.cfg.IMessage.Message,
-			m.cfg.IMessage.Service,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to init imessage bot: %w", err)
-		}
-		bot.ConfigurePolling(
-			m.cfg.IMessage.PollingEnabled,
-			m.cfg.IMessage.PollingIntervalSeconds,
-			m.cfg.IMessage.PollingLimit,
-			m.cfg.IMessage.DatabasePath,
-		)
-
-		if err := m.Register(bot); err != nil {
-			return fmt.Errorf("failed to register imessage bot: %w", err)
+		if definition.Status != nil {
+			entry.Status = definition.Status(settings, channel)
 		}
+		out = append(out, entry)
 	}
+	return out
+}
 
-	if m.cfg.Demail != nil && m.cfg.Demail.Enabled {
-		if m.Get("demail") != nil {
-			return nil
-		}
-		if strings.TrimSpace(m.cfg.Demail.RPCURL) == "" || strings.TrimSpace(m.cfg.Demail.PackageID) == "" || strings.TrimSpace(m.cfg.Demail.Address) == "" {
-			return errors.New("channels.demail.rpcUrl, channels.demail.packageId and channels.demail.address are required when demail is enabled")
-		}
-
-		channel, err := NewDemailChannel(DemailOptions{
-			RPCURL:          m.cfg.Demail.RPCURL,
-			PackageID:       m.cfg.Demail.PackageID,
-			Address:         m.cfg.Demail.Address,
-			IdentityKeyFile: m.cfg.Demail.IdentityKeyFile,
-			SponsorAddress:  m.cfg.Demail.SponsorAddress,
-			GasCoin:         m.cfg.Demail.GasCoin,
-			PollInterval:    time.Duration(m.cfg.Demail.PollIntervalSeconds) * time.Second,
-			CursorFile:      m.cfg.Demail.CursorFile,
-			AllowedSenders:  m.cfg.Demail.AllowedSenders,
-			Peers:           m.cfg.Demail.Peers,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to init demail channel: %w", err)
-		}
+// ConfiguredChannel is one configured channel section as seen by /status.
+type ConfiguredChannel struct {
+	Name    string
+	Enabled bool
+	Running bool
+	Status  ChannelStatus
+}
 
-		if err := m.Register(channel); err != nil {
-			return fmt.Errorf("failed to register demail channel: %w", err)
-		}
+// channelAgentConfig returns the validated default agent and allowlist of the
+// active Agent Runtime router.
+func channelAgentConfig(cfg *config.AgentsConfig) (string, []string, error) {
+	defaultAgent, allowedAgents, agentConfigName := activeChannelAgentConfig(cfg)
+	if err := validateOhMyCodeAgentConfig(defaultAgent, allowedAgents); err != nil {
+		return "", nil, fmt.Errorf("invalid %s config: %w", agentConfigName, err)
 	}
-
-	return nil
+	return defaultAgent, allowedAgents, nil
 }
 
 func activeChannelAgentConfig(cfg *config.AgentsConfig) (string, []string, string) {
 	if cfg == nil {
 		return "", nil, "agents.ohMyCode"
 	}
-	router := strings.TrimSpace(cfg.Router)
-	if router == "codexAppCDP" && cfg.CodexAppCDP != nil {
-		return cfg.CodexAppCDP.DefaultAgent, cfg.CodexAppCDP.AllowedAgents, "agents.codexAppCDP"
-	}
-	if router == "" && cfg.CodexAppCDP != nil && cfg.CodexAppCDP.Enabled && (cfg.OhMyCode == nil || !cfg.OhMyCode.Enabled) {
-		return cfg.CodexAppCDP.DefaultAgent, cfg.CodexAppCDP.AllowedAgents, "agents.codexAppCDP"
+	if router := cfg.ActiveRouter(); router != "" {
+		if routing, ok := cfg.RuntimeRouting(router); ok {
+			return routing.DefaultAgent, routing.AllowedAgents, cfg.RuntimeConfigPath(router)
+		}
 	}
 	if cfg.OhMyCode != nil {
-		return cfg.OhMyCode.DefaultAgent, cfg.OhMyCode.AllowedAgents, "agents.ohMyCode"
+		defaultAgent, allowedAgents := cfg.OhMyCode.RoutingAgents()
+		return defaultAgent, allowedAgents, "agents.ohMyCode"
 	}
 	return "", nil, "agents.ohMyCode"
 }
@@ -0,0 +1,397 @@
+package channels
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// ChannelSettings is the adapter-neutral view of one decoded channel section.
+type ChannelSettings struct {
+	Enabled bool
+	// Config is the adapter-specific decoded section, for example
+	// *config.TelegramConfig.
+	Config interface{}
+}
+
+// ChannelStatus carries adapter-specific fields reported in /status.
+type ChannelStatus struct {
+	Mode    string
+	Webhook *TelegramWebhookStatus
+}
+
+// ChannelDefinition registers a channel adapter with the Manager. Decode and
+// New are required; Validate and Status are optional.
+type ChannelDefinition struct {
+	Name string
+
+	// Decode extracts the channel section from config. ok is false when the
+	// section is absent.
+	Decode func(cfg *config.ChannelsConfig) (settings ChannelSettings, ok bool, err error)
+
+	// Validate rejects incomplete settings of an enabled channel.
+	Validate func(settings ChannelSettings) error
+
+	// New builds the channel from enabled, validated settings.
+	New func(settings ChannelSettings, agentsCfg *config.AgentsConfig) (Channel, error)
+
+	// Status reports adapter-specific status fields. ch is nil when the
+	// channel is configured but not registered.
+	Status func(settings ChannelSettings, ch Channel) ChannelStatus
+}
+
+// ChannelRegistry holds channel definitions in registration order.
+type ChannelRegistry struct {
+	mu          sync.RWMutex
+	definitions []ChannelDefinition
+}
+
+// NewChannelRegistry returns an empty registry.
+func NewChannelRegistry() *ChannelRegistry {
+	return &ChannelRegistry{}
+}
+
+// Register adds a definition. Names must be unique.
+func (r *ChannelRegistry) Register(definition ChannelDefinition) error {
+	definition.Name = strings.TrimSpace(definition.Name)
+	if definition.Name == "" {
+		return errors.New("channel name is required")
+	}
+	if definition.Decode == nil {
+		return fmt.Errorf("channel %q: decoder is required", definition.Name)
+	}
+	if definition.New == nil {
+		return fmt.Errorf("channel %q: factory is required", definition.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.definitions {
+		if existing.Name == definition.Name {
+			return fmt.Errorf("channel %q already registered", definition.Name)
+		}
+	}
+	r.definitions = append(r.definitions, definition)
+	return nil
+}
+
+// Definitions returns registered definitions in registration order.
+func (r *ChannelRegistry) Definitions() []ChannelDefinition {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ChannelDefinition(nil), r.definitions...)
+}
+
+// Lookup returns the definition registered under name.
+func (r *ChannelRegistry) Lookup(name string) (ChannelDefinition, bool) {
+	if r == nil {
+		return ChannelDefinition{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, definition := range r.definitions {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return ChannelDefinition{}, false
+}
+
+func (r *ChannelRegistry) clone() *ChannelRegistry {
+	return &ChannelRegistry{definitions: r.Definitions()}
+}
+
+var defaultChannelRegistry = newBuiltinChannelRegistry()
+
+// RegisterChannel adds a definition to the process-wide registry. Managers
+// created afterwards build the channel when its section is enabled.
+func RegisterChannel(definition ChannelDefinition) error {
+	return defaultChannelRegistry.Register(definition)
+}
+
+func newBuiltinChannelRegistry() *ChannelRegistry {
+	registry := NewChannelRegistry()
+	for _, definition := range []ChannelDefinition{
+		telegramChannelDefinition(),
+		slackChannelDefinition(),
+		feishuChannelDefinition(),
+		discordChannelDefinition(),
+		imessageChannelDefinition(),
+		demailChannelDefinition(),
+	} {
+		if err := registry.Register(definition); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+func telegramChannelDefinition() ChannelDefinition {
+	return ChannelDefinition{
+		Name: "telegram",
+		Decode: func(cfg *config.ChannelsConfig) (ChannelSettings, bool, error) {
+			if cfg == nil || cfg.Telegram == nil {
+				return ChannelSettings{}, false, nil
+			}
+			return ChannelSettings{Enabled: cfg.Telegram.Enabled, Config: cfg.Telegram}, true, nil
+		},
+		Validate: func(settings ChannelSettings) error {
+			if settings.Config.(*config.TelegramConfig).BotToken == "" {
+				return errors.New("channels.telegram.botToken is required when telegram is enabled")
+			}
+			return nil
+		},
+		New: func(settings ChannelSettings, agentsCfg *config.AgentsConfig) (Channel, error) {
+			cfg := settings.Config.(*config.TelegramConfig)
+			defaultAgent, allowedAgents, err := channelAgentConfig(agentsCfg)
+			if err != nil {
+				return nil, err
+			}
+			bot, err := NewTelegramBot(cfg.BotToken, cfg.AllowedUsers, cfg.AdminID, defaultAgent, allowedAgents)
+			if err != nil {
+				return nil, fmt.Errorf("failed to init telegram bot: %w", err)
+			}
+			bot.setAllowedChats(cfg.AllowedChats)
+
+			bot.ConfigureMode(cfg.Mode)
+			bot.ConfigurePolling(
+				cfg.PollingTimeoutSeconds,
+				cfg.PollingLimit,
+				cfg.PollingOffsetFile,
+			)
+			bot.ConfigureWebhook(
+				cfg.WebhookListenAddr,
+				cfg.WebhookPath,
+				cfg.WebhookPublicURL,
+				cfg.WebhookSecretToken,
+			)
+			bot.ConfigureWebhookLifecycle(
+				cfg.WebhookRegisterOnStart,
+				cfg.WebhookDeleteOnStop,
+			)
+			return bot, nil
+		},
+		Status: func(settings ChannelSettings, ch Channel) ChannelStatus {
+			cfg := settings.Config.(*config.TelegramConfig)
+			status := ChannelStatus{
+				Mode: telegramModeFromConfig(cfg),
+				Webhook: &TelegramWebhookStatus{
+					RegisterOnStart:      cfg.WebhookRegisterOnStart,
+					DeleteOnStop:         cfg.WebhookDeleteOnStop,
+					PublicURLConfigured:  strings.TrimSpace(cfg.WebhookPublicURL) != "",
+					ListenAddrConfigured: strings.TrimSpace(cfg.WebhookListenAddr) != "",
+				},
+			}
+			if bot, ok := ch.(*TelegramBot); ok {
+				if bot.Mode() != "" {
+					status.Mode = bot.Mode()
+				}
+				webhook := bot.WebhookStatus()
+				status.Webhook = &webhook
+			}
+			return status
+		},
+	}
+}
+
+func telegramModeFromConfig(cfg *config.TelegramConfig) string {
+	mode := strings.ToLower(strings.TrimSpace(cfg.Mode))
+	if mode == "" || mode == "auto" {
+		if strings.TrimSpace(cfg.WebhookListenAddr) != "" || strings.TrimSpace(cfg.WebhookPublicURL) != "" {
+			return "webhook"
+		}
+		return "polling"
+	}
+	return mode
+}
+
+func slackChannelDefinition() ChannelDefinition {
+	return ChannelDefinition{
+		Name: "slack",
+		Decode: func(cfg *config.ChannelsConfig) (ChannelSettings, bool, error) {
+			if cfg == nil || cfg.Slack == nil {
+				return ChannelSettings{}, false, nil
+			}
+			return ChannelSettings{Enabled: cfg.Slack.Enabled, Config: cfg.Slack}, true, nil
+		},
+		Validate: func(settings ChannelSettings) error {
+			cfg := settings.Config.(*config.SlackConfig)
+			if strings.TrimSpace(cfg.BotToken) == "" || strings.TrimSpace(cfg.AppToken) == "" {
+				return errors.New("channels.slack.botToken and channels.slack.appToken are required when slack is enabled")
+			}
+			return nil
+		},
+		New: func(settings ChannelSettings, agentsCfg *config.AgentsConfig) (Channel, error) {
+			cfg := settings.Config.(*config.SlackConfig)
+			defaultAgent, allowedAgents, err := channelAgentConfig(agentsCfg)
+			if err != nil {
+				return nil, err
+			}
+			bot, err := NewSlackBot(
+				cfg.BotToken,
+				cfg.AppToken,
+				cfg.AllowedUsers,
+				cfg.AllowedChannels,
+				defaultAgent,
+				allowedAgents,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to init slack bot: %w", err)
+			}
+			return bot, nil
+		},
+	}
+}
+
+func feishuChannelDefinition() ChannelDefinition {
+	return ChannelDefinition{
+		Name: "feishu",
+		Decode: func(cfg *config.ChannelsConfig) (ChannelSettings, bool, error) {
+			if cfg == nil || cfg.Feishu == nil {
+				return ChannelSettings{}, false, nil
+			}
+			return ChannelSettings{Enabled: cfg.Feishu.Enabled, Config: cfg.Feishu}, true, nil
+		},
+		Validate: func(settings ChannelSettings) error {
+			cfg := settings.Config.(*config.FeishuConfig)
+			if strings.TrimSpace(cfg.AppID) == "" || strings.TrimSpace(cfg.AppSecret) == "" {
+				return errors.New("channels.feishu.appId and channels.feishu.appSecret are required when feishu is enabled")
+			}
+			return nil
+		},
+		New: func(settings ChannelSettings, agentsCfg *config.AgentsConfig) (Channel, error) {
+			cfg := settings.Config.(*config.FeishuConfig)
+			defaultAgent, allowedAgents, err := channelAgentConfig(agentsCfg)
+			if err != nil {
+				return nil, err
+			}
+			bot, err := NewFeishuBot(
+				cfg.AppID,
+				cfg.AppSecret,
+				cfg.Domain,
+				cfg.AllowedUsers,
+				defaultAgent,
+				allowedAgents,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to init feishu bot: %w", err)
+			}
+			return bot, nil
+		},
+	}
+}
+
+func discordChannelDefinition() ChannelDefinition {
+	return ChannelDefinition{
+		Name: "discord",
+		Decode: func(cfg *config.ChannelsConfig) (ChannelSettings, bool, error) {
+			if cfg == nil || cfg.Discord == nil {
+				return ChannelSettings{}, false, nil
+			}
+			return ChannelSettings{Enabled: cfg.Discord.Enabled, Config: cfg.Discord}, true, nil
+		},
+		Validate: func(settings ChannelSettings) error {
+			if strings.TrimSpace(settings.Config.(*config.DiscordConfig).Token) == "" {
+				return errors.New("channels.discord.token is required when discord is enabled")
+			}
+			return nil
+		},
+		New: func(settings ChannelSettings, agentsCfg *config.AgentsConfig) (Channel, error) {
+			cfg := settings.Config.(*config.DiscordConfig)
+			defaultAgent, allowedAgents, err := channelAgentConfig(agentsCfg)
+			if err != nil {
+				return nil, err
+			}
+			bot, err := NewDiscordBot(
+				cfg.Token,
+				cfg.AllowedUsers,
+				defaultAgent,
+				allowedAgents,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to init discord bot: %w", err)
+			}
+			return bot, nil
+		},
+	}
+}
+
+func imessageChannelDefinition() ChannelDefinition {
+	return ChannelDefinition{
+		Name: "imessage",
+		Decode: func(cfg *config.ChannelsConfig) (ChannelSettings, bool, error) {
+			if cfg == nil || cfg.IMessage == nil {
+				return ChannelSettings{}, false, nil
+			}
+			return ChannelSettings{Enabled: cfg.IMessage.Enabled, Config: cfg.IMessage}, true, nil
+		},
+		Validate: func(settings ChannelSettings) error {
+			if strings.TrimSpace(settings.Config.(*config.IMessageConfig).Recipient) == "" {
+				return errors.New("channels.imessage.recipient is required when imessage is enabled")
+			}
+			return nil
+		},
+		New: func(settings ChannelSettings, _ *config.AgentsConfig) (Channel, error) {
+			cfg := settings.Config.(*config.IMessageConfig)
+			bot, err := NewIMessageBot(
+				cfg.Recipient,
+				cfg.Message,
+				cfg.Service,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to init imessage bot: %w", err)
+			}
+			bot.ConfigurePolling(
+				cfg.PollingEnabled,
+				cfg.PollingIntervalSeconds,
+				cfg.PollingLimit,
+				cfg.DatabasePath,
+			)
+			return bot, nil
+		},
+	}
+}
+
+func demailChannelDefinition() ChannelDefinition {
+	return ChannelDefinition{
+		Name: "demail",
+		Decode: func(cfg *config.ChannelsConfig) (ChannelSettings, bool, error) {
+			if cfg == nil || cfg.Demail == nil {
+				return ChannelSettings{}, false, nil
+			}
+			return ChannelSettings{Enabled: cfg.Demail.Enabled, Config: cfg.Demail}, true, nil
+		},
+		Validate: func(settings ChannelSettings) error {
+			cfg := settings.Config.(*config.DemailConfig)
+			if strings.TrimSpace(cfg.RPCURL) == "" || strings.TrimSpace(cfg.PackageID) == "" || strings.TrimSpace(cfg.Address) == "" {
+				return errors.New("channels.demail.rpcUrl, channels.demail.packageId and channels.demail.address are required when demail is enabled")
+			}
+			return nil
+		},
+		New: func(settings ChannelSettings, _ *config.AgentsConfig) (Channel, error) {
+			cfg := settings.Config.(*config.DemailConfig)
+			channel, err := NewDemailChannel(DemailOptions{
+				RPCURL:          cfg.RPCURL,
+				PackageID:       cfg.PackageID,
+				Address:         cfg.Address,
+				IdentityKeyFile: cfg.IdentityKeyFile,
+				SponsorAddress:  cfg.SponsorAddress,
+				GasCoin:         cfg.GasCoin,
+				PollInterval:    time.Duration(cfg.PollIntervalSeconds) * time.Second,
+				CursorFile:      cfg.CursorFile,
+				AllowedSenders:  cfg.AllowedSenders,
+				Peers:           cfg.Peers,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to init demail channel: %w", err)
+			}
+			return channel, nil
+		},
+	}
+}
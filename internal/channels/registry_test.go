@@ -0,0 +1,147 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestChannelRegistryRejectsInvalidDefinitions(t *testing.T) {
+	registry := NewChannelRegistry()
+	decode := func(*config.ChannelsConfig) (ChannelSettings, bool, error) { return ChannelSettings{}, false, nil }
+	newChannel := func(ChannelSettings, *config.AgentsConfig) (Channel, error) { return nil, nil }
+
+	if err := registry.Register(ChannelDefinition{Decode: decode, New: newChannel}); err == nil {
+		t.Fatal("expected missing name error")
+	}
+	if err := registry.Register(ChannelDefinition{Name: "x", New: newChannel}); err == nil {
+		t.Fatal("expected missing decoder error")
+	}
+	if err := registry.Register(ChannelDefinition{Name: "x", Decode: decode, New: newChannel}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := registry.Register(ChannelDefinition{Name: "x", Decode: decode, New: newChannel}); err == nil {
+		t.Fatal("expected duplicate error")
+	}
+}
+
+// extensionChannel is a channel built from an extension config section. The
+// manager starts and stops it on other goroutines, so its state is locked.
+type extensionChannel struct {
+	room string
+
+	mu      sync.Mutex
+	running bool
+}
+
+func (c *extensionChannel) Name() string { return "fake" }
+
+func (c *extensionChannel) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running = true
+	return nil
+}
+
+func (c *extensionChannel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running = false
+	return nil
+}
+
+func (c *extensionChannel) Send(ctx context.Context, msg OutboundMessage) (*SendResult, error) {
+	return &SendResult{ChannelID: msg.To}, nil
+}
+
+func (c *extensionChannel) IsRunning() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.running
+}
+
+func (c *extensionChannel) IsAllowed(senderID string) bool { return true }
+
+func TestManagerBuildsRegisteredExtensionChannel(t *testing.T) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("enabled: true\nroom: ops\n"), &node); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	cfg := &config.ChannelsConfig{Extensions: map[string]yaml.Node{"fake": *node.Content[0]}}
+	manager := NewManager(cfg, nil)
+
+	type fakeSection struct {
+		Enabled bool   `yaml:"enabled"`
+		Room    string `yaml:"room"`
+	}
+	err := manager.Registry().Register(ChannelDefinition{
+		Name: "fake",
+		Decode: func(cfg *config.ChannelsConfig) (ChannelSettings, bool, error) {
+			var section fakeSection
+			ok, err := cfg.DecodeExtension("fake", &section)
+			return ChannelSettings{Enabled: section.Enabled, Config: section}, ok, err
+		},
+		New: func(settings ChannelSettings, _ *config.AgentsConfig) (Channel, error) {
+			return &extensionChannel{room: settings.Config.(fakeSection).Room}, nil
+		},
+		Status: func(ChannelSettings, Channel) ChannelStatus {
+			return ChannelStatus{Mode: "fake-mode"}
+		},
+	})
+	if err != nil {
+		t.Fatalf("register definition: %v", err)
+	}
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer manager.Stop()
+
+	ch, ok := manager.Get("fake").(*extensionChannel)
+	if !ok {
+		t.Fatalf("expected fake channel to be registered, got %#v", manager.Get("fake"))
+	}
+	if ch.room != "ops" {
+		t.Fatalf("expected decoded room, got %q", ch.room)
+	}
+
+	statuses := manager.ConfiguredStatus()
+	if len(statuses) != 1 || statuses[0].Name != "fake" || !statuses[0].Enabled || statuses[0].Status.Mode != "fake-mode" {
+		t.Fatalf("unexpected configured status: %#v", statuses)
+	}
+}
+
+func TestManagerRegistryIsPerInstance(t *testing.T) {
+	first := NewManager(nil, nil)
+	second := NewManager(nil, nil)
+	err := first.Registry().Register(ChannelDefinition{
+		Name:   "only-first",
+		Decode: func(*config.ChannelsConfig) (ChannelSettings, bool, error) { return ChannelSettings{}, false, nil },
+		New:    func(ChannelSettings, *config.AgentsConfig) (Channel, error) { return nil, nil },
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, ok := second.Registry().Lookup("only-first"); ok {
+		t.Fatal("expected definition to stay local to the first manager")
+	}
+	if _, ok := second.Registry().Lookup("telegram"); !ok {
+		t.Fatal("expected built-in telegram definition")
+	}
+}
+
+func TestActiveChannelAgentConfigUsesClaudeDesktopRouter(t *testing.T) {
+	cfg := &config.AgentsConfig{
+		Router:        "claudeDesktop",
+		OhMyCode:      &config.OhMyCodeConfig{DefaultAgent: "omc", AllowedAgents: []string{"omc"}},
+		ClaudeDesktop: &config.ClaudeDesktopConfig{Enabled: true, DefaultAgent: "claude", AllowedAgents: []string{"claude"}},
+	}
+	defaultAgent, allowed, name := activeChannelAgentConfig(cfg)
+	if defaultAgent != "claude" || strings.Join(allowed, ",") != "claude" || name != "agents.claudeDesktop" {
+		t.Fatalf("unexpected agent config: %q %v %q", defaultAgent, allowed, name)
+	}
+}
@@ -33,7 +33,9 @@ type SlackBot struct {
 	defaultAgent     string
 	agentAllow       AgentAllowlist
 
-	handler IncomingMessageHandler
+	handler         IncomingMessageHandler
+	agentPrefs      *AgentPreferences
+	agentAddressing AgentAddressing
 
 	apiClient    *slack.Client
 	socketClient *socketmode.Client
@@ -91,6 +93,7 @@ func NewSlackBot(botToken, appToken string, allowedUsers []string, allowedChanne
 		channelAllowlist: NewSlackAllowlist(allowedChannels),
 		defaultAgent:     strings.TrimSpace(defaultAgent),
 		agentAllow:       NewAgentAllowlist(allowedAgents),
+		agentAddressing:  DefaultAgentAddressing(),
 		ctx:              context.Background(),
 	}, nil
 }
@@ -103,6 +106,21 @@ func (b *SlackBot) SetHandler(handler IncomingMessageHandler) {
 	b.handler = handler
 }
 
+// SetAgentPreferences sets the store behind /use.
+func (b *SlackBot) SetAgentPreferences(preferences *AgentPreferences) {
+	b.agentPrefs = preferences
+}
+
+// SetAgentAddressing sets the mention syntaxes and aliases recognised in
+// messages.
+func (b *SlackBot) SetAgentAddressing(addressing AgentAddressing) {
+	b.agentAddressing = addressing
+}
+
+func (b *SlackBot) agentPreferenceKey(msg *slackInboundMessage) AgentPreferenceKey {
+	return AgentPreferenceKey{Channel: "slack", ChatID: msg.channelID, UserID: msg.userID}
+}
+
 func (b *SlackBot) IsRunning() bool {
 	b.runningMu.RLock()
 	defer b.runningMu.RUnlock()
@@ -449,7 +467,7 @@ func (b *SlackBot) slashCommandReply(ctx context.Context, msg *slackInboundMessa
 		return replyText
 	}
 
-	selection, err := ParseAgentSelection(msg.text)
+	selection, err := ParseAgentSelection(msg.text, b.agentAddressing)
 	if err != nil {
 		return b.formatCommandError(err)
 	}
@@ -458,9 +476,10 @@ func (b *SlackBot) slashCommandReply(ctx context.Context, msg *slackInboundMessa
 		return ""
 	}
 
-	enforceSelection := selection.Specified || b.defaultAgent != "" || b.agentAllow.configured
+	preferredAgent := b.agentPrefs.Get(b.agentPreferenceKey(msg))
+	enforceSelection := selection.Specified || preferredAgent != "" || b.defaultAgent != "" || b.agentAllow.configured
 	if enforceSelection {
-		selection, err = ResolveAgentSelection(selection, b.defaultAgent, b.agentAllow)
+		selection, err = ResolveAgentSelection(selection, preferredAgent, b.defaultAgent, b.agentAllow)
 		if err != nil {
 			if isDefaultAgentMissingError(err) && !selection.Specified && b.agentAllow.configured {
 				return "❌ Default agent is missing or invalid.\nSet agents.ohMyCode.defaultAgent or use /agent <name> <task> (or /to <name> <task>).\nTip: use /agents to see allowed agents."
@@ -471,11 +490,14 @@ func (b *SlackBot) slashCommandReply(ctx context.Context, msg *slackInboundMessa
 
 	if b.handler != nil {
 		recentMessages := b.fetchRecentMessages(ctx, msg.channelID, 5)
-		replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, selection.Agent, trustLevel, recentMessages))
-		if err != nil {
-			log.Printf("slack handler error: %v", err)
-			replyText = "❌ Something went wrong. Please try again."
-		}
+		replyText := fanOutAgentSelection(selection, func(agent string) string {
+			replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, agent, trustLevel, recentMessages))
+			if err != nil {
+				log.Printf("slack handler error: %v", err)
+				replyText = "❌ Something went wrong. Please try again."
+			}
+			return replyText
+		})
 		return strings.TrimSpace(replyText)
 	}
 
@@ -545,7 +567,7 @@ func (b *SlackBot) handleMessageEvent(ctx context.Context, msg *slackInboundMess
 		return
 	}
 
-	selection, err := ParseAgentSelection(msg.text)
+	selection, err := ParseAgentSelection(msg.text, b.agentAddressing)
 	if err != nil {
 		reply := fmt.Sprintf("❌ %v", err)
 		if isAgentNotAllowedError(err) {
@@ -561,9 +583,10 @@ func (b *SlackBot) handleMessageEvent(ctx context.Context, msg *slackInboundMess
 		return
 	}
 
-	enforceSelection := selection.Specified || b.defaultAgent != "" || b.agentAllow.configured
+	preferredAgent := b.agentPrefs.Get(b.agentPreferenceKey(msg))
+	enforceSelection := selection.Specified || preferredAgent != "" || b.defaultAgent != "" || b.agentAllow.configured
 	if enforceSelection {
-		selection, err = ResolveAgentSelection(selection, b.defaultAgent, b.agentAllow)
+		selection, err = ResolveAgentSelection(selection, preferredAgent, b.defaultAgent, b.agentAllow)
 		if err != nil {
 			reply := fmt.Sprintf("❌ %v", err)
 			if isDefaultAgentMissingError(err) && !selection.Specified && b.agentAllow.configured {
@@ -580,11 +603,14 @@ func (b *SlackBot) handleMessageEvent(ctx context.Context, msg *slackInboundMess
 
 	if b.handler != nil {
 		recentMessages := b.fetchRecentMessages(ctx, msg.channelID, 5)
-		replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, selection.Agent, trustLevel, recentMessages))
-		if err != nil {
-			log.Printf("slack handler error: %v", err)
-			replyText = "❌ Something went wrong. Please try again."
-		}
+		replyText := fanOutAgentSelection(selection, func(agent string) string {
+			replyText, err := b.handler.HandleIncoming(ctx, b.toProtocolMessage(msg, selection.Task, agent, trustLevel, recentMessages))
+			if err != nil {
+				log.Printf("slack handler error: %v", err)
+				replyText = "❌ Something went wrong. Please try again."
+			}
+			return replyText
+		})
 		if strings.TrimSpace(replyText) != "" {
 			_ = b.reply(ctx, msg, replyText)
 		}
@@ -653,6 +679,12 @@ func (b *SlackBot) commandResponse(ctx context.Context, msg *slackInboundMessage
 			return true, "", err
 		}
 		return true, strings.TrimSpace(replyText), nil
+	case "/use":
+		reply, err := useCommandReply(b.agentPrefs, b.agentPreferenceKey(msg), fields[1:], b.defaultAgent, b.agentAllow, b.agentAddressing)
+		if err != nil {
+			return true, "", err
+		}
+		return true, reply, nil
 	case "/agents":
 		names := b.agentAllow.Names()
 		defaultName := strings.TrimSpace(b.defaultAgent)
@@ -675,6 +707,9 @@ func (b *SlackBot) commandResponse(ctx context.Context, msg *slackInboundMessage
 		for _, name := range names {
 			sb.WriteString(fmt.Sprintf("  - %s\n", name))
 		}
+		if registry := agentDirectoryText(b.handler); registry != "" {
+			sb.WriteString("\n" + registry)
+		}
 		return true, strings.TrimSpace(sb.String()), nil
 	case "/monitor":
 		agentName, lines, err := parseMonitorArgs(fields)
@@ -737,11 +772,18 @@ func (b *SlackBot) commandResponse(ctx context.Context, msg *slackInboundMessage
 		}
 		return true, out, nil
 	case "/doctor":
+		if len(fields) > 2 {
+			return true, "", fmt.Errorf("usage: /doctor [workspace]")
+		}
+		workspace := ""
+		if len(fields) == 2 {
+			workspace = fields[1]
+		}
 		lifecycle, ok := b.handler.(AgentLifecycle)
 		if !ok || lifecycle == nil {
 			return true, "", errors.New("agent-manager is not available (set agents.ohMyCode.enabled)")
 		}
-		out, err := lifecycle.Doctor(b.ctx)
+		out, err := lifecycle.Doctor(b.ctx, workspace)
 		if err != nil {
 			return true, "", b.sanitizeLifecycleError(command, err)
 		}
@@ -788,6 +830,7 @@ func (b *SlackBot) helpText() string {
 		"Agent routing:",
 		"  /agent <name> <task...>",
 		"  /to <name> <task...> (alias of /agent)",
+		"  /use [name|default] - show or set your agent in this chat",
 		"  /agents - see available agents",
 		"  Note: if an allowlist is configured, only allowlisted agents can be used.",
 		"",
@@ -65,7 +65,7 @@ func (f *fakeSlackLifecycle) StopAgent(ctx context.Context, agentName string) (s
 	return "", nil
 }
 
-func (f *fakeSlackLifecycle) Doctor(ctx context.Context) (string, error) {
+func (f *fakeSlackLifecycle) Doctor(ctx context.Context, workspace string) (string, error) {
 	_ = ctx
 	return "", nil
 }
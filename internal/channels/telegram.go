@@ -36,8 +36,11 @@ type TelegramBot struct {
 	defaultAgent   string
 	agentAllowlist AgentAllowlist
 
-	handler     IncomingMessageHandler
-	userManager *UserManager
+	handler          IncomingMessageHandler
+	agentPrefs       *AgentPreferences
+	agentAddressing  AgentAddressing
+	heartbeatControl HeartbeatControl
+	userManager      *UserManager
 
 	allowedChats           map[int64]struct{}
 	allowedChatsConfigured bool
@@ -96,18 +99,19 @@ func NewTelegramBot(token string, allowedUsers []int64, adminID int64, defaultAg
 	}
 
 	return &TelegramBot{
-		botToken:       token,
-		defaultAgent:   strings.TrimSpace(defaultAgent),
-		agentAllowlist: NewAgentAllowlist(allowedAgents),
-		userManager:    userManager,
-		adminID:        adminID,
-		webhookPath:    defaultTelegramWebhookPath,
-		ctx:            context.Background(),
-		startTime:      time.Now(),
-		pollingTimeout: defaultTelegramPollingTimeout,
-		pollingLimit:   defaultTelegramPollingLimit,
-		httpClient:     &http.Client{Timeout: 35 * time.Second},
-		sleeper:        time.Sleep,
+		botToken:        token,
+		defaultAgent:    strings.TrimSpace(defaultAgent),
+		agentAllowlist:  NewAgentAllowlist(allowedAgents),
+		agentAddressing: DefaultAgentAddressing(),
+		userManager:     userManager,
+		adminID:         adminID,
+		webhookPath:     defaultTelegramWebhookPath,
+		ctx:             context.Background(),
+		startTime:       time.Now(),
+		pollingTimeout:  defaultTelegramPollingTimeout,
+		pollingLimit:    defaultTelegramPollingLimit,
+		httpClient:      &http.Client{Timeout: 35 * time.Second},
+		sleeper:         time.Sleep,
 	}, nil
 }
 
@@ -126,6 +130,26 @@ func (b *TelegramBot) SetHandler(handler IncomingMessageHandler) {
 	b.handler = handler
 }
 
+// SetAgentPreferences sets the store behind /use.
+func (b *TelegramBot) SetAgentPreferences(preferences *AgentPreferences) {
+	b.agentPrefs = preferences
+}
+
+// SetAgentAddressing sets the mention syntaxes and aliases recognised in
+// messages.
+func (b *TelegramBot) SetAgentAddressing(addressing AgentAddressing) {
+	b.agentAddressing = addressing
+}
+
+// SetHeartbeatControl enables the admin /heartbeat command.
+func (b *TelegramBot) SetHeartbeatControl(control HeartbeatControl) {
+	b.heartbeatControl = control
+}
+
+func (b *TelegramBot) agentPreferenceKey(msg *TelegramMessage) AgentPreferenceKey {
+	return AgentPreferenceKey{Channel: "telegram", ChatID: strconv.FormatInt(msg.Chat.ID, 10), UserID: strconv.FormatInt(msg.From.ID, 10)}
+}
+
 // IsRunning reports whether the bot has been started.
 func (b *TelegramBot) IsRunning() bool {
 	b.runningMu.RLock()
@@ -675,7 +699,7 @@ func (b *TelegramBot) handleIncomingMessage(message *TelegramMessage) {
 		return
 	}
 
-	selection, err := ParseAgentSelection(message.Text)
+	selection, err := ParseAgentSelection(message.Text, b.agentAddressing)
 	if err != nil {
 		reply := fmt.Sprintf("❌ %v", err)
 		if isAgentNotAllowedError(err) {
@@ -691,9 +715,10 @@ func (b *TelegramBot) handleIncomingMessage(message *TelegramMessage) {
 		return
 	}
 
-	enforceSelection := selection.Specified || b.defaultAgent != "" || b.agentAllowlist.configured
+	preferredAgent := b.agentPrefs.Get(b.agentPreferenceKey(message))
+	enforceSelection := selection.Specified || preferredAgent != "" || b.defaultAgent != "" || b.agentAllowlist.configured
 	if enforceSelection && !isTelegramToolInvocation(selection.Task) {
-		selection, err = ResolveAgentSelection(selection, b.defaultAgent, b.agentAllowlist)
+		selection, err = ResolveAgentSelection(selection, preferredAgent, b.defaultAgent, b.agentAllowlist)
 		if err != nil {
 			reply := fmt.Sprintf("❌ %v", err)
 			if !selection.Specified && (isDefaultAgentMissingError(err) || isInvalidAgentNameError(err)) {
@@ -709,14 +734,16 @@ func (b *TelegramBot) handleIncomingMessage(message *TelegramMessage) {
 	}
 
 	attachments := b.extractTelegramAttachments(b.ctx, message)
-	msg := b.convertToProtocolMessage(message, selection.Task, selection.Agent, attachments)
 
 	if b.handler != nil {
-		replyText, err := b.handler.HandleIncoming(b.ctx, msg)
-		if err != nil {
-			log.Printf("Telegram handler error: %v", err)
-			replyText = "❌ Something went wrong. Please try again."
-		}
+		replyText := fanOutAgentSelection(selection, func(agent string) string {
+			replyText, err := b.handler.HandleIncoming(b.ctx, b.convertToProtocolMessage(message, selection.Task, agent, attachments))
+			if err != nil {
+				log.Printf("Telegram handler error: %v", err)
+				replyText = "❌ Something went wrong. Please try again."
+			}
+			return replyText
+		})
 		if strings.TrimSpace(replyText) != "" {
 			_ = b.sendToChat(b.ctx, message.Chat.ID, TruncateTelegramReply(replyText))
 		}
@@ -773,6 +800,13 @@ func (b *TelegramBot) handleCommand(msg *TelegramMessage) (bool, error) {
 		reply := formatTelegramWhoamiReply(msg, b.adminID)
 		return true, b.sendToChat(b.ctx, msg.Chat.ID, TruncateTelegramReply(reply))
 
+	case "/use":
+		reply, err := useCommandReply(b.agentPrefs, b.agentPreferenceKey(msg), parts[1:], b.defaultAgent, b.agentAllowlist, b.agentAddressing)
+		if err != nil {
+			return true, err
+		}
+		return true, b.sendToChat(b.ctx, msg.Chat.ID, reply)
+
 	case "/agents":
 		names := b.agentAllowlist.Names()
 		defaultName := strings.TrimSpace(b.defaultAgent)
@@ -790,6 +824,9 @@ func (b *TelegramBot) handleCommand(msg *TelegramMessage) (bool, error) {
 		for _, name := range names {
 			sb.WriteString(fmt.Sprintf("  - %s\n", name))
 		}
+		if registry := agentDirectoryText(b.handler); registry != "" {
+			sb.WriteString("\n" + registry)
+		}
 		return true, b.sendToChat(b.ctx, msg.Chat.ID, strings.TrimSpace(sb.String()))
 
 	case "/monitor":
@@ -865,11 +902,18 @@ func (b *TelegramBot) handleCommand(msg *TelegramMessage) (bool, error) {
 		if err := requireAdmin(); err != nil {
 			return true, err
 		}
+		if len(parts) > 2 {
+			return true, fmt.Errorf("usage: /doctor [workspace]")
+		}
+		workspace := ""
+		if len(parts) == 2 {
+			workspace = strings.TrimSpace(parts[1])
+		}
 		lifecycle, ok := b.handler.(AgentLifecycle)
 		if !ok || lifecycle == nil {
 			return true, errors.New("agent-manager is not available")
 		}
-		out, err := lifecycle.Doctor(b.ctx)
+		out, err := lifecycle.Doctor(b.ctx, workspace)
 		if err != nil {
 			return true, b.sanitizeLifecycleError(command, err)
 		}
@@ -878,6 +922,16 @@ func (b *TelegramBot) handleCommand(msg *TelegramMessage) (bool, error) {
 		}
 		return true, b.sendToChat(b.ctx, msg.Chat.ID, TruncateTelegramReply(out))
 
+	case "/heartbeat":
+		if err := requireAdmin(); err != nil {
+			return true, err
+		}
+		reply, err := heartbeatCommandReply(b.heartbeatControl, parts[1:], fmt.Sprintf("telegram:%d", msg.From.ID))
+		if err != nil {
+			return true, err
+		}
+		return true, b.sendToChat(b.ctx, msg.Chat.ID, TruncateTelegramReply(reply))
+
 	case "/adduser":
 		if err := requireAdmin(); err != nil {
 			return true, err
@@ -990,7 +1044,8 @@ func (b *TelegramBot) helpText() string {
 	sb.WriteString("  /listusers - admin only\n")
 	sb.WriteString("  /startagent <name> - admin only\n")
 	sb.WriteString("  /stopagent <name> - admin only\n")
-	sb.WriteString("  /doctor - admin only\n")
+	sb.WriteString("  /doctor [workspace] - admin only\n")
+	sb.WriteString("  /heartbeat [pause|resume|run <job>] - admin only\n")
 	sb.WriteString("\n")
 	sb.WriteString("Gateway mode:\n")
 	sb.WriteString("  /tools and /tool are intentionally unavailable.\n")
@@ -998,6 +1053,7 @@ func (b *TelegramBot) helpText() string {
 	sb.WriteString("Agent routing:\n")
 	sb.WriteString("  /agent <name> <task...>\n")
 	sb.WriteString("  /to <name> <task...> (alias of /agent)\n")
+	sb.WriteString("  /use [name|default] - show or set your agent in this chat\n")
 	sb.WriteString("  /agents - see available agents\n")
 	sb.WriteString("  Note: if an allowlist is configured, only allowlisted agents can be used.\n")
 	if b.defaultAgent != "" {
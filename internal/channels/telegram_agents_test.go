@@ -3,6 +3,9 @@ package channels
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
 )
 
 func TestTelegramAgentsIncludesDefault(t *testing.T) {
@@ -162,3 +165,45 @@ func TestTelegramAgentNotAllowedDefaultOnly(t *testing.T) {
 		}
 	}
 }
+
+type fakeAgentDirectory struct {
+	fakeReplyHandler
+	agents []protocol.AgentInfo
+}
+
+func (f *fakeAgentDirectory) List() []protocol.AgentInfo {
+	return f.agents
+}
+
+func TestTelegramAgentsShowsRegistryStatus(t *testing.T) {
+	bot, err := NewTelegramBot("token", nil, 123, "qa-1", []string{"qa-1", "coder-a"})
+	if err != nil {
+		t.Fatalf("NewTelegramBot: %v", err)
+	}
+	var payload sendMessagePayload
+	bot.httpClient = captureHTTPClient(t, &payload)
+	lastActivity := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	bot.SetHandler(&fakeAgentDirectory{agents: []protocol.AgentInfo{
+		{ID: "ohMyCode:coder-a", Name: "coder-a", Runtime: "ohMyCode", Status: "stopped"},
+		{ID: "ohMyCode:qa-1", Name: "qa-1", Runtime: "ohMyCode", Status: "running", LastActivity: &lastActivity},
+	}})
+
+	msg := &TelegramMessage{
+		Text: "/agents",
+		From: &TelegramUser{ID: 123},
+		Chat: &TelegramChat{ID: 99},
+	}
+	if handled, err := bot.handleCommand(msg); !handled || err != nil {
+		t.Fatalf("handled=%v err=%v", handled, err)
+	}
+	for _, want := range []string{
+		"Default agent: qa-1",
+		"Agent status:",
+		"  - coder-a: stopped (ohMyCode)",
+		"  - qa-1: running (ohMyCode, last active 2026-10-18T09:30:00Z)",
+	} {
+		if !strings.Contains(payload.Text, want) {
+			t.Fatalf("expected %q in %q", want, payload.Text)
+		}
+	}
+}
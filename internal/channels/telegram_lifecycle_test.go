@@ -25,8 +25,9 @@ type fakeLifecycle struct {
 	stopAgent  string
 	stopErr    error
 
-	doctorCalled bool
-	doctorErr    error
+	doctorCalled    bool
+	doctorWorkspace string
+	doctorErr       error
 }
 
 func (f *fakeLifecycle) HandleIncoming(ctx context.Context, msg *protocol.Message) (string, error) {
@@ -52,8 +53,9 @@ func (f *fakeLifecycle) StopAgent(ctx context.Context, agentName string) (string
 	return "", f.stopErr
 }
 
-func (f *fakeLifecycle) Doctor(ctx context.Context) (string, error) {
+func (f *fakeLifecycle) Doctor(ctx context.Context, workspace string) (string, error) {
 	f.doctorCalled = true
+	f.doctorWorkspace = workspace
 	return "", f.doctorErr
 }
 
@@ -115,6 +117,34 @@ func TestTelegramMonitorDispatch(t *testing.T) {
 	}
 }
 
+func TestTelegramLifecycleCommandsAcceptWorkspaces(t *testing.T) {
+	bot, err := NewTelegramBot("token", nil, 123, "qa-1", []string{"qa-1", "backend/api-1"})
+	if err != nil {
+		t.Fatalf("NewTelegramBot: %v", err)
+	}
+	bot.httpClient = stubHTTPClient()
+
+	lifecycle := &fakeLifecycle{}
+	bot.SetHandler(lifecycle)
+
+	for _, text := range []string{"/monitor backend/api-1", "/doctor backend"} {
+		handled, err := bot.handleCommand(&TelegramMessage{
+			Text: text,
+			From: &TelegramUser{ID: 123},
+			Chat: &TelegramChat{ID: 1},
+		})
+		if !handled || err != nil {
+			t.Fatalf("%s: handled=%t err=%v", text, handled, err)
+		}
+	}
+	if lifecycle.monitorAgent != "backend/api-1" {
+		t.Fatalf("monitor agent=%q", lifecycle.monitorAgent)
+	}
+	if lifecycle.doctorWorkspace != "backend" {
+		t.Fatalf("doctor workspace=%q", lifecycle.doctorWorkspace)
+	}
+}
+
 func TestTelegramMonitorMissingLifecycle(t *testing.T) {
 	bot, err := NewTelegramBot("token", nil, 123, "qa-1", []string{"qa-1"})
 	if err != nil {
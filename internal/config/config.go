@@ -2,17 +2,29 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/robfig/cron/v3"
 	"gopkg.in/yaml.v3"
 )
 
-var agentNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
+// agentNamePattern accepts an optional "<workspace>/" prefix used to address
+// agents in named oh-my-code workspaces.
+var agentNamePattern = regexp.MustCompile(`^([a-zA-Z0-9_][a-zA-Z0-9_-]*/)?[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
+
+// workspaceNamePattern matches oh-my-code workspace names and the agent names
+// inside a workspace.
+var workspaceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_-]*$`)
+
+// claudeDesktopIDPattern matches Claude conversation and project ids.
+var claudeDesktopIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
 
 // Config represents the main configuration.
 type Config struct {
@@ -37,6 +49,17 @@ type ChannelsConfig struct {
 	Discord  *DiscordConfig  `yaml:"discord,omitempty"`
 	IMessage *IMessageConfig `yaml:"imessage,omitempty"`
 	Demail   *DemailConfig   `yaml:"demail,omitempty"`
+
+	// AgentPreferencesPath persists /use selections. Defaults to
+	// agent-preferences.json in agents.workspace.
+	AgentPreferencesPath string `yaml:"agentPreferencesPath,omitempty"`
+
+	// Plugins launches out-of-process channel adapters.
+	Plugins []PluginConfig `yaml:"plugins,omitempty"`
+
+	// Extensions holds sections for out-of-tree channel adapters, keyed by
+	// channel name. Adapters decode them with DecodeExtension.
+	Extensions map[string]yaml.Node `yaml:",inline"`
 }
 
 // TelegramConfig contains Telegram channel settings.
@@ -175,6 +198,99 @@ type OhMyCodeConfig struct {
 
 	// AssignTimeoutSeconds limits how long we wait for agent-manager output.
 	AssignTimeoutSeconds int `yaml:"assignTimeoutSeconds,omitempty"`
+
+	// Tasks tracks assignments until the agent reports completion.
+	Tasks OhMyCodeTaskTrackingConfig `yaml:"tasks,omitempty"`
+
+	// Workspaces adds named repositories whose agents are addressed as
+	// "<workspace>/<agent>". Workspace is optional when Workspaces is set.
+	Workspaces map[string]OhMyCodeWorkspaceConfig `yaml:"workspaces,omitempty"`
+}
+
+// OhMyCodeWorkspaceConfig is one named oh-my-code repository.
+type OhMyCodeWorkspaceConfig struct {
+	// Workspace is the path to the repository.
+	Workspace string `yaml:"workspace"`
+
+	// AgentManagerScript defaults to the top-level agentManagerScript.
+	AgentManagerScript string `yaml:"agentManagerScript,omitempty"`
+
+	// DefaultAgent and AllowedAgents use unqualified agent names.
+	DefaultAgent  string   `yaml:"defaultAgent,omitempty"`
+	AllowedAgents []string `yaml:"allowedAgents,omitempty"`
+
+	// AssignTimeoutSeconds defaults to the top-level assignTimeoutSeconds.
+	AssignTimeoutSeconds int `yaml:"assignTimeoutSeconds,omitempty"`
+}
+
+// RoutingAgents returns the default agent and the allowlist that chat
+// routing sees. Agents of named workspaces are listed as "<workspace>/<agent>".
+func (c *OhMyCodeConfig) RoutingAgents() (string, []string) {
+	defaultAgent := strings.TrimSpace(c.DefaultAgent)
+	if len(c.Workspaces) == 0 {
+		return defaultAgent, c.AllowedAgents
+	}
+	var allowed []string
+	if strings.TrimSpace(c.Workspace) != "" {
+		allowed = append(allowed, effectiveAgents(c.DefaultAgent, c.AllowedAgents)...)
+	}
+	names := make([]string, 0, len(c.Workspaces))
+	for name := range c.Workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ws := c.Workspaces[name]
+		for _, agent := range effectiveAgents(ws.DefaultAgent, ws.AllowedAgents) {
+			allowed = append(allowed, name+"/"+agent)
+		}
+	}
+	if defaultAgent != "" && !containsString(allowed, defaultAgent) {
+		allowed = append(allowed, defaultAgent)
+	}
+	return defaultAgent, allowed
+}
+
+// effectiveAgents returns the allowlist, or the default agent alone when no
+// allowlist is configured.
+func effectiveAgents(defaultAgent string, allowedAgents []string) []string {
+	var agents []string
+	for _, name := range allowedAgents {
+		if trimmed := strings.TrimSpace(name); trimmed != "" {
+			agents = append(agents, trimmed)
+		}
+	}
+	if len(agents) == 0 {
+		if trimmed := strings.TrimSpace(defaultAgent); trimmed != "" {
+			agents = append(agents, trimmed)
+		}
+	}
+	return agents
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// OhMyCodeTaskTrackingConfig follows assigned tasks and posts their outcome
+// to the chat that sent them.
+type OhMyCodeTaskTrackingConfig struct {
+	// Enabled tracks every assignment made from a chat message.
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// PollIntervalSeconds paces reads of the agent's monitor output. Defaults to 15.
+	PollIntervalSeconds int `yaml:"pollIntervalSeconds,omitempty"`
+
+	// TimeoutSeconds gives up on a task that has not reported completion. Defaults to 3600.
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty"`
+
+	// HistorySize keeps this many finished tasks for /status. Defaults to 20.
+	HistorySize int `yaml:"historySize,omitempty"`
 }
 
 // CodexAppCDPConfig contains routing settings for a Codex App-managed agent.
@@ -226,6 +342,25 @@ type CodexAppCDPConfig struct {
 
 	// Watch periodically checks CDP readiness while the gateway is running.
 	Watch CodexAppCDPWatchConfig `yaml:"watch,omitempty"`
+
+	// Redelivery drains queued inbox envelopes once the watchdog sees CDP
+	// recover.
+	Redelivery InboxRedeliveryConfig `yaml:"redelivery,omitempty"`
+
+	// ResponseRelay posts the agent's answer back to the originating chat.
+	ResponseRelay ResponseRelayConfig `yaml:"responseRelay,omitempty"`
+}
+
+// ClaudeDesktopTargetConfig describes where one agent's messages go in
+// Claude Desktop.
+type ClaudeDesktopTargetConfig struct {
+	// ProjectID starts new conversations inside this Claude project, the id in
+	// https://claude.ai/project/<id>.
+	ProjectID string `yaml:"projectId,omitempty"`
+
+	// ConversationID pins this agent to an existing conversation. It overrides
+	// agents.claudeDesktop.conversationId and conversationMode.
+	ConversationID string `yaml:"conversationId,omitempty"`
 }
 
 // CodexAppCDPTargetProjectConfig describes a stable logical Codex App target.
@@ -244,6 +379,41 @@ type CodexAppCDPTargetProjectConfig struct {
 	StateDB string `yaml:"stateDb,omitempty"`
 }
 
+// ResponseRelayConfig controls relaying a desktop agent's reply to the chat
+// that sent the message.
+type ResponseRelayConfig struct {
+	// Enabled follows the target conversation after delivery and posts the
+	// final assistant message to the originating channel and thread.
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Stream also posts intermediate assistant messages as they appear.
+	Stream bool `yaml:"stream,omitempty"`
+
+	// TimeoutSeconds stops following a turn that has not finished. Defaults to 900.
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty"`
+
+	// Channels limits relaying to these channels. Empty relays for every channel.
+	Channels []string `yaml:"channels,omitempty"`
+
+	// StillWorkingSeconds posts a one-time "still working" notice when no reply
+	// has been relayed after this long. 0 disables the notice.
+	StillWorkingSeconds int `yaml:"stillWorkingSeconds,omitempty"`
+}
+
+// InboxRedeliveryConfig controls automatic delivery of queued inbox envelopes
+// after a desktop runtime becomes reachable again.
+type InboxRedeliveryConfig struct {
+	// Enabled turns redelivery on. Nil defaults to true when fallbackToInbox is set.
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// IntervalSeconds paces consecutive deliveries. Defaults to 5.
+	IntervalSeconds int `yaml:"intervalSeconds,omitempty"`
+
+	// NotifyChat tells the originating chat that its message was delivered
+	// late. Nil defaults to true.
+	NotifyChat *bool `yaml:"notifyChat,omitempty"`
+}
+
 // CodexAppCDPWatchConfig controls the long-running Codex App CDP watchdog.
 type CodexAppCDPWatchConfig struct {
 	// Enabled controls the watchdog. Nil defaults to true when Codex App CDP is enabled.
@@ -269,6 +439,19 @@ type ClaudeDesktopConfig struct {
 	// TargetSelector optionally matches a Claude chat target title or URL.
 	TargetSelector string `yaml:"targetSelector,omitempty"`
 
+	// ConversationID optionally pins delivery to an existing Claude
+	// conversation, the id in https://claude.ai/chat/<id>.
+	ConversationID string `yaml:"conversationId,omitempty"`
+
+	// ConversationMode selects the conversation when no ID is pinned.
+	// Supported values: "active" (the chat the target shows), "perThread" (a
+	// new conversation per chat thread, reused for later messages), and "new"
+	// (a new conversation per message). Empty defaults to "active".
+	ConversationMode string `yaml:"conversationMode,omitempty"`
+
+	// Targets maps agent names to a Claude project or pinned conversation.
+	Targets map[string]ClaudeDesktopTargetConfig `yaml:"targets,omitempty"`
+
 	// InboxPath is a durable queue used when CDP delivery is unavailable.
 	InboxPath string `yaml:"inboxPath,omitempty"`
 
@@ -283,6 +466,57 @@ type ClaudeDesktopConfig struct {
 
 	// DeliveryTimeoutSeconds limits CDP delivery. Defaults to 20 seconds.
 	DeliveryTimeoutSeconds int `yaml:"deliveryTimeoutSeconds,omitempty"`
+
+	// ResponseRelay captures Claude's reply from the chat page and posts it
+	// back to the originating chat.
+	ResponseRelay ResponseRelayConfig `yaml:"responseRelay,omitempty"`
+
+	// Watch periodically checks that CDP is reachable and a signed-in chat
+	// target exists.
+	Watch ClaudeDesktopWatchConfig `yaml:"watch,omitempty"`
+}
+
+// ClaudeDesktopWatchConfig controls the Claude Desktop readiness watchdog.
+type ClaudeDesktopWatchConfig struct {
+	// Enabled controls the watchdog. Nil defaults to true when Claude Desktop
+	// is enabled with a cdpEndpoint.
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// IntervalSeconds sets the watchdog interval. Defaults to 60.
+	IntervalSeconds int `yaml:"intervalSeconds,omitempty"`
+
+	// HistorySize is how many readiness changes /status keeps. Defaults to 20.
+	HistorySize int `yaml:"historySize,omitempty"`
+
+	// Alert posts readiness changes to an admin chat.
+	Alert ReadinessAlertConfig `yaml:"alert,omitempty"`
+}
+
+// ReadinessAlertConfig names the chat that receives runtime readiness alerts.
+type ReadinessAlertConfig struct {
+	// Channel is the registered channel name, for example "telegram".
+	Channel string `yaml:"channel,omitempty"`
+
+	// ChatID is the chat or user id on that channel.
+	ChatID string `yaml:"chatId,omitempty"`
+}
+
+// AgentAddressingConfig controls how chat messages address agents without
+// /agent, for example "@qa-1 please rerun the tests".
+type AgentAddressingConfig struct {
+	// Mentions lists mention syntaxes, each containing "{agent}" once.
+	// Defaults to ["@{agent}"].
+	Mentions []string `yaml:"mentions,omitempty"`
+
+	// Aliases maps nicknames to agent names.
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+}
+
+// AgentRegistryConfig controls how often the agent registry asks each
+// enabled runtime for its agents.
+type AgentRegistryConfig struct {
+	// RefreshIntervalSeconds defaults to 60.
+	RefreshIntervalSeconds int `yaml:"refreshIntervalSeconds,omitempty"`
 }
 
 // HeartbeatConfig schedules runtime-neutral agent wakeups.
@@ -291,8 +525,65 @@ type HeartbeatConfig struct {
 	StatePath     string               `yaml:"statePath,omitempty"`
 	MaxConcurrent int                  `yaml:"maxConcurrent,omitempty"`
 	Jobs          []HeartbeatJobConfig `yaml:"jobs,omitempty"`
+
+	// Calendars are named blackout calendars that jobs list in blackouts.
+	Calendars map[string]HeartbeatCalendarConfig `yaml:"calendars,omitempty"`
+
+	// Notify receives failure and recovery alerts for jobs without their
+	// own notify target.
+	Notify *HeartbeatNotifyConfig `yaml:"notify,omitempty"`
+}
+
+// HeartbeatNotifyConfig is a chat that receives heartbeat alerts. A job alerts
+// once after FailureThreshold consecutive failed runs (default 3), once when
+// its runtime has been unreachable for UnreachableAfter, and once when it
+// recovers after RecoverAfter successful runs (default 1).
+type HeartbeatNotifyConfig struct {
+	Channel          string `yaml:"channel" json:"channel"`
+	To               string `yaml:"to" json:"to"`
+	FailureThreshold int    `yaml:"failureThreshold,omitempty" json:"failure_threshold,omitempty"`
+	UnreachableAfter string `yaml:"unreachableAfter,omitempty" json:"unreachable_after,omitempty"`
+	RecoverAfter     int    `yaml:"recoverAfter,omitempty" json:"recover_after,omitempty"`
+}
+
+// HeartbeatCalendarConfig lists blackout days as YYYY-MM-DD dates, events
+// from an iCalendar file, or both. Dates and all-day events are read in the
+// timezone of the job that uses the calendar.
+type HeartbeatCalendarConfig struct {
+	Dates   []string `yaml:"dates,omitempty"`
+	ICSPath string   `yaml:"icsPath,omitempty"`
+}
+
+// HeartbeatWindowConfig is a daily HH:MM window in the job timezone, which may
+// cross midnight.
+type HeartbeatWindowConfig struct {
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
 }
 
+// HeartbeatQuietHoursConfig is a daily HH:MM window, which may cross
+// midnight. Timezone defaults to the job timezone.
+type HeartbeatQuietHoursConfig struct {
+	Start    string `yaml:"start" json:"start"`
+	End      string `yaml:"end" json:"end"`
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+}
+
+// Blackout and activity policies.
+const (
+	// HeartbeatBlackoutSkip drops firings that are held back.
+	HeartbeatBlackoutSkip = "skip"
+	// HeartbeatBlackoutDefer runs once when the hold ends.
+	HeartbeatBlackoutDefer = "defer"
+
+	// HeartbeatMisfireSkip drops missed firings.
+	HeartbeatMisfireSkip = "skip"
+	// HeartbeatMisfireRunOnce runs one catch-up for any number of misses.
+	HeartbeatMisfireRunOnce = "run-once"
+	// HeartbeatMisfireRunAll runs each missed firing, up to misfireLimit.
+	HeartbeatMisfireRunAll = "run-all"
+)
+
 // HeartbeatJobConfig targets one Agent Runtime and agent on a cron schedule.
 type HeartbeatJobConfig struct {
 	ID                 string            `yaml:"id"`
@@ -303,6 +594,83 @@ type HeartbeatJobConfig struct {
 	Timezone           string            `yaml:"timezone"`
 	AgentCronProfiles  map[string]string `yaml:"agentCronProfiles,omitempty"`
 	ResetCronOnInbound bool              `yaml:"resetCronOnInbound,omitempty"`
+
+	// Every, At and Window are alternatives to Cron; exactly one of the four
+	// is set. Every fires at a fixed interval aligned to the Unix epoch on
+	// the Timezone wall clock, each firing delayed by up to Jitter. At fires once, at an RFC 3339 time or
+	// "YYYY-MM-DD HH:MM" in Timezone. Window fires once a day at a random
+	// time inside the window.
+	Every  string                 `yaml:"every,omitempty"`
+	Jitter string                 `yaml:"jitter,omitempty"`
+	At     string                 `yaml:"at,omitempty"`
+	Window *HeartbeatWindowConfig `yaml:"window,omitempty"`
+
+	// QuietHours and Blackouts (calendar names) hold back firings;
+	// BlackoutPolicy is skip (default) or defer.
+	QuietHours     []HeartbeatQuietHoursConfig `yaml:"quietHours,omitempty"`
+	Blackouts      []string                    `yaml:"blackouts,omitempty"`
+	BlackoutPolicy string                      `yaml:"blackoutPolicy,omitempty"`
+
+	// MisfirePolicy decides what happens to firings missed while the gateway
+	// was down or asleep: skip (default), run-once, or run-all, which runs
+	// at most MisfireLimit of them. Missed firings older than MisfireExpiry
+	// are never run.
+	MisfirePolicy string `yaml:"misfirePolicy,omitempty"`
+	MisfireLimit  int    `yaml:"misfireLimit,omitempty"`
+	MisfireExpiry string `yaml:"misfireExpiry,omitempty"`
+
+	// ActivityThreshold holds back a firing while the target agent is busy
+	// or was active within this duration, e.g. "15m". ActivityPolicy is skip
+	// (default) or defer.
+	ActivityThreshold string `yaml:"activityThreshold,omitempty"`
+	ActivityPolicy    string `yaml:"activityPolicy,omitempty"`
+
+	// BackoffAfter steps the job to the next AgentCronProfiles name in
+	// BackoffLadder after that many consecutive HEARTBEAT_OK replies. A reply
+	// with real work, or a routed inbound message, restores the default cron.
+	BackoffAfter  int      `yaml:"backoffAfter,omitempty"`
+	BackoffLadder []string `yaml:"backoffLadder,omitempty"`
+
+	// Notify overrides agents.heartbeat.notify for this job.
+	Notify *HeartbeatNotifyConfig `yaml:"notify,omitempty"`
+}
+
+// HeartbeatTextData is the data a heartbeat job's text is rendered with on
+// each firing. Times are in the job timezone and zero when unknown.
+type HeartbeatTextData struct {
+	JobID   string
+	Runtime string
+	Agent   string
+	// Now is when the firing is dispatched; ScheduledAt is the firing it
+	// stands for, earlier for catch-up runs.
+	Now         time.Time
+	ScheduledAt time.Time
+	// Profile is the active cron profile, empty on the default schedule.
+	Profile string
+	// LastRunAt and LastStatus describe the previous dispatch.
+	LastRunAt  time.Time
+	LastStatus string
+	// LastInboundAt is the agent's last inbound message since the gateway
+	// started; SinceInbound is the time since then, rounded to seconds.
+	LastInboundAt time.Time
+	SinceInbound  time.Duration
+	// PendingInbox counts messages for the agent still pending in its
+	// runtime inbox.
+	PendingInbox int
+}
+
+// ParseHeartbeatText parses a heartbeat job's text as a template and renders
+// it once against empty data, so a field HeartbeatTextData lacks is rejected
+// when the config is loaded rather than when the job fires.
+func ParseHeartbeatText(name, text string) (*template.Template, error) {
+	parsed, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := parsed.Execute(io.Discard, HeartbeatTextData{}); err != nil {
+		return nil, err
+	}
+	return parsed, nil
 }
 
 // AgentsConfig contains gateway-side agent routing settings.
@@ -314,6 +682,20 @@ type AgentsConfig struct {
 	CodexAppCDP   *CodexAppCDPConfig   `yaml:"codexAppCDP,omitempty"`
 	ClaudeDesktop *ClaudeDesktopConfig `yaml:"claudeDesktop,omitempty"`
 	Heartbeat     *HeartbeatConfig     `yaml:"heartbeat,omitempty"`
+
+	// Registry controls agent discovery across the enabled runtimes.
+	Registry AgentRegistryConfig `yaml:"registry,omitempty"`
+
+	// Addressing controls @mentions and agent aliases in chat messages.
+	Addressing *AgentAddressingConfig `yaml:"addressing,omitempty"`
+
+	// Plugins launches out-of-process Agent Runtimes. A plugin runtime is
+	// selected by setting agents.router to its name.
+	Plugins []PluginConfig `yaml:"plugins,omitempty"`
+
+	// Extensions holds sections for out-of-tree Agent Runtimes, keyed by
+	// runtime name. Runtimes decode them with DecodeExtension.
+	Extensions map[string]yaml.Node `yaml:",inline"`
 }
 
 // ResolveConfigPath returns the config file path using this priority:
@@ -396,13 +778,21 @@ func validateConfig(cfg *Config) error {
 	if err := validateRouterConfig(cfg); err != nil {
 		return err
 	}
-	if err := validateOhMyCodeConfig(cfg); err != nil {
-		return err
+	for _, spec := range Runtimes() {
+		if spec.Validate == nil {
+			continue
+		}
+		if err := spec.Validate(cfg); err != nil {
+			return err
+		}
 	}
-	if err := validateCodexAppCDPConfig(cfg); err != nil {
+	if err := validatePluginsConfig(cfg); err != nil {
 		return err
 	}
-	if err := validateClaudeDesktopConfig(cfg); err != nil {
+	if cfg != nil && cfg.Agents != nil && cfg.Agents.Registry.RefreshIntervalSeconds < 0 {
+		return fmt.Errorf("agents.registry.refreshIntervalSeconds: must be >= 0")
+	}
+	if err := validateAgentAddressingConfig(cfg); err != nil {
 		return err
 	}
 	if err := validateHeartbeatConfig(cfg); err != nil {
@@ -411,12 +801,82 @@ func validateConfig(cfg *Config) error {
 	return nil
 }
 
+func validateAgentAddressingConfig(cfg *Config) error {
+	if cfg == nil || cfg.Agents == nil || cfg.Agents.Addressing == nil {
+		return nil
+	}
+	addressing := cfg.Agents.Addressing
+	for idx, syntax := range addressing.Mentions {
+		trimmed := strings.TrimSpace(syntax)
+		if strings.Count(trimmed, "{agent}") != 1 {
+			return fmt.Errorf("agents.addressing.mentions[%d]: must contain {agent} once", idx)
+		}
+		if trimmed == "{agent}" {
+			return fmt.Errorf("agents.addressing.mentions[%d]: needs text around {agent}", idx)
+		}
+	}
+	// Aliases may only point at agents some runtime routes to; with no
+	// runtime allowlists configured every valid name is accepted.
+	known := routingAgentNames(cfg.Agents)
+	seen := make(map[string]bool, len(addressing.Aliases))
+	for alias, agent := range addressing.Aliases {
+		name, target := strings.TrimSpace(alias), strings.TrimSpace(agent)
+		if err := validateAgentName(name); err != nil {
+			return fmt.Errorf("agents.addressing.aliases: %w", err)
+		}
+		if err := validateAgentName(target); err != nil {
+			return fmt.Errorf("agents.addressing.aliases.%s: %w", alias, err)
+		}
+		if seen[name] {
+			return fmt.Errorf("agents.addressing.aliases.%s: duplicate alias", alias)
+		}
+		seen[name] = true
+		if name != target && known[name] {
+			return fmt.Errorf("agents.addressing.aliases.%s: collides with the agent of the same name", alias)
+		}
+		if len(known) > 0 && !known[target] {
+			return fmt.Errorf("agents.addressing.aliases.%s: unknown agent %q", alias, target)
+		}
+	}
+	return nil
+}
+
+// routingAgentNames returns the default and allowed agents of every runtime
+// and runtime plugin section.
+func routingAgentNames(cfg *AgentsConfig) map[string]bool {
+	names := make(map[string]bool)
+	add := func(routing RuntimeRouting) {
+		for _, name := range append([]string{routing.DefaultAgent}, routing.AllowedAgents...) {
+			if trimmed := strings.TrimSpace(name); trimmed != "" {
+				names[trimmed] = true
+			}
+		}
+	}
+	for _, spec := range Runtimes() {
+		if routing, ok := spec.Routing(cfg); ok {
+			add(routing)
+		}
+	}
+	for _, plugin := range cfg.Plugins {
+		if routing, ok := cfg.RuntimeRouting(plugin.Name); ok {
+			add(routing)
+		}
+	}
+	return names
+}
+
 func validateRouterConfig(cfg *Config) error {
 	if cfg == nil || cfg.Agents == nil {
 		return nil
 	}
 	router := strings.TrimSpace(cfg.Agents.Router)
-	if router == "" || router == "ohMyCode" || router == "codexAppCDP" || router == "claudeDesktop" {
+	if router == "" {
+		return nil
+	}
+	if _, ok := LookupRuntime(router); ok {
+		return nil
+	}
+	if _, ok := cfg.Agents.RuntimePlugin(router); ok {
 		return nil
 	}
 	return fmt.Errorf("agents.router: unsupported router %q", router)
@@ -439,6 +899,22 @@ func validateClaudeDesktopConfig(cfg *Config) error {
 	if claude.DeliveryTimeoutSeconds < 0 {
 		return fmt.Errorf("agents.claudeDesktop.deliveryTimeoutSeconds: must be >= 0")
 	}
+	if err := validateClaudeDesktopTargets(claude); err != nil {
+		return err
+	}
+	if err := validateResponseRelayConfig("agents.claudeDesktop.responseRelay", claude.ResponseRelay); err != nil {
+		return err
+	}
+	if claude.Watch.IntervalSeconds < 0 {
+		return fmt.Errorf("agents.claudeDesktop.watch.intervalSeconds: must be >= 0")
+	}
+	if claude.Watch.HistorySize < 0 {
+		return fmt.Errorf("agents.claudeDesktop.watch.historySize: must be >= 0")
+	}
+	alert := claude.Watch.Alert
+	if (strings.TrimSpace(alert.Channel) == "") != (strings.TrimSpace(alert.ChatID) == "") {
+		return fmt.Errorf("agents.claudeDesktop.watch.alert: channel and chatId must be set together")
+	}
 	return nil
 }
 
@@ -453,79 +929,370 @@ func validateHeartbeatConfig(cfg *Config) error {
 	if !heartbeat.Enabled {
 		return nil
 	}
-	if len(heartbeat.Jobs) == 0 {
-		return fmt.Errorf("agents.heartbeat.jobs: at least one job is required when heartbeat is enabled")
+	if err := validateHeartbeatCalendars(heartbeat); err != nil {
+		return err
+	}
+	if err := validateHeartbeatNotify("agents.heartbeat.notify", &heartbeat.Notify); err != nil {
+		return err
 	}
 
 	seenIDs := make(map[string]struct{}, len(heartbeat.Jobs))
 	for idx := range heartbeat.Jobs {
 		job := &heartbeat.Jobs[idx]
 		prefix := fmt.Sprintf("agents.heartbeat.jobs[%d]", idx)
-		job.ID = strings.TrimSpace(job.ID)
-		job.Runtime = strings.TrimSpace(job.Runtime)
-		job.Agent = strings.TrimSpace(job.Agent)
-		job.Text = strings.TrimSpace(job.Text)
-		job.Cron = strings.TrimSpace(job.Cron)
-		job.Timezone = strings.TrimSpace(job.Timezone)
-
-		if job.ID == "" {
-			return fmt.Errorf("%s.id: required", prefix)
-		}
-		if err := validateAgentName(job.ID); err != nil {
-			return fmt.Errorf("%s.id: %w", prefix, err)
+		if err := validateHeartbeatJob(cfg.Agents, prefix, job); err != nil {
+			return err
 		}
 		if _, exists := seenIDs[job.ID]; exists {
 			return fmt.Errorf("%s.id: duplicate heartbeat job %q", prefix, job.ID)
 		}
 		seenIDs[job.ID] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateHeartbeatJob normalizes and checks one heartbeat job with the rules
+// applied to agents.heartbeat.jobs. It does not check for duplicate IDs.
+func ValidateHeartbeatJob(agents *AgentsConfig, job *HeartbeatJobConfig) error {
+	if agents == nil {
+		return fmt.Errorf("agents config is required")
+	}
+	if job == nil {
+		return fmt.Errorf("heartbeat job is required")
+	}
+	return validateHeartbeatJob(agents, "job", job)
+}
+
+func validateHeartbeatJob(agents *AgentsConfig, prefix string, job *HeartbeatJobConfig) error {
+	job.ID = strings.TrimSpace(job.ID)
+	job.Runtime = strings.TrimSpace(job.Runtime)
+	job.Agent = strings.TrimSpace(job.Agent)
+	job.Text = strings.TrimSpace(job.Text)
+	job.Cron = strings.TrimSpace(job.Cron)
+	job.Timezone = strings.TrimSpace(job.Timezone)
+
+	if job.ID == "" {
+		return fmt.Errorf("%s.id: required", prefix)
+	}
+	if err := validateAgentName(job.ID); err != nil {
+		return fmt.Errorf("%s.id: %w", prefix, err)
+	}
+	if job.Agent == "" {
+		return fmt.Errorf("%s.agent: required", prefix)
+	}
+	if err := validateAgentName(job.Agent); err != nil {
+		return fmt.Errorf("%s.agent: %w", prefix, err)
+	}
+	if job.Text == "" {
+		return fmt.Errorf("%s.text: required", prefix)
+	}
+	if _, err := ParseHeartbeatText(job.ID, job.Text); err != nil {
+		return fmt.Errorf("%s.text: %w", prefix, err)
+	}
+	if job.Timezone == "" {
+		return fmt.Errorf("%s.timezone: required", prefix)
+	}
+	if _, err := time.LoadLocation(job.Timezone); err != nil {
+		return fmt.Errorf("%s.timezone: %w", prefix, err)
+	}
+	if err := validateHeartbeatSchedule(prefix, job); err != nil {
+		return err
+	}
+	if err := validateHeartbeatRuntimeTarget(agents, job.Runtime, job.Agent); err != nil {
+		return fmt.Errorf("%s.runtime: %w", prefix, err)
+	}
 
-		if job.Agent == "" {
-			return fmt.Errorf("%s.agent: required", prefix)
+	normalizedProfiles := make(map[string]string, len(job.AgentCronProfiles))
+	for rawProfile, rawExpression := range job.AgentCronProfiles {
+		profile := strings.TrimSpace(rawProfile)
+		expression := strings.TrimSpace(rawExpression)
+		if profile == "" {
+			return fmt.Errorf("%s.agentCronProfiles: profile name is required", prefix)
+		}
+		if err := validateAgentName(profile); err != nil {
+			return fmt.Errorf("%s.agentCronProfiles[%q]: %w", prefix, profile, err)
 		}
-		if err := validateAgentName(job.Agent); err != nil {
-			return fmt.Errorf("%s.agent: %w", prefix, err)
+		if expression == "" {
+			return fmt.Errorf("%s.agentCronProfiles[%q]: cron expression is required", prefix, profile)
 		}
-		if job.Text == "" {
-			return fmt.Errorf("%s.text: required", prefix)
+		if _, err := parseHeartbeatCron(expression, job.Timezone); err != nil {
+			return fmt.Errorf("%s.agentCronProfiles[%q]: %w", prefix, profile, err)
+		}
+		if _, exists := normalizedProfiles[profile]; exists {
+			return fmt.Errorf("%s.agentCronProfiles: duplicate profile %q", prefix, profile)
+		}
+		normalizedProfiles[profile] = expression
+	}
+	job.AgentCronProfiles = normalizedProfiles
+	if err := validateHeartbeatBlackouts(agents, prefix, job); err != nil {
+		return err
+	}
+	if err := validateHeartbeatMisfire(prefix, job); err != nil {
+		return err
+	}
+	if err := validateHeartbeatActivity(prefix, job); err != nil {
+		return err
+	}
+	if err := validateHeartbeatBackoff(prefix, job); err != nil {
+		return err
+	}
+	return validateHeartbeatNotify(prefix+".notify", &job.Notify)
+}
+
+// validateHeartbeatNotify normalizes a notify target; one without channel and
+// to is dropped.
+func validateHeartbeatNotify(prefix string, target **HeartbeatNotifyConfig) error {
+	notify := *target
+	if notify == nil {
+		return nil
+	}
+	notify.Channel = strings.ToLower(strings.TrimSpace(notify.Channel))
+	notify.To = strings.TrimSpace(notify.To)
+	if notify.Channel == "" && notify.To == "" {
+		*target = nil
+		return nil
+	}
+	if notify.Channel == "" {
+		return fmt.Errorf("%s.channel: required", prefix)
+	}
+	if notify.To == "" {
+		return fmt.Errorf("%s.to: required", prefix)
+	}
+	if notify.FailureThreshold < 0 {
+		return fmt.Errorf("%s.failureThreshold: must not be negative", prefix)
+	}
+	if notify.RecoverAfter < 0 {
+		return fmt.Errorf("%s.recoverAfter: must not be negative", prefix)
+	}
+	notify.UnreachableAfter = strings.TrimSpace(notify.UnreachableAfter)
+	if notify.UnreachableAfter != "" {
+		after, err := time.ParseDuration(notify.UnreachableAfter)
+		if err != nil {
+			return fmt.Errorf("%s.unreachableAfter: %w", prefix, err)
+		}
+		if after <= 0 {
+			return fmt.Errorf("%s.unreachableAfter: must be positive", prefix)
+		}
+	}
+	return nil
+}
+
+func validateHeartbeatBackoff(prefix string, job *HeartbeatJobConfig) error {
+	if job.BackoffAfter < 0 {
+		return fmt.Errorf("%s.backoffAfter: must not be negative", prefix)
+	}
+	seen := make(map[string]bool, len(job.BackoffLadder))
+	for idx, profile := range job.BackoffLadder {
+		profile = strings.TrimSpace(profile)
+		if _, ok := job.AgentCronProfiles[profile]; !ok {
+			return fmt.Errorf("%s.backoffLadder[%d]: unknown profile %q", prefix, idx, profile)
 		}
-		if job.Cron == "" {
-			return fmt.Errorf("%s.cron: required", prefix)
+		if seen[profile] {
+			return fmt.Errorf("%s.backoffLadder[%d]: duplicate profile %q", prefix, idx, profile)
 		}
-		if job.Timezone == "" {
-			return fmt.Errorf("%s.timezone: required", prefix)
+		seen[profile] = true
+		job.BackoffLadder[idx] = profile
+	}
+	if job.BackoffAfter > 0 && len(job.BackoffLadder) == 0 {
+		return fmt.Errorf("%s.backoffLadder: required when backoffAfter is set", prefix)
+	}
+	return nil
+}
+
+func validateHeartbeatSchedule(prefix string, job *HeartbeatJobConfig) error {
+	job.Every = strings.TrimSpace(job.Every)
+	job.Jitter = strings.TrimSpace(job.Jitter)
+	job.At = strings.TrimSpace(job.At)
+	if job.Window != nil {
+		job.Window.Start = strings.TrimSpace(job.Window.Start)
+		job.Window.End = strings.TrimSpace(job.Window.End)
+		if job.Window.Start == "" && job.Window.End == "" {
+			job.Window = nil
 		}
-		if _, err := time.LoadLocation(job.Timezone); err != nil {
-			return fmt.Errorf("%s.timezone: %w", prefix, err)
+	}
+	kinds := 0
+	for _, set := range []bool{job.Cron != "", job.Every != "", job.At != "", job.Window != nil} {
+		if set {
+			kinds++
 		}
+	}
+	if kinds == 0 {
+		return fmt.Errorf("%s.cron: required unless every, at or window is set", prefix)
+	}
+	if kinds > 1 {
+		return fmt.Errorf("%s: set only one of cron, every, at and window", prefix)
+	}
+	if job.Jitter != "" && job.Every == "" {
+		return fmt.Errorf("%s.jitter: requires every", prefix)
+	}
+
+	switch {
+	case job.Cron != "":
 		if _, err := parseHeartbeatCron(job.Cron, job.Timezone); err != nil {
 			return fmt.Errorf("%s.cron: %w", prefix, err)
 		}
-		if err := validateHeartbeatRuntimeTarget(cfg.Agents, job.Runtime, job.Agent); err != nil {
-			return fmt.Errorf("%s.runtime: %w", prefix, err)
+	case job.Every != "":
+		every, err := time.ParseDuration(job.Every)
+		if err != nil {
+			return fmt.Errorf("%s.every: %w", prefix, err)
 		}
-
-		normalizedProfiles := make(map[string]string, len(job.AgentCronProfiles))
-		for rawProfile, rawExpression := range job.AgentCronProfiles {
-			profile := strings.TrimSpace(rawProfile)
-			expression := strings.TrimSpace(rawExpression)
-			if profile == "" {
-				return fmt.Errorf("%s.agentCronProfiles: profile name is required", prefix)
-			}
-			if err := validateAgentName(profile); err != nil {
-				return fmt.Errorf("%s.agentCronProfiles[%q]: %w", prefix, profile, err)
+		if every < time.Minute {
+			return fmt.Errorf("%s.every: must be at least 1m", prefix)
+		}
+		if job.Jitter != "" {
+			jitter, err := time.ParseDuration(job.Jitter)
+			if err != nil {
+				return fmt.Errorf("%s.jitter: %w", prefix, err)
 			}
-			if expression == "" {
-				return fmt.Errorf("%s.agentCronProfiles[%q]: cron expression is required", prefix, profile)
+			if jitter < 0 || jitter >= every {
+				return fmt.Errorf("%s.jitter: must be at least 0 and less than every", prefix)
 			}
-			if _, err := parseHeartbeatCron(expression, job.Timezone); err != nil {
-				return fmt.Errorf("%s.agentCronProfiles[%q]: %w", prefix, profile, err)
+		}
+	case job.At != "":
+		if _, err := parseHeartbeatAt(job.At, job.Timezone); err != nil {
+			return fmt.Errorf("%s.at: %w", prefix, err)
+		}
+	default:
+		start, err := time.Parse("15:04", job.Window.Start)
+		if err != nil {
+			return fmt.Errorf("%s.window.start: must be HH:MM", prefix)
+		}
+		end, err := time.Parse("15:04", job.Window.End)
+		if err != nil {
+			return fmt.Errorf("%s.window.end: must be HH:MM", prefix)
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("%s.window: start and end must differ", prefix)
+		}
+	}
+	return nil
+}
+
+func parseHeartbeatAt(value, timezone string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	at, err := time.ParseInLocation("2006-01-02 15:04", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(`must be RFC 3339 or "YYYY-MM-DD HH:MM"`)
+	}
+	return at, nil
+}
+
+func validateHeartbeatActivity(prefix string, job *HeartbeatJobConfig) error {
+	job.ActivityThreshold = strings.TrimSpace(job.ActivityThreshold)
+	if job.ActivityThreshold != "" {
+		threshold, err := time.ParseDuration(job.ActivityThreshold)
+		if err != nil {
+			return fmt.Errorf("%s.activityThreshold: %w", prefix, err)
+		}
+		if threshold <= 0 {
+			return fmt.Errorf("%s.activityThreshold: must be positive", prefix)
+		}
+	}
+	job.ActivityPolicy = strings.ToLower(strings.TrimSpace(job.ActivityPolicy))
+	switch job.ActivityPolicy {
+	case "", HeartbeatBlackoutSkip, HeartbeatBlackoutDefer:
+	default:
+		return fmt.Errorf("%s.activityPolicy: must be %s or %s", prefix, HeartbeatBlackoutSkip, HeartbeatBlackoutDefer)
+	}
+	return nil
+}
+
+func validateHeartbeatMisfire(prefix string, job *HeartbeatJobConfig) error {
+	job.MisfirePolicy = strings.ToLower(strings.TrimSpace(job.MisfirePolicy))
+	switch job.MisfirePolicy {
+	case "", HeartbeatMisfireSkip, HeartbeatMisfireRunOnce, HeartbeatMisfireRunAll:
+	default:
+		return fmt.Errorf("%s.misfirePolicy: must be %s, %s or %s", prefix, HeartbeatMisfireSkip, HeartbeatMisfireRunOnce, HeartbeatMisfireRunAll)
+	}
+	if job.MisfireLimit < 0 {
+		return fmt.Errorf("%s.misfireLimit: must be >= 0", prefix)
+	}
+	job.MisfireExpiry = strings.TrimSpace(job.MisfireExpiry)
+	if job.MisfireExpiry != "" {
+		expiry, err := time.ParseDuration(job.MisfireExpiry)
+		if err != nil {
+			return fmt.Errorf("%s.misfireExpiry: %w", prefix, err)
+		}
+		if expiry <= 0 {
+			return fmt.Errorf("%s.misfireExpiry: must be positive", prefix)
+		}
+	}
+	return nil
+}
+
+func validateHeartbeatCalendars(heartbeat *HeartbeatConfig) error {
+	normalized := make(map[string]HeartbeatCalendarConfig, len(heartbeat.Calendars))
+	for rawName, calendar := range heartbeat.Calendars {
+		name := strings.TrimSpace(rawName)
+		prefix := fmt.Sprintf("agents.heartbeat.calendars[%q]", name)
+		if err := validateAgentName(name); err != nil {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+		if _, exists := normalized[name]; exists {
+			return fmt.Errorf("agents.heartbeat.calendars: duplicate calendar %q", name)
+		}
+		calendar.ICSPath = strings.TrimSpace(calendar.ICSPath)
+		if len(calendar.Dates) == 0 && calendar.ICSPath == "" {
+			return fmt.Errorf("%s: dates or icsPath is required", prefix)
+		}
+		for idx, date := range calendar.Dates {
+			date = strings.TrimSpace(date)
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				return fmt.Errorf("%s.dates[%d]: must be YYYY-MM-DD", prefix, idx)
 			}
-			if _, exists := normalizedProfiles[profile]; exists {
-				return fmt.Errorf("%s.agentCronProfiles: duplicate profile %q", prefix, profile)
+			calendar.Dates[idx] = date
+		}
+		normalized[name] = calendar
+	}
+	heartbeat.Calendars = normalized
+	return nil
+}
+
+func validateHeartbeatBlackouts(agents *AgentsConfig, prefix string, job *HeartbeatJobConfig) error {
+	for idx := range job.QuietHours {
+		window := &job.QuietHours[idx]
+		window.Start = strings.TrimSpace(window.Start)
+		window.End = strings.TrimSpace(window.End)
+		window.Timezone = strings.TrimSpace(window.Timezone)
+		start, err := time.Parse("15:04", window.Start)
+		if err != nil {
+			return fmt.Errorf("%s.quietHours[%d].start: must be HH:MM", prefix, idx)
+		}
+		end, err := time.Parse("15:04", window.End)
+		if err != nil {
+			return fmt.Errorf("%s.quietHours[%d].end: must be HH:MM", prefix, idx)
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("%s.quietHours[%d]: start and end must differ", prefix, idx)
+		}
+		if window.Timezone != "" {
+			if _, err := time.LoadLocation(window.Timezone); err != nil {
+				return fmt.Errorf("%s.quietHours[%d].timezone: %w", prefix, idx, err)
 			}
-			normalizedProfiles[profile] = expression
 		}
-		job.AgentCronProfiles = normalizedProfiles
+	}
+	var calendars map[string]HeartbeatCalendarConfig
+	if agents.Heartbeat != nil {
+		calendars = agents.Heartbeat.Calendars
+	}
+	for idx, name := range job.Blackouts {
+		name = strings.TrimSpace(name)
+		if _, ok := calendars[name]; !ok {
+			return fmt.Errorf("%s.blackouts[%d]: unknown calendar %q", prefix, idx, name)
+		}
+		job.Blackouts[idx] = name
+	}
+	job.BlackoutPolicy = strings.ToLower(strings.TrimSpace(job.BlackoutPolicy))
+	switch job.BlackoutPolicy {
+	case "", HeartbeatBlackoutSkip, HeartbeatBlackoutDefer:
+	default:
+		return fmt.Errorf("%s.blackoutPolicy: must be %s or %s", prefix, HeartbeatBlackoutSkip, HeartbeatBlackoutDefer)
 	}
 	return nil
 }
@@ -535,25 +1302,15 @@ func parseHeartbeatCron(expression, timezone string) (cron.Schedule, error) {
 }
 
 func validateHeartbeatRuntimeTarget(agents *AgentsConfig, runtimeName, agentName string) error {
-	switch runtimeName {
-	case "ohMyCode":
-		if agents.OhMyCode == nil || !agents.OhMyCode.Enabled {
-			return fmt.Errorf("ohMyCode runtime is not enabled")
-		}
-		return validateHeartbeatAgentAllowed("agents.ohMyCode", agentName, agents.OhMyCode.DefaultAgent, agents.OhMyCode.AllowedAgents)
-	case "codexAppCDP":
-		if agents.CodexAppCDP == nil || !agents.CodexAppCDP.Enabled {
-			return fmt.Errorf("codexAppCDP runtime is not enabled")
-		}
-		return validateHeartbeatAgentAllowed("agents.codexAppCDP", agentName, agents.CodexAppCDP.DefaultAgent, agents.CodexAppCDP.AllowedAgents)
-	case "claudeDesktop":
-		if agents.ClaudeDesktop == nil || !agents.ClaudeDesktop.Enabled {
-			return fmt.Errorf("claudeDesktop runtime is not enabled")
-		}
-		return validateHeartbeatAgentAllowed("agents.claudeDesktop", agentName, agents.ClaudeDesktop.DefaultAgent, agents.ClaudeDesktop.AllowedAgents)
-	default:
+	configPath := agents.RuntimeConfigPath(runtimeName)
+	if configPath == "" {
 		return fmt.Errorf("unsupported runtime %q", runtimeName)
 	}
+	routing, ok := agents.RuntimeRouting(runtimeName)
+	if !ok || !routing.Enabled {
+		return fmt.Errorf("%s runtime is not enabled", runtimeName)
+	}
+	return validateHeartbeatAgentAllowed(configPath, agentName, routing.DefaultAgent, routing.AllowedAgents)
 }
 
 func validateHeartbeatAgentAllowed(prefix, agentName, defaultAgent string, allowedAgents []string) error {
@@ -576,29 +1333,98 @@ func validateOhMyCodeConfig(cfg *Config) error {
 		return nil
 	}
 	ohMyCode := cfg.Agents.OhMyCode
-	if err := validateRoutingAgents("agents.ohMyCode", ohMyCode.DefaultAgent, ohMyCode.AllowedAgents); err != nil {
+	if err := validateOhMyCodeWorkspaces(ohMyCode); err != nil {
+		return err
+	}
+	defaultAgent, allowedAgents := ohMyCode.RoutingAgents()
+	if err := validateRoutingAgents("agents.ohMyCode", defaultAgent, allowedAgents); err != nil {
 		return err
 	}
 
+	if ohMyCode.Tasks.PollIntervalSeconds < 0 {
+		return fmt.Errorf("agents.ohMyCode.tasks.pollIntervalSeconds: must be >= 0")
+	}
+	if ohMyCode.Tasks.TimeoutSeconds < 0 {
+		return fmt.Errorf("agents.ohMyCode.tasks.timeoutSeconds: must be >= 0")
+	}
+	if ohMyCode.Tasks.HistorySize < 0 {
+		return fmt.Errorf("agents.ohMyCode.tasks.historySize: must be >= 0")
+	}
+
 	if !ohMyCode.Enabled {
 		return nil
 	}
 
 	workspace := strings.TrimSpace(ohMyCode.Workspace)
 	if workspace == "" {
-		return fmt.Errorf("agents.ohMyCode.workspace: required when agents.ohMyCode.enabled is true")
+		if len(ohMyCode.Workspaces) == 0 {
+			return fmt.Errorf("agents.ohMyCode.workspace: required when agents.ohMyCode.enabled is true")
+		}
+		if !strings.Contains(ohMyCode.DefaultAgent, "/") {
+			return fmt.Errorf("agents.ohMyCode.defaultAgent: must be <workspace>/<agent> when agents.ohMyCode.workspace is empty")
+		}
+		return nil
 	}
+	return validateAgentManagerScript("agents.ohMyCode", workspace, ohMyCode.AgentManagerScript)
+}
 
-	script := strings.TrimSpace(ohMyCode.AgentManagerScript)
+// validateOhMyCodeWorkspaces checks the named workspaces and that qualified
+// top-level agent names refer to one of them.
+func validateOhMyCodeWorkspaces(ohMyCode *OhMyCodeConfig) error {
+	for name, ws := range ohMyCode.Workspaces {
+		prefix := fmt.Sprintf("agents.ohMyCode.workspaces.%s", name)
+		if !workspaceNamePattern.MatchString(name) {
+			return fmt.Errorf("agents.ohMyCode.workspaces: invalid workspace name %q", name)
+		}
+		if err := validateRoutingAgents(prefix, ws.DefaultAgent, ws.AllowedAgents); err != nil {
+			return err
+		}
+		for idx, agent := range append([]string{ws.DefaultAgent}, ws.AllowedAgents...) {
+			if strings.Contains(agent, "/") {
+				field := fmt.Sprintf("allowedAgents[%d]", idx-1)
+				if idx == 0 {
+					field = "defaultAgent"
+				}
+				return fmt.Errorf("%s.%s: must not include a workspace prefix", prefix, field)
+			}
+		}
+		if ws.AssignTimeoutSeconds < 0 {
+			return fmt.Errorf("%s.assignTimeoutSeconds: must be >= 0", prefix)
+		}
+		workspace := strings.TrimSpace(ws.Workspace)
+		if workspace == "" {
+			return fmt.Errorf("%s.workspace: required", prefix)
+		}
+		if err := validateAgentManagerScript(prefix, workspace, ws.AgentManagerScript); err != nil {
+			return err
+		}
+	}
+
+	for idx, agent := range ohMyCode.AllowedAgents {
+		if strings.Contains(agent, "/") {
+			return fmt.Errorf("agents.ohMyCode.allowedAgents[%d]: list workspace agents under agents.ohMyCode.workspaces", idx)
+		}
+	}
+	if workspace, _, qualified := strings.Cut(strings.TrimSpace(ohMyCode.DefaultAgent), "/"); qualified {
+		if _, ok := ohMyCode.Workspaces[workspace]; !ok {
+			return fmt.Errorf("agents.ohMyCode.defaultAgent: unknown workspace %q", workspace)
+		}
+	}
+	return nil
+}
+
+// validateAgentManagerScript keeps script inside workspace.
+func validateAgentManagerScript(prefix, workspace, script string) error {
+	script = strings.TrimSpace(script)
 	if script == "" {
 		return nil
 	}
 	if !filepath.IsAbs(workspace) {
 		if filepath.IsAbs(script) {
-			return fmt.Errorf("agents.ohMyCode.agentManagerScript: must be relative when agents.ohMyCode.workspace is relative")
+			return fmt.Errorf("%s.agentManagerScript: must be relative when %s.workspace is relative", prefix, prefix)
 		}
 		if rel := filepath.Clean(script); rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
-			return fmt.Errorf("agents.ohMyCode.agentManagerScript: must not escape agents.ohMyCode.workspace")
+			return fmt.Errorf("%s.agentManagerScript: must not escape %s.workspace", prefix, prefix)
 		}
 		return nil
 	}
@@ -609,11 +1435,11 @@ func validateOhMyCodeConfig(cfg *Config) error {
 	}
 	rel, err := filepath.Rel(workspace, resolvedScript)
 	if err != nil {
-		return fmt.Errorf("agents.ohMyCode.agentManagerScript: must be within agents.ohMyCode.workspace")
+		return fmt.Errorf("%s.agentManagerScript: must be within %s.workspace", prefix, prefix)
 	}
 	rel = filepath.Clean(rel)
 	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
-		return fmt.Errorf("agents.ohMyCode.agentManagerScript: must be within agents.ohMyCode.workspace")
+		return fmt.Errorf("%s.agentManagerScript: must be within %s.workspace", prefix, prefix)
 	}
 
 	return nil
@@ -647,6 +1473,51 @@ func validateCodexAppCDPConfig(cfg *Config) error {
 	if codex.Watch.CooldownSeconds < 0 {
 		return fmt.Errorf("agents.codexAppCDP.watch.cooldownSeconds: must be >= 0")
 	}
+	if codex.Redelivery.IntervalSeconds < 0 {
+		return fmt.Errorf("agents.codexAppCDP.redelivery.intervalSeconds: must be >= 0")
+	}
+	if err := validateResponseRelayConfig("agents.codexAppCDP.responseRelay", codex.ResponseRelay); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateClaudeDesktopTargets(claude *ClaudeDesktopConfig) error {
+	switch strings.TrimSpace(claude.ConversationMode) {
+	case "", "active", "perThread", "new":
+	default:
+		return fmt.Errorf("agents.claudeDesktop.conversationMode: unsupported value %q", claude.ConversationMode)
+	}
+	if id := strings.TrimSpace(claude.ConversationID); id != "" && !claudeDesktopIDPattern.MatchString(id) {
+		return fmt.Errorf("agents.claudeDesktop.conversationId: invalid id %q", id)
+	}
+	for agentName, target := range claude.Targets {
+		prefix := fmt.Sprintf("agents.claudeDesktop.targets.%s", agentName)
+		if err := validateAgentName(agentName); err != nil {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+		if id := strings.TrimSpace(target.ProjectID); id != "" && !claudeDesktopIDPattern.MatchString(id) {
+			return fmt.Errorf("%s.projectId: invalid id %q", prefix, id)
+		}
+		if id := strings.TrimSpace(target.ConversationID); id != "" && !claudeDesktopIDPattern.MatchString(id) {
+			return fmt.Errorf("%s.conversationId: invalid id %q", prefix, id)
+		}
+	}
+	return nil
+}
+
+func validateResponseRelayConfig(prefix string, relay ResponseRelayConfig) error {
+	if relay.TimeoutSeconds < 0 {
+		return fmt.Errorf("%s.timeoutSeconds: must be >= 0", prefix)
+	}
+	if relay.StillWorkingSeconds < 0 {
+		return fmt.Errorf("%s.stillWorkingSeconds: must be >= 0", prefix)
+	}
+	for idx, channel := range relay.Channels {
+		if strings.TrimSpace(channel) == "" {
+			return fmt.Errorf("%s.channels[%d]: channel name is required", prefix, idx)
+		}
+	}
 	return nil
 }
 
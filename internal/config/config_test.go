@@ -178,6 +178,64 @@ func TestLoadConfigAcceptsOhMyCodeAbsoluteWorkspaceWithRelativeScript(t *testing
 	}
 }
 
+func TestLoadConfigRejectsNegativeOhMyCodeTaskPollInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte("agents:\n  ohMyCode:\n    enabled: true\n    workspace: \"/tmp\"\n    tasks:\n      enabled: true\n      pollIntervalSeconds: -1\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, err := LoadConfig(path)
+	if err == nil {
+		t.Fatal("expected error for negative poll interval")
+	}
+	if !strings.Contains(err.Error(), "agents.ohMyCode.tasks.pollIntervalSeconds") {
+		t.Fatalf("expected error to mention agents.ohMyCode.tasks.pollIntervalSeconds, got %v", err)
+	}
+}
+
+func TestLoadConfigRejectsNegativeRegistryRefreshInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte("agents:\n  registry:\n    refreshIntervalSeconds: -5\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "agents.registry.refreshIntervalSeconds") {
+		t.Fatalf("expected registry refresh interval error, got %v", err)
+	}
+}
+
+func TestLoadConfigValidatesAgentAddressing(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	content := []byte("agents:\n  addressing:\n    mentions: [\"@{agent}\", \"{agent}:\"]\n    aliases:\n      qa: \"backend/qa-1\"\n")
+	if err := os.WriteFile(valid, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadConfig(valid)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got := cfg.Agents.Addressing; got == nil || len(got.Mentions) != 2 || got.Aliases["qa"] != "backend/qa-1" {
+		t.Fatalf("unexpected addressing: %#v", got)
+	}
+
+	for name, body := range map[string]string{
+		"agents.addressing.mentions[0]":                        "agents:\n  addressing:\n    mentions: [\"@agent\"]\n",
+		"agents.addressing.aliases.qa":                         "agents:\n  addressing:\n    aliases:\n      qa: \"not valid\"\n",
+		"agents.addressing.aliases.qa: unknown agent \"qa-2\"": "agents:\n  codexAppCDP:\n    defaultAgent: qa-1\n    allowedAgents: [qa-1, coder-a]\n  addressing:\n    aliases:\n      qa: qa-2\n",
+		"agents.addressing.aliases.coder-a: collides":          "agents:\n  codexAppCDP:\n    defaultAgent: qa-1\n    allowedAgents: [qa-1, coder-a]\n  addressing:\n    aliases:\n      coder-a: qa-1\n",
+	} {
+		path := filepath.Join(dir, "invalid.yaml")
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected error mentioning %s, got %v", name, err)
+		}
+	}
+}
+
 func TestResolveConfigPathExplicitFlag(t *testing.T) {
 	got := ResolveConfigPath("/custom/path.yaml")
 	if got != "/custom/path.yaml" {
@@ -442,6 +500,39 @@ func TestLoadConfigRequiresClaudeDesktopEndpointOrInboxWhenEnabled(t *testing.T)
 	}
 }
 
+func TestLoadConfigValidatesClaudeDesktopTargets(t *testing.T) {
+	base := "agents:\n  claudeDesktop:\n    enabled: true\n    inboxPath: /tmp/claude-inbox\n    defaultAgent: main\n"
+	tests := []struct {
+		name    string
+		extra   string
+		wantErr string
+	}{
+		{name: "valid", extra: "    conversationMode: perThread\n    targets:\n      main:\n        projectId: 0199-abc\n"},
+		{name: "mode", extra: "    conversationMode: sticky\n", wantErr: "agents.claudeDesktop.conversationMode"},
+		{name: "conversation", extra: "    conversationId: \"../x\"\n", wantErr: "agents.claudeDesktop.conversationId"},
+		{name: "agent", extra: "    targets:\n      \"bad name\":\n        projectId: p1\n", wantErr: "agents.claudeDesktop.targets.bad name"},
+		{name: "project", extra: "    targets:\n      main:\n        projectId: \"p/1\"\n", wantErr: "agents.claudeDesktop.targets.main.projectId"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(base+test.extra), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(path)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadConfig failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("expected %s error, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestLoadConfigAcceptsHeartbeatJobs(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "config.yaml")
 	content := []byte(`agents:
@@ -465,6 +556,16 @@ func TestLoadConfigAcceptsHeartbeatJobs(t *testing.T) {
         agentCronProfiles:
           " idle ": " 0 * * * * "
         resetCronOnInbound: true
+        quietHours:
+          - start: " 22:00 "
+            end: "07:00"
+            timezone: Europe/Berlin
+        blackouts: [" holidays "]
+        blackoutPolicy: " Defer "
+    calendars:
+      holidays:
+        dates: [" 2026-12-25 "]
+        icsPath: ./holidays.ics
 `)
 	if err := os.WriteFile(path, content, 0644); err != nil {
 		t.Fatal(err)
@@ -483,6 +584,15 @@ func TestLoadConfigAcceptsHeartbeatJobs(t *testing.T) {
 	if got := job.AgentCronProfiles["idle"]; got != "0 * * * *" || len(job.AgentCronProfiles) != 1 {
 		t.Fatalf("profiles=%#v", job.AgentCronProfiles)
 	}
+	if len(job.QuietHours) != 1 || job.QuietHours[0].Start != "22:00" || job.QuietHours[0].Timezone != "Europe/Berlin" {
+		t.Fatalf("quiet hours=%#v", job.QuietHours)
+	}
+	if len(job.Blackouts) != 1 || job.Blackouts[0] != "holidays" || job.BlackoutPolicy != HeartbeatBlackoutDefer {
+		t.Fatalf("blackouts=%#v policy=%q", job.Blackouts, job.BlackoutPolicy)
+	}
+	if calendar := cfg.Agents.Heartbeat.Calendars["holidays"]; calendar.Dates[0] != "2026-12-25" || calendar.ICSPath != "./holidays.ics" {
+		t.Fatalf("calendar=%#v", calendar)
+	}
 }
 
 func TestValidateHeartbeatConfigRejectsInvalidJobs(t *testing.T) {
@@ -492,18 +602,43 @@ func TestValidateHeartbeatConfigRejectsInvalidJobs(t *testing.T) {
 		wantError string
 	}{
 		{
-			name: "missing jobs",
+			name: "invalid cron",
 			mutate: func(cfg *Config) {
-				cfg.Agents.Heartbeat.Jobs = nil
+				cfg.Agents.Heartbeat.Jobs[0].Cron = "sometimes"
 			},
-			wantError: "at least one job",
+			wantError: ".cron",
 		},
 		{
-			name: "invalid cron",
+			name: "cron and every",
 			mutate: func(cfg *Config) {
-				cfg.Agents.Heartbeat.Jobs[0].Cron = "sometimes"
+				cfg.Agents.Heartbeat.Jobs[0].Every = "30m"
 			},
-			wantError: ".cron",
+			wantError: "set only one of cron, every, at and window",
+		},
+		{
+			name: "jitter not below every",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].Cron = ""
+				cfg.Agents.Heartbeat.Jobs[0].Every = "30m"
+				cfg.Agents.Heartbeat.Jobs[0].Jitter = "30m"
+			},
+			wantError: ".jitter: must be at least 0 and less than every",
+		},
+		{
+			name: "invalid at",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].Cron = ""
+				cfg.Agents.Heartbeat.Jobs[0].At = "tomorrow"
+			},
+			wantError: ".at: must be RFC 3339",
+		},
+		{
+			name: "empty window",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].Cron = ""
+				cfg.Agents.Heartbeat.Jobs[0].Window = &HeartbeatWindowConfig{Start: "09:00", End: "09:00"}
+			},
+			wantError: ".window: start and end must differ",
 		},
 		{
 			name: "missing timezone",
@@ -561,6 +696,126 @@ func TestValidateHeartbeatConfigRejectsInvalidJobs(t *testing.T) {
 			},
 			wantError: "duplicate profile",
 		},
+		{
+			name: "invalid text template",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].Text = "It is {{.Now.Format \"15:04\"}"
+			},
+			wantError: ".text: template:",
+		},
+		{
+			name: "unknown text template field",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].Text = "{{.Pending}} messages waiting"
+			},
+			wantError: "can't evaluate field Pending",
+		},
+		{
+			name: "invalid quiet hours",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].QuietHours = []HeartbeatQuietHoursConfig{{Start: "10pm", End: "07:00"}}
+			},
+			wantError: ".quietHours[0].start",
+		},
+		{
+			name: "empty quiet hours",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].QuietHours = []HeartbeatQuietHoursConfig{{Start: "07:00", End: "07:00"}}
+			},
+			wantError: "start and end must differ",
+		},
+		{
+			name: "unknown blackout calendar",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].Blackouts = []string{"holidays"}
+			},
+			wantError: `unknown calendar "holidays"`,
+		},
+		{
+			name: "invalid calendar date",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Calendars = map[string]HeartbeatCalendarConfig{"holidays": {Dates: []string{"12/25"}}}
+			},
+			wantError: "must be YYYY-MM-DD",
+		},
+		{
+			name: "empty calendar",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Calendars = map[string]HeartbeatCalendarConfig{"holidays": {}}
+			},
+			wantError: "dates or icsPath is required",
+		},
+		{
+			name: "invalid blackout policy",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].BlackoutPolicy = "queue"
+			},
+			wantError: ".blackoutPolicy",
+		},
+		{
+			name: "invalid misfire policy",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].MisfirePolicy = "replay"
+			},
+			wantError: ".misfirePolicy",
+		},
+		{
+			name: "negative misfire limit",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].MisfireLimit = -1
+			},
+			wantError: ".misfireLimit",
+		},
+		{
+			name: "invalid misfire expiry",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].MisfireExpiry = "-1h"
+			},
+			wantError: ".misfireExpiry: must be positive",
+		},
+		{
+			name: "invalid activity threshold",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].ActivityThreshold = "soon"
+			},
+			wantError: ".activityThreshold",
+		},
+		{
+			name: "invalid activity policy",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].ActivityPolicy = "wait"
+			},
+			wantError: ".activityPolicy: must be skip or defer",
+		},
+		{
+			name: "backoff ladder with unknown profile",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].BackoffAfter = 3
+				cfg.Agents.Heartbeat.Jobs[0].BackoffLadder = []string{"nap"}
+			},
+			wantError: `.backoffLadder[0]: unknown profile "nap"`,
+		},
+		{
+			name: "backoff without ladder",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].BackoffAfter = 3
+			},
+			wantError: ".backoffLadder: required when backoffAfter is set",
+		},
+		{
+			name: "notify without chat",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Jobs[0].Notify = &HeartbeatNotifyConfig{Channel: "telegram"}
+			},
+			wantError: ".notify.to: required",
+		},
+		{
+			name: "invalid global notify unreachableAfter",
+			mutate: func(cfg *Config) {
+				cfg.Agents.Heartbeat.Notify = &HeartbeatNotifyConfig{Channel: "telegram", To: "42", UnreachableAfter: "0s"}
+			},
+			wantError: "agents.heartbeat.notify.unreachableAfter: must be positive",
+		},
 	}
 
 	for _, test := range tests {
@@ -613,6 +868,14 @@ func TestValidateHeartbeatSupportsEveryRuntime(t *testing.T) {
 	}
 }
 
+func TestValidateHeartbeatConfigAllowsNoConfiguredJobs(t *testing.T) {
+	cfg := validHeartbeatConfig()
+	cfg.Agents.Heartbeat.Jobs = nil
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("heartbeat without configured jobs was rejected: %v", err)
+	}
+}
+
 func validHeartbeatConfig() *Config {
 	return &Config{Agents: &AgentsConfig{
 		CodexAppCDP: &CodexAppCDPConfig{
@@ -715,3 +978,66 @@ func TestLoadConfigDemailDisabledByDefault(t *testing.T) {
 		t.Fatalf("expected nil demail config, got %#v", cfg.Channels.Demail)
 	}
 }
+
+func TestLoadConfigOhMyCodeWorkspaces(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte("agents:\n  ohMyCode:\n    enabled: true\n    defaultAgent: \"backend/api-1\"\n    workspaces:\n      backend:\n        workspace: \"" + root + "\"\n        defaultAgent: \"api-1\"\n        allowedAgents:\n          - \"api-1\"\n          - \"api-2\"\n      web:\n        workspace: \"" + root + "\"\n        defaultAgent: \"ui-1\"\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	routing, ok := cfg.Agents.RuntimeRouting("ohMyCode")
+	if !ok {
+		t.Fatal("expected ohMyCode routing")
+	}
+	want := []string{"backend/api-1", "backend/api-2", "web/ui-1"}
+	if routing.DefaultAgent != "backend/api-1" || strings.Join(routing.AllowedAgents, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected routing: %#v", routing)
+	}
+}
+
+func TestLoadConfigRejectsInvalidOhMyCodeWorkspaces(t *testing.T) {
+	root := t.TempDir()
+	cases := map[string]struct {
+		yaml string
+		want string
+	}{
+		"unqualified default without top-level workspace": {
+			yaml: "    defaultAgent: \"api-1\"\n    workspaces:\n      backend:\n        workspace: \"" + root + "\"\n        defaultAgent: \"api-1\"\n",
+			want: "agents.ohMyCode.defaultAgent",
+		},
+		"unknown workspace in default": {
+			yaml: "    defaultAgent: \"web/ui-1\"\n    workspaces:\n      backend:\n        workspace: \"" + root + "\"\n        defaultAgent: \"api-1\"\n",
+			want: "unknown workspace \"web\"",
+		},
+		"missing workspace path": {
+			yaml: "    defaultAgent: \"backend/api-1\"\n    workspaces:\n      backend:\n        defaultAgent: \"api-1\"\n",
+			want: "agents.ohMyCode.workspaces.backend.workspace",
+		},
+		"qualified workspace agent": {
+			yaml: "    defaultAgent: \"backend/api-1\"\n    workspaces:\n      backend:\n        workspace: \"" + root + "\"\n        defaultAgent: \"web/api-1\"\n",
+			want: "agents.ohMyCode.workspaces.backend.defaultAgent",
+		},
+		"script escapes workspace": {
+			yaml: "    defaultAgent: \"backend/api-1\"\n    workspaces:\n      backend:\n        workspace: \"" + root + "\"\n        agentManagerScript: \"../main.py\"\n        defaultAgent: \"api-1\"\n",
+			want: "agents.ohMyCode.workspaces.backend.agentManagerScript",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			content := []byte("agents:\n  ohMyCode:\n    enabled: true\n" + tc.yaml)
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error mentioning %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
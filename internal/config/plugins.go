@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PluginConfig launches an external executable that speaks the stdio
+// JSON-RPC plugin protocol. Entries under channels.plugins implement a
+// channel; entries under agents.plugins implement an Agent Runtime.
+type PluginConfig struct {
+	Name    string            `yaml:"name"`
+	Enabled bool              `yaml:"enabled"`
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Dir     string            `yaml:"dir,omitempty"`
+
+	// Settings is passed to the plugin verbatim in the initialize call.
+	Settings map[string]interface{} `yaml:"settings,omitempty"`
+
+	// DefaultAgent and AllowedAgents apply to runtime plugins only.
+	DefaultAgent  string   `yaml:"defaultAgent,omitempty"`
+	AllowedAgents []string `yaml:"allowedAgents,omitempty"`
+
+	// RestartBackoffSeconds is the first restart delay after the plugin
+	// exits; it doubles up to MaxRestartBackoffSeconds. Defaults: 1 and 60.
+	RestartBackoffSeconds    int `yaml:"restartBackoffSeconds,omitempty"`
+	MaxRestartBackoffSeconds int `yaml:"maxRestartBackoffSeconds,omitempty"`
+
+	// CallTimeoutSeconds bounds each RPC call. Default: 30.
+	CallTimeoutSeconds int `yaml:"callTimeoutSeconds,omitempty"`
+}
+
+// RuntimePlugin returns the runtime plugin configured under name.
+func (cfg *AgentsConfig) RuntimePlugin(name string) (PluginConfig, bool) {
+	if cfg == nil {
+		return PluginConfig{}, false
+	}
+	return findPlugin(cfg.Plugins, name)
+}
+
+// ChannelPlugin returns the channel plugin configured under name.
+func (cfg *ChannelsConfig) ChannelPlugin(name string) (PluginConfig, bool) {
+	if cfg == nil {
+		return PluginConfig{}, false
+	}
+	return findPlugin(cfg.Plugins, name)
+}
+
+func findPlugin(plugins []PluginConfig, name string) (PluginConfig, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return PluginConfig{}, false
+	}
+	for _, plugin := range plugins {
+		if strings.TrimSpace(plugin.Name) == name {
+			return plugin, true
+		}
+	}
+	return PluginConfig{}, false
+}
+
+func pluginConfigPath(section, name string) string {
+	return fmt.Sprintf("%s.plugins.%s", section, name)
+}
+
+var builtinChannelNames = map[string]struct{}{
+	"telegram": {},
+	"slack":    {},
+	"feishu":   {},
+	"discord":  {},
+	"imessage": {},
+	"demail":   {},
+}
+
+func validatePluginsConfig(cfg *Config) error {
+	if cfg == nil {
+		return nil
+	}
+	seen := make(map[string]string)
+	if cfg.Channels != nil {
+		for idx, plugin := range cfg.Channels.Plugins {
+			if err := validatePluginConfig("channels", idx, plugin, seen); err != nil {
+				return err
+			}
+			if _, builtin := builtinChannelNames[strings.TrimSpace(plugin.Name)]; builtin {
+				return fmt.Errorf("channels.plugins[%d].name: %q is a built-in channel", idx, plugin.Name)
+			}
+		}
+	}
+	if cfg.Agents != nil {
+		for idx, plugin := range cfg.Agents.Plugins {
+			if err := validatePluginConfig("agents", idx, plugin, seen); err != nil {
+				return err
+			}
+			name := strings.TrimSpace(plugin.Name)
+			if _, builtin := LookupRuntime(name); builtin {
+				return fmt.Errorf("agents.plugins[%d].name: %q is a built-in runtime", idx, plugin.Name)
+			}
+			if err := validateRoutingAgents(pluginConfigPath("agents", name), plugin.DefaultAgent, plugin.AllowedAgents); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validatePluginConfig(section string, idx int, plugin PluginConfig, seen map[string]string) error {
+	prefix := fmt.Sprintf("%s.plugins[%d]", section, idx)
+	name := strings.TrimSpace(plugin.Name)
+	if name == "" {
+		return fmt.Errorf("%s.name: required", prefix)
+	}
+	if err := validateAgentName(name); err != nil {
+		return fmt.Errorf("%s.name: %w", prefix, err)
+	}
+	if previous, ok := seen[name]; ok {
+		return fmt.Errorf("%s.name: %q is already used by %s", prefix, name, previous)
+	}
+	seen[name] = prefix
+	if plugin.Enabled && strings.TrimSpace(plugin.Command) == "" {
+		return fmt.Errorf("%s.command: required when enabled", prefix)
+	}
+	if plugin.RestartBackoffSeconds < 0 {
+		return fmt.Errorf("%s.restartBackoffSeconds: must be >= 0", prefix)
+	}
+	if plugin.MaxRestartBackoffSeconds < 0 {
+		return fmt.Errorf("%s.maxRestartBackoffSeconds: must be >= 0", prefix)
+	}
+	if plugin.CallTimeoutSeconds < 0 {
+		return fmt.Errorf("%s.callTimeoutSeconds: must be >= 0", prefix)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadPluginTestConfig(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return LoadConfig(path)
+}
+
+func TestLoadConfigAcceptsRuntimePluginRouter(t *testing.T) {
+	cfg, err := loadPluginTestConfig(t, `agents:
+  router: remote
+  plugins:
+    - name: remote
+      enabled: true
+      command: /usr/local/bin/remote-runtime
+      defaultAgent: main
+      allowedAgents: [main]
+channels:
+  plugins:
+    - name: matrix
+      enabled: true
+      command: /usr/local/bin/matrix-channel
+`)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	routing, ok := cfg.Agents.RuntimeRouting("remote")
+	if !ok || !routing.Enabled || routing.DefaultAgent != "main" {
+		t.Fatalf("unexpected routing: %#v ok=%v", routing, ok)
+	}
+	if got := cfg.Agents.RuntimeConfigPath("remote"); got != "agents.plugins.remote" {
+		t.Fatalf("config path=%q", got)
+	}
+	if _, ok := cfg.Channels.ChannelPlugin("matrix"); !ok {
+		t.Fatal("expected matrix channel plugin")
+	}
+	if len(cfg.Channels.Extensions) != 0 {
+		t.Fatalf("plugins must not be treated as extensions: %#v", cfg.Channels.Extensions)
+	}
+}
+
+func TestLoadConfigRejectsInvalidPlugins(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		want    string
+	}{
+		"missing command": {
+			content: "agents:\n  plugins:\n    - name: remote\n      enabled: true\n",
+			want:    "agents.plugins[0].command",
+		},
+		"duplicate name": {
+			content: "agents:\n  plugins:\n    - name: dup\n      command: a\nchannels:\n  plugins:\n    - name: dup\n      command: b\n",
+			want:    "already used",
+		},
+		"builtin channel": {
+			content: "channels:\n  plugins:\n    - name: slack\n      command: a\n",
+			want:    "built-in channel",
+		},
+		"builtin runtime": {
+			content: "agents:\n  plugins:\n    - name: ohMyCode\n      command: a\n",
+			want:    "built-in runtime",
+		},
+		"default not allowed": {
+			content: "agents:\n  plugins:\n    - name: remote\n      command: a\n      defaultAgent: main\n      allowedAgents: [other]\n",
+			want:    "agents.plugins.remote.defaultAgent",
+		},
+		"negative backoff": {
+			content: "channels:\n  plugins:\n    - name: matrix\n      command: a\n      restartBackoffSeconds: -1\n",
+			want:    "restartBackoffSeconds",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadPluginTestConfig(t, tc.content)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuntimeSpec describes how one Agent Runtime section under agents is
+// validated and which routing settings it contributes. Built-in runtimes are
+// registered by this package; out-of-tree runtimes register their own spec
+// before the config is loaded.
+type RuntimeSpec struct {
+	// Name is the runtime identifier used by agents.router and heartbeat jobs.
+	Name string
+
+	// ConfigPath is the config key used in validation messages, for example
+	// "agents.ohMyCode".
+	ConfigPath string
+
+	// Routing returns the runtime's routing settings. ok is false when the
+	// runtime section is absent.
+	Routing func(cfg *AgentsConfig) (routing RuntimeRouting, ok bool)
+
+	// Validate checks the runtime section. It runs on every config load.
+	Validate func(cfg *Config) error
+}
+
+// RuntimeRouting is the runtime-neutral view of an agent routing section.
+type RuntimeRouting struct {
+	Enabled       bool
+	DefaultAgent  string
+	AllowedAgents []string
+
+	// InboxPath is the runtime's durable fallback inbox, if any.
+	InboxPath string
+}
+
+type runtimeSpecRegistry struct {
+	mu    sync.RWMutex
+	specs []RuntimeSpec
+}
+
+var runtimeSpecs = &runtimeSpecRegistry{}
+
+// RegisterRuntime adds a runtime spec. Registration order is the automatic
+// router priority used when agents.router is empty.
+func RegisterRuntime(spec RuntimeSpec) error {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if spec.Name == "" {
+		return fmt.Errorf("runtime name is required")
+	}
+	if spec.Routing == nil {
+		return fmt.Errorf("runtime %q: routing provider is required", spec.Name)
+	}
+	if strings.TrimSpace(spec.ConfigPath) == "" {
+		spec.ConfigPath = "agents." + spec.Name
+	}
+	runtimeSpecs.mu.Lock()
+	defer runtimeSpecs.mu.Unlock()
+	for _, existing := range runtimeSpecs.specs {
+		if existing.Name == spec.Name {
+			return fmt.Errorf("runtime %q already registered", spec.Name)
+		}
+	}
+	runtimeSpecs.specs = append(runtimeSpecs.specs, spec)
+	return nil
+}
+
+// Runtimes returns registered runtime specs in registration order.
+func Runtimes() []RuntimeSpec {
+	runtimeSpecs.mu.RLock()
+	defer runtimeSpecs.mu.RUnlock()
+	return append([]RuntimeSpec(nil), runtimeSpecs.specs...)
+}
+
+// LookupRuntime returns the spec registered under name.
+func LookupRuntime(name string) (RuntimeSpec, bool) {
+	name = strings.TrimSpace(name)
+	runtimeSpecs.mu.RLock()
+	defer runtimeSpecs.mu.RUnlock()
+	for _, spec := range runtimeSpecs.specs {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return RuntimeSpec{}, false
+}
+
+// ActiveRouter returns the configured router, or the first enabled runtime in
+// registration order when agents.router is empty.
+func (cfg *AgentsConfig) ActiveRouter() string {
+	if cfg == nil {
+		return ""
+	}
+	if router := strings.TrimSpace(cfg.Router); router != "" {
+		return router
+	}
+	for _, spec := range Runtimes() {
+		if routing, ok := spec.Routing(cfg); ok && routing.Enabled {
+			return spec.Name
+		}
+	}
+	return ""
+}
+
+// RuntimeRouting returns the routing settings of the named runtime or
+// runtime plugin.
+func (cfg *AgentsConfig) RuntimeRouting(name string) (RuntimeRouting, bool) {
+	if cfg == nil {
+		return RuntimeRouting{}, false
+	}
+	if spec, ok := LookupRuntime(name); ok {
+		return spec.Routing(cfg)
+	}
+	if plugin, ok := cfg.RuntimePlugin(name); ok {
+		return RuntimeRouting{
+			Enabled:       plugin.Enabled,
+			DefaultAgent:  plugin.DefaultAgent,
+			AllowedAgents: plugin.AllowedAgents,
+		}, true
+	}
+	return RuntimeRouting{}, false
+}
+
+// RuntimeConfigPath returns the config key of the named runtime or runtime
+// plugin, or "" when the name is unknown.
+func (cfg *AgentsConfig) RuntimeConfigPath(name string) string {
+	if spec, ok := LookupRuntime(name); ok {
+		return spec.ConfigPath
+	}
+	if _, ok := cfg.RuntimePlugin(name); ok {
+		return pluginConfigPath("agents", strings.TrimSpace(name))
+	}
+	return ""
+}
+
+// RuntimeInboxes returns the configured inbox directory of every runtime that
+// has one, keyed by runtime name.
+func (cfg *AgentsConfig) RuntimeInboxes() map[string]string {
+	inboxes := make(map[string]string)
+	for _, spec := range Runtimes() {
+		if spec.Routing == nil {
+			continue
+		}
+		if routing, ok := spec.Routing(cfg); ok && routing.InboxPath != "" {
+			inboxes[spec.Name] = routing.InboxPath
+		}
+	}
+	return inboxes
+}
+
+// DecodeExtension decodes an out-of-tree section under agents into out. It
+// reports false when the section is absent.
+func (cfg *AgentsConfig) DecodeExtension(name string, out interface{}) (bool, error) {
+	if cfg == nil {
+		return false, nil
+	}
+	return decodeExtension(cfg.Extensions, "agents", name, out)
+}
+
+// DecodeExtension decodes an out-of-tree section under channels into out. It
+// reports false when the section is absent.
+func (cfg *ChannelsConfig) DecodeExtension(name string, out interface{}) (bool, error) {
+	if cfg == nil {
+		return false, nil
+	}
+	return decodeExtension(cfg.Extensions, "channels", name, out)
+}
+
+func decodeExtension(extensions map[string]yaml.Node, prefix, name string, out interface{}) (bool, error) {
+	node, ok := extensions[strings.TrimSpace(name)]
+	if !ok {
+		return false, nil
+	}
+	if err := node.Decode(out); err != nil {
+		return true, fmt.Errorf("%s.%s: %w", prefix, name, err)
+	}
+	return true, nil
+}
+
+func init() {
+	builtins := []RuntimeSpec{
+		{
+			Name:       "ohMyCode",
+			ConfigPath: "agents.ohMyCode",
+			Routing: func(cfg *AgentsConfig) (RuntimeRouting, bool) {
+				if cfg == nil || cfg.OhMyCode == nil {
+					return RuntimeRouting{}, false
+				}
+				defaultAgent, allowedAgents := cfg.OhMyCode.RoutingAgents()
+				return RuntimeRouting{
+					Enabled:       cfg.OhMyCode.Enabled,
+					DefaultAgent:  defaultAgent,
+					AllowedAgents: allowedAgents,
+				}, true
+			},
+			Validate: validateOhMyCodeConfig,
+		},
+		{
+			Name:       "codexAppCDP",
+			ConfigPath: "agents.codexAppCDP",
+			Routing: func(cfg *AgentsConfig) (RuntimeRouting, bool) {
+				if cfg == nil || cfg.CodexAppCDP == nil {
+					return RuntimeRouting{}, false
+				}
+				return RuntimeRouting{
+					Enabled:       cfg.CodexAppCDP.Enabled,
+					DefaultAgent:  cfg.CodexAppCDP.DefaultAgent,
+					AllowedAgents: cfg.CodexAppCDP.AllowedAgents,
+					InboxPath:     strings.TrimSpace(cfg.CodexAppCDP.InboxPath),
+				}, true
+			},
+			Validate: validateCodexAppCDPConfig,
+		},
+		{
+			Name:       "claudeDesktop",
+			ConfigPath: "agents.claudeDesktop",
+			Routing: func(cfg *AgentsConfig) (RuntimeRouting, bool) {
+				if cfg == nil || cfg.ClaudeDesktop == nil {
+					return RuntimeRouting{}, false
+				}
+				return RuntimeRouting{
+					Enabled:       cfg.ClaudeDesktop.Enabled,
+					DefaultAgent:  cfg.ClaudeDesktop.DefaultAgent,
+					AllowedAgents: cfg.ClaudeDesktop.AllowedAgents,
+					InboxPath:     strings.TrimSpace(cfg.ClaudeDesktop.InboxPath),
+				}, true
+			},
+			Validate: validateClaudeDesktopConfig,
+		},
+	}
+	for _, spec := range builtins {
+		if err := RegisterRuntime(spec); err != nil {
+			panic(err)
+		}
+	}
+}
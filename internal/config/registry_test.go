@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testRuntimeSection struct {
+	Enabled      bool   `yaml:"enabled"`
+	DefaultAgent string `yaml:"defaultAgent"`
+}
+
+var registerTestRuntimeOnce sync.Once
+
+func registerTestRuntime(t *testing.T) {
+	t.Helper()
+	registerTestRuntimeOnce.Do(func() {
+		err := RegisterRuntime(RuntimeSpec{
+			Name: "testRuntime",
+			Routing: func(cfg *AgentsConfig) (RuntimeRouting, bool) {
+				var section testRuntimeSection
+				ok, err := cfg.DecodeExtension("testRuntime", &section)
+				if err != nil || !ok {
+					return RuntimeRouting{}, false
+				}
+				return RuntimeRouting{Enabled: section.Enabled, DefaultAgent: section.DefaultAgent}, true
+			},
+			Validate: func(cfg *Config) error {
+				var section testRuntimeSection
+				if _, err := cfg.Agents.DecodeExtension("testRuntime", &section); err != nil {
+					return err
+				}
+				if section.Enabled && section.DefaultAgent == "" {
+					return errors.New("agents.testRuntime.defaultAgent is required")
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("register runtime: %v", err)
+		}
+	})
+}
+
+func TestRegisterRuntimeRejectsDuplicateAndIncompleteSpecs(t *testing.T) {
+	if err := RegisterRuntime(RuntimeSpec{Name: "ohMyCode", Routing: func(*AgentsConfig) (RuntimeRouting, bool) { return RuntimeRouting{}, false }}); err == nil {
+		t.Fatal("expected duplicate runtime error")
+	}
+	if err := RegisterRuntime(RuntimeSpec{Name: "noRouting"}); err == nil {
+		t.Fatal("expected missing routing error")
+	}
+	if err := RegisterRuntime(RuntimeSpec{Name: " "}); err == nil {
+		t.Fatal("expected missing name error")
+	}
+}
+
+func TestLoadConfigAcceptsRegisteredRuntimeRouter(t *testing.T) {
+	registerTestRuntime(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte("agents:\n  router: testRuntime\n  testRuntime:\n    enabled: true\n    defaultAgent: helper\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if got := cfg.Agents.ActiveRouter(); got != "testRuntime" {
+		t.Fatalf("active router=%q, want testRuntime", got)
+	}
+	routing, ok := cfg.Agents.RuntimeRouting("testRuntime")
+	if !ok || !routing.Enabled || routing.DefaultAgent != "helper" {
+		t.Fatalf("unexpected routing: %#v ok=%v", routing, ok)
+	}
+}
+
+func TestLoadConfigRunsRegisteredRuntimeValidation(t *testing.T) {
+	registerTestRuntime(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte("agents:\n  testRuntime:\n    enabled: true\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "agents.testRuntime.defaultAgent") {
+		t.Fatalf("expected testRuntime validation error, got %v", err)
+	}
+}
+
+func TestLoadConfigRejectsUnknownRouter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte("agents:\n  router: missingRuntime\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for unregistered router")
+	}
+}
+
+func TestActiveRouterPrefersRegistrationOrder(t *testing.T) {
+	cfg := &AgentsConfig{
+		OhMyCode:      &OhMyCodeConfig{Enabled: true},
+		ClaudeDesktop: &ClaudeDesktopConfig{Enabled: true},
+	}
+	if got := cfg.ActiveRouter(); got != "ohMyCode" {
+		t.Fatalf("active router=%q, want ohMyCode", got)
+	}
+	cfg.OhMyCode.Enabled = false
+	if got := cfg.ActiveRouter(); got != "claudeDesktop" {
+		t.Fatalf("active router=%q, want claudeDesktop", got)
+	}
+}
+
+func TestChannelsDecodeExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := []byte("channels:\n  matrix:\n    enabled: true\n    homeserver: https://matrix.example\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	var section struct {
+		Enabled    bool   `yaml:"enabled"`
+		Homeserver string `yaml:"homeserver"`
+	}
+	ok, err := cfg.Channels.DecodeExtension("matrix", &section)
+	if err != nil || !ok {
+		t.Fatalf("decode extension ok=%v err=%v", ok, err)
+	}
+	if !section.Enabled || section.Homeserver != "https://matrix.example" {
+		t.Fatalf("unexpected section: %#v", section)
+	}
+	if ok, _ := cfg.Channels.DecodeExtension("missing", &section); ok {
+		t.Fatal("expected missing extension to report false")
+	}
+}
@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+type registryAgent struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Runtime      string `json:"runtime,omitempty"`
+	Status       string `json:"status"`
+	Detail       string `json:"detail,omitempty"`
+	LastActivity string `json:"last_activity,omitempty"`
+}
+
+type agentsResponse struct {
+	Status string          `json:"status"`
+	Agents []registryAgent `json:"agents"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// handleAgents serves the agent registry:
+//
+//	GET /api/v1/agents
+func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if s.agentManager == nil {
+		writeJSON(w, http.StatusServiceUnavailable, agentsResponse{Status: "error", Agents: []registryAgent{}, Error: "agent manager is not running"})
+		return
+	}
+	writeJSON(w, http.StatusOK, agentsResponse{Status: "ok", Agents: registryAgents(s.agentManager.List())})
+}
+
+func registryAgents(infos []protocol.AgentInfo) []registryAgent {
+	agents := make([]registryAgent, 0, len(infos))
+	for _, info := range infos {
+		agent := registryAgent{
+			ID:      info.ID,
+			Name:    info.Name,
+			Runtime: info.Runtime,
+			Status:  info.Status,
+			Detail:  info.Detail,
+		}
+		if info.LastActivity != nil {
+			agent.LastActivity = formatStatusTime(*info.LastActivity)
+		}
+		agents = append(agents, agent)
+	}
+	return agents
+}
@@ -0,0 +1,386 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/internal/heartbeat"
+)
+
+// heartbeatJobRequest creates or updates a heartbeat job. On update, omitted
+// fields keep their current value.
+type heartbeatJobRequest struct {
+	ID                 string            `json:"id,omitempty"`
+	Runtime            *string           `json:"runtime,omitempty"`
+	Agent              *string           `json:"agent,omitempty"`
+	Text               *string           `json:"text,omitempty"`
+	Cron               *string           `json:"cron,omitempty"`
+	Every              *string           `json:"every,omitempty"`
+	Jitter             *string           `json:"jitter,omitempty"`
+	At                 *string           `json:"at,omitempty"`
+	Timezone           *string           `json:"timezone,omitempty"`
+	AgentCronProfiles  map[string]string `json:"agent_cron_profiles,omitempty"`
+	ResetCronOnInbound *bool             `json:"reset_cron_on_inbound,omitempty"`
+
+	QuietHours     []config.HeartbeatQuietHoursConfig `json:"quiet_hours,omitempty"`
+	Blackouts      []string                           `json:"blackouts,omitempty"`
+	BlackoutPolicy *string                            `json:"blackout_policy,omitempty"`
+	MisfirePolicy  *string                            `json:"misfire_policy,omitempty"`
+	MisfireLimit   *int                               `json:"misfire_limit,omitempty"`
+	MisfireExpiry  *string                            `json:"misfire_expiry,omitempty"`
+
+	ActivityThreshold *string `json:"activity_threshold,omitempty"`
+	ActivityPolicy    *string `json:"activity_policy,omitempty"`
+
+	BackoffAfter  *int     `json:"backoff_after,omitempty"`
+	BackoffLadder []string `json:"backoff_ladder,omitempty"`
+
+	Notify *config.HeartbeatNotifyConfig `json:"notify,omitempty"`
+	Window *config.HeartbeatWindowConfig `json:"window,omitempty"`
+}
+
+type heartbeatJobsResponse struct {
+	Status string                `json:"status"`
+	Jobs   []heartbeat.JobStatus `json:"jobs,omitempty"`
+	Job    *heartbeat.JobStatus  `json:"job,omitempty"`
+	Error  string                `json:"error,omitempty"`
+}
+
+// handleHeartbeatJobs serves the loopback-only job collection:
+//
+//	GET  /api/v1/heartbeat/jobs
+//	POST /api/v1/heartbeat/jobs
+func (s *Server) handleHeartbeatJobs(w http.ResponseWriter, r *http.Request) {
+	if !s.heartbeatJobsAvailable(w, r) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, heartbeatJobsResponse{Status: "ok", Jobs: s.heartbeat.Status().Jobs})
+	case http.MethodPost:
+		var request heartbeatJobRequest
+		if !decodeJSONRequest(w, r, &request) {
+			return
+		}
+		status, err := s.heartbeat.CreateJob(request.jobConfig())
+		writeHeartbeatJob(w, status, err)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, heartbeatJobsResponse{Status: "error", Error: "method not allowed"})
+	}
+}
+
+// handleHeartbeatJob serves one job, its controls and its cron profile
+// endpoint:
+//
+//	GET    /api/v1/heartbeat/jobs/{id}
+//	PUT    /api/v1/heartbeat/jobs/{id}
+//	DELETE /api/v1/heartbeat/jobs/{id}
+//	POST   /api/v1/heartbeat/jobs/{id}/pause
+//	POST   /api/v1/heartbeat/jobs/{id}/resume
+//	POST   /api/v1/heartbeat/jobs/{id}/run
+//	GET    /api/v1/heartbeat/jobs/{id}/runs?limit=N
+//	PUT    /api/v1/heartbeat/jobs/{id}/cron
+//	DELETE /api/v1/heartbeat/jobs/{id}/cron
+func (s *Server) handleHeartbeatJob(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/cron") {
+		s.handleHeartbeatCron(w, r)
+		return
+	}
+	if !s.heartbeatJobsAvailable(w, r) {
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/heartbeat/jobs/"), "/"), "/")
+	jobID := parts[0]
+	if jobID == "" || len(parts) > 2 {
+		writeJSON(w, http.StatusNotFound, heartbeatJobsResponse{Status: "error", Error: "heartbeat job endpoint not found"})
+		return
+	}
+	if len(parts) == 2 && parts[1] == "runs" {
+		s.handleHeartbeatRuns(w, r, jobID)
+		return
+	}
+	if len(parts) == 2 {
+		s.handleHeartbeatJobControl(w, r, jobID, parts[1])
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		for _, job := range s.heartbeat.Status().Jobs {
+			if job.ID == jobID {
+				writeJSON(w, http.StatusOK, heartbeatJobsResponse{Status: "ok", Job: &job})
+				return
+			}
+		}
+		writeJSON(w, http.StatusNotFound, heartbeatJobsResponse{Status: "error", Error: fmt.Sprintf("heartbeat job %q not found", jobID)})
+	case http.MethodPut:
+		var request heartbeatJobRequest
+		if !decodeJSONRequest(w, r, &request) {
+			return
+		}
+		if request.ID != "" && strings.TrimSpace(request.ID) != jobID {
+			writeJSON(w, http.StatusBadRequest, heartbeatJobsResponse{Status: "error", Error: "job ID cannot be changed"})
+			return
+		}
+		status, err := s.heartbeat.UpdateJob(jobID, request.jobUpdate())
+		writeHeartbeatJob(w, status, err)
+	case http.MethodDelete:
+		if err := s.heartbeat.DeleteJob(jobID); err != nil {
+			writeHeartbeatJob(w, heartbeat.JobStatus{}, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, heartbeatJobsResponse{Status: "ok"})
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut+", "+http.MethodDelete)
+		writeJSON(w, http.StatusMethodNotAllowed, heartbeatJobsResponse{Status: "error", Error: "method not allowed"})
+	}
+}
+
+type heartbeatRunsResponse struct {
+	Status string                `json:"status"`
+	Runs   []heartbeat.RunRecord `json:"runs"`
+	Error  string                `json:"error,omitempty"`
+}
+
+func (s *Server) handleHeartbeatRuns(w http.ResponseWriter, r *http.Request, jobID string) {
+	if !requireMethod(w, r, http.MethodGet) {
+		return
+	}
+	limit := 0
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			writeJSON(w, http.StatusBadRequest, heartbeatRunsResponse{Status: "error", Error: "limit must be a non-negative integer"})
+			return
+		}
+		limit = parsed
+	}
+	runs, err := s.heartbeat.RunHistory(jobID, limit)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, heartbeat.ErrJobNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		writeJSON(w, statusCode, heartbeatRunsResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []heartbeat.RunRecord{}
+	}
+	writeJSON(w, http.StatusOK, heartbeatRunsResponse{Status: "ok", Runs: runs})
+}
+
+type heartbeatPauseRequest struct {
+	Until    string `json:"until,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func (s *Server) handleHeartbeatJobControl(w http.ResponseWriter, r *http.Request, jobID, action string) {
+	if action != "pause" && action != "resume" && action != "run" {
+		writeJSON(w, http.StatusNotFound, heartbeatJobsResponse{Status: "error", Error: "heartbeat job endpoint not found"})
+		return
+	}
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	var (
+		status heartbeat.JobStatus
+		err    error
+	)
+	switch action {
+	case "pause":
+		var request heartbeatPauseRequest
+		if !decodeJSONRequest(w, r, &request) {
+			return
+		}
+		until, parseErr := pauseEnd(request, time.Now())
+		if parseErr != nil {
+			writeJSON(w, http.StatusBadRequest, heartbeatJobsResponse{Status: "error", Error: parseErr.Error()})
+			return
+		}
+		status, err = s.heartbeat.Pause(jobID, until, request.Reason, "local-api")
+	case "resume":
+		status, err = s.heartbeat.Resume(jobID)
+	default:
+		status, err = s.heartbeat.RunNow(jobID)
+	}
+	writeHeartbeatJob(w, status, err)
+}
+
+// pauseEnd resolves an absolute until or a relative duration. Neither means
+// an indefinite pause.
+func pauseEnd(request heartbeatPauseRequest, now time.Time) (time.Time, error) {
+	until := strings.TrimSpace(request.Until)
+	duration := strings.TrimSpace(request.Duration)
+	switch {
+	case until != "" && duration != "":
+		return time.Time{}, errors.New("set until or duration, not both")
+	case until != "":
+		parsed, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("until: %w", err)
+		}
+		return parsed, nil
+	case duration != "":
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("duration: %w", err)
+		}
+		if parsed <= 0 {
+			return time.Time{}, errors.New("duration: must be positive")
+		}
+		return now.Add(parsed), nil
+	}
+	return time.Time{}, nil
+}
+
+func (s *Server) heartbeatJobsAvailable(w http.ResponseWriter, r *http.Request) bool {
+	if !isLoopbackRequest(r) {
+		writeJSON(w, http.StatusForbidden, heartbeatJobsResponse{Status: "error", Error: "heartbeat job API is restricted to loopback clients"})
+		return false
+	}
+	heartbeatStatus := s.heartbeat.Status()
+	if heartbeatStatus == nil || !heartbeatStatus.Enabled {
+		writeJSON(w, http.StatusServiceUnavailable, heartbeatJobsResponse{Status: "error", Error: "heartbeat scheduler is disabled"})
+		return false
+	}
+	return true
+}
+
+func writeHeartbeatJob(w http.ResponseWriter, status heartbeat.JobStatus, err error) {
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		switch {
+		case errors.Is(err, heartbeat.ErrJobNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, heartbeat.ErrJobExists), errors.Is(err, heartbeat.ErrJobReadOnly), errors.Is(err, heartbeat.ErrJobBusy):
+			statusCode = http.StatusConflict
+		}
+		writeJSON(w, statusCode, heartbeatJobsResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, heartbeatJobsResponse{Status: "ok", Job: &status})
+}
+
+func (r heartbeatJobRequest) jobConfig() config.HeartbeatJobConfig {
+	job := config.HeartbeatJobConfig{
+		ID:                r.ID,
+		Runtime:           stringValue(r.Runtime),
+		Agent:             stringValue(r.Agent),
+		Text:              stringValue(r.Text),
+		Cron:              stringValue(r.Cron),
+		Every:             stringValue(r.Every),
+		Jitter:            stringValue(r.Jitter),
+		At:                stringValue(r.At),
+		Window:            r.Window,
+		Timezone:          stringValue(r.Timezone),
+		AgentCronProfiles: r.AgentCronProfiles,
+		QuietHours:        r.QuietHours,
+		Blackouts:         r.Blackouts,
+		BlackoutPolicy:    stringValue(r.BlackoutPolicy),
+		MisfirePolicy:     stringValue(r.MisfirePolicy),
+		MisfireExpiry:     stringValue(r.MisfireExpiry),
+		ActivityThreshold: stringValue(r.ActivityThreshold),
+		ActivityPolicy:    stringValue(r.ActivityPolicy),
+		BackoffLadder:     r.BackoffLadder,
+		Notify:            r.Notify,
+	}
+	if r.ResetCronOnInbound != nil {
+		job.ResetCronOnInbound = *r.ResetCronOnInbound
+	}
+	if r.MisfireLimit != nil {
+		job.MisfireLimit = *r.MisfireLimit
+	}
+	if r.BackoffAfter != nil {
+		job.BackoffAfter = *r.BackoffAfter
+	}
+	return job
+}
+
+func (r heartbeatJobRequest) jobUpdate() heartbeat.JobUpdate {
+	return heartbeat.JobUpdate{
+		Runtime:            r.Runtime,
+		Agent:              r.Agent,
+		Text:               r.Text,
+		Cron:               r.Cron,
+		Every:              r.Every,
+		Jitter:             r.Jitter,
+		At:                 r.At,
+		Window:             r.Window,
+		Timezone:           r.Timezone,
+		AgentCronProfiles:  r.AgentCronProfiles,
+		ResetCronOnInbound: r.ResetCronOnInbound,
+		QuietHours:         r.QuietHours,
+		Blackouts:          r.Blackouts,
+		BlackoutPolicy:     r.BlackoutPolicy,
+		MisfirePolicy:      r.MisfirePolicy,
+		MisfireLimit:       r.MisfireLimit,
+		MisfireExpiry:      r.MisfireExpiry,
+		ActivityThreshold:  r.ActivityThreshold,
+		ActivityPolicy:     r.ActivityPolicy,
+		BackoffAfter:       r.BackoffAfter,
+		BackoffLadder:      r.BackoffLadder,
+		Notify:             r.Notify,
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// heartbeatChatControl exposes the scheduler to admin chat commands.
+type heartbeatChatControl struct {
+	scheduler *heartbeat.Scheduler
+}
+
+var _ channels.HeartbeatControl = heartbeatChatControl{}
+
+func (c heartbeatChatControl) HeartbeatJobs() []channels.HeartbeatJobState {
+	status := c.scheduler.Status()
+	if status == nil {
+		return nil
+	}
+	jobs := make([]channels.HeartbeatJobState, 0, len(status.Jobs))
+	for _, job := range status.Jobs {
+		jobs = append(jobs, heartbeatJobState(job))
+	}
+	return jobs
+}
+
+func (c heartbeatChatControl) PauseHeartbeat(jobID string, until time.Time, reason, pausedBy string) (channels.HeartbeatJobState, error) {
+	status, err := c.scheduler.Pause(jobID, until, reason, pausedBy)
+	return heartbeatJobState(status), err
+}
+
+func (c heartbeatChatControl) ResumeHeartbeat(jobID string) (channels.HeartbeatJobState, error) {
+	status, err := c.scheduler.Resume(jobID)
+	return heartbeatJobState(status), err
+}
+
+func (c heartbeatChatControl) RunHeartbeat(jobID string) (channels.HeartbeatJobState, error) {
+	status, err := c.scheduler.RunNow(jobID)
+	return heartbeatJobState(status), err
+}
+
+func heartbeatJobState(job heartbeat.JobStatus) channels.HeartbeatJobState {
+	return channels.HeartbeatJobState{
+		ID:          job.ID,
+		Runtime:     job.Runtime,
+		Agent:       job.Agent,
+		NextRunAt:   job.NextRunAt,
+		Paused:      job.Paused,
+		PausedUntil: job.PausedUntil,
+		PauseReason: job.PauseReason,
+	}
+}
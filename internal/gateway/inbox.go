@@ -0,0 +1,279 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/inbox"
+)
+
+type inboxStatus struct {
+	Pending                 int    `json:"pending"`
+	Claimed                 int    `json:"claimed"`
+	Done                    int    `json:"done"`
+	Failed                  int    `json:"failed"`
+	OldestPendingAt         string `json:"oldest_pending_at,omitempty"`
+	OldestPendingAgeSeconds int64  `json:"oldest_pending_age_seconds,omitempty"`
+	Error                   string `json:"error,omitempty"`
+}
+
+type inboxRedeliveryStatus struct {
+	Running        bool   `json:"running"`
+	LastStartedAt  string `json:"last_started_at,omitempty"`
+	LastFinishedAt string `json:"last_finished_at,omitempty"`
+	Delivered      int    `json:"delivered"`
+	Skipped        int    `json:"skipped"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+type inboxListResponse struct {
+	Status  string       `json:"status"`
+	Runtime string       `json:"runtime,omitempty"`
+	Items   []inbox.Item `json:"items,omitempty"`
+	Inbox   *inboxStatus `json:"inbox,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+type inboxItemResponse struct {
+	Status  string          `json:"status"`
+	Item    *inbox.Item     `json:"item,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Purged  int             `json:"purged,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+type inboxClaimRequest struct {
+	Owner        string `json:"owner"`
+	LeaseSeconds int    `json:"lease_seconds,omitempty"`
+}
+
+type inboxAckRequest struct {
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type inboxPurgeRequest struct {
+	State            string `json:"state"`
+	OlderThanSeconds int    `json:"older_than_seconds,omitempty"`
+}
+
+// handleInbox serves the loopback-only inbox API:
+//
+//	GET  /api/v1/inbox/{runtime}[?state=pending]
+//	GET  /api/v1/inbox/{runtime}/{id}
+//	POST /api/v1/inbox/{runtime}/claim
+//	POST /api/v1/inbox/{runtime}/purge
+//	POST /api/v1/inbox/{runtime}/{id}/ack
+//	POST /api/v1/inbox/{runtime}/{id}/requeue
+func (s *Server) handleInbox(w http.ResponseWriter, r *http.Request) {
+	if !isLoopbackRequest(r) {
+		writeJSON(w, http.StatusForbidden, inboxItemResponse{Status: "error", Error: "inbox API is restricted to loopback clients"})
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/inbox/"), "/"), "/")
+	if len(parts) == 0 || parts[0] == "" || len(parts) > 3 {
+		writeJSON(w, http.StatusNotFound, inboxItemResponse{Status: "error", Error: "inbox endpoint not found"})
+		return
+	}
+	runtimeName := parts[0]
+	store := s.inboxStore(runtimeName)
+	if store == nil {
+		writeJSON(w, http.StatusNotFound, inboxItemResponse{Status: "error", Error: "no inbox configured for runtime " + runtimeName})
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		if !requireMethod(w, r, http.MethodGet) {
+			return
+		}
+		state, err := inbox.ParseState(r.URL.Query().Get("state"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, inboxListResponse{Status: "error", Error: err.Error()})
+			return
+		}
+		items, err := store.List(state)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, inboxListResponse{Status: "error", Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, inboxListResponse{Status: "ok", Runtime: runtimeName, Items: items, Inbox: inboxStatusFor(store.Dir())})
+
+	case len(parts) == 2 && parts[1] == "claim":
+		if !requireMethod(w, r, http.MethodPost) {
+			return
+		}
+		var request inboxClaimRequest
+		if !decodeJSONRequest(w, r, &request) {
+			return
+		}
+		item, err := store.Claim(request.Owner, time.Duration(request.LeaseSeconds)*time.Second)
+		if errors.Is(err, inbox.ErrNotFound) {
+			writeJSON(w, http.StatusOK, inboxItemResponse{Status: "empty"})
+			return
+		}
+		writeInboxItem(w, item, err)
+
+	case len(parts) == 2 && parts[1] == "purge":
+		if !requireMethod(w, r, http.MethodPost) {
+			return
+		}
+		var request inboxPurgeRequest
+		if !decodeJSONRequest(w, r, &request) {
+			return
+		}
+		state, err := inbox.ParseState(request.State)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, inboxItemResponse{Status: "error", Error: err.Error()})
+			return
+		}
+		purged, err := store.Purge(state, time.Duration(request.OlderThanSeconds)*time.Second)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, inboxItemResponse{Status: "error", Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, inboxItemResponse{Status: "ok", Purged: purged})
+
+	case len(parts) == 2:
+		if !requireMethod(w, r, http.MethodGet) {
+			return
+		}
+		item, payload, err := store.Get(parts[1])
+		if err != nil {
+			writeInboxItem(w, item, err)
+			return
+		}
+		response := inboxItemResponse{Status: "ok", Item: &item}
+		if json.Valid(payload) {
+			response.Payload = payload
+		}
+		writeJSON(w, http.StatusOK, response)
+
+	case parts[2] == "ack":
+		if !requireMethod(w, r, http.MethodPost) {
+			return
+		}
+		var request inboxAckRequest
+		if !decodeJSONRequest(w, r, &request) {
+			return
+		}
+		var failed bool
+		switch strings.ToLower(strings.TrimSpace(request.Status)) {
+		case "", string(inbox.StateDone):
+		case string(inbox.StateFailed):
+			failed = true
+		default:
+			writeJSON(w, http.StatusBadRequest, inboxItemResponse{Status: "error", Error: "ack status must be done or failed"})
+			return
+		}
+		item, err := store.Ack(parts[1], failed, request.Error)
+		writeInboxItem(w, item, err)
+
+	case parts[2] == "requeue":
+		if !requireMethod(w, r, http.MethodPost) {
+			return
+		}
+		item, err := store.Requeue(parts[1])
+		writeInboxItem(w, item, err)
+
+	default:
+		writeJSON(w, http.StatusNotFound, inboxItemResponse{Status: "error", Error: "inbox endpoint not found"})
+	}
+}
+
+func (s *Server) inboxStore(runtimeName string) *inbox.Store {
+	if s.config == nil || s.config.Agents == nil {
+		return nil
+	}
+	path, ok := s.config.Agents.RuntimeInboxes()[runtimeName]
+	if !ok {
+		return nil
+	}
+	return inbox.Open(path)
+}
+
+// countPendingInbox counts the pending items in the inbox at dir that were
+// routed to agentName. Listing does not reap expired leases, so the count
+// leaves the inbox unchanged.
+func countPendingInbox(dir, agentName string) int {
+	if strings.TrimSpace(dir) == "" {
+		return 0
+	}
+	items, err := inbox.Open(dir).List(inbox.StatePending)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, item := range items {
+		if item.SelectedAgent == agentName {
+			count++
+		}
+	}
+	return count
+}
+
+func requireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeJSON(w, http.StatusMethodNotAllowed, inboxItemResponse{Status: "error", Error: "method not allowed"})
+	return false
+}
+
+func writeInboxItem(w http.ResponseWriter, item inbox.Item, err error) {
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, inbox.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		writeJSON(w, statusCode, inboxItemResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, inboxItemResponse{Status: "ok", Item: &item})
+}
+
+// inboxStatusFor summarizes an inbox for /status. Errors are reported inline
+// so a broken inbox does not hide the rest of the runtime status.
+func inboxStatusFor(path string) *inboxStatus {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	stats, err := inbox.Open(path).Stats()
+	if err != nil {
+		return &inboxStatus{Error: err.Error()}
+	}
+	status := &inboxStatus{
+		Pending: stats.Pending,
+		Claimed: stats.Claimed,
+		Done:    stats.Done,
+		Failed:  stats.Failed,
+	}
+	if !stats.OldestPendingAt.IsZero() {
+		status.OldestPendingAt = formatStatusTime(stats.OldestPendingAt)
+		status.OldestPendingAgeSeconds = int64(time.Since(stats.OldestPendingAt).Seconds())
+	}
+	return status
+}
+
+func (s *Server) inboxRedeliveryStatus(runtimeName string) *inboxRedeliveryStatus {
+	if s.agentManager == nil {
+		return nil
+	}
+	status := s.agentManager.InboxRedeliveryStatus(runtimeName)
+	if status == nil {
+		return nil
+	}
+	return &inboxRedeliveryStatus{
+		Running:        status.Running,
+		LastStartedAt:  formatStatusTime(status.LastStartedAt),
+		LastFinishedAt: formatStatusTime(status.LastFinishedAt),
+		Delivered:      status.Delivered,
+		Skipped:        status.Skipped,
+		LastError:      status.LastError,
+	}
+}
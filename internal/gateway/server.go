@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,16 +11,19 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/fractalmind-ai/fractalbot/internal/agent"
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
 	"github.com/fractalmind-ai/fractalbot/internal/bus"
 	"github.com/fractalmind-ai/fractalbot/internal/channels"
 	"github.com/fractalmind-ai/fractalbot/internal/config"
 	"github.com/fractalmind-ai/fractalbot/internal/heartbeat"
+	"github.com/fractalmind-ai/fractalbot/internal/plugin"
 	"github.com/gorilla/websocket"
 )
 
@@ -33,6 +37,7 @@ type Server struct {
 	agentManager *agent.Manager
 	messageBus   *bus.MessageBus
 	heartbeat    *heartbeat.Scheduler
+	plugins      *plugin.Host
 	startTime    time.Time
 }
 
@@ -49,18 +54,42 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	agentManager := agent.NewManager(cfg.Agents)
 	agentManager.ChannelManager = channelManager
 
+	// Out-of-process adapters register like built-in ones.
+	pluginHost := plugin.NewHost(cfg.Channels, cfg.Agents)
+	if err := pluginHost.RegisterChannels(channelManager.Registry()); err != nil {
+		return nil, fmt.Errorf("register channel plugins: %w", err)
+	}
+	if err := pluginHost.RegisterRuntimes(agentManager); err != nil {
+		return nil, fmt.Errorf("register runtime plugins: %w", err)
+	}
+
 	var heartbeatConfig *config.HeartbeatConfig
 	var workspace string
 	if cfg.Agents != nil {
 		heartbeatConfig = cfg.Agents.Heartbeat
 		workspace = cfg.Agents.Workspace
 	}
-	heartbeatScheduler, err := heartbeat.New(heartbeatConfig, workspace, agentManager)
+	heartbeatScheduler, err := heartbeat.New(heartbeatConfig, workspace, agentManager, func(job *config.HeartbeatJobConfig) error {
+		return config.ValidateHeartbeatJob(cfg.Agents, job)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("initialize heartbeat scheduler: %w", err)
 	}
 	if heartbeatScheduler != nil {
-		agentManager.SetInboundRoutedHook(heartbeatScheduler.ResetForInbound)
+		agentManager.SetInboundRoutedHook(func(runtimeName, agentName string) {
+			heartbeatScheduler.RecordActivity(runtimeName, agentName, heartbeat.ActivityInbound)
+			heartbeatScheduler.ResetForInbound(runtimeName, agentName)
+		})
+		agentManager.SetReplyRelayedHook(func(runtimeName, agentName string) {
+			heartbeatScheduler.RecordActivity(runtimeName, agentName, heartbeat.ActivityOutbound)
+		})
+		agentManager.SetHeartbeatReplyHook(heartbeatScheduler.ApplyReply)
+		heartbeatScheduler.SetBusyCheck(agentManager.AgentBusy)
+		inboxes := cfg.Agents.RuntimeInboxes()
+		heartbeatScheduler.SetInboxCounter(func(runtimeName, agentName string) int {
+			return countPendingInbox(inboxes[runtimeName], agentName)
+		})
+		channelManager.SetHeartbeatControl(heartbeatChatControl{scheduler: heartbeatScheduler})
 	}
 
 	// Initialize message bus — decouples channels from agent router
@@ -70,6 +99,13 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	// Wire bus as the inbound handler (bus implements IncomingMessageHandler)
 	channelManager.SetHandler(messageBus)
 
+	if heartbeatScheduler != nil {
+		heartbeatScheduler.SetNotifier(func(ctx context.Context, channel, to, text string) error {
+			_, err := messageBus.PublishOutbound(ctx, channel, channels.OutboundMessage{To: to, Text: text})
+			return err
+		})
+	}
+
 	return &Server{
 		config: cfg,
 		upgrader: websocket.Upgrader{
@@ -81,6 +117,7 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		agentManager: agentManager,
 		messageBus:   messageBus,
 		heartbeat:    heartbeatScheduler,
+		plugins:      pluginHost,
 	}, nil
 }
 
@@ -96,8 +133,12 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// Status endpoint
 	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/api/v1/agents", s.handleAgents)
 	mux.HandleFunc("/api/v1/message/send", s.handleMessageSend)
-	mux.HandleFunc("/api/v1/heartbeat/jobs/", s.handleHeartbeatCron)
+	mux.HandleFunc("/api/v1/heartbeat/jobs", s.handleHeartbeatJobs)
+	mux.HandleFunc("/api/v1/heartbeat/jobs/", s.handleHeartbeatJob)
+	mux.HandleFunc("/api/v1/inbox/", s.handleInbox)
+	mux.HandleFunc("/api/v1/tasks/", s.handleTask)
 
 	if s.startTime.IsZero() {
 		s.startTime = time.Now()
@@ -112,6 +153,9 @@ func (s *Server) Start(ctx context.Context) error {
 		IdleTimeout:       60 * time.Second,
 	}
 
+	// Start plugins before the channels and runtimes that wrap them
+	s.plugins.Start(ctx)
+
 	// Start channels
 	if s.agentManager.ChannelManager != nil {
 		if err := s.agentManager.ChannelManager.Start(ctx); err != nil {
@@ -163,6 +207,9 @@ func (s *Server) Stop() error {
 			return fmt.Errorf("failed to stop agent manager: %w", err)
 		}
 	}
+	if err := s.plugins.Stop(ctx); err != nil {
+		return fmt.Errorf("failed to stop plugins: %w", err)
+	}
 
 	// Disconnect all clients
 	clients := s.snapshotClients()
@@ -264,9 +311,25 @@ type healthResponse struct {
 	Status            string               `json:"status"`
 	Uptime            string               `json:"uptime"`
 	Channels          []healthChannelEntry `json:"channels"`
+	Plugins           []healthPluginEntry  `json:"plugins,omitempty"`
+	ClaudeDesktop     *healthReadiness     `json:"claude_desktop,omitempty"`
 	MessagesProcessed int64                `json:"messages_processed"`
 }
 
+type healthReadiness struct {
+	Available     bool   `json:"available"`
+	LastCheckedAt string `json:"last_checked_at,omitempty"`
+	LastChangedAt string `json:"last_changed_at,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+}
+
+type healthPluginEntry struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	State   string `json:"state"`
+	Healthy bool   `json:"healthy"`
+}
+
 type healthChannelEntry struct {
 	Name    string `json:"name"`
 	Running bool   `json:"running"`
@@ -294,10 +357,32 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var pluginEntries []healthPluginEntry
+	for _, status := range s.plugins.Status() {
+		pluginEntries = append(pluginEntries, healthPluginEntry{
+			Name:    status.Name,
+			Kind:    status.Kind,
+			State:   status.State,
+			Healthy: status.Healthy,
+		})
+	}
+
+	var claudeReadiness *healthReadiness
+	if ready := s.claudeDesktopReadiness(); ready != nil {
+		claudeReadiness = &healthReadiness{
+			Available:     ready.Available,
+			LastCheckedAt: ready.LastCheckedAt,
+			LastChangedAt: ready.LastChangedAt,
+			LastError:     ready.LastError,
+		}
+	}
+
 	writeJSON(w, http.StatusOK, healthResponse{
 		Status:            "ok",
 		Uptime:            uptime.String(),
 		Channels:          chEntries,
+		Plugins:           pluginEntries,
+		ClaudeDesktop:     claudeReadiness,
 		MessagesProcessed: messagesProcessed,
 	})
 }
@@ -309,6 +394,7 @@ type statusResponse struct {
 	Channels      []channelStatus   `json:"channels,omitempty"`
 	Agents        *agentStatus      `json:"agents,omitempty"`
 	Heartbeat     *heartbeat.Status `json:"heartbeat,omitempty"`
+	Plugins       []plugin.Status   `json:"plugins,omitempty"`
 }
 
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
@@ -324,6 +410,7 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 		Channels:      s.channelStatus(),
 		Agents:        s.agentStatus(),
 		Heartbeat:     s.heartbeat.Status(),
+		Plugins:       s.plugins.Status(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -336,6 +423,10 @@ type messageSendRequest struct {
 	Text     string   `json:"text"`
 	ThreadTS string   `json:"thread_ts,omitempty"`
 	Images   []string `json:"images,omitempty"`
+	// Agent names the agent sending the message, counted as activity for
+	// activity-aware heartbeats.
+	Agent   string `json:"agent,omitempty"`
+	Runtime string `json:"runtime,omitempty"`
 }
 
 type messageSendResponse struct {
@@ -368,6 +459,8 @@ func (s *Server) handleMessageSend(w http.ResponseWriter, r *http.Request) {
 	request.To = strings.TrimSpace(request.To)
 	request.Text = strings.TrimSpace(request.Text)
 	request.ThreadTS = strings.TrimSpace(request.ThreadTS)
+	request.Agent = strings.TrimSpace(request.Agent)
+	request.Runtime = strings.TrimSpace(request.Runtime)
 	trimmedImages := make([]string, 0, len(request.Images))
 	for _, path := range request.Images {
 		if trimmed := strings.TrimSpace(path); trimmed != "" {
@@ -416,6 +509,10 @@ func (s *Server) handleMessageSend(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if request.Agent != "" {
+		s.heartbeat.RecordActivity(request.Runtime, request.Agent, heartbeat.ActivityOutbound)
+	}
+
 	resp := messageSendResponse{
 		Status:   "ok",
 		Channel:  request.Channel,
@@ -526,6 +623,25 @@ func imageSendChannelSupported(channel string) bool {
 	return channel == "feishu"
 }
 
+// errorResponse is the error body shared by the local API handlers.
+type errorResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// decodeJSONRequest decodes a local API request body into out. An empty body
+// leaves out unchanged; anything else that is not valid JSON for out is
+// answered with 400 and reported as false.
+func decodeJSONRequest(w http.ResponseWriter, r *http.Request, out interface{}) bool {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, 64*1024))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Status: "error", Error: "invalid JSON payload"})
+		return false
+	}
+	return true
+}
+
 func writeJSON(w http.ResponseWriter, statusCode int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -558,6 +674,8 @@ type agentStatus struct {
 	OhMyCode            *ohMyCodeStatus      `json:"oh_my_code,omitempty"`
 	CodexAppCDP         *codexAppCDPStatus   `json:"codex_app_cdp,omitempty"`
 	ClaudeDesktop       *claudeDesktopStatus `json:"claude_desktop,omitempty"`
+	// Runtimes holds status reported by registered runtime adapters.
+	Runtimes map[string]interface{} `json:"runtimes,omitempty"`
 }
 
 type agentRoutingStatus struct {
@@ -582,6 +700,22 @@ type ohMyCodeStatus struct {
 	DefaultAgent        string                 `json:"default_agent,omitempty"`
 	AllowedAgents       []string               `json:"allowed_agents,omitempty"`
 	LastRouting         *ohMyCodeRoutingStatus `json:"last_routing,omitempty"`
+	Tasks               *ohMyCodeTasksStatus   `json:"tasks,omitempty"`
+	Workspaces          []ohMyCodeWorkspace    `json:"workspaces,omitempty"`
+}
+
+type ohMyCodeWorkspace struct {
+	Name          string   `json:"name"`
+	DefaultAgent  string   `json:"default_agent,omitempty"`
+	AllowedAgents []string `json:"allowed_agents,omitempty"`
+}
+
+type ohMyCodeTasksStatus struct {
+	Enabled             bool                 `json:"enabled"`
+	PollIntervalSeconds int                  `json:"poll_interval_seconds,omitempty"`
+	TimeoutSeconds      int                  `json:"timeout_seconds,omitempty"`
+	Running             int                  `json:"running"`
+	Items               []ohMyCodeTaskStatus `json:"items,omitempty"`
 }
 
 type codexAppCDPStatus struct {
@@ -594,6 +728,9 @@ type codexAppCDPStatus struct {
 	ResolvedConversation *codexAppCDPResolvedTarget `json:"resolved_conversation,omitempty"`
 	InboxConfigured      bool                       `json:"inbox_configured"`
 	FallbackToInbox      bool                       `json:"fallback_to_inbox"`
+	Inbox                *inboxStatus               `json:"inbox,omitempty"`
+	Redelivery           *inboxRedeliveryStatus     `json:"redelivery,omitempty"`
+	ResponseRelay        *responseRelayStatus       `json:"response_relay,omitempty"`
 	RepairPolicy         string                     `json:"repair_policy,omitempty"`
 	CheckOnIncoming      bool                       `json:"check_on_incoming_message"`
 	Watch                codexAppCDPWatch           `json:"watch"`
@@ -603,15 +740,74 @@ type codexAppCDPStatus struct {
 	LastRouting          *agentRoutingStatus        `json:"last_routing,omitempty"`
 }
 
+type responseRelayStatus struct {
+	Enabled       bool     `json:"enabled"`
+	Stream        bool     `json:"stream"`
+	Channels      []string `json:"channels,omitempty"`
+	StillWorkingS int      `json:"still_working_seconds,omitempty"`
+	Active        int      `json:"active"`
+	Relayed       int      `json:"relayed"`
+	LastRelayedAt string   `json:"last_relayed_at,omitempty"`
+	LastError     string   `json:"last_error,omitempty"`
+}
+
 type claudeDesktopStatus struct {
-	Enabled          bool     `json:"enabled"`
-	CDPEndpoint      string   `json:"cdp_endpoint,omitempty"`
-	TargetSelector   string   `json:"target_selector,omitempty"`
-	InboxConfigured  bool     `json:"inbox_configured"`
-	FallbackToInbox  bool     `json:"fallback_to_inbox"`
-	DefaultAgent     string   `json:"default_agent,omitempty"`
-	AllowedAgents    []string `json:"allowed_agents,omitempty"`
-	DeliveryTimeoutS int      `json:"delivery_timeout_seconds,omitempty"`
+	Enabled              bool                           `json:"enabled"`
+	CDPEndpoint          string                         `json:"cdp_endpoint,omitempty"`
+	TargetSelector       string                         `json:"target_selector,omitempty"`
+	InboxConfigured      bool                           `json:"inbox_configured"`
+	FallbackToInbox      bool                           `json:"fallback_to_inbox"`
+	Inbox                *inboxStatus                   `json:"inbox,omitempty"`
+	DefaultAgent         string                         `json:"default_agent,omitempty"`
+	AllowedAgents        []string                       `json:"allowed_agents,omitempty"`
+	DeliveryTimeoutS     int                            `json:"delivery_timeout_seconds,omitempty"`
+	ResponseRelay        *responseRelayStatus           `json:"response_relay,omitempty"`
+	ConversationID       string                         `json:"conversation_id,omitempty"`
+	ConversationMode     string                         `json:"conversation_mode,omitempty"`
+	Targets              map[string]claudeDesktopTarget `json:"targets,omitempty"`
+	ResolvedConversation *claudeDesktopResolvedTarget   `json:"resolved_conversation,omitempty"`
+	Watch                *claudeDesktopWatch            `json:"watch,omitempty"`
+	Readiness            *claudeDesktopReady            `json:"readiness,omitempty"`
+}
+
+type claudeDesktopWatch struct {
+	Enabled         bool   `json:"enabled"`
+	Running         bool   `json:"running"`
+	IntervalSeconds int    `json:"interval_seconds,omitempty"`
+	AlertChannel    string `json:"alert_channel,omitempty"`
+}
+
+type claudeDesktopReady struct {
+	Available       bool                      `json:"available"`
+	TargetCount     int                       `json:"target_count"`
+	ChatTargetCount int                       `json:"chat_target_count"`
+	LastCheckedAt   string                    `json:"last_checked_at,omitempty"`
+	LastChangedAt   string                    `json:"last_changed_at,omitempty"`
+	LastError       string                    `json:"last_error,omitempty"`
+	History         []claudeDesktopReadyEvent `json:"history,omitempty"`
+}
+
+type claudeDesktopReadyEvent struct {
+	At        string `json:"at"`
+	Available bool   `json:"available"`
+	Error     string `json:"error,omitempty"`
+}
+
+type claudeDesktopTarget struct {
+	ProjectID      string `json:"project_id,omitempty"`
+	ConversationID string `json:"conversation_id,omitempty"`
+}
+
+type claudeDesktopResolvedTarget struct {
+	Mode           string `json:"mode"`
+	Agent          string `json:"agent,omitempty"`
+	ID             string `json:"id,omitempty"`
+	ProjectID      string `json:"project_id,omitempty"`
+	ThreadKey      string `json:"thread_key,omitempty"`
+	Source         string `json:"source,omitempty"`
+	Threads        int    `json:"threads"`
+	LastResolvedAt string `json:"last_resolved_at,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
 }
 
 type codexAppCDPTargetProject struct {
@@ -654,18 +850,13 @@ func (s *Server) channelStatus() []channelStatus {
 		return nil
 	}
 
-	statuses := make([]channelStatus, 0, 5)
-	getChannel := func(name string) channels.Channel {
-		if s.agentManager == nil || s.agentManager.ChannelManager == nil {
-			return nil
-		}
-		return s.agentManager.ChannelManager.Get(name)
+	var manager *channels.Manager
+	if s.agentManager != nil {
+		manager = s.agentManager.ChannelManager
 	}
-	isRunning := func(ch channels.Channel) bool {
-		if ch == nil {
-			return false
-		}
-		return ch.IsRunning()
+	if manager == nil {
+		// Without a running channel manager, report configured sections only.
+		manager = channels.NewManager(s.config.Channels, s.config.Agents)
 	}
 	telemetry := func(ch channels.Channel) (string, string) {
 		if ch == nil {
@@ -677,68 +868,16 @@ func (s *Server) channelStatus() []channelStatus {
 		return "", ""
 	}
 
-	if s.config.Channels.Telegram != nil {
-		mode := telegramModeFromConfig(s.config.Channels.Telegram)
-		webhookStatus := telegramWebhookStatusFromConfig(s.config.Channels.Telegram)
-		ch := getChannel("telegram")
-		lastError, lastActivity := telemetry(ch)
-		if bot, ok := ch.(*channels.TelegramBot); ok {
-			if bot.Mode() != "" {
-				mode = bot.Mode()
-			}
-			status := bot.WebhookStatus()
-			webhookStatus = &status
-		}
-		statuses = append(statuses, channelStatus{
-			Name:         "telegram",
-			Enabled:      s.config.Channels.Telegram.Enabled,
-			Running:      isRunning(ch),
-			Mode:         mode,
-			Webhook:      webhookStatus,
-			LastError:    lastError,
-			LastActivity: lastActivity,
-		})
-	}
-	if s.config.Channels.Slack != nil {
-		ch := getChannel("slack")
-		lastError, lastActivity := telemetry(ch)
-		statuses = append(statuses, channelStatus{
-			Name:         "slack",
-			Enabled:      s.config.Channels.Slack.Enabled,
-			Running:      isRunning(ch),
-			LastError:    lastError,
-			LastActivity: lastActivity,
-		})
-	}
-	if s.config.Channels.Feishu != nil {
-		ch := getChannel("feishu")
-		lastError, lastActivity := telemetry(ch)
-		statuses = append(statuses, channelStatus{
-			Name:         "feishu",
-			Enabled:      s.config.Channels.Feishu.Enabled,
-			Running:      isRunning(ch),
-			LastError:    lastError,
-			LastActivity: lastActivity,
-		})
-	}
-	if s.config.Channels.Discord != nil {
-		ch := getChannel("discord")
-		lastError, lastActivity := telemetry(ch)
-		statuses = append(statuses, channelStatus{
-			Name:         "discord",
-			Enabled:      s.config.Channels.Discord.Enabled,
-			Running:      isRunning(ch),
-			LastError:    lastError,
-			LastActivity: lastActivity,
-		})
-	}
-	if s.config.Channels.IMessage != nil {
-		ch := getChannel("imessage")
-		lastError, lastActivity := telemetry(ch)
+	configured := manager.ConfiguredStatus()
+	statuses := make([]channelStatus, 0, len(configured))
+	for _, entry := range configured {
+		lastError, lastActivity := telemetry(manager.Get(entry.Name))
 		statuses = append(statuses, channelStatus{
-			Name:         "imessage",
-			Enabled:      s.config.Channels.IMessage.Enabled,
-			Running:      isRunning(ch),
+			Name:         entry.Name,
+			Enabled:      entry.Enabled,
+			Running:      entry.Running,
+			Mode:         entry.Status.Mode,
+			Webhook:      entry.Status.Webhook,
 			LastError:    lastError,
 			LastActivity: lastActivity,
 		})
@@ -747,30 +886,51 @@ func (s *Server) channelStatus() []channelStatus {
 	return statuses
 }
 
-func telegramModeFromConfig(cfg *config.TelegramConfig) string {
-	if cfg == nil {
-		return ""
+func (s *Server) responseRelayStatus(runtimeName string, cfg config.ResponseRelayConfig) *responseRelayStatus {
+	if !cfg.Enabled {
+		return nil
+	}
+	status := &responseRelayStatus{Enabled: true, Stream: cfg.Stream, StillWorkingS: cfg.StillWorkingSeconds}
+	if len(cfg.Channels) > 0 {
+		status.Channels = append([]string{}, cfg.Channels...)
 	}
-	mode := strings.ToLower(strings.TrimSpace(cfg.Mode))
-	if mode == "" || mode == "auto" {
-		if strings.TrimSpace(cfg.WebhookListenAddr) != "" || strings.TrimSpace(cfg.WebhookPublicURL) != "" {
-			return "webhook"
+	if s.agentManager != nil {
+		if relay := s.agentManager.ResponseRelayStatus(runtimeName); relay != nil {
+			status.Active = relay.Active
+			status.Relayed = relay.Relayed
+			status.LastRelayedAt = formatStatusTime(relay.LastRelayedAt)
+			status.LastError = relay.LastError
 		}
-		return "polling"
 	}
-	return mode
+	return status
 }
 
-func telegramWebhookStatusFromConfig(cfg *config.TelegramConfig) *channels.TelegramWebhookStatus {
-	if cfg == nil {
+// claudeDesktopReadiness returns the last watchdog result, or nil before the
+// first check.
+func (s *Server) claudeDesktopReadiness() *claudeDesktopReady {
+	if s.agentManager == nil {
+		return nil
+	}
+	readiness := s.agentManager.ClaudeDesktopReadinessStatus()
+	if readiness == nil || readiness.LastCheckedAt.IsZero() {
 		return nil
 	}
-	return &channels.TelegramWebhookStatus{
-		RegisterOnStart:      cfg.WebhookRegisterOnStart,
-		DeleteOnStop:         cfg.WebhookDeleteOnStop,
-		PublicURLConfigured:  strings.TrimSpace(cfg.WebhookPublicURL) != "",
-		ListenAddrConfigured: strings.TrimSpace(cfg.WebhookListenAddr) != "",
+	ready := &claudeDesktopReady{
+		Available:       readiness.Available,
+		TargetCount:     readiness.TargetCount,
+		ChatTargetCount: readiness.ChatTargetCount,
+		LastCheckedAt:   formatStatusTime(readiness.LastCheckedAt),
+		LastChangedAt:   formatStatusTime(readiness.LastChangedAt),
+		LastError:       readiness.LastError,
+	}
+	for _, event := range readiness.History {
+		ready.History = append(ready.History, claudeDesktopReadyEvent{
+			At:        formatStatusTime(event.At),
+			Available: event.Available,
+			Error:     event.Error,
+		})
 	}
+	return ready
 }
 
 func formatStatusTime(value time.Time) string {
@@ -779,6 +939,7 @@ func formatStatusTime(value time.Time) string {
 	}
 	return value.UTC().Format(time.RFC3339)
 }
+
 func (s *Server) agentStatus() *agentStatus {
 	if s.config == nil || s.config.Agents == nil {
 		return nil
@@ -808,6 +969,36 @@ func (s *Server) agentStatus() *agentStatus {
 		if status.LastRouting != nil && status.LastRouting.Backend == "ohMyCode" {
 			status.OhMyCode.LastRouting = status.LastRouting
 		}
+		names := make([]string, 0, len(ohMyCode.Workspaces))
+		for name := range ohMyCode.Workspaces {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ws := ohMyCode.Workspaces[name]
+			status.OhMyCode.Workspaces = append(status.OhMyCode.Workspaces, ohMyCodeWorkspace{
+				Name:          name,
+				DefaultAgent:  strings.TrimSpace(ws.DefaultAgent),
+				AllowedAgents: append([]string(nil), ws.AllowedAgents...),
+			})
+		}
+		if ohMyCode.Tasks.Enabled {
+			tasks := &ohMyCodeTasksStatus{
+				Enabled:             true,
+				PollIntervalSeconds: ohMyCode.Tasks.PollIntervalSeconds,
+				TimeoutSeconds:      ohMyCode.Tasks.TimeoutSeconds,
+			}
+			if s.agentManager != nil {
+				items := s.agentManager.OhMyCodeTasks()
+				for _, item := range items {
+					if item.State == agent.OhMyCodeTaskRunning {
+						tasks.Running++
+					}
+				}
+				tasks.Items = ohMyCodeTaskStatuses(items)
+			}
+			status.OhMyCode.Tasks = tasks
+		}
 	}
 
 	if s.config.Agents.CodexAppCDP != nil {
@@ -820,6 +1011,9 @@ func (s *Server) agentStatus() *agentStatus {
 			ConversationID:  strings.TrimSpace(codex.ConversationID),
 			InboxConfigured: strings.TrimSpace(codex.InboxPath) != "",
 			FallbackToInbox: codex.FallbackToInbox,
+			Inbox:           inboxStatusFor(codex.InboxPath),
+			Redelivery:      s.inboxRedeliveryStatus("codexAppCDP"),
+			ResponseRelay:   s.responseRelayStatus("codexAppCDP", codex.ResponseRelay),
 			RepairPolicy:    codexRepairPolicy(codex),
 			CheckOnIncoming: codexCheckOnIncoming(codex),
 			Watch: codexAppCDPWatch{
@@ -879,34 +1073,73 @@ func (s *Server) agentStatus() *agentStatus {
 			TargetSelector:   strings.TrimSpace(claude.TargetSelector),
 			InboxConfigured:  strings.TrimSpace(claude.InboxPath) != "",
 			FallbackToInbox:  claude.FallbackToInbox,
+			Inbox:            inboxStatusFor(claude.InboxPath),
 			DefaultAgent:     strings.TrimSpace(claude.DefaultAgent),
 			DeliveryTimeoutS: claude.DeliveryTimeoutSeconds,
+			ResponseRelay:    s.responseRelayStatus("claudeDesktop", claude.ResponseRelay),
 		}
 		if len(claude.AllowedAgents) > 0 {
 			status.ClaudeDesktop.AllowedAgents = append([]string{}, claude.AllowedAgents...)
 		}
+		status.ClaudeDesktop.ConversationID = strings.TrimSpace(claude.ConversationID)
+		status.ClaudeDesktop.ConversationMode = strings.TrimSpace(claude.ConversationMode)
+		if status.ClaudeDesktop.ConversationMode == "" {
+			status.ClaudeDesktop.ConversationMode = "active"
+		}
+		for agentName, target := range claude.Targets {
+			if status.ClaudeDesktop.Targets == nil {
+				status.ClaudeDesktop.Targets = make(map[string]claudeDesktopTarget, len(claude.Targets))
+			}
+			status.ClaudeDesktop.Targets[agentName] = claudeDesktopTarget{
+				ProjectID:      strings.TrimSpace(target.ProjectID),
+				ConversationID: strings.TrimSpace(target.ConversationID),
+			}
+		}
+		status.ClaudeDesktop.Watch = &claudeDesktopWatch{
+			Enabled:         claude.Enabled && strings.TrimSpace(claude.CDPEndpoint) != "" && (claude.Watch.Enabled == nil || *claude.Watch.Enabled),
+			IntervalSeconds: claude.Watch.IntervalSeconds,
+			AlertChannel:    strings.TrimSpace(claude.Watch.Alert.Channel),
+		}
+		status.ClaudeDesktop.Readiness = s.claudeDesktopReadiness()
+		if s.agentManager != nil {
+			if readiness := s.agentManager.ClaudeDesktopReadinessStatus(); readiness != nil {
+				status.ClaudeDesktop.Watch.Running = readiness.WatchRunning
+				status.ClaudeDesktop.Watch.IntervalSeconds = readiness.IntervalSeconds
+			}
+			if resolved := s.agentManager.ClaudeDesktopResolvedConversationStatus(); resolved != nil {
+				status.ClaudeDesktop.ResolvedConversation = &claudeDesktopResolvedTarget{
+					Mode:           resolved.Mode,
+					Agent:          resolved.Agent,
+					ID:             resolved.ID,
+					ProjectID:      resolved.ProjectID,
+					ThreadKey:      resolved.ThreadKey,
+					Source:         resolved.Source,
+					Threads:        resolved.Threads,
+					LastResolvedAt: formatStatusTime(resolved.LastResolvedAt),
+					LastError:      resolved.LastError,
+				}
+			}
+		}
+	}
+
+	if s.agentManager != nil {
+		for _, rt := range s.agentManager.Runtimes() {
+			provider, ok := rt.(agentruntime.StatusProvider)
+			if !ok {
+				continue
+			}
+			if status.Runtimes == nil {
+				status.Runtimes = make(map[string]interface{})
+			}
+			status.Runtimes[rt.Name()] = provider.RuntimeStatus()
+		}
 	}
 
 	return status
 }
 
 func activeAgentRouterName(cfg *config.AgentsConfig) string {
-	if cfg == nil {
-		return ""
-	}
-	if router := strings.TrimSpace(cfg.Router); router != "" {
-		return router
-	}
-	if cfg.OhMyCode != nil && cfg.OhMyCode.Enabled {
-		return "ohMyCode"
-	}
-	if cfg.CodexAppCDP != nil && cfg.CodexAppCDP.Enabled {
-		return "codexAppCDP"
-	}
-	if cfg.ClaudeDesktop != nil && cfg.ClaudeDesktop.Enabled {
-		return "claudeDesktop"
-	}
-	return ""
+	return cfg.ActiveRouter()
 }
 
 func codexRepairPolicy(cfg *config.CodexAppCDPConfig) string {
@@ -152,6 +152,10 @@ type statusPayload struct {
 			DefaultAgent     string   `json:"default_agent"`
 			AllowedAgents    []string `json:"allowed_agents"`
 			DeliveryTimeoutS int      `json:"delivery_timeout_seconds"`
+			ConversationMode string   `json:"conversation_mode"`
+			Targets          map[string]struct {
+				ProjectID string `json:"project_id"`
+			} `json:"targets"`
 		} `json:"claude_desktop"`
 	} `json:"agents"`
 }
@@ -390,6 +394,8 @@ func TestStatusIncludesClaudeDesktopConfig(t *testing.T) {
 				DefaultAgent:           "main",
 				AllowedAgents:          []string{"main"},
 				DeliveryTimeoutSeconds: 20,
+				ConversationMode:       "perThread",
+				Targets:                map[string]config.ClaudeDesktopTargetConfig{"main": {ProjectID: "proj-1"}},
 			},
 		},
 	}
@@ -412,6 +418,9 @@ func TestStatusIncludesClaudeDesktopConfig(t *testing.T) {
 	if len(claude.AllowedAgents) != 1 || claude.AllowedAgents[0] != "main" {
 		t.Fatalf("unexpected allowed agents: %#v", claude.AllowedAgents)
 	}
+	if claude.ConversationMode != "perThread" || claude.Targets["main"].ProjectID != "proj-1" {
+		t.Fatalf("unexpected Claude Desktop targets: %#v", claude)
+	}
 }
 
 func TestStatusDoesNotExposeSecrets(t *testing.T) {
@@ -1139,6 +1148,138 @@ func TestHeartbeatProfileResetsAfterMatchingInboundRoute(t *testing.T) {
 	}
 }
 
+func TestHeartbeatJobsAPI(t *testing.T) {
+	server, err := NewServer(heartbeatGatewayConfig(t, filepath.Join(t.TempDir(), "inbox")))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	call := func(method, path, body string) (int, heartbeatJobsResponse) {
+		t.Helper()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.RemoteAddr = "127.0.0.1:12345"
+		response := httptest.NewRecorder()
+		if strings.HasPrefix(path, "/api/v1/heartbeat/jobs/") {
+			server.handleHeartbeatJob(response, request)
+		} else {
+			server.handleHeartbeatJobs(response, request)
+		}
+		var payload heartbeatJobsResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode %s %s: %v (%s)", method, path, err, response.Body.String())
+		}
+		return response.Code, payload
+	}
+
+	code, payload := call(http.MethodPost, "/api/v1/heartbeat/jobs", `{"id":"nightly","runtime":"codexAppCDP","agent":"main","text":"review PRs","cron":"0 2 * * *","timezone":"UTC"}`)
+	if code != http.StatusOK || payload.Job == nil || payload.Job.Source != "api" || payload.Job.NextRunAt == "" {
+		t.Fatalf("create status=%d payload=%#v", code, payload)
+	}
+	if code, payload := call(http.MethodPost, "/api/v1/heartbeat/jobs", `{"id":"other","runtime":"codexAppCDP","agent":"intruder","text":"x","cron":"0 2 * * *","timezone":"UTC"}`); code != http.StatusBadRequest || !strings.Contains(payload.Error, "not allowed") {
+		t.Fatalf("expected allowlist rejection, status=%d payload=%#v", code, payload)
+	}
+	if code, _ := call(http.MethodPost, "/api/v1/heartbeat/jobs", `{"id":"nightly","runtime":"codexAppCDP","agent":"main","text":"x","cron":"0 2 * * *","timezone":"UTC"}`); code != http.StatusConflict {
+		t.Fatalf("expected duplicate conflict, status=%d", code)
+	}
+
+	code, payload = call(http.MethodPut, "/api/v1/heartbeat/jobs/nightly", `{"cron":"0 4 * * *"}`)
+	if code != http.StatusOK || payload.Job.ConfiguredCron != "0 4 * * *" {
+		t.Fatalf("update status=%d payload=%#v", code, payload)
+	}
+	if code, _ := call(http.MethodPut, "/api/v1/heartbeat/jobs/cloudbank-main", `{"cron":"0 4 * * *"}`); code != http.StatusConflict {
+		t.Fatalf("expected config job conflict, status=%d", code)
+	}
+
+	code, payload = call(http.MethodGet, "/api/v1/heartbeat/jobs", "")
+	if code != http.StatusOK || len(payload.Jobs) != 2 {
+		t.Fatalf("list status=%d payload=%#v", code, payload)
+	}
+	if code, payload := call(http.MethodGet, "/api/v1/heartbeat/jobs/nightly", ""); code != http.StatusOK || payload.Job == nil || payload.Job.ID != "nightly" {
+		t.Fatalf("get status=%d payload=%#v", code, payload)
+	}
+
+	if code, _ := call(http.MethodDelete, "/api/v1/heartbeat/jobs/nightly", ""); code != http.StatusOK {
+		t.Fatalf("delete status=%d", code)
+	}
+	if code, _ := call(http.MethodGet, "/api/v1/heartbeat/jobs/nightly", ""); code != http.StatusNotFound {
+		t.Fatalf("expected deleted job to be gone, status=%d", code)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/heartbeat/jobs", nil)
+	request.RemoteAddr = "203.0.113.20:4567"
+	response := httptest.NewRecorder()
+	server.handleHeartbeatJobs(response, request)
+	if response.Code != http.StatusForbidden {
+		t.Fatalf("remote status=%d", response.Code)
+	}
+}
+
+func TestHeartbeatJobControlAPI(t *testing.T) {
+	server, err := NewServer(heartbeatGatewayConfig(t, filepath.Join(t.TempDir(), "inbox")))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	call := func(path, body string) (int, heartbeatJobsResponse) {
+		t.Helper()
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		request.RemoteAddr = "127.0.0.1:12345"
+		response := httptest.NewRecorder()
+		server.handleHeartbeatJob(response, request)
+		var payload heartbeatJobsResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode %s: %v (%s)", path, err, response.Body.String())
+		}
+		return response.Code, payload
+	}
+
+	code, payload := call("/api/v1/heartbeat/jobs/cloudbank-main/pause", `{"duration":"2h","reason":"incident"}`)
+	if code != http.StatusOK || payload.Job == nil || !payload.Job.Paused || payload.Job.PausedUntil == "" || payload.Job.PauseReason != "incident" || payload.Job.PausedBy != "local-api" {
+		t.Fatalf("pause status=%d payload=%#v", code, payload)
+	}
+	if code, payload := call("/api/v1/heartbeat/jobs/cloudbank-main/pause", `{"duration":"2h","until":"2030-01-01T00:00:00Z"}`); code != http.StatusBadRequest || !strings.Contains(payload.Error, "not both") {
+		t.Fatalf("expected conflicting pause end to fail, status=%d payload=%#v", code, payload)
+	}
+	code, payload = call("/api/v1/heartbeat/jobs/cloudbank-main/resume", "")
+	if code != http.StatusOK || payload.Job == nil || payload.Job.Paused || payload.Job.NextRunAt == "" {
+		t.Fatalf("resume status=%d payload=%#v", code, payload)
+	}
+	if code, _ := call("/api/v1/heartbeat/jobs/missing/run", ""); code != http.StatusNotFound {
+		t.Fatalf("run missing status=%d", code)
+	}
+	if code, _ := call("/api/v1/heartbeat/jobs/cloudbank-main/stop", ""); code != http.StatusNotFound {
+		t.Fatalf("unknown action status=%d", code)
+	}
+}
+
+func TestHeartbeatRunsAPI(t *testing.T) {
+	server, err := NewServer(heartbeatGatewayConfig(t, filepath.Join(t.TempDir(), "inbox")))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	call := func(method, path string) (int, heartbeatRunsResponse) {
+		t.Helper()
+		request := httptest.NewRequest(method, path, nil)
+		request.RemoteAddr = "127.0.0.1:12345"
+		response := httptest.NewRecorder()
+		server.handleHeartbeatJob(response, request)
+		var payload heartbeatRunsResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode %s: %v (%s)", path, err, response.Body.String())
+		}
+		return response.Code, payload
+	}
+
+	code, payload := call(http.MethodGet, "/api/v1/heartbeat/jobs/cloudbank-main/runs?limit=10")
+	if code != http.StatusOK || payload.Status != "ok" || payload.Runs == nil || len(payload.Runs) != 0 {
+		t.Fatalf("runs status=%d payload=%#v", code, payload)
+	}
+	if code, _ := call(http.MethodGet, "/api/v1/heartbeat/jobs/missing/runs"); code != http.StatusNotFound {
+		t.Fatalf("missing job status=%d", code)
+	}
+	if code, payload := call(http.MethodGet, "/api/v1/heartbeat/jobs/cloudbank-main/runs?limit=-1"); code != http.StatusBadRequest || !strings.Contains(payload.Error, "limit") {
+		t.Fatalf("bad limit status=%d payload=%#v", code, payload)
+	}
+}
+
 func heartbeatGatewayConfig(t *testing.T, inbox string) *config.Config {
 	t.Helper()
 	return &config.Config{
@@ -1172,3 +1313,396 @@ func heartbeatGatewayConfig(t *testing.T, inbox string) *config.Config {
 		},
 	}
 }
+
+func TestHealthAndStatusReportPlugins(t *testing.T) {
+	cfg := &config.Config{
+		Gateway:  &config.GatewayConfig{Bind: "127.0.0.1", Port: 0},
+		Channels: &config.ChannelsConfig{},
+		Agents: &config.AgentsConfig{
+			Plugins: []config.PluginConfig{{Name: "remote", Command: "/bin/false"}},
+		},
+	}
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if server.agentManager.Runtime("remote") == nil {
+		t.Fatal("expected runtime plugin to be registered with the agent manager")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", server.handleHealth)
+	mux.HandleFunc("/status", server.handleStatus)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	var health struct {
+		Plugins []struct {
+			Name    string `json:"name"`
+			Kind    string `json:"kind"`
+			State   string `json:"state"`
+			Healthy bool   `json:"healthy"`
+		} `json:"plugins"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode health response: %v", err)
+	}
+	if len(health.Plugins) != 1 || health.Plugins[0].Name != "remote" || health.Plugins[0].Kind != "runtime" || health.Plugins[0].State != "disabled" || health.Plugins[0].Healthy {
+		t.Fatalf("unexpected plugin health: %#v", health.Plugins)
+	}
+
+	resp, err = http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("status request failed: %v", err)
+	}
+	var status struct {
+		Plugins []struct {
+			Name     string `json:"name"`
+			Restarts int    `json:"restarts"`
+		} `json:"plugins"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode status response: %v", err)
+	}
+	if len(status.Plugins) != 1 || status.Plugins[0].Name != "remote" {
+		t.Fatalf("unexpected plugin status: %#v", status.Plugins)
+	}
+}
+
+func TestInboxAPIClaimAckAndStatus(t *testing.T) {
+	inboxDir := filepath.Join(t.TempDir(), "inbox")
+	if err := os.MkdirAll(inboxDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	receivedAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	for _, id := range []string{"a", "b"} {
+		envelope := fmt.Sprintf(`{"id":"env-%s","received_at":%q,"selected_agent":"main","text":"hello"}`, id, receivedAt)
+		if err := os.WriteFile(filepath.Join(inboxDir, id+".json"), []byte(envelope), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server, err := NewServer(heartbeatGatewayConfig(t, inboxDir))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.RemoteAddr = "127.0.0.1:12345"
+		response := httptest.NewRecorder()
+		server.handleInbox(response, request)
+		return response
+	}
+	decodeItem := func(response *httptest.ResponseRecorder) inboxItemResponse {
+		t.Helper()
+		var payload inboxItemResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode response: %v (%s)", err, response.Body.String())
+		}
+		return payload
+	}
+
+	claimed := call(http.MethodPost, "/api/v1/inbox/codexAppCDP/claim", `{"owner":"codex","lease_seconds":60}`)
+	claimPayload := decodeItem(claimed)
+	if claimed.Code != http.StatusOK || claimPayload.Item == nil || claimPayload.Item.State != "claimed" || claimPayload.Item.Owner != "codex" {
+		t.Fatalf("claim status=%d body=%s", claimed.Code, claimed.Body.String())
+	}
+	id := claimPayload.Item.ID
+
+	shown := call(http.MethodGet, "/api/v1/inbox/codexAppCDP/"+id, "")
+	if shown.Code != http.StatusOK || !strings.Contains(shown.Body.String(), `"text":"hello"`) {
+		t.Fatalf("show status=%d body=%s", shown.Code, shown.Body.String())
+	}
+
+	acked := call(http.MethodPost, "/api/v1/inbox/codexAppCDP/"+id+"/ack", `{"status":"failed","error":"agent offline"}`)
+	if ackPayload := decodeItem(acked); acked.Code != http.StatusOK || ackPayload.Item == nil || ackPayload.Item.State != "failed" {
+		t.Fatalf("ack status=%d body=%s", acked.Code, acked.Body.String())
+	}
+	if missing := call(http.MethodPost, "/api/v1/inbox/codexAppCDP/"+id+"/ack", `{}`); missing.Code != http.StatusNotFound {
+		t.Fatalf("second ack status=%d body=%s", missing.Code, missing.Body.String())
+	}
+
+	listed := call(http.MethodGet, "/api/v1/inbox/codexAppCDP?state=failed", "")
+	var listPayload inboxListResponse
+	if err := json.Unmarshal(listed.Body.Bytes(), &listPayload); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(listPayload.Items) != 1 || listPayload.Items[0].Error != "agent offline" || listPayload.Inbox == nil || listPayload.Inbox.Pending != 1 {
+		t.Fatalf("unexpected list: %s", listed.Body.String())
+	}
+
+	statusRecorder := httptest.NewRecorder()
+	server.handleStatus(statusRecorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status struct {
+		Agents struct {
+			CodexAppCDP struct {
+				Inbox *inboxStatus `json:"inbox"`
+			} `json:"codex_app_cdp"`
+		} `json:"agents"`
+	}
+	if err := json.Unmarshal(statusRecorder.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	inbox := status.Agents.CodexAppCDP.Inbox
+	if inbox == nil || inbox.Pending != 1 || inbox.Failed != 1 || inbox.OldestPendingAt == "" || inbox.OldestPendingAgeSeconds < 3500 {
+		t.Fatalf("unexpected inbox status: %#v", inbox)
+	}
+}
+
+func TestInboxAPIRejectsRemoteAndUnknownRuntime(t *testing.T) {
+	server, err := NewServer(heartbeatGatewayConfig(t, filepath.Join(t.TempDir(), "inbox")))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	remote := httptest.NewRecorder()
+	server.handleInbox(remote, httptest.NewRequest(http.MethodGet, "/api/v1/inbox/codexAppCDP", nil))
+	if remote.Code != http.StatusForbidden {
+		t.Fatalf("remote status=%d", remote.Code)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/inbox/claudeDesktop", nil)
+	request.RemoteAddr = "127.0.0.1:12345"
+	unknown := httptest.NewRecorder()
+	server.handleInbox(unknown, request)
+	if unknown.Code != http.StatusNotFound || !strings.Contains(unknown.Body.String(), "no inbox configured") {
+		t.Fatalf("unknown status=%d body=%s", unknown.Code, unknown.Body.String())
+	}
+}
+
+func TestHealthAndStatusIncludeClaudeDesktopReadiness(t *testing.T) {
+	cdp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusServiceUnavailable)
+	}))
+	defer cdp.Close()
+	cfg := &config.Config{
+		Gateway:  &config.GatewayConfig{Bind: "127.0.0.1", Port: 0},
+		Channels: &config.ChannelsConfig{},
+		Agents: &config.AgentsConfig{
+			ClaudeDesktop: &config.ClaudeDesktopConfig{
+				Enabled:      true,
+				CDPEndpoint:  cdp.URL,
+				DefaultAgent: "main",
+				Watch:        config.ClaudeDesktopWatchConfig{IntervalSeconds: 60},
+			},
+		},
+	}
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if err := server.agentManager.Start(context.Background()); err != nil {
+		t.Fatalf("start agent manager: %v", err)
+	}
+	defer server.agentManager.Stop(context.Background())
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if readiness := server.agentManager.ClaudeDesktopReadinessStatus(); readiness != nil && !readiness.LastCheckedAt.IsZero() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", server.handleHealth)
+	mux.HandleFunc("/status", server.handleStatus)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	var health struct {
+		ClaudeDesktop *struct {
+			Available bool   `json:"available"`
+			LastError string `json:"last_error"`
+		} `json:"claude_desktop"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode health response: %v", err)
+	}
+	if health.ClaudeDesktop == nil || health.ClaudeDesktop.Available || !strings.Contains(health.ClaudeDesktop.LastError, "HTTP 503") {
+		t.Fatalf("unexpected Claude Desktop health: %#v", health.ClaudeDesktop)
+	}
+
+	resp, err = http.Get(ts.URL + "/status")
+	if err != nil {
+		t.Fatalf("status request failed: %v", err)
+	}
+	var status struct {
+		Agents struct {
+			ClaudeDesktop struct {
+				Watch struct {
+					Running         bool `json:"running"`
+					IntervalSeconds int  `json:"interval_seconds"`
+				} `json:"watch"`
+				Readiness struct {
+					Available bool `json:"available"`
+					History   []struct {
+						Available bool `json:"available"`
+					} `json:"history"`
+				} `json:"readiness"`
+			} `json:"claude_desktop"`
+		} `json:"agents"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode status response: %v", err)
+	}
+	claude := status.Agents.ClaudeDesktop
+	if !claude.Watch.Running || claude.Watch.IntervalSeconds != 60 || claude.Readiness.Available || len(claude.Readiness.History) != 1 {
+		t.Fatalf("unexpected Claude Desktop status: %#v", claude)
+	}
+}
+
+func TestTaskAPICompletesTrackedOhMyCodeTask(t *testing.T) {
+	workspace := t.TempDir()
+	scriptPath := filepath.Join(workspace, "agent_manager.py")
+	script := `import sys
+
+if len(sys.argv) >= 2 and sys.argv[1] in ("assign", "monitor"):
+    print("ok")
+    sys.exit(0)
+
+sys.exit(1)
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	server, err := NewServer(&config.Config{
+		Gateway:  &config.GatewayConfig{Bind: "127.0.0.1", Port: 0},
+		Channels: &config.ChannelsConfig{},
+		Agents: &config.AgentsConfig{
+			OhMyCode: &config.OhMyCodeConfig{
+				Enabled:            true,
+				Workspace:          workspace,
+				AgentManagerScript: scriptPath,
+				DefaultAgent:       "qa-1",
+				Tasks:              config.OhMyCodeTaskTrackingConfig{Enabled: true, PollIntervalSeconds: 3600},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	defer server.agentManager.Stop(context.Background())
+
+	if _, err := server.agentManager.HandleIncoming(context.Background(), &protocol.Message{Data: map[string]interface{}{
+		"channel": "telegram",
+		"text":    "run the migration",
+		"chat_id": int64(321),
+	}}); err != nil {
+		t.Fatalf("HandleIncoming: %v", err)
+	}
+	tasks := server.agentStatus().OhMyCode.Tasks
+	if tasks == nil || !tasks.Enabled || tasks.Running != 1 || len(tasks.Items) != 1 {
+		t.Fatalf("unexpected task status: %#v", tasks)
+	}
+	task := tasks.Items[0]
+	if task.Agent != "qa-1" || task.ChatID != "321" || task.State != "running" || task.StartedAt == "" {
+		t.Fatalf("unexpected running task: %#v", task)
+	}
+
+	call := func(remoteAddr, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		request.RemoteAddr = remoteAddr
+		response := httptest.NewRecorder()
+		server.handleTask(response, request)
+		return response
+	}
+	if response := call("203.0.113.1:1234", "/api/v1/tasks/"+task.ID+"/complete", `{}`); response.Code != http.StatusForbidden {
+		t.Fatalf("remote status=%d", response.Code)
+	}
+	if response := call("127.0.0.1:1234", "/api/v1/tasks/missing/complete", `{}`); response.Code != http.StatusNotFound {
+		t.Fatalf("unknown task status=%d body=%s", response.Code, response.Body.String())
+	}
+	if response := call("127.0.0.1:1234", "/api/v1/tasks/"+task.ID+"/complete", `{"status":"maybe"}`); response.Code != http.StatusBadRequest {
+		t.Fatalf("bad status=%d body=%s", response.Code, response.Body.String())
+	}
+	if response := call("127.0.0.1:1234", "/api/v1/tasks/"+task.ID+"/complete", `{"status":"done","summary":"migrated"}`); response.Code != http.StatusOK {
+		t.Fatalf("complete status=%d body=%s", response.Code, response.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tasks = server.agentStatus().OhMyCode.Tasks
+		if tasks.Running == 0 && len(tasks.Items) == 1 && tasks.Items[0].State == "done" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task did not finish: %#v", tasks)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if finished := tasks.Items[0]; finished.Summary != "migrated" || finished.FinishedAt == "" {
+		t.Fatalf("unexpected finished task: %#v", finished)
+	}
+}
+
+func TestAgentsAPIServesRegistry(t *testing.T) {
+	workspace := t.TempDir()
+	scriptPath := filepath.Join(workspace, "agent_manager.py")
+	script := `import sys
+
+if len(sys.argv) >= 2 and sys.argv[1] == "list":
+    print("qa-1 running")
+    sys.exit(0)
+
+sys.exit(1)
+`
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	server, err := NewServer(&config.Config{
+		Gateway:  &config.GatewayConfig{Bind: "127.0.0.1", Port: 0},
+		Channels: &config.ChannelsConfig{},
+		Agents: &config.AgentsConfig{
+			OhMyCode: &config.OhMyCodeConfig{
+				Enabled:            true,
+				Workspace:          workspace,
+				AgentManagerScript: scriptPath,
+				DefaultAgent:       "qa-1",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	server.agentManager.RefreshAgents(context.Background())
+
+	response := httptest.NewRecorder()
+	server.handleAgents(response, httptest.NewRequest(http.MethodGet, "/api/v1/agents", nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", response.Code, response.Body.String())
+	}
+	var body agentsResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Status != "ok" || len(body.Agents) != 1 {
+		t.Fatalf("unexpected body: %#v", body)
+	}
+	if got := body.Agents[0]; got.ID != "ohMyCode:qa-1" || got.Runtime != "ohMyCode" || got.Status != "running" {
+		t.Fatalf("unexpected agent: %#v", got)
+	}
+
+	response = httptest.NewRecorder()
+	server.handleAgents(response, httptest.NewRequest(http.MethodPost, "/api/v1/agents", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status=%d", response.Code)
+	}
+}
@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agent"
+)
+
+type ohMyCodeTaskStatus struct {
+	ID         string `json:"id"`
+	Agent      string `json:"agent"`
+	Channel    string `json:"channel,omitempty"`
+	ChatID     string `json:"chat_id,omitempty"`
+	ThreadTS   string `json:"thread_ts,omitempty"`
+	State      string `json:"state"`
+	Summary    string `json:"summary,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+type taskCompleteRequest struct {
+	Status  string `json:"status,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+type taskResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func ohMyCodeTaskStatuses(tasks []agent.OhMyCodeTaskStatus) []ohMyCodeTaskStatus {
+	if len(tasks) == 0 {
+		return nil
+	}
+	statuses := make([]ohMyCodeTaskStatus, 0, len(tasks))
+	for _, task := range tasks {
+		statuses = append(statuses, ohMyCodeTaskStatus{
+			ID:         task.ID,
+			Agent:      task.Agent,
+			Channel:    task.Channel,
+			ChatID:     task.ChatID,
+			ThreadTS:   task.ThreadTS,
+			State:      task.State,
+			Summary:    task.Summary,
+			Error:      task.Error,
+			StartedAt:  formatStatusTime(task.StartedAt),
+			FinishedAt: formatStatusTime(task.FinishedAt),
+		})
+	}
+	return statuses
+}
+
+// handleTask serves the loopback-only completion callback for tracked
+// oh-my-code tasks:
+//
+//	POST /api/v1/tasks/{id}/complete
+func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
+	if !isLoopbackRequest(r) {
+		writeJSON(w, http.StatusForbidden, taskResponse{Status: "error", Error: "task API is restricted to loopback clients"})
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/tasks/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "complete" {
+		writeJSON(w, http.StatusNotFound, taskResponse{Status: "error", Error: "task endpoint not found"})
+		return
+	}
+	if !requireMethod(w, r, http.MethodPost) {
+		return
+	}
+	var request taskCompleteRequest
+	if !decodeJSONRequest(w, r, &request) {
+		return
+	}
+	var failed bool
+	switch strings.TrimSpace(request.Status) {
+	case "", agent.OhMyCodeTaskDone:
+	case agent.OhMyCodeTaskFailed:
+		failed = true
+	default:
+		writeJSON(w, http.StatusBadRequest, taskResponse{Status: "error", Error: "status must be done or failed"})
+		return
+	}
+	if s.agentManager == nil {
+		writeJSON(w, http.StatusServiceUnavailable, taskResponse{Status: "error", Error: "agent manager is not running"})
+		return
+	}
+	if err := s.agentManager.CompleteOhMyCodeTask(parts[0], failed, request.Summary); err != nil {
+		statusCode := http.StatusConflict
+		if errors.Is(err, agent.ErrOhMyCodeTaskNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		writeJSON(w, statusCode, taskResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, taskResponse{Status: "ok"})
+}
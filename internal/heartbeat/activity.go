@@ -0,0 +1,97 @@
+package heartbeat
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// Kinds of agent activity passed to RecordActivity.
+const (
+	ActivityInbound  = "inbound message"
+	ActivityOutbound = "outbound message"
+)
+
+type agentActivity struct {
+	at   time.Time
+	kind string
+}
+
+// BusyFunc reports whether an agent is busy right now.
+type BusyFunc func(runtimeName, agentName string) bool
+
+// RecordActivity notes that an agent received or sent a message. Jobs with an
+// activityThreshold hold back firings while their agent was recently active.
+// An empty runtimeName matches the agent on every runtime.
+func (s *Scheduler) RecordActivity(runtimeName, agentName, kind string) {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return
+	}
+	agentName = strings.TrimSpace(agentName)
+	if agentName == "" {
+		return
+	}
+	s.mu.Lock()
+	now := s.now()
+	s.activity[activityKey(runtimeName, agentName)] = agentActivity{at: now, kind: kind}
+	if kind == ActivityInbound {
+		s.inbound[activityKey(runtimeName, agentName)] = now
+	}
+	s.mu.Unlock()
+}
+
+// SetBusyCheck registers how to ask whether an agent is busy, normally from
+// the agent registry.
+func (s *Scheduler) SetBusyCheck(busy BusyFunc) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.busy = busy
+	s.mu.Unlock()
+}
+
+func activityKey(runtimeName, agentName string) string {
+	return strings.TrimSpace(runtimeName) + "/" + strings.TrimSpace(agentName)
+}
+
+func parseActivityThreshold(jobConfig config.HeartbeatJobConfig) (time.Duration, error) {
+	raw := strings.TrimSpace(jobConfig.ActivityThreshold)
+	if raw == "" {
+		return 0, nil
+	}
+	threshold, err := time.ParseDuration(raw)
+	if err != nil || threshold <= 0 {
+		return 0, fmt.Errorf("activity threshold %q: must be a positive duration", raw)
+	}
+	return threshold, nil
+}
+
+// activeUntilLocked reports whether the job's agent is busy or was active
+// within the job's threshold, and when the agent counts as idle again.
+func (s *Scheduler) activeUntilLocked(job *compiledJob, now time.Time) (time.Time, string, bool) {
+	if job.activityThreshold <= 0 {
+		return time.Time{}, "", false
+	}
+	runtimeName, agentName := job.config.Runtime, job.config.Agent
+	if s.busy != nil && s.busy(runtimeName, agentName) {
+		return now.Add(job.activityThreshold), fmt.Sprintf("agent %s is busy", agentName), true
+	}
+	var latest agentActivity
+	for _, key := range []string{activityKey(runtimeName, agentName), activityKey("", agentName)} {
+		if activity, ok := s.activity[key]; ok && activity.at.After(latest.at) {
+			latest = activity
+		}
+	}
+	if latest.at.IsZero() {
+		return time.Time{}, "", false
+	}
+	idleAt := latest.at.Add(job.activityThreshold)
+	if !idleAt.After(now) {
+		return time.Time{}, "", false
+	}
+	ago := now.Sub(latest.at).Round(time.Second)
+	return idleAt, fmt.Sprintf("agent %s was active (%s %s ago)", agentName, latest.kind, ago), true
+}
@@ -0,0 +1,86 @@
+package heartbeat
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+)
+
+// ApplyReply steps a job through its backoff ladder from an agent reply that
+// arrived after the dispatch returned, as the built-in runtimes capture it.
+// Only the first reply to the job's latest run counts.
+func (s *Scheduler) ApplyReply(jobID, runID, reply string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[strings.TrimSpace(jobID)]
+	if !ok || job.config.BackoffAfter <= 0 || strings.TrimSpace(runID) == "" || job.lastRunID != strings.TrimSpace(runID) {
+		return
+	}
+	job.lastRunID = ""
+	job.applyReply(reply, s.now())
+	if err := s.saveStateLocked(); err != nil {
+		log.Printf("heartbeat: persist reply of %q: %v", job.config.ID, err)
+	}
+}
+
+// applyReply steps a job with backoffAfter set through its backoff ladder
+// from an agent reply. HeartbeatOK replies are counted; any other reply
+// restores the default cron. An empty reply, from a runtime that only queued
+// the wakeup, changes nothing.
+func (job *compiledJob) applyReply(reply string, now time.Time) {
+	if job.config.BackoffAfter <= 0 {
+		return
+	}
+	reply = strings.TrimSpace(reply)
+	switch reply {
+	case "":
+	case agentruntime.HeartbeatOK:
+		job.state.ConsecutiveOK++
+		if job.state.ConsecutiveOK < job.config.BackoffAfter {
+			return
+		}
+		profile := job.nextBackoffProfile()
+		if profile == "" {
+			return
+		}
+		job.state.ConsecutiveOK = 0
+		job.setScheduleProfile(now, profile, fmt.Sprintf("%d consecutive %s replies", job.config.BackoffAfter, agentruntime.HeartbeatOK))
+	default:
+		job.state.ConsecutiveOK = 0
+		if job.state.EffectiveProfile != "" {
+			job.setScheduleProfile(now, "", "agent reported work")
+		}
+	}
+}
+
+// nextBackoffProfile returns the ladder step after the current profile, or
+// an empty string on the last step or a profile outside the ladder.
+func (job *compiledJob) nextBackoffProfile() string {
+	ladder := job.config.BackoffLadder
+	if job.state.EffectiveProfile == "" {
+		if len(ladder) == 0 {
+			return ""
+		}
+		return ladder[0]
+	}
+	for index, profile := range ladder {
+		if profile == job.state.EffectiveProfile && index+1 < len(ladder) {
+			return ladder[index+1]
+		}
+	}
+	return ""
+}
+
+func (job *compiledJob) setScheduleProfile(now time.Time, profile, reason string) {
+	job.state.EffectiveProfile = profile
+	job.state.ScheduleReason = reason
+	job.state.ScheduleUpdatedBy = "heartbeat"
+	job.state.ScheduleUpdatedAt = now
+	job.state.NextRunAt = job.nextRun(now)
+}
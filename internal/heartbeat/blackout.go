@@ -0,0 +1,283 @@
+package heartbeat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// maxBlackoutChain bounds how many back-to-back windows are merged when
+// looking for the end of a blackout.
+const maxBlackoutChain = 400
+
+// blackoutCalendar is a named list of events. Floating events, including
+// dates and all-day events, are placed in the timezone of the job using the
+// calendar.
+type blackoutCalendar struct {
+	name   string
+	events []calendarEvent
+}
+
+type calendarEvent struct {
+	start    time.Time
+	end      time.Time
+	floating bool
+}
+
+type quietHours struct {
+	start    int
+	end      int
+	location *time.Location
+	label    string
+}
+
+// jobBlackouts holds the compiled quiet hours and calendars of one job.
+type jobBlackouts struct {
+	quiet     []quietHours
+	calendars []*blackoutCalendar
+	location  *time.Location
+	deferRuns bool
+}
+
+// loadCalendars reads agents.heartbeat.calendars, including any ICS files.
+func loadCalendars(configs map[string]config.HeartbeatCalendarConfig) (map[string]*blackoutCalendar, error) {
+	calendars := make(map[string]*blackoutCalendar, len(configs))
+	for name, calendarConfig := range configs {
+		calendar := &blackoutCalendar{name: name}
+		for _, raw := range calendarConfig.Dates {
+			date, err := time.Parse("2006-01-02", strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("heartbeat calendar %q: invalid date %q", name, raw)
+			}
+			calendar.events = append(calendar.events, calendarEvent{start: date, end: date.AddDate(0, 0, 1), floating: true})
+		}
+		if path := strings.TrimSpace(calendarConfig.ICSPath); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("heartbeat calendar %q: %w", name, err)
+			}
+			events, err := parseICS(data)
+			if err != nil {
+				return nil, fmt.Errorf("heartbeat calendar %q: %s: %w", name, path, err)
+			}
+			calendar.events = append(calendar.events, events...)
+		}
+		calendars[name] = calendar
+	}
+	return calendars, nil
+}
+
+// parseICS reads the VEVENT start and end times of an iCalendar file.
+// Recurrence rules are not expanded; only the first occurrence is used.
+func parseICS(data []byte) ([]calendarEvent, error) {
+	var (
+		events  []calendarEvent
+		inEvent bool
+		event   calendarEvent
+		hasEnd  bool
+		allDay  bool
+		lines   []string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for number, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		property, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(property) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent, event, hasEnd, allDay = true, calendarEvent{}, false, false
+			}
+		case "END":
+			if !strings.EqualFold(value, "VEVENT") || !inEvent {
+				continue
+			}
+			inEvent = false
+			if event.start.IsZero() {
+				return nil, fmt.Errorf("line %d: event without DTSTART", number+1)
+			}
+			if !hasEnd {
+				event.end = event.start
+				if allDay {
+					event.end = event.start.AddDate(0, 0, 1)
+				}
+			}
+			if event.end.After(event.start) {
+				events = append(events, event)
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			parsed, floating, date, err := parseICSTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number+1, err)
+			}
+			if strings.EqualFold(property, "DTSTART") {
+				event.start, event.floating, allDay = parsed, floating, date
+			} else {
+				event.end, hasEnd = parsed, true
+			}
+		}
+	}
+	return events, nil
+}
+
+// parseICSTime parses DATE, floating, UTC and TZID date-time values.
+func parseICSTime(params, value string) (time.Time, bool, bool, error) {
+	value = strings.TrimSpace(value)
+	var tzid string
+	for _, param := range strings.Split(params, ";") {
+		key, paramValue, _ := strings.Cut(param, "=")
+		if strings.EqualFold(key, "TZID") {
+			tzid = strings.Trim(paramValue, `"`)
+		}
+	}
+	if len(value) == len("20060102") {
+		date, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, false, false, fmt.Errorf("invalid date %q", value)
+		}
+		return date, true, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		parsed, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return parsed, false, false, nil
+	}
+	location := time.UTC
+	floating := tzid == ""
+	if !floating {
+		loaded, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, false, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		location = loaded
+	}
+	parsed, err := time.ParseInLocation("20060102T150405", value, location)
+	if err != nil {
+		return time.Time{}, false, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return parsed, floating, false, nil
+}
+
+func compileBlackouts(jobConfig config.HeartbeatJobConfig, calendars map[string]*blackoutCalendar) (jobBlackouts, error) {
+	location, err := time.LoadLocation(strings.TrimSpace(jobConfig.Timezone))
+	if err != nil {
+		return jobBlackouts{}, err
+	}
+	blackouts := jobBlackouts{
+		location:  location,
+		deferRuns: jobConfig.BlackoutPolicy == config.HeartbeatBlackoutDefer,
+	}
+	for _, window := range jobConfig.QuietHours {
+		start, err := time.Parse("15:04", strings.TrimSpace(window.Start))
+		if err != nil {
+			return jobBlackouts{}, fmt.Errorf("quiet hours start %q: must be HH:MM", window.Start)
+		}
+		end, err := time.Parse("15:04", strings.TrimSpace(window.End))
+		if err != nil {
+			return jobBlackouts{}, fmt.Errorf("quiet hours end %q: must be HH:MM", window.End)
+		}
+		quiet := quietHours{
+			start:    start.Hour()*60 + start.Minute(),
+			end:      end.Hour()*60 + end.Minute(),
+			location: location,
+		}
+		if timezone := strings.TrimSpace(window.Timezone); timezone != "" {
+			if quiet.location, err = time.LoadLocation(timezone); err != nil {
+				return jobBlackouts{}, err
+			}
+		}
+		quiet.label = fmt.Sprintf("quiet hours %s-%s %s", start.Format("15:04"), end.Format("15:04"), quiet.location)
+		blackouts.quiet = append(blackouts.quiet, quiet)
+	}
+	for _, name := range jobConfig.Blackouts {
+		calendar, ok := calendars[strings.TrimSpace(name)]
+		if !ok {
+			return jobBlackouts{}, fmt.Errorf("unknown blackout calendar %q", name)
+		}
+		blackouts.calendars = append(blackouts.calendars, calendar)
+	}
+	return blackouts, nil
+}
+
+// at reports whether at falls inside quiet hours or a blackout, and when the
+// blackout ends. Back-to-back windows are merged.
+func (b jobBlackouts) at(at time.Time) (time.Time, string, bool) {
+	end, label, ok := b.window(at)
+	if !ok {
+		return time.Time{}, "", false
+	}
+	for i := 0; i < maxBlackoutChain; i++ {
+		next, _, inside := b.window(end)
+		if !inside || !next.After(end) {
+			break
+		}
+		end = next
+	}
+	return end, label, true
+}
+
+func (b jobBlackouts) window(at time.Time) (time.Time, string, bool) {
+	for _, quiet := range b.quiet {
+		if end, ok := quiet.window(at); ok {
+			return end, quiet.label, true
+		}
+	}
+	for _, calendar := range b.calendars {
+		for _, event := range calendar.events {
+			start, end := event.start, event.end
+			if event.floating {
+				start, end = inLocation(start, b.location), inLocation(end, b.location)
+			}
+			if !at.Before(start) && at.Before(end) {
+				return end, "blackout calendar " + calendar.name, true
+			}
+		}
+	}
+	return time.Time{}, "", false
+}
+
+func (q quietHours) window(at time.Time) (time.Time, bool) {
+	local := at.In(q.location)
+	minute := local.Hour()*60 + local.Minute()
+	inside := minute >= q.start && minute < q.end
+	if q.start > q.end {
+		inside = minute >= q.start || minute < q.end
+	}
+	if !inside {
+		return time.Time{}, false
+	}
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.end/60, q.end%60, 0, 0, q.location)
+	if !end.After(at) {
+		end = time.Date(local.Year(), local.Month(), local.Day()+1, q.end/60, q.end%60, 0, 0, q.location)
+	}
+	return end, true
+}
+
+// inLocation reads the wall clock of a floating time in location.
+func inLocation(value time.Time, location *time.Location) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), value.Hour(), value.Minute(), value.Second(), 0, location)
+}
@@ -0,0 +1,133 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ErrJobBusy is returned when a job cannot be run right away because it is
+// already in flight or the scheduler is at maxConcurrent.
+var ErrJobBusy = errors.New("is busy")
+
+// Pause stops scheduled runs of a job until resumed, or until until when it
+// is non-zero. Pausing a paused job replaces the pause.
+func (s *Scheduler) Pause(jobID string, until time.Time, reason, pausedBy string) (JobStatus, error) {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return JobStatus{}, errors.New("heartbeat scheduler is disabled")
+	}
+	jobID = strings.TrimSpace(jobID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w", jobID, ErrJobNotFound)
+	}
+	now := s.now()
+	if !until.IsZero() && !until.After(now) {
+		return JobStatus{}, errors.New("pause end must be in the future")
+	}
+	previous := job.state
+	job.state.Paused = true
+	job.state.PausedUntil = until
+	job.state.PauseReason = strings.TrimSpace(reason)
+	job.state.PausedBy = strings.TrimSpace(pausedBy)
+	job.state.PausedAt = now
+	job.state.NextRunAt = job.nextRun(now)
+	if err := s.saveStateLocked(); err != nil {
+		job.state = previous
+		return JobStatus{}, err
+	}
+	return s.jobStatusLocked(job), nil
+}
+
+// Resume lifts a pause and schedules the next run from now. Resuming a job
+// that is not paused is a no-op.
+func (s *Scheduler) Resume(jobID string) (JobStatus, error) {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return JobStatus{}, errors.New("heartbeat scheduler is disabled")
+	}
+	jobID = strings.TrimSpace(jobID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w", jobID, ErrJobNotFound)
+	}
+	if !job.state.Paused {
+		return s.jobStatusLocked(job), nil
+	}
+	previous := job.state
+	job.clearPause()
+	job.state.NextRunAt = job.nextRun(s.now())
+	if err := s.saveStateLocked(); err != nil {
+		job.state = previous
+		return JobStatus{}, err
+	}
+	return s.jobStatusLocked(job), nil
+}
+
+// RunNow dispatches a job immediately, even while it is paused. The regular
+// schedule is unchanged.
+func (s *Scheduler) RunNow(jobID string) (JobStatus, error) {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return JobStatus{}, errors.New("heartbeat scheduler is disabled")
+	}
+	jobID = strings.TrimSpace(jobID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w", jobID, ErrJobNotFound)
+	}
+	if job.inFlight {
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w: a run is already in flight", jobID, ErrJobBusy)
+	}
+	select {
+	case s.semaphore <- struct{}{}:
+	default:
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w: maxConcurrent runs are in flight", jobID, ErrJobBusy)
+	}
+	now := s.now()
+	job.inFlight = true
+	job.state.InFlight = true
+	job.state.LastScheduledAt = now
+	request := job.dispatchRequest(now)
+	text := s.textLocked(job, now)
+	run := newRunRecord(job, RunTriggerManual, now)
+	if err := s.saveStateLocked(); err != nil {
+		log.Printf("heartbeat: persist triggered state: %v", err)
+	}
+	dispatchContext := s.ctx
+	if dispatchContext == nil {
+		dispatchContext = context.Background()
+	}
+	s.dispatchWg.Add(1)
+	go s.dispatch(dispatchContext, request, text, run)
+	return s.jobStatusLocked(job), nil
+}
+
+// nextRun is the next scheduled run after now, or after the end of a timed
+// pause. It is zero while the job is paused indefinitely.
+func (job *compiledJob) nextRun(now time.Time) time.Time {
+	if job.state.Paused {
+		if job.state.PausedUntil.IsZero() {
+			return time.Time{}
+		}
+		if now.Before(job.state.PausedUntil) {
+			now = job.state.PausedUntil
+		}
+	}
+	return job.effectiveSchedule().Next(now)
+}
+
+func (job *compiledJob) clearPause() {
+	job.state.Paused = false
+	job.state.PausedUntil = time.Time{}
+	job.state.PauseReason = ""
+	job.state.PausedBy = ""
+	job.state.PausedAt = time.Time{}
+}
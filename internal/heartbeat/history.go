@@ -0,0 +1,182 @@
+package heartbeat
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHistoryDirname = "heartbeat-runs"
+
+	// RunTriggerSchedule marks runs started by the cron schedule.
+	RunTriggerSchedule = "schedule"
+	// RunTriggerManual marks runs started with RunNow.
+	RunTriggerManual = "manual"
+)
+
+// historyMaxFileBytes and historyMaxFiles bound each job's history: the
+// active file is rotated to <job>.jsonl.1 once it would exceed the size, and
+// the oldest rotated file beyond historyMaxFiles is removed.
+var (
+	historyMaxFileBytes int64 = 1 << 20
+	historyMaxFiles           = 4
+)
+
+// RunRecord is one scheduled, manual, skipped or deferred run of a job.
+type RunRecord struct {
+	RunID       string `json:"run_id,omitempty"`
+	JobID       string `json:"job_id"`
+	Trigger     string `json:"trigger"`
+	ScheduledAt string `json:"scheduled_at,omitempty"`
+	StartedAt   string `json:"started_at,omitempty"`
+	FinishedAt  string `json:"finished_at,omitempty"`
+	DurationMS  int64  `json:"duration_ms"`
+	Attempts    int    `json:"attempts"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	EnvelopeID  string `json:"envelope_id,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+}
+
+// RunHistory returns up to limit runs of a job, newest first. A limit of zero
+// or less returns every retained run. History outlives deleted jobs.
+func (s *Scheduler) RunHistory(jobID string, limit int) ([]RunRecord, error) {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return nil, errors.New("heartbeat scheduler is disabled")
+	}
+	jobID = strings.TrimSpace(jobID)
+	s.mu.RLock()
+	_, known := s.jobs[jobID]
+	s.mu.RUnlock()
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	var runs []RunRecord
+	found := false
+	base := s.historyPath(jobID)
+	for index := historyMaxFiles - 1; index >= 0; index-- {
+		path := base
+		if index > 0 {
+			path = fmt.Sprintf("%s.%d", base, index)
+		}
+		records, err := readRunRecords(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		runs = append(runs, records...)
+	}
+	if !known && !found {
+		return nil, fmt.Errorf("heartbeat job %q %w", jobID, ErrJobNotFound)
+	}
+	for left, right := 0, len(runs)-1; left < right; left, right = left+1, right-1 {
+		runs[left], runs[right] = runs[right], runs[left]
+	}
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+// recordRun appends a run to the job's history. Failures are logged by the
+// caller and never block scheduling.
+func (s *Scheduler) recordRun(run RunRecord) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("encode heartbeat run: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+	if err := os.MkdirAll(s.historyDir, 0700); err != nil {
+		return fmt.Errorf("create heartbeat history directory: %w", err)
+	}
+	path := s.historyPath(run.JobID)
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(data)) > historyMaxFileBytes {
+		if err := rotateHistory(path); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open heartbeat history: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write heartbeat history: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close heartbeat history: %w", err)
+	}
+	return nil
+}
+
+func (s *Scheduler) historyPath(jobID string) string {
+	return filepath.Join(s.historyDir, url.PathEscape(jobID)+".jsonl")
+}
+
+func rotateHistory(path string) error {
+	oldest := fmt.Sprintf("%s.%d", path, historyMaxFiles-1)
+	if err := os.Remove(oldest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove heartbeat history: %w", err)
+	}
+	for index := historyMaxFiles - 2; index >= 0; index-- {
+		from := path
+		if index > 0 {
+			from = fmt.Sprintf("%s.%d", path, index)
+		}
+		err := os.Rename(from, fmt.Sprintf("%s.%d", path, index+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotate heartbeat history: %w", err)
+		}
+	}
+	return nil
+}
+
+func readRunRecords(path string) ([]RunRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var runs []RunRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var run RunRecord
+		if err := json.Unmarshal([]byte(line), &run); err != nil {
+			// A torn final line from a crash must not hide the rest.
+			continue
+		}
+		runs = append(runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read heartbeat history: %w", err)
+	}
+	return runs, nil
+}
+
+func newRunRecord(job *compiledJob, trigger string, scheduledAt time.Time) RunRecord {
+	return RunRecord{
+		JobID:       job.config.ID,
+		Trigger:     trigger,
+		ScheduledAt: formatTime(scheduledAt),
+		Profile:     job.state.EffectiveProfile,
+	}
+}
@@ -0,0 +1,393 @@
+package heartbeat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+const (
+	defaultJobsFilename  = "heartbeat-jobs.json"
+	persistedJobsVersion = 1
+
+	// JobSourceConfig marks jobs defined in agents.heartbeat.jobs.
+	JobSourceConfig = "config"
+	// JobSourceAPI marks jobs created through the job management API.
+	JobSourceAPI = "api"
+)
+
+var (
+	// ErrJobNotFound is returned for unknown job IDs.
+	ErrJobNotFound = errors.New("not found")
+	// ErrJobExists is returned when creating a job whose ID is taken.
+	ErrJobExists = errors.New("already exists")
+	// ErrJobReadOnly is returned when changing a job defined in config.
+	ErrJobReadOnly = errors.New("is defined in the config file and cannot be changed at runtime")
+)
+
+// JobValidator normalizes and checks a job before it is scheduled, normally
+// with config.ValidateHeartbeatJob.
+type JobValidator func(job *config.HeartbeatJobConfig) error
+
+// JobUpdate changes selected fields of an API job. Nil fields keep their
+// current value; non-nil AgentCronProfiles, QuietHours, Blackouts and
+// BackoffLadder replace the current ones, and a Notify without channel and to
+// removes the job's own notify target. Setting any of Cron, Every, At or
+// Window replaces the whole schedule, including Jitter.
+type JobUpdate struct {
+	Runtime            *string
+	Agent              *string
+	Text               *string
+	Cron               *string
+	Every              *string
+	Jitter             *string
+	At                 *string
+	Window             *config.HeartbeatWindowConfig
+	Timezone           *string
+	AgentCronProfiles  map[string]string
+	ResetCronOnInbound *bool
+	QuietHours         []config.HeartbeatQuietHoursConfig
+	Blackouts          []string
+	BlackoutPolicy     *string
+	MisfirePolicy      *string
+	MisfireLimit       *int
+	MisfireExpiry      *string
+	ActivityThreshold  *string
+	ActivityPolicy     *string
+	BackoffAfter       *int
+	BackoffLadder      []string
+	Notify             *config.HeartbeatNotifyConfig
+}
+
+type persistedJobs struct {
+	Version int            `json:"version"`
+	Jobs    []persistedJob `json:"jobs"`
+}
+
+type persistedJob struct {
+	ID                 string            `json:"id"`
+	Runtime            string            `json:"runtime"`
+	Agent              string            `json:"agent"`
+	Text               string            `json:"text"`
+	Cron               string            `json:"cron"`
+	Timezone           string            `json:"timezone"`
+	AgentCronProfiles  map[string]string `json:"agent_cron_profiles,omitempty"`
+	ResetCronOnInbound bool              `json:"reset_cron_on_inbound,omitempty"`
+
+	Every  string                        `json:"every,omitempty"`
+	Jitter string                        `json:"jitter,omitempty"`
+	At     string                        `json:"at,omitempty"`
+	Window *config.HeartbeatWindowConfig `json:"window,omitempty"`
+
+	QuietHours     []config.HeartbeatQuietHoursConfig `json:"quiet_hours,omitempty"`
+	Blackouts      []string                           `json:"blackouts,omitempty"`
+	BlackoutPolicy string                             `json:"blackout_policy,omitempty"`
+	MisfirePolicy  string                             `json:"misfire_policy,omitempty"`
+	MisfireLimit   int                                `json:"misfire_limit,omitempty"`
+	MisfireExpiry  string                             `json:"misfire_expiry,omitempty"`
+
+	ActivityThreshold string `json:"activity_threshold,omitempty"`
+	ActivityPolicy    string `json:"activity_policy,omitempty"`
+
+	BackoffAfter  int      `json:"backoff_after,omitempty"`
+	BackoffLadder []string `json:"backoff_ladder,omitempty"`
+
+	Notify *config.HeartbeatNotifyConfig `json:"notify,omitempty"`
+}
+
+// CreateJob validates and schedules a new job and persists it so it is
+// restored on restart.
+func (s *Scheduler) CreateJob(jobConfig config.HeartbeatJobConfig) (JobStatus, error) {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return JobStatus{}, errors.New("heartbeat scheduler is disabled")
+	}
+	if err := s.checkJob(&jobConfig); err != nil {
+		return JobStatus{}, err
+	}
+	job, err := compileJob(jobConfig, JobSourceAPI, s.calendars)
+	if err != nil {
+		return JobStatus{}, err
+	}
+	if err := job.checkAtFuture(s.now()); err != nil {
+		return JobStatus{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[jobConfig.ID]; exists {
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w", jobConfig.ID, ErrJobExists)
+	}
+	job.state.NextRunAt = job.nextRun(s.now())
+	s.jobs[jobConfig.ID] = job
+	dormant, hadDormant := s.dormant[jobConfig.ID]
+	delete(s.dormant, jobConfig.ID)
+	if err := s.saveJobsLocked(); err != nil {
+		delete(s.jobs, jobConfig.ID)
+		if hadDormant {
+			s.dormant[jobConfig.ID] = dormant
+		}
+		return JobStatus{}, err
+	}
+	if err := s.saveStateLocked(); err != nil {
+		log.Printf("heartbeat: persist state for created job %q: %v", jobConfig.ID, err)
+	}
+	return s.jobStatusLocked(job), nil
+}
+
+// UpdateJob applies update to an API job and recalculates its next run. A
+// cron profile that no longer exists is cleared.
+func (s *Scheduler) UpdateJob(jobID string, update JobUpdate) (JobStatus, error) {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return JobStatus{}, errors.New("heartbeat scheduler is disabled")
+	}
+	jobID = strings.TrimSpace(jobID)
+
+	s.mu.RLock()
+	current, err := s.apiJobLocked(jobID)
+	var jobConfig config.HeartbeatJobConfig
+	if err == nil {
+		jobConfig = current.config
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return JobStatus{}, err
+	}
+	applyJobUpdate(&jobConfig, update)
+	if err := s.checkJob(&jobConfig); err != nil {
+		return JobStatus{}, err
+	}
+	updated, err := compileJob(jobConfig, JobSourceAPI, s.calendars)
+	if err != nil {
+		return JobStatus{}, err
+	}
+	// Only a new at time is checked, so a one-shot kept after its run can
+	// still be edited.
+	if update.At != nil || update.Timezone != nil {
+		if err := updated.checkAtFuture(s.now()); err != nil {
+			return JobStatus{}, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.apiJobLocked(jobID)
+	if err != nil {
+		return JobStatus{}, err
+	}
+	previous := *job
+	job.config = updated.config
+	job.defaultSchedule = updated.defaultSchedule
+	job.profileSchedules = updated.profileSchedules
+	job.blackouts = updated.blackouts
+	job.misfireExpiry = updated.misfireExpiry
+	job.activityThreshold = updated.activityThreshold
+	job.text = updated.text
+	job.location = updated.location
+	if _, ok := job.profileSchedules[job.state.EffectiveProfile]; !ok {
+		job.state.EffectiveProfile = ""
+	}
+	job.state.NextRunAt = job.nextRun(s.now())
+	if err := s.saveJobsLocked(); err != nil {
+		previous.inFlight = job.inFlight
+		previous.state.InFlight = job.state.InFlight
+		*job = previous
+		return JobStatus{}, err
+	}
+	if err := s.saveStateLocked(); err != nil {
+		log.Printf("heartbeat: persist state for updated job %q: %v", jobID, err)
+	}
+	return s.jobStatusLocked(job), nil
+}
+
+// DeleteJob unschedules an API job, or drops a stored job that could not be
+// scheduled. A dispatch already in flight finishes but its result is
+// discarded.
+func (s *Scheduler) DeleteJob(jobID string) error {
+	if s == nil || s.config == nil || !s.config.Enabled {
+		return errors.New("heartbeat scheduler is disabled")
+	}
+	jobID = strings.TrimSpace(jobID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.apiJobLocked(jobID)
+	if err != nil {
+		if dormant, ok := s.dormant[jobID]; ok && errors.Is(err, ErrJobNotFound) {
+			delete(s.dormant, jobID)
+			if err := s.saveJobsLocked(); err != nil {
+				s.dormant[jobID] = dormant
+				return err
+			}
+			return nil
+		}
+		return err
+	}
+	delete(s.jobs, jobID)
+	if err := s.saveJobsLocked(); err != nil {
+		s.jobs[jobID] = job
+		return err
+	}
+	if err := s.saveStateLocked(); err != nil {
+		log.Printf("heartbeat: persist state for deleted job %q: %v", jobID, err)
+	}
+	return nil
+}
+
+func (s *Scheduler) checkJob(jobConfig *config.HeartbeatJobConfig) error {
+	jobConfig.ID = strings.TrimSpace(jobConfig.ID)
+	if s.validateJob != nil {
+		return s.validateJob(jobConfig)
+	}
+	if jobConfig.ID == "" {
+		return errors.New("job.id: required")
+	}
+	return nil
+}
+
+func (s *Scheduler) apiJobLocked(jobID string) (*compiledJob, error) {
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("heartbeat job %q %w", jobID, ErrJobNotFound)
+	}
+	if job.source != JobSourceAPI {
+		return nil, fmt.Errorf("heartbeat job %q %w", jobID, ErrJobReadOnly)
+	}
+	return job, nil
+}
+
+func applyJobUpdate(jobConfig *config.HeartbeatJobConfig, update JobUpdate) {
+	if update.Runtime != nil {
+		jobConfig.Runtime = *update.Runtime
+	}
+	if update.Agent != nil {
+		jobConfig.Agent = *update.Agent
+	}
+	if update.Text != nil {
+		jobConfig.Text = *update.Text
+	}
+	if update.Cron != nil || update.Every != nil || update.At != nil || update.Window != nil {
+		jobConfig.Cron, jobConfig.Every, jobConfig.Jitter, jobConfig.At, jobConfig.Window = "", "", "", "", nil
+	}
+	if update.Cron != nil {
+		jobConfig.Cron = *update.Cron
+	}
+	if update.Every != nil {
+		jobConfig.Every = *update.Every
+	}
+	if update.Jitter != nil {
+		jobConfig.Jitter = *update.Jitter
+	}
+	if update.At != nil {
+		jobConfig.At = *update.At
+	}
+	if update.Window != nil {
+		window := *update.Window
+		jobConfig.Window = &window
+	}
+	if update.Timezone != nil {
+		jobConfig.Timezone = *update.Timezone
+	}
+	if update.AgentCronProfiles != nil {
+		jobConfig.AgentCronProfiles = update.AgentCronProfiles
+	}
+	if update.ResetCronOnInbound != nil {
+		jobConfig.ResetCronOnInbound = *update.ResetCronOnInbound
+	}
+	if update.QuietHours != nil {
+		jobConfig.QuietHours = update.QuietHours
+	}
+	if update.Blackouts != nil {
+		jobConfig.Blackouts = update.Blackouts
+	}
+	if update.BlackoutPolicy != nil {
+		jobConfig.BlackoutPolicy = *update.BlackoutPolicy
+	}
+	if update.MisfirePolicy != nil {
+		jobConfig.MisfirePolicy = *update.MisfirePolicy
+	}
+	if update.MisfireLimit != nil {
+		jobConfig.MisfireLimit = *update.MisfireLimit
+	}
+	if update.MisfireExpiry != nil {
+		jobConfig.MisfireExpiry = *update.MisfireExpiry
+	}
+	if update.ActivityThreshold != nil {
+		jobConfig.ActivityThreshold = *update.ActivityThreshold
+	}
+	if update.ActivityPolicy != nil {
+		jobConfig.ActivityPolicy = *update.ActivityPolicy
+	}
+	if update.BackoffAfter != nil {
+		jobConfig.BackoffAfter = *update.BackoffAfter
+	}
+	if update.BackoffLadder != nil {
+		jobConfig.BackoffLadder = update.BackoffLadder
+	}
+	if update.Notify != nil {
+		notify := *update.Notify
+		jobConfig.Notify = &notify
+	}
+}
+
+// loadJobs adds persisted API jobs next to the config jobs. Jobs that clash
+// with a config job or no longer validate are kept on disk but not scheduled.
+func (s *Scheduler) loadJobs() error {
+	data, err := os.ReadFile(s.jobsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read heartbeat jobs: %w", err)
+	}
+	var stored persistedJobs
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&stored); err != nil {
+		return fmt.Errorf("decode heartbeat jobs: %w", err)
+	}
+	if stored.Version != persistedJobsVersion {
+		return fmt.Errorf("decode heartbeat jobs: unsupported version %d", stored.Version)
+	}
+	for _, entry := range stored.Jobs {
+		jobConfig := config.HeartbeatJobConfig(entry)
+		if _, exists := s.jobs[jobConfig.ID]; exists {
+			log.Printf("heartbeat: API job %q is shadowed by a config job with the same ID", jobConfig.ID)
+			s.dormant[jobConfig.ID] = jobConfig
+			continue
+		}
+		checked := jobConfig
+		if err := s.checkJob(&checked); err != nil {
+			log.Printf("heartbeat: API job %q is not scheduled: %v", jobConfig.ID, err)
+			s.dormant[jobConfig.ID] = jobConfig
+			continue
+		}
+		job, err := compileJob(checked, JobSourceAPI, s.calendars)
+		if err != nil {
+			log.Printf("heartbeat: API job %q is not scheduled: %v", jobConfig.ID, err)
+			s.dormant[jobConfig.ID] = jobConfig
+			continue
+		}
+		s.jobs[checked.ID] = job
+	}
+	return nil
+}
+
+func (s *Scheduler) saveJobsLocked() error {
+	stored := persistedJobs{Version: persistedJobsVersion, Jobs: []persistedJob{}}
+	for _, job := range s.jobs {
+		if job.source == JobSourceAPI {
+			stored.Jobs = append(stored.Jobs, persistedJob(job.config))
+		}
+	}
+	for _, jobConfig := range s.dormant {
+		stored.Jobs = append(stored.Jobs, persistedJob(jobConfig))
+	}
+	sort.Slice(stored.Jobs, func(i, j int) bool { return stored.Jobs[i].ID < stored.Jobs[j].ID })
+	return writeStateFile(s.jobsPath, "heartbeat jobs", stored)
+}
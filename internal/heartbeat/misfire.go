@@ -0,0 +1,97 @@
+package heartbeat
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+const (
+	// misfireThreshold is how late a firing may be handled before it counts
+	// as missed.
+	misfireThreshold = time.Minute
+	// defaultMisfireLimit caps run-all catch-ups when misfireLimit is unset.
+	defaultMisfireLimit = 3
+	// maxMisfireScan bounds how many missed firings are enumerated after a
+	// long outage; the missed count saturates there.
+	maxMisfireScan = 10000
+
+	// RunTriggerCatchUp marks runs replaying firings missed during downtime.
+	RunTriggerCatchUp = "catch_up"
+)
+
+func parseMisfireExpiry(jobConfig config.HeartbeatJobConfig) (time.Duration, error) {
+	raw := strings.TrimSpace(jobConfig.MisfireExpiry)
+	if raw == "" {
+		return 0, nil
+	}
+	expiry, err := time.ParseDuration(raw)
+	if err != nil || expiry <= 0 {
+		return 0, fmt.Errorf("misfire expiry %q: must be a positive duration", raw)
+	}
+	return expiry, nil
+}
+
+func (job *compiledJob) misfireLimit() int {
+	if job.config.MisfireLimit > 0 {
+		return job.config.MisfireLimit
+	}
+	return defaultMisfireLimit
+}
+
+// misfire settles firings that were due more than misfireThreshold before
+// now, typically after the gateway was down or the machine slept. Firings the
+// policy does not run are counted in MissedRuns; the ones it does run are
+// queued in CatchUp. A firing that is only just due stays in NextRunAt.
+func (job *compiledJob) misfire(now time.Time) {
+	schedule := job.effectiveSchedule()
+	var overdue []time.Time
+	for at := job.state.NextRunAt; !at.IsZero() && !at.After(now) && len(overdue) < maxMisfireScan; at = schedule.Next(at) {
+		overdue = append(overdue, at)
+	}
+	job.state.NextRunAt = job.nextRun(now)
+	current := false
+	if count := len(overdue); count > 0 && now.Sub(overdue[count-1]) <= misfireThreshold {
+		job.state.NextRunAt = overdue[count-1]
+		overdue = overdue[:count-1]
+		current = true
+	}
+	if len(overdue) == 0 {
+		return
+	}
+
+	runnable := overdue
+	for len(runnable) > 0 && job.misfireExpiry > 0 && now.Sub(runnable[0]) > job.misfireExpiry {
+		runnable = runnable[1:]
+	}
+	switch job.config.MisfirePolicy {
+	case config.HeartbeatMisfireRunOnce:
+		if current || len(job.state.CatchUp) > 0 {
+			runnable = nil
+		} else if len(runnable) > 1 {
+			runnable = runnable[len(runnable)-1:]
+		}
+	case config.HeartbeatMisfireRunAll:
+		room := job.misfireLimit() - len(job.state.CatchUp)
+		if room < 0 {
+			room = 0
+		}
+		if len(runnable) > room {
+			runnable = runnable[len(runnable)-room:]
+		}
+	default:
+		runnable = nil
+	}
+	job.state.CatchUp = append(job.state.CatchUp, runnable...)
+
+	missed := len(overdue) - len(runnable)
+	if missed == 0 {
+		return
+	}
+	job.state.MissedRuns += missed
+	job.state.LastMissedAt = overdue[missed-1]
+	log.Printf("heartbeat: job %q missed %d run(s) since %s; %d queued to catch up", job.config.ID, missed, formatTime(overdue[0]), len(runnable))
+}
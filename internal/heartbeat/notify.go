@@ -0,0 +1,111 @@
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultRecoverAfter     = 1
+)
+
+// Notifier sends a heartbeat alert to a chat on a channel.
+type Notifier func(ctx context.Context, channel, to, text string) error
+
+type alert struct {
+	target *config.HeartbeatNotifyConfig
+	text   string
+}
+
+// SetNotifier registers how failure and recovery alerts are sent, normally
+// through the message bus.
+func (s *Scheduler) SetNotifier(notifier Notifier) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.notifier = notifier
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) notifyTargetLocked(job *compiledJob) *config.HeartbeatNotifyConfig {
+	if job.config.Notify != nil {
+		return job.config.Notify
+	}
+	return s.config.Notify
+}
+
+// trackHealth counts failed and successful runs and returns the alerts they
+// trigger. Each alert is sent once per outage: a failing run clears nothing,
+// and the outage ends after recoverAfter successful runs in a row.
+func (job *compiledJob) trackHealth(run RunRecord, now time.Time, target *config.HeartbeatNotifyConfig) []alert {
+	state := &job.state
+	if run.Status == "error" {
+		state.ConsecutiveSuccesses = 0
+		state.ConsecutiveFailures++
+		if state.FailingSince.IsZero() {
+			state.FailingSince = now
+		}
+		if target == nil {
+			return nil
+		}
+		var alerts []alert
+		threshold := target.FailureThreshold
+		if threshold <= 0 {
+			threshold = defaultFailureThreshold
+		}
+		if !state.FailureAlerted && state.ConsecutiveFailures >= threshold {
+			state.FailureAlerted = true
+			alerts = append(alerts, alert{target: target, text: fmt.Sprintf("⚠️ Heartbeat job %s has failed %d runs since %s: %s",
+				job.config.ID, state.ConsecutiveFailures, formatTime(state.FailingSince), run.Error)})
+		}
+		after, _ := time.ParseDuration(target.UnreachableAfter)
+		if down := now.Sub(state.FailingSince); after > 0 && !state.UnreachableAlerted && down >= after {
+			state.UnreachableAlerted = true
+			alerts = append(alerts, alert{target: target, text: fmt.Sprintf("🚨 Heartbeat job %s: runtime %s has been unreachable for %s: %s",
+				job.config.ID, job.config.Runtime, down.Round(time.Second), run.Error)})
+		}
+		return alerts
+	}
+
+	if state.FailingSince.IsZero() {
+		return nil
+	}
+	state.ConsecutiveSuccesses++
+	recoverAfter := defaultRecoverAfter
+	if target != nil && target.RecoverAfter > 0 {
+		recoverAfter = target.RecoverAfter
+	}
+	if state.ConsecutiveSuccesses < recoverAfter {
+		return nil
+	}
+	alerted := state.FailureAlerted || state.UnreachableAlerted
+	since := state.FailingSince
+	state.ConsecutiveFailures = 0
+	state.ConsecutiveSuccesses = 0
+	state.FailingSince = time.Time{}
+	state.FailureAlerted = false
+	state.UnreachableAlerted = false
+	if target == nil || !alerted {
+		return nil
+	}
+	return []alert{{target: target, text: fmt.Sprintf("✅ Heartbeat job %s recovered after failing since %s",
+		job.config.ID, formatTime(since))}}
+}
+
+func (s *Scheduler) sendAlerts(ctx context.Context, notifier Notifier, alerts []alert) {
+	for _, item := range alerts {
+		if notifier == nil {
+			log.Printf("heartbeat: no notifier for alert: %s", item.text)
+			continue
+		}
+		if err := notifier(ctx, item.target.Channel, item.target.To, item.text); err != nil {
+			log.Printf("heartbeat: notify %s %s: %v", item.target.Channel, item.target.To, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package heartbeat
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/robfig/cron/v3"
+)
+
+// parseJobSchedule compiles the default schedule of a job: cron, every, at or
+// window. Jitter and window times are derived from the job ID, so they are
+// random across jobs but stable across restarts.
+func parseJobSchedule(jobConfig config.HeartbeatJobConfig) (cron.Schedule, error) {
+	switch {
+	case strings.TrimSpace(jobConfig.Every) != "":
+		every, err := time.ParseDuration(strings.TrimSpace(jobConfig.Every))
+		if err != nil || every < time.Minute {
+			return nil, fmt.Errorf("every %q: must be a duration of at least 1m", jobConfig.Every)
+		}
+		location, err := time.LoadLocation(strings.TrimSpace(jobConfig.Timezone))
+		if err != nil {
+			return nil, err
+		}
+		schedule := everySchedule{interval: every, location: location, seed: jobConfig.ID}
+		if raw := strings.TrimSpace(jobConfig.Jitter); raw != "" {
+			if schedule.jitter, err = time.ParseDuration(raw); err != nil || schedule.jitter < 0 || schedule.jitter >= every {
+				return nil, fmt.Errorf("jitter %q: must be at least 0 and less than every", raw)
+			}
+		}
+		return schedule, nil
+	case strings.TrimSpace(jobConfig.At) != "":
+		location, err := time.LoadLocation(strings.TrimSpace(jobConfig.Timezone))
+		if err != nil {
+			return nil, err
+		}
+		raw := strings.TrimSpace(jobConfig.At)
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if at, err = time.ParseInLocation("2006-01-02 15:04", raw, location); err != nil {
+				return nil, fmt.Errorf("at %q: must be RFC 3339 or \"YYYY-MM-DD HH:MM\"", raw)
+			}
+		}
+		return atSchedule{at: at}, nil
+	case jobConfig.Window != nil:
+		location, err := time.LoadLocation(strings.TrimSpace(jobConfig.Timezone))
+		if err != nil {
+			return nil, err
+		}
+		start, err := time.Parse("15:04", strings.TrimSpace(jobConfig.Window.Start))
+		if err != nil {
+			return nil, fmt.Errorf("window start %q: must be HH:MM", jobConfig.Window.Start)
+		}
+		end, err := time.Parse("15:04", strings.TrimSpace(jobConfig.Window.End))
+		if err != nil {
+			return nil, fmt.Errorf("window end %q: must be HH:MM", jobConfig.Window.End)
+		}
+		length := end.Sub(start)
+		if length < 0 {
+			length += 24 * time.Hour
+		}
+		if length == 0 {
+			return nil, fmt.Errorf("window %s-%s: start and end must differ", jobConfig.Window.Start, jobConfig.Window.End)
+		}
+		return windowSchedule{start: start.Hour()*60 + start.Minute(), length: length, location: location, seed: jobConfig.ID}, nil
+	default:
+		return parseSchedule(jobConfig.Cron, jobConfig.Timezone)
+	}
+}
+
+// oneShot reports whether the job runs only once, at its at time.
+func (job *compiledJob) oneShot() bool {
+	_, ok := job.defaultSchedule.(atSchedule)
+	return ok
+}
+
+// spent reports whether an API one-shot job has nothing left to run, so it
+// can be deleted. A paused one-shot is kept for a manual run.
+func (job *compiledJob) spent() bool {
+	return job.oneShot() && job.source == JobSourceAPI && !job.inFlight && !job.state.Paused &&
+		job.state.NextRunAt.IsZero() && len(job.state.CatchUp) == 0
+}
+
+// checkAtFuture rejects a one-shot job whose at time has already passed,
+// since it would never fire.
+func (job *compiledJob) checkAtFuture(now time.Time) error {
+	if schedule, ok := job.defaultSchedule.(atSchedule); ok && !schedule.at.After(now) {
+		return fmt.Errorf("heartbeat job %q: at %s is in the past", job.config.ID, formatTime(schedule.at))
+	}
+	return nil
+}
+
+// everySchedule fires once per interval plus a jitter below jitter. Slots are
+// counted from the Unix epoch on the wall clock of the job timezone, so an
+// hourly job fires on the local hour and a daily one at local midnight. Each
+// slot has its own jitter, so consecutive firings are more than
+// interval-jitter and less than interval+jitter apart.
+type everySchedule struct {
+	interval time.Duration
+	jitter   time.Duration
+	location *time.Location
+	seed     string
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	local := t.In(s.location)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+	slot := wall.UnixNano() / int64(s.interval)
+	for ; ; slot++ {
+		start := time.Unix(0, slot*int64(s.interval)).UTC()
+		at := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), s.location)
+		at = at.Add(spread(s.seed, fmt.Sprint(slot), s.jitter))
+		if at.After(t) {
+			return at
+		}
+	}
+}
+
+// atSchedule fires once; Next is zero after that.
+type atSchedule struct {
+	at time.Time
+}
+
+func (s atSchedule) Next(t time.Time) time.Time {
+	if s.at.After(t) {
+		return s.at
+	}
+	return time.Time{}
+}
+
+// windowSchedule fires once a day, length after start at most.
+type windowSchedule struct {
+	start    int
+	length   time.Duration
+	location *time.Location
+	seed     string
+}
+
+func (s windowSchedule) Next(t time.Time) time.Time {
+	local := t.In(s.location)
+	// Start from yesterday for a window that crosses midnight.
+	for day := -1; ; day++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+day, s.start/60, s.start%60, 0, 0, s.location)
+		at := date.Add(spread(s.seed, date.Format("2006-01-02"), s.length))
+		if at.After(t) {
+			return at
+		}
+	}
+}
+
+// spread picks a whole-second offset below limit from seed and key.
+func spread(seed, key string, limit time.Duration) time.Duration {
+	seconds := uint64(limit / time.Second)
+	if seconds == 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(seed + "/" + key))
+	return time.Duration(hash.Sum64()%seconds) * time.Second
+}
@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
@@ -38,9 +39,14 @@ type Status struct {
 // JobStatus contains schedule and delivery telemetry without instruction text.
 type JobStatus struct {
 	ID                    string `json:"id"`
+	Source                string `json:"source"`
 	Runtime               string `json:"runtime"`
 	Agent                 string `json:"agent"`
 	ConfiguredCron        string `json:"configured_cron"`
+	Every                 string `json:"every,omitempty"`
+	Jitter                string `json:"jitter,omitempty"`
+	At                    string `json:"at,omitempty"`
+	Window                string `json:"window,omitempty"`
 	EffectiveProfile      string `json:"effective_profile,omitempty"`
 	EffectiveCron         string `json:"effective_cron"`
 	Timezone              string `json:"timezone"`
@@ -55,43 +61,91 @@ type JobStatus struct {
 	LastScheduleReason    string `json:"last_schedule_reason,omitempty"`
 	LastScheduleUpdatedBy string `json:"last_schedule_updated_by,omitempty"`
 	LastScheduleUpdatedAt string `json:"last_schedule_updated_at,omitempty"`
+	Paused                bool   `json:"paused"`
+	PausedUntil           string `json:"paused_until,omitempty"`
+	PauseReason           string `json:"pause_reason,omitempty"`
+	PausedBy              string `json:"paused_by,omitempty"`
+	PausedAt              string `json:"paused_at,omitempty"`
+
+	QuietHours     []config.HeartbeatQuietHoursConfig `json:"quiet_hours,omitempty"`
+	Blackouts      []string                           `json:"blackouts,omitempty"`
+	BlackoutPolicy string                             `json:"blackout_policy,omitempty"`
+
+	MisfirePolicy  string `json:"misfire_policy,omitempty"`
+	MissedRuns     int    `json:"missed_runs"`
+	LastMissedAt   string `json:"last_missed_at,omitempty"`
+	PendingCatchUp int    `json:"pending_catch_up,omitempty"`
+
+	ActivityThreshold string `json:"activity_threshold,omitempty"`
+	ActivityPolicy    string `json:"activity_policy,omitempty"`
+
+	BackoffAfter  int      `json:"backoff_after,omitempty"`
+	BackoffLadder []string `json:"backoff_ladder,omitempty"`
+	ConsecutiveOK int      `json:"consecutive_ok,omitempty"`
+
+	Notify              *config.HeartbeatNotifyConfig `json:"notify,omitempty"`
+	ConsecutiveFailures int                           `json:"consecutive_failures,omitempty"`
+	FailingSince        string                        `json:"failing_since,omitempty"`
 }
 
 type compiledJob struct {
-	config           config.HeartbeatJobConfig
-	defaultSchedule  cron.Schedule
-	profileSchedules map[string]cron.Schedule
-	state            persistedJobState
-	inFlight         bool
+	config            config.HeartbeatJobConfig
+	source            string
+	defaultSchedule   cron.Schedule
+	profileSchedules  map[string]cron.Schedule
+	blackouts         jobBlackouts
+	misfireExpiry     time.Duration
+	activityThreshold time.Duration
+	text              *template.Template
+	location          *time.Location
+	state             persistedJobState
+	inFlight          bool
+	// lastRunID is the run a late agent reply must match to count.
+	lastRunID string
 }
 
 // Scheduler owns cron state, dispatch concurrency, and profile overrides.
 type Scheduler struct {
 	config       *config.HeartbeatConfig
 	dispatcher   agentruntime.Dispatcher
+	validateJob  JobValidator
 	statePath    string
+	jobsPath     string
+	historyDir   string
 	now          func() time.Time
 	tickInterval time.Duration
 	retryDelay   func(attempt int) time.Duration
 
-	mu      sync.RWMutex
-	jobs    map[string]*compiledJob
-	started bool
-	cancel  context.CancelFunc
-	done    chan struct{}
-	ctx     context.Context
+	mu         sync.RWMutex
+	jobs       map[string]*compiledJob
+	calendars  map[string]*blackoutCalendar
+	activity   map[string]agentActivity
+	inbound    map[string]time.Time
+	busy       BusyFunc
+	inboxCount InboxCounter
+	notifier   Notifier
+	dormant    map[string]config.HeartbeatJobConfig
+	started    bool
+	cancel     context.CancelFunc
+	done       chan struct{}
+	ctx        context.Context
 
 	semaphore  chan struct{}
 	dispatchWg sync.WaitGroup
+
+	historyMu sync.Mutex
 }
 
-// New creates a scheduler and restores persisted profile overrides. Missed
-// executions are intentionally ignored; every next run is computed from now.
-func New(cfg *config.HeartbeatConfig, workspace string, dispatcher agentruntime.Dispatcher) (*Scheduler, error) {
-	return newScheduler(cfg, workspace, dispatcher, time.Now, defaultTickInterval)
+// New creates a scheduler, adds jobs created through the API, and restores
+// persisted profile overrides. validateJob checks API jobs against the current
+// config. Firings missed while the gateway was down are settled on the first
+// tick by each job's misfire policy: skipped by default, or run once or in
+// full as catch-up runs.
+func New(cfg *config.HeartbeatConfig, workspace string, dispatcher agentruntime.Dispatcher, validateJob JobValidator) (*Scheduler, error) {
+	return newScheduler(cfg, workspace, dispatcher, validateJob, time.Now, defaultTickInterval)
 }
 
-func newScheduler(cfg *config.HeartbeatConfig, workspace string, dispatcher agentruntime.Dispatcher, now func() time.Time, tickInterval time.Duration) (*Scheduler, error) {
+func newScheduler(cfg *config.HeartbeatConfig, workspace string, dispatcher agentruntime.Dispatcher, validateJob JobValidator, now func() time.Time, tickInterval time.Duration) (*Scheduler, error) {
 	if cfg == nil {
 		return nil, nil
 	}
@@ -105,14 +159,21 @@ func newScheduler(cfg *config.HeartbeatConfig, workspace string, dispatcher agen
 	if maxConcurrent <= 0 {
 		maxConcurrent = defaultMaxConcurrent
 	}
+	statePath := resolveStatePath(cfg.StatePath, workspace)
 	scheduler := &Scheduler{
 		config:       cfg,
 		dispatcher:   dispatcher,
-		statePath:    resolveStatePath(cfg.StatePath, workspace),
+		validateJob:  validateJob,
+		statePath:    statePath,
+		jobsPath:     filepath.Join(filepath.Dir(statePath), defaultJobsFilename),
+		historyDir:   filepath.Join(filepath.Dir(statePath), defaultHistoryDirname),
 		now:          now,
 		tickInterval: tickInterval,
 		retryDelay:   dispatchRetryDelay,
 		jobs:         make(map[string]*compiledJob, len(cfg.Jobs)),
+		dormant:      make(map[string]config.HeartbeatJobConfig),
+		activity:     make(map[string]agentActivity),
+		inbound:      make(map[string]time.Time),
 		semaphore:    make(chan struct{}, maxConcurrent),
 	}
 	if !cfg.Enabled {
@@ -122,25 +183,20 @@ func newScheduler(cfg *config.HeartbeatConfig, workspace string, dispatcher agen
 		return nil, errors.New("heartbeat dispatcher is required")
 	}
 
+	calendars, err := loadCalendars(cfg.Calendars)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.calendars = calendars
 	for idx := range cfg.Jobs {
-		jobConfig := cfg.Jobs[idx]
-		defaultSchedule, err := parseSchedule(jobConfig.Cron, jobConfig.Timezone)
+		job, err := compileJob(cfg.Jobs[idx], JobSourceConfig, calendars)
 		if err != nil {
-			return nil, fmt.Errorf("compile heartbeat job %q: %w", jobConfig.ID, err)
-		}
-		job := &compiledJob{
-			config:           jobConfig,
-			defaultSchedule:  defaultSchedule,
-			profileSchedules: make(map[string]cron.Schedule, len(jobConfig.AgentCronProfiles)),
+			return nil, err
 		}
-		for profile, expression := range jobConfig.AgentCronProfiles {
-			schedule, err := parseSchedule(expression, jobConfig.Timezone)
-			if err != nil {
-				return nil, fmt.Errorf("compile heartbeat job %q profile %q: %w", jobConfig.ID, profile, err)
-			}
-			job.profileSchedules[profile] = schedule
-		}
-		scheduler.jobs[jobConfig.ID] = job
+		scheduler.jobs[job.config.ID] = job
+	}
+	if err := scheduler.loadJobs(); err != nil {
+		return nil, err
 	}
 
 	if err := scheduler.loadState(); err != nil {
@@ -148,8 +204,12 @@ func newScheduler(cfg *config.HeartbeatConfig, workspace string, dispatcher agen
 	}
 	base := scheduler.now()
 	for _, job := range scheduler.jobs {
-		job.state.NextRunAt = job.effectiveSchedule().Next(base)
 		job.state.InFlight = false
+		if !job.state.Paused && !job.state.NextRunAt.IsZero() && job.state.NextRunAt.Before(base) {
+			job.misfire(base)
+			continue
+		}
+		job.state.NextRunAt = job.nextRun(base)
 	}
 	return scheduler, nil
 }
@@ -241,7 +301,7 @@ func (s *Scheduler) SetProfile(jobID, profile, reason, updatedBy string) (JobSta
 	defer s.mu.Unlock()
 	job, ok := s.jobs[jobID]
 	if !ok {
-		return JobStatus{}, fmt.Errorf("heartbeat job %q not found", jobID)
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w", jobID, ErrJobNotFound)
 	}
 	if _, ok := job.profileSchedules[profile]; !ok {
 		return JobStatus{}, fmt.Errorf("heartbeat profile %q is not allowed for job %q", profile, jobID)
@@ -255,7 +315,7 @@ func (s *Scheduler) SetProfile(jobID, profile, reason, updatedBy string) (JobSta
 	job.state.ScheduleReason = reason
 	job.state.ScheduleUpdatedBy = strings.TrimSpace(updatedBy)
 	job.state.ScheduleUpdatedAt = now
-	job.state.NextRunAt = job.effectiveSchedule().Next(now)
+	job.state.NextRunAt = job.nextRun(now)
 	if err := s.saveStateLocked(); err != nil {
 		job.state = previous
 		return JobStatus{}, err
@@ -272,7 +332,7 @@ func (s *Scheduler) ResetProfile(jobID, reason, updatedBy string) (JobStatus, er
 	defer s.mu.Unlock()
 	job, ok := s.jobs[strings.TrimSpace(jobID)]
 	if !ok {
-		return JobStatus{}, fmt.Errorf("heartbeat job %q not found", strings.TrimSpace(jobID))
+		return JobStatus{}, fmt.Errorf("heartbeat job %q %w", strings.TrimSpace(jobID), ErrJobNotFound)
 	}
 	if job.state.EffectiveProfile == "" {
 		return s.jobStatusLocked(job), nil
@@ -283,7 +343,7 @@ func (s *Scheduler) ResetProfile(jobID, reason, updatedBy string) (JobStatus, er
 	job.state.ScheduleReason = strings.TrimSpace(reason)
 	job.state.ScheduleUpdatedBy = strings.TrimSpace(updatedBy)
 	job.state.ScheduleUpdatedAt = now
-	job.state.NextRunAt = job.defaultSchedule.Next(now)
+	job.state.NextRunAt = job.nextRun(now)
 	if err := s.saveStateLocked(); err != nil {
 		job.state = previous
 		return JobStatus{}, err
@@ -292,7 +352,8 @@ func (s *Scheduler) ResetProfile(jobID, reason, updatedBy string) (JobStatus, er
 }
 
 // ResetForInbound restores matching idle jobs after a normal inbound message
-// was successfully routed to the same Runtime and Agent.
+// was successfully routed to the same Runtime and Agent. Jobs that back off
+// on HEARTBEAT_OK replies are always restored and restart their count.
 func (s *Scheduler) ResetForInbound(runtimeName, agentName string) {
 	if s == nil || s.config == nil || !s.config.Enabled {
 		return
@@ -305,16 +366,24 @@ func (s *Scheduler) ResetForInbound(runtimeName, agentName string) {
 	now := s.now()
 	previous := make(map[string]persistedJobState)
 	for id, job := range s.jobs {
-		if !job.config.ResetCronOnInbound || job.config.Runtime != runtimeName || job.config.Agent != agentName || job.state.EffectiveProfile == "" {
+		if job.config.Runtime != runtimeName || job.config.Agent != agentName {
+			continue
+		}
+		reset := (job.config.ResetCronOnInbound || job.config.BackoffAfter > 0) && job.state.EffectiveProfile != ""
+		if !reset && job.state.ConsecutiveOK == 0 {
 			continue
 		}
 		previous[id] = job.state
+		job.state.ConsecutiveOK = 0
+		changed = true
+		if !reset {
+			continue
+		}
 		job.state.EffectiveProfile = ""
 		job.state.ScheduleReason = "normal inbound activity"
 		job.state.ScheduleUpdatedBy = "gateway"
 		job.state.ScheduleUpdatedAt = now
-		job.state.NextRunAt = job.defaultSchedule.Next(now)
-		changed = true
+		job.state.NextRunAt = job.nextRun(now)
 	}
 	if changed {
 		if err := s.saveStateLocked(); err != nil {
@@ -350,53 +419,86 @@ func (s *Scheduler) runDue(now time.Time) {
 		return
 	}
 	type dueDispatch struct {
-		jobID   string
 		request agentruntime.DispatchRequest
+		text    jobText
+		run     RunRecord
 	}
-	var due []dueDispatch
+	var (
+		due     []dueDispatch
+		skipped []RunRecord
+	)
 
 	s.mu.Lock()
-	for id, job := range s.jobs {
-		if job.state.NextRunAt.IsZero() {
+	for _, job := range s.jobs {
+		if job.state.Paused {
+			if job.state.PausedUntil.IsZero() || now.Before(job.state.PausedUntil) {
+				continue
+			}
+			job.clearPause()
+		}
+		if !job.state.NextRunAt.IsZero() && now.Sub(job.state.NextRunAt) > misfireThreshold {
+			job.misfire(now)
+		}
+		if job.spent() {
+			// The one-shot firing was missed and not caught up.
+			run := newRunRecord(job, RunTriggerSchedule, job.defaultSchedule.(atSchedule).at)
+			run.Status = "skipped_missed"
+			run.Reason = "one-shot run was missed; job deleted"
+			job.state.LastDispatchStatus = run.Status
+			skipped = append(skipped, run)
+			s.retireLocked(job)
+			continue
+		}
+		if job.state.NextRunAt.IsZero() && len(job.state.CatchUp) == 0 {
 			job.state.NextRunAt = job.effectiveSchedule().Next(now)
 			continue
 		}
-		if job.state.NextRunAt.After(now) {
+		if job.state.NextRunAt.IsZero() || job.state.NextRunAt.After(now) {
+			if len(job.state.CatchUp) == 0 || job.inFlight {
+				continue
+			}
+			if _, _, blocked := job.blackouts.at(now); blocked {
+				continue
+			}
+			select {
+			case s.semaphore <- struct{}{}:
+				scheduledAt := job.state.CatchUp[0]
+				job.state.CatchUp = job.state.CatchUp[1:]
+				job.state.LastScheduledAt = scheduledAt
+				job.inFlight = true
+				job.state.InFlight = true
+				due = append(due, dueDispatch{request: job.dispatchRequest(scheduledAt), text: s.textLocked(job, scheduledAt), run: newRunRecord(job, RunTriggerCatchUp, scheduledAt)})
+			default:
+			}
+			continue
+		}
+		if end, label, blocked := job.blackouts.at(job.state.NextRunAt); blocked {
+			skipped = append(skipped, job.holdBack(now, end, label, "blackout", job.blackouts.deferRuns))
+			continue
+		}
+		if idleAt, label, active := s.activeUntilLocked(job, now); active {
+			skipped = append(skipped, job.holdBack(now, idleAt, label, "active", job.config.ActivityPolicy == config.HeartbeatBlackoutDefer))
 			continue
 		}
 		scheduledAt := job.state.NextRunAt
 		job.state.NextRunAt = job.effectiveSchedule().Next(now)
 		job.state.LastScheduledAt = scheduledAt
+		run := newRunRecord(job, RunTriggerSchedule, scheduledAt)
 		if job.inFlight {
 			job.state.LastDispatchStatus = "skipped_overlap"
+			run.Status = job.state.LastDispatchStatus
+			skipped = append(skipped, run)
 			continue
 		}
 		select {
 		case s.semaphore <- struct{}{}:
 			job.inFlight = true
 			job.state.InFlight = true
-			runID := newRunID()
-			profiles := make([]string, 0, len(job.profileSchedules))
-			for profile := range job.profileSchedules {
-				profiles = append(profiles, profile)
-			}
-			due = append(due, dueDispatch{
-				jobID: id,
-				request: agentruntime.DispatchRequest{
-					Runtime:      job.config.Runtime,
-					Agent:        job.config.Agent,
-					Text:         job.config.Text,
-					Source:       "heartbeat",
-					JobID:        id,
-					RunID:        runID,
-					ScheduledAt:  scheduledAt,
-					ExpiresAt:    job.state.NextRunAt,
-					CoalesceKey:  "heartbeat:" + id,
-					CronProfiles: profiles,
-				},
-			})
+			due = append(due, dueDispatch{request: job.dispatchRequest(scheduledAt), text: s.textLocked(job, scheduledAt), run: run})
 		default:
 			job.state.LastDispatchStatus = "skipped_capacity"
+			run.Status = job.state.LastDispatchStatus
+			skipped = append(skipped, run)
 		}
 	}
 	if err := s.saveStateLocked(); err != nil {
@@ -408,16 +510,26 @@ func (s *Scheduler) runDue(now time.Time) {
 	}
 	for _, item := range due {
 		s.dispatchWg.Add(1)
-		go s.dispatch(dispatchContext, item.jobID, item.request)
+		go s.dispatch(dispatchContext, item.request, item.text, item.run)
 	}
 	s.mu.Unlock()
+
+	for _, run := range skipped {
+		if err := s.recordRun(run); err != nil {
+			log.Printf("heartbeat: record run of %q: %v", run.JobID, err)
+		}
+	}
 }
 
-func (s *Scheduler) dispatch(ctx context.Context, jobID string, request agentruntime.DispatchRequest) {
+func (s *Scheduler) dispatch(ctx context.Context, request agentruntime.DispatchRequest, text jobText, run RunRecord) {
 	defer s.dispatchWg.Done()
 	defer func() { <-s.semaphore }()
+	request.Text = text.render()
+	startedAt := s.now()
+	attempts := 0
 	result := agentruntime.DispatchResult{Status: "error", Error: "heartbeat dispatch did not run"}
 	for attempt := 1; attempt <= maxDispatchAttempts; attempt++ {
+		attempts++
 		result = s.dispatcher.DispatchRuntime(ctx, request)
 		if strings.TrimSpace(result.Status) != "error" || attempt == maxDispatchAttempts {
 			break
@@ -440,22 +552,80 @@ func (s *Scheduler) dispatch(ctx context.Context, jobID string, request agentrun
 	}
 	now := s.now()
 
+	run.RunID = request.RunID
+	run.StartedAt = formatTime(startedAt)
+	run.FinishedAt = formatTime(now)
+	run.DurationMS = now.Sub(startedAt).Milliseconds()
+	run.Attempts = attempts
+	run.Status = strings.TrimSpace(result.Status)
+	run.Error = strings.TrimSpace(result.Error)
+	run.EnvelopeID = strings.TrimSpace(result.EnvelopeID)
+	if err := s.recordRun(run); err != nil {
+		log.Printf("heartbeat: record run of %q: %v", run.JobID, err)
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	job, ok := s.jobs[jobID]
+	job, ok := s.jobs[request.JobID]
 	if !ok {
+		s.mu.Unlock()
 		return
 	}
 	job.inFlight = false
 	job.state.InFlight = false
 	job.state.LastDispatchAt = now
-	job.state.LastDispatchStatus = strings.TrimSpace(result.Status)
-	job.state.LastDispatchError = strings.TrimSpace(result.Error)
-	job.state.LastEnvelopeID = strings.TrimSpace(result.EnvelopeID)
+	job.state.LastDispatchStatus = run.Status
+	job.state.LastDispatchError = run.Error
+	job.state.LastEnvelopeID = run.EnvelopeID
 	job.state.LastInboxPath = strings.TrimSpace(result.InboxPath)
+	job.applyReply(result.Reply, now)
+	alerts := job.trackHealth(run, now, s.notifyTargetLocked(job))
+	// A one-shot job is deleted once its scheduled run is recorded, whether
+	// or not it succeeded. A manual run leaves the schedule alone, so it
+	// never retires one.
+	if run.Trigger != RunTriggerManual && job.spent() {
+		s.retireLocked(job)
+	}
 	if err := s.saveStateLocked(); err != nil {
 		log.Printf("heartbeat: persist dispatch result: %v", err)
 	}
+	notifier := s.notifier
+	s.mu.Unlock()
+
+	s.sendAlerts(ctx, notifier, alerts)
+}
+
+// retireLocked deletes a spent one-shot job. Its run history is kept.
+func (s *Scheduler) retireLocked(job *compiledJob) {
+	delete(s.jobs, job.config.ID)
+	if err := s.saveJobsLocked(); err != nil {
+		s.jobs[job.config.ID] = job
+		log.Printf("heartbeat: delete one-shot job %q: %v", job.config.ID, err)
+	}
+}
+
+// holdBack skips or defers a firing held back until end, by quiet hours, a
+// blackout or agent activity, and records why in the schedule audit fields.
+// cause completes the run status, as in skipped_blackout.
+func (job *compiledJob) holdBack(now, end time.Time, label, cause string, deferRun bool) RunRecord {
+	run := newRunRecord(job, RunTriggerSchedule, job.state.NextRunAt)
+	if deferRun {
+		job.state.NextRunAt = end
+		run.Status = "deferred_" + cause
+		run.Reason = fmt.Sprintf("%s: deferred to %s", label, formatTime(end))
+	} else {
+		after := end.Add(-time.Nanosecond)
+		if now.After(after) {
+			after = now
+		}
+		job.state.NextRunAt = job.effectiveSchedule().Next(after)
+		run.Status = "skipped_" + cause
+		run.Reason = fmt.Sprintf("%s: skipped until %s", label, formatTime(job.state.NextRunAt))
+	}
+	job.state.LastDispatchStatus = run.Status
+	job.state.ScheduleReason = run.Reason
+	job.state.ScheduleUpdatedBy = "heartbeat"
+	job.state.ScheduleUpdatedAt = now
+	return run
 }
 
 func dispatchRetryDelay(attempt int) time.Duration {
@@ -465,6 +635,66 @@ func dispatchRetryDelay(attempt int) time.Duration {
 	return initialRetryDelay << (attempt - 1)
 }
 
+// dispatchRequest builds the request for a firing and remembers its run ID,
+// so that only a reply to the latest run moves the backoff ladder.
+func (job *compiledJob) dispatchRequest(scheduledAt time.Time) agentruntime.DispatchRequest {
+	profiles := make([]string, 0, len(job.profileSchedules))
+	for profile := range job.profileSchedules {
+		profiles = append(profiles, profile)
+	}
+	request := agentruntime.DispatchRequest{
+		Runtime:      job.config.Runtime,
+		Agent:        job.config.Agent,
+		Text:         job.config.Text,
+		Source:       "heartbeat",
+		JobID:        job.config.ID,
+		RunID:        newRunID(),
+		ScheduledAt:  scheduledAt,
+		ExpiresAt:    job.state.NextRunAt,
+		CoalesceKey:  "heartbeat:" + job.config.ID,
+		CronProfiles: profiles,
+		WantReply:    job.config.BackoffAfter > 0,
+	}
+	job.lastRunID = request.RunID
+	return request
+}
+
+func compileJob(jobConfig config.HeartbeatJobConfig, source string, calendars map[string]*blackoutCalendar) (*compiledJob, error) {
+	defaultSchedule, err := parseJobSchedule(jobConfig)
+	if err != nil {
+		return nil, fmt.Errorf("compile heartbeat job %q: %w", jobConfig.ID, err)
+	}
+	job := &compiledJob{
+		config:           jobConfig,
+		source:           source,
+		defaultSchedule:  defaultSchedule,
+		profileSchedules: make(map[string]cron.Schedule, len(jobConfig.AgentCronProfiles)),
+	}
+	for profile, expression := range jobConfig.AgentCronProfiles {
+		schedule, err := parseSchedule(expression, jobConfig.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("compile heartbeat job %q profile %q: %w", jobConfig.ID, profile, err)
+		}
+		job.profileSchedules[profile] = schedule
+	}
+	if job.blackouts, err = compileBlackouts(jobConfig, calendars); err != nil {
+		return nil, fmt.Errorf("compile heartbeat job %q: %w", jobConfig.ID, err)
+	}
+	if job.misfireExpiry, err = parseMisfireExpiry(jobConfig); err != nil {
+		return nil, fmt.Errorf("compile heartbeat job %q: %w", jobConfig.ID, err)
+	}
+	if job.activityThreshold, err = parseActivityThreshold(jobConfig); err != nil {
+		return nil, fmt.Errorf("compile heartbeat job %q: %w", jobConfig.ID, err)
+	}
+	if job.text, err = parseText(jobConfig); err != nil {
+		return nil, fmt.Errorf("compile heartbeat job %q text: %w", jobConfig.ID, err)
+	}
+	if job.location, err = time.LoadLocation(jobConfig.Timezone); err != nil {
+		return nil, fmt.Errorf("compile heartbeat job %q: %w", jobConfig.ID, err)
+	}
+	return job, nil
+}
+
 func (job *compiledJob) effectiveSchedule() cron.Schedule {
 	if schedule, ok := job.profileSchedules[job.state.EffectiveProfile]; ok {
 		return schedule
@@ -482,9 +712,14 @@ func (job *compiledJob) effectiveCron() string {
 func (s *Scheduler) jobStatusLocked(job *compiledJob) JobStatus {
 	return JobStatus{
 		ID:                    job.config.ID,
+		Source:                job.source,
 		Runtime:               job.config.Runtime,
 		Agent:                 job.config.Agent,
 		ConfiguredCron:        job.config.Cron,
+		Every:                 job.config.Every,
+		Jitter:                job.config.Jitter,
+		At:                    job.config.At,
+		Window:                formatWindow(job.config.Window),
 		EffectiveProfile:      job.state.EffectiveProfile,
 		EffectiveCron:         job.effectiveCron(),
 		Timezone:              job.config.Timezone,
@@ -499,7 +734,34 @@ func (s *Scheduler) jobStatusLocked(job *compiledJob) JobStatus {
 		LastScheduleReason:    job.state.ScheduleReason,
 		LastScheduleUpdatedBy: job.state.ScheduleUpdatedBy,
 		LastScheduleUpdatedAt: formatTime(job.state.ScheduleUpdatedAt),
+		Paused:                job.state.Paused,
+		PausedUntil:           formatTime(job.state.PausedUntil),
+		PauseReason:           job.state.PauseReason,
+		PausedBy:              job.state.PausedBy,
+		PausedAt:              formatTime(job.state.PausedAt),
+		QuietHours:            job.config.QuietHours,
+		Blackouts:             job.config.Blackouts,
+		BlackoutPolicy:        job.config.BlackoutPolicy,
+		MisfirePolicy:         job.config.MisfirePolicy,
+		MissedRuns:            job.state.MissedRuns,
+		LastMissedAt:          formatTime(job.state.LastMissedAt),
+		PendingCatchUp:        len(job.state.CatchUp),
+		ActivityThreshold:     job.config.ActivityThreshold,
+		ActivityPolicy:        job.config.ActivityPolicy,
+		BackoffAfter:          job.config.BackoffAfter,
+		BackoffLadder:         job.config.BackoffLadder,
+		ConsecutiveOK:         job.state.ConsecutiveOK,
+		Notify:                job.config.Notify,
+		ConsecutiveFailures:   job.state.ConsecutiveFailures,
+		FailingSince:          formatTime(job.state.FailingSince),
+	}
+}
+
+func formatWindow(window *config.HeartbeatWindowConfig) string {
+	if window == nil {
+		return ""
 	}
+	return window.Start + "-" + window.End
 }
 
 func parseSchedule(expression, timezone string) (cron.Schedule, error) {
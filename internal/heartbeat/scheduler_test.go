@@ -3,6 +3,9 @@ package heartbeat
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -10,6 +13,7 @@ import (
 
 	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
 	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/robfig/cron/v3"
 )
 
 type testClock struct {
@@ -91,7 +95,7 @@ func TestSchedulerCalculatesTimezoneAndDispatchesRuntimeEnvelope(t *testing.T) {
 		EnvelopeID: "env-1",
 		InboxPath:  "/tmp/inbox/heartbeat.json",
 	}}
-	scheduler, err := newScheduler(heartbeatTestConfig(""), t.TempDir(), dispatcher, clock.Now, time.Hour)
+	scheduler, err := newScheduler(heartbeatTestConfig(""), t.TempDir(), dispatcher, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("newScheduler: %v", err)
 	}
@@ -130,7 +134,7 @@ func TestSchedulerProfileIsIdempotentPersistsAndSkipsMissedRuns(t *testing.T) {
 	base := time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)
 	clock := &testClock{now: base}
 	cfg := heartbeatTestConfig(statePath)
-	scheduler, err := newScheduler(cfg, "", &recordingDispatcher{}, clock.Now, time.Hour)
+	scheduler, err := newScheduler(cfg, "", &recordingDispatcher{}, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("newScheduler: %v", err)
 	}
@@ -154,7 +158,7 @@ func TestSchedulerProfileIsIdempotentPersistsAndSkipsMissedRuns(t *testing.T) {
 
 	restartTime := time.Date(2026, 7, 26, 4, 12, 0, 0, time.UTC)
 	clock.Set(restartTime)
-	restarted, err := newScheduler(cfg, "", &recordingDispatcher{}, clock.Now, time.Hour)
+	restarted, err := newScheduler(cfg, "", &recordingDispatcher{}, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("restart scheduler: %v", err)
 	}
@@ -173,7 +177,7 @@ func TestSchedulerProfileIsIdempotentPersistsAndSkipsMissedRuns(t *testing.T) {
 
 func TestSchedulerInboundResetRequiresMatchingTarget(t *testing.T) {
 	clock := &testClock{now: time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)}
-	scheduler, err := newScheduler(heartbeatTestConfig(""), t.TempDir(), &recordingDispatcher{}, clock.Now, time.Hour)
+	scheduler, err := newScheduler(heartbeatTestConfig(""), t.TempDir(), &recordingDispatcher{}, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("newScheduler: %v", err)
 	}
@@ -213,7 +217,7 @@ func TestSchedulerSkipsOverlappingRun(t *testing.T) {
 	}
 	cfg := heartbeatTestConfig("")
 	cfg.Jobs[0].Cron = "* * * * *"
-	scheduler, err := newScheduler(cfg, t.TempDir(), dispatcher, clock.Now, time.Hour)
+	scheduler, err := newScheduler(cfg, t.TempDir(), dispatcher, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("newScheduler: %v", err)
 	}
@@ -256,7 +260,7 @@ func TestSchedulerEnforcesGlobalCapacityAndRecordsFailure(t *testing.T) {
 	second := cfg.Jobs[0]
 	second.ID = "cloudbank-secondary"
 	cfg.Jobs = append(cfg.Jobs, second)
-	scheduler, err := newScheduler(cfg, t.TempDir(), dispatcher, clock.Now, time.Hour)
+	scheduler, err := newScheduler(cfg, t.TempDir(), dispatcher, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("newScheduler: %v", err)
 	}
@@ -297,7 +301,7 @@ func TestSchedulerEnforcesGlobalCapacityAndRecordsFailure(t *testing.T) {
 
 func TestSchedulerStatusDoesNotExposeInstruction(t *testing.T) {
 	clock := &testClock{now: time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC)}
-	scheduler, err := newScheduler(heartbeatTestConfig(""), t.TempDir(), &recordingDispatcher{}, clock.Now, time.Hour)
+	scheduler, err := newScheduler(heartbeatTestConfig(""), t.TempDir(), &recordingDispatcher{}, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("newScheduler: %v", err)
 	}
@@ -320,7 +324,7 @@ func TestSchedulerStopCancelsRetryBackoff(t *testing.T) {
 	}
 	cfg := heartbeatTestConfig("")
 	cfg.Jobs[0].Cron = "* * * * *"
-	scheduler, err := newScheduler(cfg, t.TempDir(), dispatcher, clock.Now, time.Hour)
+	scheduler, err := newScheduler(cfg, t.TempDir(), dispatcher, nil, clock.Now, time.Hour)
 	if err != nil {
 		t.Fatalf("newScheduler: %v", err)
 	}
@@ -359,3 +363,1000 @@ func waitForScheduler(t *testing.T, scheduler *Scheduler, predicate func(JobStat
 	}
 	t.Fatalf("scheduler condition not reached: %#v", scheduler.Status())
 }
+
+func TestSchedulerJobsPersistAndMergeWithConfigOnRestart(t *testing.T) {
+	statePath := t.TempDir() + "/heartbeat-state.json"
+	clock := &testClock{now: time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)}
+	cfg := heartbeatTestConfig(statePath)
+	scheduler, err := newScheduler(cfg, "", &recordingDispatcher{}, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+
+	created, err := scheduler.CreateJob(config.HeartbeatJobConfig{
+		ID:       "nightly",
+		Runtime:  agentruntime.CodexAppCDP,
+		Agent:    "main",
+		Text:     "review open pull requests",
+		Cron:     "0 2 * * *",
+		Timezone: "UTC",
+	})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	if created.Source != JobSourceAPI || created.NextRunAt != "2026-07-26T02:00:00Z" {
+		t.Fatalf("unexpected created job: %#v", created)
+	}
+	if _, err := scheduler.CreateJob(config.HeartbeatJobConfig{ID: "cloudbank-main", Cron: "* * * * *", Timezone: "UTC"}); !errors.Is(err, ErrJobExists) {
+		t.Fatalf("expected duplicate ID error, got %v", err)
+	}
+	if _, err := scheduler.UpdateJob("cloudbank-main", JobUpdate{}); !errors.Is(err, ErrJobReadOnly) {
+		t.Fatalf("expected config job to be read-only, got %v", err)
+	}
+	cronExpression := "30 3 * * *"
+	updated, err := scheduler.UpdateJob("nightly", JobUpdate{Cron: &cronExpression})
+	if err != nil {
+		t.Fatalf("UpdateJob: %v", err)
+	}
+	if updated.ConfiguredCron != cronExpression || updated.NextRunAt != "2026-07-26T03:30:00Z" || updated.Agent != "main" {
+		t.Fatalf("unexpected updated job: %#v", updated)
+	}
+
+	restarted, err := newScheduler(cfg, "", &recordingDispatcher{}, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("restart scheduler: %v", err)
+	}
+	jobs := restarted.Status().Jobs
+	if len(jobs) != 2 || jobs[0].ID != "cloudbank-main" || jobs[0].Source != JobSourceConfig || jobs[1].ID != "nightly" || jobs[1].ConfiguredCron != cronExpression {
+		t.Fatalf("API job not merged on restart: %#v", jobs)
+	}
+
+	if err := restarted.DeleteJob("nightly"); err != nil {
+		t.Fatalf("DeleteJob: %v", err)
+	}
+	if err := restarted.DeleteJob("nightly"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected deleted job to be gone, got %v", err)
+	}
+	again, err := newScheduler(cfg, "", &recordingDispatcher{}, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("restart scheduler: %v", err)
+	}
+	if jobs := again.Status().Jobs; len(jobs) != 1 {
+		t.Fatalf("deleted job came back: %#v", jobs)
+	}
+}
+
+func TestSchedulerRunsAPIJobsWithoutConfiguredJobs(t *testing.T) {
+	statePath := t.TempDir() + "/heartbeat-state.json"
+	clock := &testClock{now: time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)}
+	cfg := heartbeatTestConfig(statePath)
+	cfg.Jobs = nil
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "delivered"}}
+	scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	if _, err := scheduler.CreateJob(config.HeartbeatJobConfig{ID: "nightly", Runtime: agentruntime.CodexAppCDP, Agent: "main", Text: "check", Cron: "0 2 * * *", Timezone: "UTC"}); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	restarted, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("restart scheduler: %v", err)
+	}
+	next := time.Date(2026, 7, 26, 2, 0, 0, 0, time.UTC)
+	clock.Set(next)
+	restarted.runDue(next)
+	restarted.dispatchWg.Wait()
+	if requests := dispatcher.Requests(); len(requests) != 1 || requests[0].JobID != "nightly" {
+		t.Fatalf("API job did not run without configured jobs: %#v", requests)
+	}
+}
+
+func TestSchedulerKeepsUnschedulableJobsOnDisk(t *testing.T) {
+	statePath := t.TempDir() + "/heartbeat-state.json"
+	clock := &testClock{now: time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)}
+	cfg := heartbeatTestConfig(statePath)
+	scheduler, err := newScheduler(cfg, "", &recordingDispatcher{}, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	if _, err := scheduler.CreateJob(config.HeartbeatJobConfig{ID: "nightly", Runtime: agentruntime.OhMyCode, Agent: "qa-1", Text: "check", Cron: "0 2 * * *", Timezone: "UTC"}); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	rejectOhMyCode := func(job *config.HeartbeatJobConfig) error {
+		if job.Runtime == agentruntime.OhMyCode {
+			return errors.New("ohMyCode runtime is not enabled")
+		}
+		return nil
+	}
+	restarted, err := newScheduler(cfg, "", &recordingDispatcher{}, rejectOhMyCode, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("restart scheduler: %v", err)
+	}
+	if jobs := restarted.Status().Jobs; len(jobs) != 1 {
+		t.Fatalf("invalid API job should not be scheduled: %#v", jobs)
+	}
+	if _, err := restarted.CreateJob(config.HeartbeatJobConfig{ID: "weekly", Runtime: agentruntime.CodexAppCDP, Agent: "main", Text: "plan", Cron: "0 9 * * 1", Timezone: "UTC"}); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(statePath), defaultJobsFilename))
+	if err != nil {
+		t.Fatalf("read jobs file: %v", err)
+	}
+	if !strings.Contains(string(data), `"id": "nightly"`) || !strings.Contains(string(data), `"id": "weekly"`) {
+		t.Fatalf("jobs file lost an entry: %s", data)
+	}
+}
+
+func TestSchedulerPauseResumeAndRunNow(t *testing.T) {
+	statePath := t.TempDir() + "/heartbeat-state.json"
+	base := time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)
+	clock := &testClock{now: base}
+	release := make(chan struct{})
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "queued"}, block: release}
+	cfg := heartbeatTestConfig(statePath)
+	scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+
+	paused, err := scheduler.Pause("cloudbank-main", time.Time{}, "incident 42", "ops")
+	if err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if !paused.Paused || paused.NextRunAt != "" || paused.PauseReason != "incident 42" || paused.PausedBy != "ops" {
+		t.Fatalf("unexpected paused status: %#v", paused)
+	}
+	clock.Set(base.Add(time.Hour))
+	scheduler.runDue(clock.Now())
+	if len(dispatcher.Requests()) != 0 {
+		t.Fatalf("paused job was dispatched")
+	}
+
+	restarted, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("restart scheduler: %v", err)
+	}
+	if job := restarted.Status().Jobs[0]; !job.Paused || job.PauseReason != "incident 42" || job.NextRunAt != "" {
+		t.Fatalf("pause not restored: %#v", job)
+	}
+
+	if _, err := restarted.RunNow("cloudbank-main"); err != nil {
+		t.Fatalf("RunNow while paused: %v", err)
+	}
+	if _, err := restarted.RunNow("cloudbank-main"); !errors.Is(err, ErrJobBusy) {
+		t.Fatalf("expected busy error for overlapping run, got %v", err)
+	}
+	close(release)
+	waitForScheduler(t, restarted, func(job JobStatus) bool { return job.LastDispatchStatus == "queued" && !job.InFlight })
+	if requests := dispatcher.Requests(); len(requests) != 1 || requests[0].JobID != "cloudbank-main" {
+		t.Fatalf("unexpected run-now requests: %#v", requests)
+	}
+
+	until := clock.Now().Add(90 * time.Minute)
+	timed, err := restarted.Pause("cloudbank-main", until, "", "ops")
+	if err != nil {
+		t.Fatalf("timed Pause: %v", err)
+	}
+	if timed.PausedUntil != "2026-07-26T02:35:00Z" || timed.NextRunAt != "2026-07-26T02:40:00Z" {
+		t.Fatalf("unexpected timed pause: %#v", timed)
+	}
+	clock.Set(time.Date(2026, 7, 26, 2, 40, 0, 0, time.UTC))
+	restarted.runDue(clock.Now())
+	waitForScheduler(t, restarted, func(job JobStatus) bool { return !job.Paused && len(dispatcher.Requests()) == 2 })
+
+	if _, err := restarted.Pause("cloudbank-main", time.Time{}, "", "ops"); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	resumed, err := restarted.Resume("cloudbank-main")
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if resumed.Paused || resumed.NextRunAt != "2026-07-26T02:50:00Z" {
+		t.Fatalf("unexpected resumed status: %#v", resumed)
+	}
+	if _, err := restarted.Pause("cloudbank-main", clock.Now().Add(-time.Minute), "", "ops"); err == nil {
+		t.Fatalf("expected past pause end to be rejected")
+	}
+}
+
+func TestSchedulerRecordsRunHistory(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "heartbeat-state.json")
+	base := time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)
+	clock := &testClock{now: base}
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	dispatcher := &recordingDispatcher{
+		result:  agentruntime.DispatchResult{Status: "queued", EnvelopeID: "env-1"},
+		block:   release,
+		started: started,
+	}
+	cfg := heartbeatTestConfig(statePath)
+	cfg.Jobs[0].Cron = "* * * * *"
+	scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	if runs, err := scheduler.RunHistory("cloudbank-main", 0); err != nil || len(runs) != 0 {
+		t.Fatalf("expected empty history, runs=%#v err=%v", runs, err)
+	}
+
+	due := base.Add(time.Minute)
+	clock.Set(due)
+	scheduler.runDue(due)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled dispatch did not start")
+	}
+	clock.Set(due.Add(time.Minute))
+	scheduler.runDue(clock.Now())
+	close(release)
+	waitForScheduler(t, scheduler, func(job JobStatus) bool { return job.LastDispatchStatus == "queued" && !job.InFlight })
+	if _, err := scheduler.RunNow("cloudbank-main"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	waitForScheduler(t, scheduler, func(job JobStatus) bool { return !job.InFlight && len(dispatcher.Requests()) == 2 })
+
+	runs, err := scheduler.RunHistory("cloudbank-main", 0)
+	if err != nil {
+		t.Fatalf("RunHistory: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %#v", runs)
+	}
+	// Runs are logged when they finish, so the skip comes before the run it
+	// overlapped.
+	manual, scheduled, overlap := runs[0], runs[1], runs[2]
+	if manual.Trigger != RunTriggerManual || manual.Status != "queued" || manual.Attempts != 1 || manual.EnvelopeID != "env-1" {
+		t.Fatalf("unexpected manual run: %#v", manual)
+	}
+	if overlap.Trigger != RunTriggerSchedule || overlap.Status != "skipped_overlap" || overlap.Attempts != 0 || overlap.ScheduledAt != "2026-07-26T00:07:00Z" {
+		t.Fatalf("unexpected skipped run: %#v", overlap)
+	}
+	if scheduled.Trigger != RunTriggerSchedule || scheduled.Status != "queued" || scheduled.ScheduledAt != "2026-07-26T00:06:00Z" || scheduled.StartedAt == "" || scheduled.RunID == "" {
+		t.Fatalf("unexpected scheduled run: %#v", scheduled)
+	}
+	if limited, err := scheduler.RunHistory("cloudbank-main", 1); err != nil || len(limited) != 1 || limited[0].Trigger != RunTriggerManual {
+		t.Fatalf("limit did not return the newest run: %#v err=%v", limited, err)
+	}
+	if _, err := scheduler.RunHistory("missing", 0); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected not found for unknown job, got %v", err)
+	}
+}
+
+func TestSchedulerRotatesRunHistory(t *testing.T) {
+	previousBytes, previousFiles := historyMaxFileBytes, historyMaxFiles
+	historyMaxFileBytes, historyMaxFiles = 200, 3
+	t.Cleanup(func() { historyMaxFileBytes, historyMaxFiles = previousBytes, previousFiles })
+
+	statePath := filepath.Join(t.TempDir(), "heartbeat-state.json")
+	scheduler, err := newScheduler(heartbeatTestConfig(statePath), "", &recordingDispatcher{}, nil, time.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	for index := 0; index < 20; index++ {
+		run := RunRecord{JobID: "cloudbank-main", Trigger: RunTriggerSchedule, Status: "queued", Attempts: index}
+		if err := scheduler.recordRun(run); err != nil {
+			t.Fatalf("recordRun: %v", err)
+		}
+	}
+	base := filepath.Join(filepath.Dir(statePath), defaultHistoryDirname, "cloudbank-main.jsonl")
+	if _, err := os.Stat(base + ".2"); err != nil {
+		t.Fatalf("expected rotated history: %v", err)
+	}
+	if _, err := os.Stat(base + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected history beyond maxFiles to be removed, err=%v", err)
+	}
+	runs, err := scheduler.RunHistory("cloudbank-main", 0)
+	if err != nil {
+		t.Fatalf("RunHistory: %v", err)
+	}
+	if len(runs) == 0 || len(runs) >= 20 || runs[0].Attempts != 19 {
+		t.Fatalf("unexpected retained runs: %d newest=%#v", len(runs), runs[0])
+	}
+	for index := 1; index < len(runs); index++ {
+		if runs[index].Attempts != runs[index-1].Attempts-1 {
+			t.Fatalf("runs out of order at %d: %#v", index, runs)
+		}
+	}
+}
+
+func TestSchedulerQuietHoursSkipOrDeferFirings(t *testing.T) {
+	// 21:30 in Asia/Shanghai; the hourly 22:00 firing is inside quiet hours.
+	base := time.Date(2026, 7, 26, 13, 30, 0, 0, time.UTC)
+	quietStart := time.Date(2026, 7, 26, 14, 0, 0, 0, time.UTC)
+	quietEnd := time.Date(2026, 7, 26, 23, 0, 0, 0, time.UTC)
+	for policy, wantStatus := range map[string]string{
+		config.HeartbeatBlackoutSkip:  "skipped_blackout",
+		config.HeartbeatBlackoutDefer: "deferred_blackout",
+	} {
+		t.Run(policy, func(t *testing.T) {
+			clock := &testClock{now: base}
+			dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "queued"}}
+			cfg := heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json"))
+			cfg.Jobs[0].Cron = "0 * * * *"
+			cfg.Jobs[0].QuietHours = []config.HeartbeatQuietHoursConfig{{Start: "22:00", End: "07:00"}}
+			cfg.Jobs[0].BlackoutPolicy = policy
+			scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+			if err != nil {
+				t.Fatalf("newScheduler: %v", err)
+			}
+
+			clock.Set(quietStart)
+			scheduler.runDue(quietStart)
+			job := scheduler.Status().Jobs[0]
+			if len(dispatcher.Requests()) != 0 {
+				t.Fatalf("firing inside quiet hours was dispatched")
+			}
+			if job.NextRunAt != formatTime(quietEnd) || job.LastDispatchStatus != wantStatus {
+				t.Fatalf("unexpected held back status: %#v", job)
+			}
+			if !strings.Contains(job.LastScheduleReason, "quiet hours 22:00-07:00 Asia/Shanghai") || job.LastScheduleUpdatedBy != "heartbeat" {
+				t.Fatalf("missing blackout reason: %#v", job)
+			}
+			runs, err := scheduler.RunHistory("cloudbank-main", 0)
+			if err != nil || len(runs) != 1 || runs[0].Reason != job.LastScheduleReason {
+				t.Fatalf("held back firing not recorded: %#v err=%v", runs, err)
+			}
+
+			clock.Set(quietEnd)
+			scheduler.runDue(quietEnd)
+			waitForScheduler(t, scheduler, func(job JobStatus) bool { return job.LastDispatchStatus == "queued" })
+			if requests := dispatcher.Requests(); len(requests) != 1 || !requests[0].ScheduledAt.Equal(quietEnd) {
+				t.Fatalf("unexpected dispatch after quiet hours: %#v", requests)
+			}
+		})
+	}
+}
+
+func TestSchedulerHoldsBackFiringsWhileAgentActive(t *testing.T) {
+	base := time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)
+	due := time.Date(2026, 7, 26, 2, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		wantStatus string
+		wantNext   time.Time
+	}{
+		config.HeartbeatBlackoutSkip:  {wantStatus: "skipped_active", wantNext: time.Date(2026, 7, 26, 2, 10, 0, 0, time.UTC)},
+		config.HeartbeatBlackoutDefer: {wantStatus: "deferred_active", wantNext: time.Date(2026, 7, 26, 2, 8, 0, 0, time.UTC)},
+	}
+	for policy, test := range tests {
+		t.Run(policy, func(t *testing.T) {
+			clock := &testClock{now: base}
+			dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "queued"}}
+			cfg := heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json"))
+			cfg.Jobs[0].ActivityThreshold = "10m"
+			cfg.Jobs[0].ActivityPolicy = policy
+			scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+			if err != nil {
+				t.Fatalf("newScheduler: %v", err)
+			}
+
+			clock.Set(base.Add(3 * time.Minute))
+			scheduler.RecordActivity(agentruntime.ClaudeDesktop, "main", ActivityInbound)
+			scheduler.RecordActivity(agentruntime.CodexAppCDP, "main", ActivityInbound)
+			clock.Set(due)
+			scheduler.runDue(due)
+			job := scheduler.Status().Jobs[0]
+			if len(dispatcher.Requests()) != 0 {
+				t.Fatalf("firing was dispatched while the agent was active")
+			}
+			if job.LastDispatchStatus != test.wantStatus || job.NextRunAt != formatTime(test.wantNext) {
+				t.Fatalf("unexpected held back status: %#v", job)
+			}
+			if !strings.Contains(job.LastScheduleReason, "agent main was active (inbound message 2m0s ago)") {
+				t.Fatalf("missing activity reason: %#v", job)
+			}
+
+			busy := true
+			scheduler.SetBusyCheck(func(runtimeName, agentName string) bool {
+				return busy && runtimeName == agentruntime.CodexAppCDP && agentName == "main"
+			})
+			clock.Set(test.wantNext)
+			scheduler.runDue(test.wantNext)
+			job = scheduler.Status().Jobs[0]
+			if len(dispatcher.Requests()) != 0 || job.LastDispatchStatus != test.wantStatus || !strings.Contains(job.LastScheduleReason, "agent main is busy") {
+				t.Fatalf("firing not held back while busy: %#v", job)
+			}
+
+			busy = false
+			next, err := time.Parse(time.RFC3339, job.NextRunAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clock.Set(next)
+			scheduler.runDue(next)
+			waitForScheduler(t, scheduler, func(job JobStatus) bool { return job.LastDispatchStatus == "queued" })
+			if requests := dispatcher.Requests(); len(requests) != 1 || !requests[0].ScheduledAt.Equal(next) {
+				t.Fatalf("unexpected dispatch once idle: %#v", requests)
+			}
+		})
+	}
+}
+
+func TestSchedulerBacksOffOnHeartbeatOKReplies(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "delivered", Reply: agentruntime.HeartbeatOK}}
+	cfg := heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json"))
+	cfg.Jobs[0].AgentCronProfiles["deep-idle"] = "0 */6 * * *"
+	cfg.Jobs[0].ResetCronOnInbound = false
+	cfg.Jobs[0].BackoffAfter = 2
+	cfg.Jobs[0].BackoffLadder = []string{"idle", "deep-idle"}
+	scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	fire := func() JobStatus {
+		t.Helper()
+		next, err := time.Parse(time.RFC3339Nano, scheduler.Status().Jobs[0].NextRunAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock.Set(next)
+		scheduler.runDue(next)
+		waitForScheduler(t, scheduler, func(job JobStatus) bool {
+			return !job.InFlight && job.LastDispatchAt == formatTime(next)
+		})
+		return scheduler.Status().Jobs[0]
+	}
+
+	steps := []struct {
+		wantProfile string
+		wantOK      int
+		wantNext    time.Time
+	}{
+		{wantProfile: "", wantOK: 1, wantNext: time.Date(2026, 7, 26, 2, 10, 0, 0, time.UTC)},
+		{wantProfile: "idle", wantOK: 0, wantNext: time.Date(2026, 7, 26, 3, 0, 0, 0, time.UTC)},
+		{wantProfile: "idle", wantOK: 1, wantNext: time.Date(2026, 7, 26, 4, 0, 0, 0, time.UTC)},
+		{wantProfile: "deep-idle", wantOK: 0, wantNext: time.Date(2026, 7, 26, 10, 0, 0, 0, time.UTC)},
+		{wantProfile: "deep-idle", wantOK: 1, wantNext: time.Date(2026, 7, 26, 16, 0, 0, 0, time.UTC)},
+		{wantProfile: "deep-idle", wantOK: 2, wantNext: time.Date(2026, 7, 26, 22, 0, 0, 0, time.UTC)},
+	}
+	for index, step := range steps {
+		job := fire()
+		if job.EffectiveProfile != step.wantProfile || job.ConsecutiveOK != step.wantOK || job.NextRunAt != formatTime(step.wantNext) {
+			t.Fatalf("step %d: profile=%q ok=%d next=%s", index, job.EffectiveProfile, job.ConsecutiveOK, job.NextRunAt)
+		}
+		if step.wantProfile != "" && job.LastScheduleReason != "2 consecutive HEARTBEAT_OK replies" {
+			t.Fatalf("step %d: reason=%q", index, job.LastScheduleReason)
+		}
+	}
+
+	dispatcher.result.Reply = "Fixed the failing build."
+	if job := fire(); job.EffectiveProfile != "" || job.ConsecutiveOK != 0 || job.LastScheduleReason != "agent reported work" {
+		t.Fatalf("work reply did not restore the default cron: %#v", job)
+	}
+
+	dispatcher.result.Reply = agentruntime.HeartbeatOK
+	fire()
+	scheduler.ResetForInbound(agentruntime.CodexAppCDP, "main")
+	fire()
+	if job := scheduler.Status().Jobs[0]; job.EffectiveProfile != "" || job.ConsecutiveOK != 1 {
+		t.Fatalf("inbound did not restart the count: %#v", job)
+	}
+	fire()
+	scheduler.ResetForInbound(agentruntime.CodexAppCDP, "main")
+	if job := scheduler.Status().Jobs[0]; job.EffectiveProfile != "" || job.LastScheduleReason != "normal inbound activity" {
+		t.Fatalf("inbound did not restore the default cron: %#v", job)
+	}
+}
+
+func TestSchedulerBacksOffOnLateRuntimeReplies(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+	// Built-in runtimes only queue the wakeup and report the reply later.
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "delivered"}}
+	cfg := heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json"))
+	cfg.Jobs[0].ResetCronOnInbound = false
+	cfg.Jobs[0].BackoffAfter = 2
+	cfg.Jobs[0].BackoffLadder = []string{"idle"}
+	scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	fire := func() agentruntime.DispatchRequest {
+		t.Helper()
+		next, err := time.Parse(time.RFC3339Nano, scheduler.Status().Jobs[0].NextRunAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock.Set(next)
+		scheduler.runDue(next)
+		waitForScheduler(t, scheduler, func(job JobStatus) bool {
+			return !job.InFlight && job.LastDispatchAt == formatTime(next)
+		})
+		requests := dispatcher.Requests()
+		request := requests[len(requests)-1]
+		if !request.WantReply {
+			t.Fatalf("request does not ask for a reply: %#v", request)
+		}
+		return request
+	}
+
+	first := fire()
+	scheduler.ApplyReply("cloudbank-main", first.RunID, agentruntime.HeartbeatOK)
+	scheduler.ApplyReply("cloudbank-main", first.RunID, agentruntime.HeartbeatOK)
+	if job := scheduler.Status().Jobs[0]; job.ConsecutiveOK != 1 {
+		t.Fatalf("repeated reply was counted twice: %#v", job)
+	}
+	second := fire()
+	scheduler.ApplyReply("cloudbank-main", first.RunID, agentruntime.HeartbeatOK)
+	if job := scheduler.Status().Jobs[0]; job.ConsecutiveOK != 1 {
+		t.Fatalf("reply to an earlier run was counted: %#v", job)
+	}
+	scheduler.ApplyReply("cloudbank-main", second.RunID, agentruntime.HeartbeatOK)
+	if job := scheduler.Status().Jobs[0]; job.EffectiveProfile != "idle" || job.LastScheduleReason != "2 consecutive HEARTBEAT_OK replies" {
+		t.Fatalf("late replies did not back off: %#v", job)
+	}
+
+	third := fire()
+	scheduler.ApplyReply("cloudbank-main", third.RunID, "Fixed the failing build.")
+	if job := scheduler.Status().Jobs[0]; job.EffectiveProfile != "" || job.LastScheduleReason != "agent reported work" {
+		t.Fatalf("work reply did not restore the default cron: %#v", job)
+	}
+}
+
+func TestSchedulerNotifiesFailuresOncePerOutage(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "error", Error: "runtime unavailable"}}
+	cfg := heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json"))
+	cfg.Notify = &config.HeartbeatNotifyConfig{Channel: "telegram", To: "1"}
+	cfg.Jobs[0].Notify = &config.HeartbeatNotifyConfig{Channel: "slack", To: "C2", FailureThreshold: 2, UnreachableAfter: "25m"}
+	scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	scheduler.retryDelay = func(int) time.Duration { return 0 }
+	var alerts []string
+	scheduler.SetNotifier(func(ctx context.Context, channel, to, text string) error {
+		alerts = append(alerts, channel+"/"+to+": "+text)
+		return nil
+	})
+	fire := func() {
+		t.Helper()
+		next, err := time.Parse(time.RFC3339Nano, scheduler.Status().Jobs[0].NextRunAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock.Set(next)
+		scheduler.runDue(next)
+		scheduler.dispatchWg.Wait()
+	}
+
+	fire()
+	if len(alerts) != 0 {
+		t.Fatalf("alerted below the failure threshold: %q", alerts)
+	}
+	fire()
+	if len(alerts) != 1 || alerts[0] != "slack/C2: ⚠️ Heartbeat job cloudbank-main has failed 2 runs since 2026-07-26T02:00:00Z: runtime unavailable" {
+		t.Fatalf("unexpected failure alert: %q", alerts)
+	}
+	fire()
+	fire()
+	if len(alerts) != 2 || !strings.HasPrefix(alerts[1], "slack/C2: 🚨 Heartbeat job cloudbank-main: runtime codexAppCDP has been unreachable for 30m0s") {
+		t.Fatalf("unexpected unreachable alert: %q", alerts)
+	}
+	fire()
+	if job := scheduler.Status().Jobs[0]; len(alerts) != 2 || job.ConsecutiveFailures != 5 || job.FailingSince != "2026-07-26T02:00:00Z" {
+		t.Fatalf("outage not deduplicated: %q %#v", alerts, job)
+	}
+
+	dispatcher.result = agentruntime.DispatchResult{Status: "queued"}
+	fire()
+	fire()
+	if len(alerts) != 3 || alerts[2] != "slack/C2: ✅ Heartbeat job cloudbank-main recovered after failing since 2026-07-26T02:00:00Z" {
+		t.Fatalf("unexpected recovery alerts: %q", alerts)
+	}
+	if job := scheduler.Status().Jobs[0]; job.ConsecutiveFailures != 0 || job.FailingSince != "" {
+		t.Fatalf("failure state not cleared: %#v", job)
+	}
+}
+
+func TestJobSchedulesEveryAtAndWindow(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	base := time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)
+	compile := func(mutate func(*config.HeartbeatJobConfig)) cron.Schedule {
+		t.Helper()
+		job := heartbeatTestConfig("").Jobs[0]
+		job.Cron = ""
+		mutate(&job)
+		schedule, err := parseJobSchedule(job)
+		if err != nil {
+			t.Fatalf("parseJobSchedule: %v", err)
+		}
+		return schedule
+	}
+
+	hourly := compile(func(job *config.HeartbeatJobConfig) { job.Every = "1h" })
+	if next := hourly.Next(base); !next.Equal(time.Date(2026, 7, 26, 2, 0, 0, 0, time.UTC)) {
+		t.Fatalf("every 1h next=%s", next)
+	}
+
+	// Slots follow the wall clock of the job timezone.
+	daily := compile(func(job *config.HeartbeatJobConfig) { job.Every = "24h" })
+	if next := daily.Next(base); !next.Equal(time.Date(2026, 7, 27, 0, 0, 0, 0, shanghai)) {
+		t.Fatalf("every 24h next=%s", next)
+	}
+	nepal := compile(func(job *config.HeartbeatJobConfig) { job.Every, job.Timezone = "1h", "Asia/Kathmandu" })
+	if next := nepal.Next(base); !next.Equal(time.Date(2026, 7, 26, 2, 15, 0, 0, time.UTC)) {
+		t.Fatalf("every 1h in a +05:45 timezone next=%s", next)
+	}
+
+	jittered := compile(func(job *config.HeartbeatJobConfig) { job.Every, job.Jitter = "30m", "5m" })
+	slot := time.Date(2026, 7, 26, 2, 0, 0, 0, time.UTC)
+	first := jittered.Next(base)
+	if first.Before(slot) || !first.Before(slot.Add(5*time.Minute)) || !jittered.Next(base.Add(time.Minute)).Equal(first) {
+		t.Fatalf("jittered next=%s is not stable inside its slot", first)
+	}
+	if second := jittered.Next(first); second.Before(slot.Add(30*time.Minute)) || !second.Before(slot.Add(35*time.Minute)) {
+		t.Fatalf("jittered second=%s", second)
+	}
+
+	once := compile(func(job *config.HeartbeatJobConfig) { job.At = "2026-07-26 10:00" })
+	at := time.Date(2026, 7, 26, 10, 0, 0, 0, shanghai)
+	if next := once.Next(base); !next.Equal(at) || !once.Next(at).IsZero() {
+		t.Fatalf("at next=%s then %s", next, once.Next(at))
+	}
+
+	for _, window := range []config.HeartbeatWindowConfig{{Start: "09:00", End: "10:00"}, {Start: "23:30", End: "00:30"}} {
+		window := window
+		daily := compile(func(job *config.HeartbeatJobConfig) { job.Window = &window })
+		previous := base
+		for day := 0; day < 3; day++ {
+			next := daily.Next(previous)
+			local := next.In(shanghai)
+			minute := local.Hour()*60 + local.Minute()
+			inside := minute >= 9*60 && minute < 10*60
+			if window.Start == "23:30" {
+				inside = minute >= 23*60+30 || minute < 30
+			}
+			if !next.After(previous) || !inside || (day > 0 && next.Sub(previous) < 12*time.Hour) {
+				t.Fatalf("window %s-%s day %d: next=%s after %s", window.Start, window.End, day, local, previous.In(shanghai))
+			}
+			previous = next
+		}
+	}
+}
+
+func TestSchedulerDeletesOneShotAPIJobAfterRunning(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "queued"}}
+	scheduler, err := newScheduler(heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json")), "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	created, err := scheduler.CreateJob(config.HeartbeatJobConfig{
+		ID:       "once",
+		Runtime:  agentruntime.CodexAppCDP,
+		Agent:    "main",
+		Text:     "check the release",
+		At:       "2026-07-26T02:05:00Z",
+		Timezone: "UTC",
+	})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	if created.At != "2026-07-26T02:05:00Z" || created.ConfiguredCron != "" || created.NextRunAt != "2026-07-26T02:05:00Z" {
+		t.Fatalf("unexpected one-shot job: %#v", created)
+	}
+
+	due := time.Date(2026, 7, 26, 2, 5, 0, 0, time.UTC)
+	clock.Set(due)
+	scheduler.runDue(due)
+	scheduler.dispatchWg.Wait()
+	// The config job's 02:00 firing is missed; only the one-shot runs.
+	if requests := dispatcher.Requests(); len(requests) != 1 || requests[0].JobID != "once" || !requests[0].ExpiresAt.IsZero() {
+		t.Fatalf("unexpected dispatches: %#v", requests)
+	}
+	if jobs := scheduler.Status().Jobs; len(jobs) != 1 || jobs[0].ID != "cloudbank-main" {
+		t.Fatalf("one-shot job was not deleted: %#v", jobs)
+	}
+	if runs, err := scheduler.RunHistory("once", 0); err != nil || len(runs) != 1 || runs[0].Status != "queued" {
+		t.Fatalf("one-shot run not kept in history: %#v err=%v", runs, err)
+	}
+}
+
+func TestSchedulerDeletesOneShotAPIJobAfterFailedOrMissedRun(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		runAt      time.Time
+		wantStatus string
+		wantRuns   int
+	}{
+		{name: "failed", runAt: time.Date(2026, 7, 26, 2, 5, 0, 0, time.UTC), wantStatus: "error", wantRuns: 1},
+		{name: "missed", runAt: time.Date(2026, 7, 26, 3, 0, 0, 0, time.UTC), wantStatus: "skipped_missed"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+			dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "error", Error: "runtime offline"}}
+			scheduler, err := newScheduler(heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json")), "", dispatcher, nil, clock.Now, time.Hour)
+			if err != nil {
+				t.Fatalf("newScheduler: %v", err)
+			}
+			scheduler.retryDelay = func(int) time.Duration { return 0 }
+			if _, err := scheduler.CreateJob(config.HeartbeatJobConfig{
+				ID:       "once",
+				Runtime:  agentruntime.CodexAppCDP,
+				Agent:    "main",
+				Text:     "check the release",
+				At:       "2026-07-26T02:05:00Z",
+				Timezone: "UTC",
+			}); err != nil {
+				t.Fatalf("CreateJob: %v", err)
+			}
+
+			clock.Set(test.runAt)
+			scheduler.runDue(test.runAt)
+			scheduler.dispatchWg.Wait()
+			for _, job := range scheduler.Status().Jobs {
+				if job.ID == "once" {
+					t.Fatalf("one-shot job was kept: %#v", job)
+				}
+			}
+			onceRuns := 0
+			for _, request := range dispatcher.Requests() {
+				if request.JobID == "once" {
+					onceRuns++
+				}
+			}
+			if onceRuns != test.wantRuns*maxDispatchAttempts {
+				t.Fatalf("one-shot dispatched %d times", onceRuns)
+			}
+			runs, err := scheduler.RunHistory("once", 0)
+			if err != nil || len(runs) != 1 || runs[0].Status != test.wantStatus || runs[0].ScheduledAt != "2026-07-26T02:05:00Z" {
+				t.Fatalf("final one-shot run not recorded: %#v err=%v", runs, err)
+			}
+		})
+	}
+}
+
+func TestSchedulerRunNowKeepsOneShotAPIJob(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "queued"}}
+	scheduler, err := newScheduler(heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json")), "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	if _, err := scheduler.CreateJob(config.HeartbeatJobConfig{
+		ID:       "once",
+		Runtime:  agentruntime.CodexAppCDP,
+		Agent:    "main",
+		Text:     "check the release",
+		At:       "2026-07-26T02:05:00Z",
+		Timezone: "UTC",
+	}); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	// Neither a manual run while the job is paused nor one after resuming
+	// retires it before its scheduled run.
+	if _, err := scheduler.Pause("once", time.Time{}, "investigating", "ops"); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	for _, paused := range []bool{true, false} {
+		if !paused {
+			if _, err := scheduler.Resume("once"); err != nil {
+				t.Fatalf("Resume: %v", err)
+			}
+		}
+		if _, err := scheduler.RunNow("once"); err != nil {
+			t.Fatalf("RunNow (paused=%v): %v", paused, err)
+		}
+		scheduler.dispatchWg.Wait()
+		var status *JobStatus
+		for _, job := range scheduler.Status().Jobs {
+			if job.ID == "once" {
+				status = &job
+			}
+		}
+		if status == nil {
+			t.Fatalf("one-shot job deleted by manual run (paused=%v)", paused)
+		}
+		wantNext := "2026-07-26T02:05:00Z"
+		if paused {
+			wantNext = ""
+		}
+		if status.LastDispatchStatus != "queued" || status.NextRunAt != wantNext {
+			t.Fatalf("unexpected one-shot status (paused=%v): %#v", paused, status)
+		}
+	}
+}
+
+func TestSchedulerRejectsPastOneShotTime(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+	scheduler, err := newScheduler(heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json")), "", &recordingDispatcher{}, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	job := config.HeartbeatJobConfig{ID: "once", Runtime: agentruntime.CodexAppCDP, Agent: "main", Text: "check", At: "2026-07-26T01:00:00Z", Timezone: "UTC"}
+	if _, err := scheduler.CreateJob(job); err == nil || !strings.Contains(err.Error(), "is in the past") {
+		t.Fatalf("expected past at to be rejected, got %v", err)
+	}
+	job.At = "2026-07-26T03:00:00Z"
+	if _, err := scheduler.CreateJob(job); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	past, shanghai := "2026-07-26 09:00", "Asia/Shanghai"
+	if _, err := scheduler.UpdateJob("once", JobUpdate{At: &past, Timezone: &shanghai}); err == nil || !strings.Contains(err.Error(), "is in the past") {
+		t.Fatalf("expected past at update to be rejected, got %v", err)
+	}
+	text := "check again"
+	if _, err := scheduler.UpdateJob("once", JobUpdate{Text: &text}); err != nil {
+		t.Fatalf("UpdateJob text: %v", err)
+	}
+}
+
+func TestSchedulerRendersTextTemplate(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 7, 26, 1, 55, 0, 0, time.UTC)}
+	dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "queued"}}
+	cfg := heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json"))
+	cfg.Jobs[0].Text = `It is {{.Now.Format "15:04"}}. Last run: {{if .LastRunAt.IsZero}}never{{else}}{{.LastRunAt.Format "15:04"}} {{.LastStatus}}{{end}}. ` +
+		`Inbound {{.SinceInbound}} ago, {{.PendingInbox}} pending, profile {{or .Profile "default"}}.`
+	scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+	if err != nil {
+		t.Fatalf("newScheduler: %v", err)
+	}
+	scheduler.SetInboxCounter(func(runtimeName, agentName string) int {
+		// Counting reads the inbox, so it runs outside the scheduler lock.
+		_ = scheduler.Status()
+		if runtimeName == agentruntime.CodexAppCDP && agentName == "main" {
+			return 2
+		}
+		return 0
+	})
+	clock.Set(time.Date(2026, 7, 26, 1, 57, 0, 0, time.UTC))
+	scheduler.RecordActivity(agentruntime.CodexAppCDP, "main", ActivityInbound)
+
+	for _, due := range []time.Time{time.Date(2026, 7, 26, 2, 0, 0, 0, time.UTC), time.Date(2026, 7, 26, 2, 10, 0, 0, time.UTC)} {
+		clock.Set(due)
+		scheduler.runDue(due)
+		scheduler.dispatchWg.Wait()
+	}
+	requests := dispatcher.Requests()
+	want := []string{
+		"It is 10:00. Last run: never. Inbound 3m0s ago, 2 pending, profile default.",
+		"It is 10:10. Last run: 10:00 queued. Inbound 13m0s ago, 2 pending, profile default.",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("unexpected dispatches: %#v", requests)
+	}
+	for idx, request := range requests {
+		if request.Text != want[idx] {
+			t.Fatalf("request %d text = %q, want %q", idx, request.Text, want[idx])
+		}
+	}
+
+	_, err = scheduler.CreateJob(config.HeartbeatJobConfig{
+		ID:       "typo",
+		Runtime:  agentruntime.CodexAppCDP,
+		Agent:    "main",
+		Text:     "{{.Pending}} messages waiting",
+		Cron:     "0 * * * *",
+		Timezone: "UTC",
+	})
+	if err == nil || !strings.Contains(err.Error(), "can't evaluate field Pending") {
+		t.Fatalf("expected unknown template field error, got %v", err)
+	}
+}
+
+func TestBlackoutCalendarsFromDatesAndICS(t *testing.T) {
+	icsPath := filepath.Join(t.TempDir(), "holidays.ics")
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Company\r\n  offsite\r\nDTSTART;VALUE=DATE:20261224\r\nDTEND;VALUE=DATE:20261227\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART:20261231T160000Z\r\nDTEND:20261231T200000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if err := os.WriteFile(icsPath, []byte(ics), 0600); err != nil {
+		t.Fatal(err)
+	}
+	calendars, err := loadCalendars(map[string]config.HeartbeatCalendarConfig{
+		"holidays": {Dates: []string{"2026-10-01"}, ICSPath: icsPath},
+	})
+	if err != nil {
+		t.Fatalf("loadCalendars: %v", err)
+	}
+	job := heartbeatTestConfig("").Jobs[0]
+	job.Blackouts = []string{"holidays"}
+	blackouts, err := compileBlackouts(job, calendars)
+	if err != nil {
+		t.Fatalf("compileBlackouts: %v", err)
+	}
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	tests := []struct {
+		at      time.Time
+		wantEnd time.Time
+	}{
+		{at: time.Date(2026, 10, 1, 9, 0, 0, 0, shanghai), wantEnd: time.Date(2026, 10, 2, 0, 0, 0, 0, shanghai)},
+		{at: time.Date(2026, 12, 25, 12, 0, 0, 0, shanghai), wantEnd: time.Date(2026, 12, 27, 0, 0, 0, 0, shanghai)},
+		{at: time.Date(2026, 12, 31, 17, 0, 0, 0, time.UTC), wantEnd: time.Date(2026, 12, 31, 20, 0, 0, 0, time.UTC)},
+		{at: time.Date(2026, 12, 27, 0, 0, 0, 0, shanghai)},
+	}
+	for _, test := range tests {
+		end, label, blocked := blackouts.at(test.at)
+		if blocked != !test.wantEnd.IsZero() || !end.Equal(test.wantEnd) {
+			t.Fatalf("at %s: end=%s blocked=%t want end %s", test.at, end, blocked, test.wantEnd)
+		}
+		if blocked && label != "blackout calendar holidays" {
+			t.Fatalf("label=%q", label)
+		}
+	}
+	if _, err := parseICS([]byte("BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n")); err == nil {
+		t.Fatalf("expected invalid DTSTART to fail")
+	}
+}
+
+func TestSchedulerMisfirePolicyAfterDowntime(t *testing.T) {
+	base := time.Date(2026, 7, 26, 0, 5, 0, 0, time.UTC)
+	restartAt := time.Date(2026, 7, 26, 5, 30, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return time.Date(2026, 7, 26, h, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name         string
+		policy       string
+		limit        int
+		expiry       string
+		restartAt    time.Time
+		wantMissed   int
+		wantLastMiss time.Time
+		wantCatchUp  []time.Time
+		wantNext     time.Time
+	}{
+		{name: "skip", policy: config.HeartbeatMisfireSkip, restartAt: restartAt, wantMissed: 5, wantLastMiss: hour(5), wantNext: hour(6)},
+		{name: "run once", policy: config.HeartbeatMisfireRunOnce, restartAt: restartAt, wantMissed: 4, wantLastMiss: hour(4), wantCatchUp: []time.Time{hour(5)}, wantNext: hour(6)},
+		{name: "run all up to limit within expiry", policy: config.HeartbeatMisfireRunAll, limit: 2, expiry: "3h", restartAt: restartAt, wantMissed: 3, wantLastMiss: hour(3), wantCatchUp: []time.Time{hour(4), hour(5)}, wantNext: hour(6)},
+		{name: "run once with a current firing", policy: config.HeartbeatMisfireRunOnce, restartAt: hour(5).Add(30 * time.Second), wantMissed: 4, wantLastMiss: hour(4), wantNext: hour(5)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := &testClock{now: base}
+			dispatcher := &recordingDispatcher{result: agentruntime.DispatchResult{Status: "queued"}}
+			cfg := heartbeatTestConfig(filepath.Join(t.TempDir(), "heartbeat-state.json"))
+			cfg.Jobs[0].Cron = "0 * * * *"
+			cfg.Jobs[0].MisfirePolicy = test.policy
+			cfg.Jobs[0].MisfireLimit = test.limit
+			cfg.Jobs[0].MisfireExpiry = test.expiry
+			scheduler, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+			if err != nil {
+				t.Fatalf("newScheduler: %v", err)
+			}
+			scheduler.runDue(base)
+
+			clock.Set(test.restartAt)
+			restarted, err := newScheduler(cfg, "", dispatcher, nil, clock.Now, time.Hour)
+			if err != nil {
+				t.Fatalf("restart scheduler: %v", err)
+			}
+			job := restarted.Status().Jobs[0]
+			if job.MissedRuns != test.wantMissed || job.LastMissedAt != formatTime(test.wantLastMiss) || job.PendingCatchUp != len(test.wantCatchUp) || job.NextRunAt != formatTime(test.wantNext) {
+				t.Fatalf("unexpected status after restart: %#v", job)
+			}
+
+			wantRuns := len(test.wantCatchUp)
+			if test.wantNext.Before(test.restartAt) {
+				wantRuns++
+			}
+			// Catch-ups run one per tick, never overlapping the job.
+			for tick := 0; tick <= wantRuns; tick++ {
+				restarted.runDue(clock.Now())
+				waitForScheduler(t, restarted, func(job JobStatus) bool { return !job.InFlight })
+			}
+			requests := dispatcher.Requests()
+			if len(requests) != wantRuns {
+				t.Fatalf("dispatches=%d want %d", len(requests), wantRuns)
+			}
+			for index, scheduledAt := range test.wantCatchUp {
+				if !requests[index].ScheduledAt.Equal(scheduledAt) {
+					t.Fatalf("catch-up %d scheduled at %s, want %s", index, requests[index].ScheduledAt, scheduledAt)
+				}
+			}
+			if wantRuns > 0 {
+				runs, err := restarted.RunHistory("cloudbank-main", 0)
+				if err != nil || len(runs) != wantRuns {
+					t.Fatalf("runs=%#v err=%v", runs, err)
+				}
+				if len(test.wantCatchUp) > 0 && runs[len(runs)-1].Trigger != RunTriggerCatchUp {
+					t.Fatalf("catch-up run trigger=%q", runs[len(runs)-1].Trigger)
+				}
+			}
+		})
+	}
+}
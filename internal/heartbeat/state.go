@@ -29,6 +29,27 @@ type persistedJobState struct {
 	ScheduleReason     string    `json:"schedule_reason,omitempty"`
 	ScheduleUpdatedBy  string    `json:"schedule_updated_by,omitempty"`
 	ScheduleUpdatedAt  time.Time `json:"schedule_updated_at,omitempty"`
+	Paused             bool      `json:"paused,omitempty"`
+	PausedUntil        time.Time `json:"paused_until,omitempty"`
+	PauseReason        string    `json:"pause_reason,omitempty"`
+	PausedBy           string    `json:"paused_by,omitempty"`
+	PausedAt           time.Time `json:"paused_at,omitempty"`
+
+	// CatchUp holds missed firings still to run under a run-once or run-all
+	// misfire policy, oldest first.
+	CatchUp      []time.Time `json:"catch_up,omitempty"`
+	MissedRuns   int         `json:"missed_runs,omitempty"`
+	LastMissedAt time.Time   `json:"last_missed_at,omitempty"`
+
+	// ConsecutiveOK counts HEARTBEAT_OK replies since the last backoff step.
+	ConsecutiveOK int `json:"consecutive_ok,omitempty"`
+
+	// Failure tracking for notify alerts; FailingSince is zero while healthy.
+	ConsecutiveFailures  int       `json:"consecutive_failures,omitempty"`
+	ConsecutiveSuccesses int       `json:"consecutive_successes,omitempty"`
+	FailingSince         time.Time `json:"failing_since,omitempty"`
+	FailureAlerted       bool      `json:"failure_alerted,omitempty"`
+	UnreachableAlerted   bool      `json:"unreachable_alerted,omitempty"`
 }
 
 func decodePersistedState(data []byte) (persistedState, error) {
@@ -55,38 +76,44 @@ func (s *Scheduler) saveStateLocked() error {
 	for id, job := range s.jobs {
 		state.Jobs[id] = job.state
 	}
-	data, err := json.MarshalIndent(state, "", "  ")
+	return writeStateFile(s.statePath, "heartbeat state", state)
+}
+
+// writeStateFile atomically replaces path with value encoded as indented
+// JSON. label names the file in errors.
+func writeStateFile(path, label string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
 	if err != nil {
-		return fmt.Errorf("encode heartbeat state: %w", err)
+		return fmt.Errorf("encode %s: %w", label, err)
 	}
 	data = append(data, '\n')
-	directory := filepath.Dir(s.statePath)
+	directory := filepath.Dir(path)
 	if err := os.MkdirAll(directory, 0700); err != nil {
-		return fmt.Errorf("create heartbeat state directory: %w", err)
+		return fmt.Errorf("create %s directory: %w", label, err)
 	}
-	tmp, err := os.CreateTemp(directory, ".heartbeat-state-*.tmp")
+	tmp, err := os.CreateTemp(directory, "."+filepath.Base(path)+"-*.tmp")
 	if err != nil {
-		return fmt.Errorf("create heartbeat state temp file: %w", err)
+		return fmt.Errorf("create %s temp file: %w", label, err)
 	}
 	tmpPath := tmp.Name()
 	defer func() { _ = os.Remove(tmpPath) }()
 	if err := tmp.Chmod(0600); err != nil {
 		_ = tmp.Close()
-		return fmt.Errorf("set heartbeat state permissions: %w", err)
+		return fmt.Errorf("set %s permissions: %w", label, err)
 	}
 	if _, err := tmp.Write(data); err != nil {
 		_ = tmp.Close()
-		return fmt.Errorf("write heartbeat state: %w", err)
+		return fmt.Errorf("write %s: %w", label, err)
 	}
 	if err := tmp.Sync(); err != nil {
 		_ = tmp.Close()
-		return fmt.Errorf("sync heartbeat state: %w", err)
+		return fmt.Errorf("sync %s: %w", label, err)
 	}
 	if err := tmp.Close(); err != nil {
-		return fmt.Errorf("close heartbeat state: %w", err)
+		return fmt.Errorf("close %s: %w", label, err)
 	}
-	if err := os.Rename(tmpPath, s.statePath); err != nil {
-		return fmt.Errorf("commit heartbeat state: %w", err)
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("commit %s: %w", label, err)
 	}
 	return nil
 }
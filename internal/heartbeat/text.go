@@ -0,0 +1,103 @@
+package heartbeat
+
+import (
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// InboxCounter counts the messages waiting in an agent's runtime inbox. It
+// must not change the inbox.
+type InboxCounter func(runtimeName, agentName string) int
+
+// SetInboxCounter registers how to count an agent's pending inbox messages
+// for the PendingInbox template value.
+func (s *Scheduler) SetInboxCounter(count InboxCounter) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.inboxCount = count
+	s.mu.Unlock()
+}
+
+// parseText compiles a job's text with config.ParseHeartbeatText, so it
+// accepts exactly what config validation does. Plain text has no template.
+func parseText(jobConfig config.HeartbeatJobConfig) (*template.Template, error) {
+	if !strings.Contains(jobConfig.Text, "{{") {
+		return nil, nil
+	}
+	return config.ParseHeartbeatText(jobConfig.ID, jobConfig.Text)
+}
+
+// jobText is a job's text with the run context captured under the scheduler
+// lock. It is rendered by the dispatch goroutine, outside the lock, because
+// counting pending inbox messages reads the inbox directory.
+type jobText struct {
+	raw        string
+	template   *template.Template
+	data       config.HeartbeatTextData
+	inboxCount InboxCounter
+}
+
+// textLocked captures what the job's text template needs for a firing.
+func (s *Scheduler) textLocked(job *compiledJob, scheduledAt time.Time) jobText {
+	text := jobText{raw: job.config.Text, template: job.text}
+	if job.text == nil {
+		return text
+	}
+	location := job.location
+	if location == nil {
+		location = time.Local
+	}
+	inLocation := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		return t.In(location)
+	}
+	now := s.now()
+	runtimeName, agentName := job.config.Runtime, job.config.Agent
+	text.data = config.HeartbeatTextData{
+		JobID:       job.config.ID,
+		Runtime:     runtimeName,
+		Agent:       agentName,
+		Now:         inLocation(now),
+		ScheduledAt: inLocation(scheduledAt),
+		Profile:     job.state.EffectiveProfile,
+		LastRunAt:   inLocation(job.state.LastDispatchAt),
+		LastStatus:  job.state.LastDispatchStatus,
+	}
+	var lastInbound time.Time
+	for _, key := range []string{activityKey(runtimeName, agentName), activityKey("", agentName)} {
+		if at := s.inbound[key]; at.After(lastInbound) {
+			lastInbound = at
+		}
+	}
+	if !lastInbound.IsZero() {
+		text.data.LastInboundAt = inLocation(lastInbound)
+		text.data.SinceInbound = now.Sub(lastInbound).Round(time.Second)
+	}
+	text.inboxCount = s.inboxCount
+	return text
+}
+
+// render returns the text for the agent. A template that fails at run time
+// falls back to the raw text rather than dropping the heartbeat.
+func (t jobText) render() string {
+	if t.template == nil {
+		return t.raw
+	}
+	if t.inboxCount != nil {
+		t.data.PendingInbox = t.inboxCount(t.data.Runtime, t.data.Agent)
+	}
+	var out strings.Builder
+	if err := t.template.Execute(&out, t.data); err != nil {
+		log.Printf("heartbeat: render text of job %q: %v", t.data.JobID, err)
+		return t.raw
+	}
+	return strings.TrimSpace(out.String())
+}
@@ -0,0 +1,522 @@
+// Package inbox manages the durable fallback inboxes used by the desktop Agent
+// Runtimes.
+//
+// An inbox is a directory. Pending items are JSON files at its root, so
+// existing consumers that only read the root keep working. Claimed, done and
+// failed items live in subdirectories of the same name, and per-item lease and
+// attempt metadata is kept in a hidden .state directory. Every transition is a
+// rename, which makes concurrent claims from the gateway and the CLI safe.
+package inbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// State is the lifecycle state of an inbox item.
+type State string
+
+const (
+	StatePending State = "pending"
+	StateClaimed State = "claimed"
+	StateDone    State = "done"
+	StateFailed  State = "failed"
+)
+
+const (
+	stateDirName = ".state"
+	itemSuffix   = ".json"
+
+	// DefaultLease is used when a claim does not specify a lease.
+	DefaultLease = 10 * time.Minute
+
+	// MaxAttempts is the number of expired leases after which an item is
+	// moved to failed instead of back to pending.
+	MaxAttempts = 5
+)
+
+// ErrNotFound is returned when an item does not exist in the expected state.
+var ErrNotFound = errors.New("inbox item not found")
+
+// ErrClaimed is returned by Put when a consumer holds the item under a lease.
+var ErrClaimed = errors.New("inbox item is claimed")
+
+// Item describes one inbox entry.
+type Item struct {
+	ID             string    `json:"id"`
+	State          State     `json:"state"`
+	Path           string    `json:"path"`
+	EnvelopeID     string    `json:"envelope_id,omitempty"`
+	SelectedAgent  string    `json:"selected_agent,omitempty"`
+	Channel        string    `json:"channel,omitempty"`
+	JobID          string    `json:"job_id,omitempty"`
+	QueuedAt       time.Time `json:"queued_at"`
+	Attempts       int       `json:"attempts,omitempty"`
+	Owner          string    `json:"owner,omitempty"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+// Stats summarizes an inbox.
+type Stats struct {
+	Pending         int       `json:"pending"`
+	Claimed         int       `json:"claimed"`
+	Done            int       `json:"done"`
+	Failed          int       `json:"failed"`
+	OldestPendingAt time.Time `json:"oldest_pending_at,omitempty"`
+}
+
+type itemMeta struct {
+	QueuedAt       time.Time `json:"queued_at"`
+	Attempts       int       `json:"attempts,omitempty"`
+	Owner          string    `json:"owner,omitempty"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+// Store operates on one inbox directory.
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// Open returns a store for dir. The directory is created on first write.
+func Open(dir string) *Store {
+	return &Store{dir: dir, now: time.Now}
+}
+
+// Dir returns the inbox directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Put atomically writes payload as a pending item named name and returns its
+// path. Writing an existing name replaces the pending item, which is how
+// heartbeat wakeups are coalesced. It returns ErrClaimed while a consumer
+// holds the item.
+func (s *Store) Put(name string, payload interface{}) (string, error) {
+	id := strings.TrimSuffix(name, itemSuffix)
+	if err := validateID(id); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(s.itemPath(StateClaimed, id)); err == nil {
+		return "", fmt.Errorf("%w: %s", ErrClaimed, id)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return "", fmt.Errorf("create inbox: %w", err)
+	}
+	finalPath := filepath.Join(s.dir, id+itemSuffix)
+	tmp, err := os.CreateTemp(s.dir, "."+id+"-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("create inbox temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("set inbox permissions: %w", err)
+	}
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(payload); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("encode inbox envelope: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("close inbox temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return "", fmt.Errorf("commit inbox envelope: %w", err)
+	}
+
+	// A replaced item starts a fresh lifecycle. Metadata is only written on
+	// the first transition so the inbox root holds nothing but pending items.
+	for _, state := range []State{StateDone, StateFailed} {
+		_ = os.Remove(s.itemPath(state, id))
+	}
+	_ = os.Remove(s.metaPath(id))
+	return finalPath, nil
+}
+
+// List returns items in state, or in every state when state is empty, oldest
+// first. It does not change the inbox, so an item whose lease expired is
+// listed as claimed until the next Claim or ReapExpired.
+func (s *Store) List(state State) ([]Item, error) {
+	states := []State{StatePending, StateClaimed, StateDone, StateFailed}
+	if state != "" {
+		if err := validateState(state); err != nil {
+			return nil, err
+		}
+		states = []State{state}
+	}
+	var items []Item
+	for _, st := range states {
+		stateItems, err := s.listState(st)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, stateItems...)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].QueuedAt.Before(items[j].QueuedAt)
+	})
+	return items, nil
+}
+
+// Get returns the item and its raw payload.
+func (s *Store) Get(id string) (Item, []byte, error) {
+	if err := validateID(id); err != nil {
+		return Item{}, nil, err
+	}
+	for _, state := range []State{StateClaimed, StatePending, StateFailed, StateDone} {
+		path := s.itemPath(state, id)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Item{}, nil, err
+		}
+		return s.buildItem(state, id, data), data, nil
+	}
+	return Item{}, nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// Claim moves the oldest pending item to claimed under a lease held by owner.
+// Expired leases are reclaimed first. It returns ErrNotFound when nothing is
+// pending.
+func (s *Store) Claim(owner string, lease time.Duration) (Item, error) {
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+	if _, err := s.ReapExpired(); err != nil {
+		return Item{}, err
+	}
+	pending, err := s.List(StatePending)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := os.MkdirAll(filepath.Join(s.dir, string(StateClaimed)), 0700); err != nil {
+		return Item{}, fmt.Errorf("create inbox: %w", err)
+	}
+	for _, item := range pending {
+		if err := os.Rename(s.itemPath(StatePending, item.ID), s.itemPath(StateClaimed, item.ID)); err != nil {
+			// Another consumer claimed it first.
+			continue
+		}
+		meta := s.readMeta(item.ID)
+		now := s.now().UTC()
+		meta.Owner = strings.TrimSpace(owner)
+		meta.LeaseExpiresAt = now.Add(lease)
+		meta.UpdatedAt = now
+		if err := s.writeMeta(item.ID, meta); err != nil {
+			return Item{}, err
+		}
+		claimed, _, err := s.Get(item.ID)
+		return claimed, err
+	}
+	return Item{}, ErrNotFound
+}
+
+// Ack finishes a claimed or pending item as done, or as failed with reason.
+func (s *Store) Ack(id string, failed bool, reason string) (Item, error) {
+	target := StateDone
+	if failed {
+		target = StateFailed
+	}
+	return s.move(id, []State{StateClaimed, StatePending}, target, func(meta *itemMeta) {
+		meta.Owner = ""
+		meta.LeaseExpiresAt = time.Time{}
+		meta.Error = strings.TrimSpace(reason)
+	})
+}
+
+// Requeue returns a claimed, failed or done item to pending.
+func (s *Store) Requeue(id string) (Item, error) {
+	return s.move(id, []State{StateClaimed, StateFailed, StateDone}, StatePending, func(meta *itemMeta) {
+		meta.Owner = ""
+		meta.LeaseExpiresAt = time.Time{}
+		meta.Error = ""
+	})
+}
+
+// Purge deletes done or failed items last updated more than olderThan ago and
+// returns how many were removed.
+func (s *Store) Purge(state State, olderThan time.Duration) (int, error) {
+	if state != StateDone && state != StateFailed {
+		return 0, fmt.Errorf("only %s and %s items can be purged", StateDone, StateFailed)
+	}
+	items, err := s.listState(state)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := s.now().Add(-olderThan)
+	removed := 0
+	for _, item := range items {
+		if olderThan > 0 && item.UpdatedAt.After(cutoff) {
+			continue
+		}
+		if err := os.Remove(item.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		_ = os.Remove(s.metaPath(item.ID))
+		removed++
+	}
+	return removed, nil
+}
+
+// ReapExpired returns claimed items with an expired lease to pending, or moves
+// them to failed after MaxAttempts expirations.
+func (s *Store) ReapExpired() (int, error) {
+	claimed, err := s.listState(StateClaimed)
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	reaped := 0
+	for _, item := range claimed {
+		if item.LeaseExpiresAt.IsZero() || item.LeaseExpiresAt.After(now) {
+			continue
+		}
+		target := StatePending
+		if item.Attempts+1 >= MaxAttempts {
+			target = StateFailed
+		}
+		_, err := s.move(item.ID, []State{StateClaimed}, target, func(meta *itemMeta) {
+			meta.Attempts++
+			meta.Error = fmt.Sprintf("lease held by %s expired", defaultOwner(meta.Owner))
+			meta.Owner = ""
+			meta.LeaseExpiresAt = time.Time{}
+		})
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return reaped, err
+		}
+		reaped++
+	}
+	return reaped, nil
+}
+
+// Stats counts items per state and reports the oldest pending item.
+func (s *Store) Stats() (Stats, error) {
+	items, err := s.List("")
+	if err != nil {
+		return Stats{}, err
+	}
+	var stats Stats
+	for _, item := range items {
+		switch item.State {
+		case StatePending:
+			stats.Pending++
+			if stats.OldestPendingAt.IsZero() || item.QueuedAt.Before(stats.OldestPendingAt) {
+				stats.OldestPendingAt = item.QueuedAt
+			}
+		case StateClaimed:
+			stats.Claimed++
+		case StateDone:
+			stats.Done++
+		case StateFailed:
+			stats.Failed++
+		}
+	}
+	return stats, nil
+}
+
+func (s *Store) move(id string, from []State, to State, update func(*itemMeta)) (Item, error) {
+	if err := validateID(id); err != nil {
+		return Item{}, err
+	}
+	if to != StatePending {
+		if err := os.MkdirAll(filepath.Join(s.dir, string(to)), 0700); err != nil {
+			return Item{}, fmt.Errorf("create inbox: %w", err)
+		}
+	}
+	for _, state := range from {
+		err := os.Rename(s.itemPath(state, id), s.itemPath(to, id))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Item{}, fmt.Errorf("move inbox item %s: %w", id, err)
+		}
+		meta := s.readMeta(id)
+		update(&meta)
+		meta.UpdatedAt = s.now().UTC()
+		if err := s.writeMeta(id, meta); err != nil {
+			return Item{}, err
+		}
+		item, _, err := s.Get(id)
+		return item, err
+	}
+	return Item{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+func (s *Store) listState(state State) ([]Item, error) {
+	dir := s.stateDir(state)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read inbox: %w", err)
+	}
+	var items []Item
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, itemSuffix) {
+			continue
+		}
+		id := strings.TrimSuffix(name, itemSuffix)
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		item := s.buildItem(state, id, data)
+		if item.QueuedAt.IsZero() {
+			if info, err := entry.Info(); err == nil {
+				item.QueuedAt = info.ModTime().UTC()
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *Store) buildItem(state State, id string, data []byte) Item {
+	meta := s.readMeta(id)
+	item := Item{
+		ID:             id,
+		State:          state,
+		Path:           s.itemPath(state, id),
+		QueuedAt:       meta.QueuedAt,
+		Attempts:       meta.Attempts,
+		Owner:          meta.Owner,
+		LeaseExpiresAt: meta.LeaseExpiresAt,
+		Error:          meta.Error,
+		UpdatedAt:      meta.UpdatedAt,
+	}
+	var payload struct {
+		envelopeFields
+		Envelope *envelopeFields `json:"envelope"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		fields := payload.envelopeFields
+		if payload.Envelope != nil {
+			fields = *payload.Envelope
+		}
+		item.EnvelopeID = fields.ID
+		item.SelectedAgent = fields.SelectedAgent
+		item.Channel = fields.Channel
+		item.JobID = fields.JobID
+		if item.QueuedAt.IsZero() {
+			item.QueuedAt, _ = time.Parse(time.RFC3339Nano, fields.ReceivedAt)
+		}
+	}
+	return item
+}
+
+type envelopeFields struct {
+	ID            string `json:"id"`
+	ReceivedAt    string `json:"received_at"`
+	SelectedAgent string `json:"selected_agent"`
+	Channel       string `json:"channel"`
+	JobID         string `json:"job_id"`
+}
+
+func (s *Store) readMeta(id string) itemMeta {
+	var meta itemMeta
+	data, err := os.ReadFile(s.metaPath(id))
+	if err != nil {
+		return meta
+	}
+	_ = json.Unmarshal(data, &meta)
+	return meta
+}
+
+func (s *Store) writeMeta(id string, meta itemMeta) error {
+	dir := filepath.Join(s.dir, stateDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create inbox state: %w", err)
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+id+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create inbox state temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write inbox state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close inbox state: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.metaPath(id)); err != nil {
+		return fmt.Errorf("commit inbox state: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) stateDir(state State) string {
+	if state == StatePending {
+		return s.dir
+	}
+	return filepath.Join(s.dir, string(state))
+}
+
+func (s *Store) itemPath(state State, id string) string {
+	return filepath.Join(s.stateDir(state), id+itemSuffix)
+}
+
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.dir, stateDirName, id+itemSuffix)
+}
+
+// ParseState validates a state name.
+func ParseState(value string) (State, error) {
+	state := State(strings.ToLower(strings.TrimSpace(value)))
+	if state == "" {
+		return "", nil
+	}
+	return state, validateState(state)
+}
+
+func validateState(state State) error {
+	switch state {
+	case StatePending, StateClaimed, StateDone, StateFailed:
+		return nil
+	}
+	return fmt.Errorf("unknown inbox state %q", state)
+}
+
+func validateID(id string) error {
+	if id == "" || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid inbox item id %q", id)
+	}
+	return nil
+}
+
+func defaultOwner(owner string) string {
+	if owner == "" {
+		return "unknown consumer"
+	}
+	return owner
+}
@@ -0,0 +1,224 @@
+package inbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testEnvelope struct {
+	ID            string `json:"id"`
+	ReceivedAt    string `json:"received_at"`
+	SelectedAgent string `json:"selected_agent"`
+}
+
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := Open(filepath.Join(t.TempDir(), "inbox"))
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func putTestItem(t *testing.T, store *Store, id string, receivedAt time.Time) {
+	t.Helper()
+	_, err := store.Put(id+".json", testEnvelope{ID: "env-" + id, ReceivedAt: receivedAt.Format(time.RFC3339Nano), SelectedAgent: "coder"})
+	if err != nil {
+		t.Fatalf("Put(%s): %v", id, err)
+	}
+}
+
+func TestPutKeepsPendingItemsAtRoot(t *testing.T) {
+	store, now := newTestStore(t)
+	putTestItem(t, store, "a", *now)
+
+	entries, err := os.ReadDir(store.Dir())
+	if err != nil {
+		t.Fatalf("read inbox: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "a.json" {
+		t.Fatalf("unexpected inbox entries: %#v", entries)
+	}
+	info, err := os.Stat(filepath.Join(store.Dir(), "a.json"))
+	if err != nil {
+		t.Fatalf("stat item: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("mode=%v", info.Mode().Perm())
+	}
+
+	item, _, err := store.Get("a")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if item.State != StatePending || item.EnvelopeID != "env-a" || item.SelectedAgent != "coder" || !item.QueuedAt.Equal(*now) {
+		t.Fatalf("unexpected item: %#v", item)
+	}
+}
+
+func TestClaimAckLifecycle(t *testing.T) {
+	store, now := newTestStore(t)
+	putTestItem(t, store, "newer", now.Add(time.Minute))
+	putTestItem(t, store, "older", *now)
+
+	item, err := store.Claim("worker-1", time.Minute)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if item.ID != "older" || item.State != StateClaimed || item.Owner != "worker-1" || !item.LeaseExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected claim: %#v", item)
+	}
+
+	done, err := store.Ack("older", false, "")
+	if err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if done.State != StateDone || done.Owner != "" || !done.LeaseExpiresAt.IsZero() {
+		t.Fatalf("unexpected ack: %#v", done)
+	}
+
+	failed, err := store.Ack("newer", true, "agent rejected")
+	if err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	if failed.State != StateFailed || failed.Error != "agent rejected" {
+		t.Fatalf("unexpected failed ack: %#v", failed)
+	}
+
+	if _, err := store.Claim("worker-1", time.Minute); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected empty inbox, got %v", err)
+	}
+
+	requeued, err := store.Requeue("newer")
+	if err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if requeued.State != StatePending || requeued.Error != "" {
+		t.Fatalf("unexpected requeue: %#v", requeued)
+	}
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Pending != 1 || stats.Done != 1 || stats.Failed != 0 || !stats.OldestPendingAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+}
+
+func TestExpiredLeaseReturnsToPendingThenFails(t *testing.T) {
+	store, now := newTestStore(t)
+	putTestItem(t, store, "a", *now)
+
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		if _, err := store.Claim("worker", time.Minute); err != nil {
+			t.Fatalf("attempt %d: Claim: %v", attempt, err)
+		}
+		*now = now.Add(2 * time.Minute)
+		reaped, err := store.ReapExpired()
+		if err != nil || reaped != 1 {
+			t.Fatalf("attempt %d: reaped=%d err=%v", attempt, reaped, err)
+		}
+		item, _, err := store.Get("a")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		want := StatePending
+		if attempt == MaxAttempts {
+			want = StateFailed
+		}
+		if item.State != want || item.Attempts != attempt {
+			t.Fatalf("attempt %d: unexpected item %#v", attempt, item)
+		}
+	}
+}
+
+func TestListLeavesExpiredLeaseUntilClaim(t *testing.T) {
+	store, now := newTestStore(t)
+	putTestItem(t, store, "a", *now)
+	if _, err := store.Claim("worker", time.Minute); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	*now = now.Add(2 * time.Minute)
+
+	items, err := store.List("")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 1 || items[0].State != StateClaimed || items[0].Attempts != 0 {
+		t.Fatalf("List changed the inbox: %#v", items)
+	}
+	claimed, err := store.Claim("worker-2", time.Minute)
+	if err != nil {
+		t.Fatalf("Claim after expiry: %v", err)
+	}
+	if claimed.ID != "a" || claimed.Owner != "worker-2" || claimed.Attempts != 1 {
+		t.Fatalf("unexpected reclaimed item: %#v", claimed)
+	}
+}
+
+func TestPutRejectsClaimedItem(t *testing.T) {
+	store, now := newTestStore(t)
+	putTestItem(t, store, "heartbeat-abc", *now)
+	if _, err := store.Claim("worker", time.Minute); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if _, err := store.Put("heartbeat-abc.json", testEnvelope{ID: "env-2"}); !errors.Is(err, ErrClaimed) {
+		t.Fatalf("expected ErrClaimed, got %v", err)
+	}
+	if items, err := store.List(""); err != nil || len(items) != 1 || items[0].State != StateClaimed {
+		t.Fatalf("unexpected items: %#v err=%v", items, err)
+	}
+}
+
+func TestPutResetsFinishedItem(t *testing.T) {
+	store, now := newTestStore(t)
+	putTestItem(t, store, "heartbeat-abc", *now)
+	if _, err := store.Ack("heartbeat-abc", true, "boom"); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	putTestItem(t, store, "heartbeat-abc", now.Add(time.Hour))
+
+	items, err := store.List("")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 1 || items[0].State != StatePending || items[0].Error != "" {
+		t.Fatalf("unexpected items: %#v", items)
+	}
+}
+
+func TestPurgeFinishedItems(t *testing.T) {
+	store, now := newTestStore(t)
+	putTestItem(t, store, "old", *now)
+	putTestItem(t, store, "recent", *now)
+	if _, err := store.Ack("old", false, ""); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	*now = now.Add(48 * time.Hour)
+	if _, err := store.Ack("recent", false, ""); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	purged, err := store.Purge(StateDone, 24*time.Hour)
+	if err != nil || purged != 1 {
+		t.Fatalf("purged=%d err=%v", purged, err)
+	}
+	if _, _, err := store.Get("old"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected old item to be purged, got %v", err)
+	}
+	if _, err := store.Purge(StatePending, 0); err == nil {
+		t.Fatal("expected pending purge to be rejected")
+	}
+}
+
+func TestInvalidIDsAreRejected(t *testing.T) {
+	store, _ := newTestStore(t)
+	for _, id := range []string{"", ".state", "../escape", `a\b`} {
+		if _, _, err := store.Get(id); err == nil || errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get(%q) err=%v", id, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+type channelSendParams struct {
+	To       string   `json:"to"`
+	Text     string   `json:"text"`
+	ThreadTS string   `json:"thread_ts,omitempty"`
+	Images   []string `json:"images,omitempty"`
+}
+
+type channelInboundParams struct {
+	Data        map[string]interface{} `json:"data"`
+	Attachments []protocol.Attachment  `json:"attachments,omitempty"`
+}
+
+type channelInboundResult struct {
+	Reply string `json:"reply,omitempty"`
+}
+
+type logParams struct {
+	Message string `json:"message"`
+}
+
+// ChannelAdapter exposes a channel plugin as a channels.Channel. The plugin
+// receives channel.start, channel.stop and channel.send, and reports inbound
+// messages with channel.inbound.
+type ChannelAdapter struct {
+	process *Process
+
+	mu           sync.RWMutex
+	handler      channels.IncomingMessageHandler
+	started      bool
+	lastError    time.Time
+	lastActivity time.Time
+}
+
+func newChannelAdapter(cfg config.PluginConfig) *ChannelAdapter {
+	adapter := &ChannelAdapter{}
+	adapter.process = newProcess(cfg, KindChannel, adapter.handleRequest)
+	adapter.process.onReady = adapter.resume
+	return adapter
+}
+
+// Name returns the plugin name, which is also the channel name.
+func (a *ChannelAdapter) Name() string {
+	return a.process.Name()
+}
+
+// Start asks the plugin to start receiving. If the plugin is still launching,
+// it is started once it answers initialize.
+func (a *ChannelAdapter) Start(ctx context.Context) error {
+	a.mu.Lock()
+	a.started = true
+	a.mu.Unlock()
+	if !a.process.Running() {
+		return nil
+	}
+	return a.call(ctx, "channel.start", nil, nil)
+}
+
+// Stop asks the plugin to stop receiving. The process keeps running.
+func (a *ChannelAdapter) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	a.started = false
+	a.mu.Unlock()
+	if !a.process.Running() {
+		return nil
+	}
+	return a.call(ctx, "channel.stop", nil, nil)
+}
+
+// Send delivers an outbound message through the plugin.
+func (a *ChannelAdapter) Send(ctx context.Context, msg channels.OutboundMessage) (*channels.SendResult, error) {
+	var result channels.SendResult
+	err := a.call(ctx, "channel.send", channelSendParams{
+		To:       msg.To,
+		Text:     msg.Text,
+		ThreadTS: msg.ThreadTS,
+		Images:   msg.Images,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// IsRunning reports whether the channel is started and its plugin is up.
+func (a *ChannelAdapter) IsRunning() bool {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+	return started && a.process.Running()
+}
+
+// IsAllowed always reports true; plugins apply their own allowlists before
+// reporting inbound messages.
+func (a *ChannelAdapter) IsAllowed(senderID string) bool {
+	return true
+}
+
+// SetHandler implements channels.HandlerAware.
+func (a *ChannelAdapter) SetHandler(handler channels.IncomingMessageHandler) {
+	a.mu.Lock()
+	a.handler = handler
+	a.mu.Unlock()
+}
+
+// LastError implements channels.TelemetryProvider.
+func (a *ChannelAdapter) LastError() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastError
+}
+
+// LastActivity implements channels.TelemetryProvider.
+func (a *ChannelAdapter) LastActivity() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastActivity
+}
+
+// Status returns the plugin supervision status.
+func (a *ChannelAdapter) Status() Status {
+	return a.process.Status()
+}
+
+func (a *ChannelAdapter) call(ctx context.Context, method string, params, result interface{}) error {
+	err := a.process.Call(ctx, method, params, result)
+	a.mu.Lock()
+	if err != nil {
+		a.lastError = time.Now()
+	} else {
+		a.lastActivity = time.Now()
+	}
+	a.mu.Unlock()
+	return err
+}
+
+// resume restarts the channel after the plugin process comes back.
+func (a *ChannelAdapter) resume(ctx context.Context) {
+	a.mu.RLock()
+	started := a.started
+	a.mu.RUnlock()
+	if !started {
+		return
+	}
+	if err := a.call(ctx, "channel.start", nil, nil); err != nil {
+		log.Printf("plugin %s: %v", a.Name(), err)
+		a.process.setError(err)
+	}
+}
+
+func (a *ChannelAdapter) handleRequest(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "channel.inbound":
+		var inbound channelInboundParams
+		if err := json.Unmarshal(params, &inbound); err != nil {
+			return nil, fmt.Errorf("invalid channel.inbound params: %w", err)
+		}
+		return a.handleInbound(ctx, inbound)
+	case "log":
+		var entry logParams
+		if err := json.Unmarshal(params, &entry); err == nil && entry.Message != "" {
+			log.Printf("plugin %s: %s", a.Name(), entry.Message)
+		}
+		return nil, nil
+	default:
+		return nil, &RPCError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+func (a *ChannelAdapter) handleInbound(ctx context.Context, inbound channelInboundParams) (interface{}, error) {
+	a.mu.Lock()
+	handler := a.handler
+	a.lastActivity = time.Now()
+	a.mu.Unlock()
+	if handler == nil {
+		return nil, errors.New("no inbound handler configured")
+	}
+
+	data := inbound.Data
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	data["channel"] = a.Name()
+	reply, err := handler.HandleIncoming(ctx, &protocol.Message{
+		Kind:        protocol.MessageKindChannel,
+		Action:      protocol.ActionCreate,
+		Data:        data,
+		Attachments: inbound.Attachments,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return channelInboundResult{Reply: reply}, nil
+}
+
+var (
+	_ channels.Channel           = (*ChannelAdapter)(nil)
+	_ channels.HandlerAware      = (*ChannelAdapter)(nil)
+	_ channels.TelemetryProvider = (*ChannelAdapter)(nil)
+)
@@ -0,0 +1,123 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+// RuntimeRegistrar accepts runtime adapters, for example *agent.Manager.
+type RuntimeRegistrar interface {
+	RegisterRuntime(rt agentruntime.Runtime) error
+}
+
+// Host owns the configured plugin processes.
+type Host struct {
+	channels []*ChannelAdapter
+	runtimes []*RuntimeAdapter
+}
+
+// NewHost builds adapters for channels.plugins and agents.plugins. Processes
+// are launched by Start.
+func NewHost(channelsCfg *config.ChannelsConfig, agentsCfg *config.AgentsConfig) *Host {
+	host := &Host{}
+	if channelsCfg != nil {
+		for _, cfg := range channelsCfg.Plugins {
+			host.channels = append(host.channels, newChannelAdapter(cfg))
+		}
+	}
+	if agentsCfg != nil {
+		for _, cfg := range agentsCfg.Plugins {
+			host.runtimes = append(host.runtimes, newRuntimeAdapter(cfg))
+		}
+	}
+	return host
+}
+
+// RegisterChannels adds a definition for every channel plugin so the channel
+// manager builds enabled ones like any other channel.
+func (h *Host) RegisterChannels(registry *channels.ChannelRegistry) error {
+	if registry == nil {
+		return errors.New("channel registry is nil")
+	}
+	for _, adapter := range h.channels {
+		adapter := adapter
+		err := registry.Register(channels.ChannelDefinition{
+			Name: adapter.Name(),
+			Decode: func(*config.ChannelsConfig) (channels.ChannelSettings, bool, error) {
+				return channels.ChannelSettings{Enabled: adapter.process.cfg.Enabled, Config: adapter}, true, nil
+			},
+			New: func(channels.ChannelSettings, *config.AgentsConfig) (channels.Channel, error) {
+				return adapter, nil
+			},
+			Status: func(channels.ChannelSettings, channels.Channel) channels.ChannelStatus {
+				return channels.ChannelStatus{Mode: "plugin"}
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterRuntimes adds every runtime plugin to registrar.
+func (h *Host) RegisterRuntimes(registrar RuntimeRegistrar) error {
+	if registrar == nil {
+		return errors.New("runtime registrar is nil")
+	}
+	for _, adapter := range h.runtimes {
+		if err := registrar.RegisterRuntime(adapter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start launches every enabled plugin under supervision.
+func (h *Host) Start(ctx context.Context) {
+	for _, process := range h.processes() {
+		process.Start(ctx)
+	}
+}
+
+// Stop shuts down every plugin process.
+func (h *Host) Stop(ctx context.Context) error {
+	var errs []error
+	for _, process := range h.processes() {
+		if err := process.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Status returns the supervision status of every configured plugin.
+func (h *Host) Status() []Status {
+	processes := h.processes()
+	if len(processes) == 0 {
+		return nil
+	}
+	statuses := make([]Status, 0, len(processes))
+	for _, process := range processes {
+		statuses = append(statuses, process.Status())
+	}
+	return statuses
+}
+
+func (h *Host) processes() []*Process {
+	if h == nil {
+		return nil
+	}
+	processes := make([]*Process, 0, len(h.channels)+len(h.runtimes))
+	for _, adapter := range h.channels {
+		processes = append(processes, adapter.process)
+	}
+	for _, adapter := range h.runtimes {
+		processes = append(processes, adapter.process)
+	}
+	return processes
+}
@@ -0,0 +1,252 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+	"github.com/fractalmind-ai/fractalbot/pkg/protocol"
+)
+
+const testPluginEnv = "FRACTALBOT_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) != "" {
+		runTestPlugin()
+		os.Exit(0)
+	}
+	backoffUnit = 10 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+// runTestPlugin is a minimal plugin served by the test binary itself.
+func runTestPlugin() {
+	out := json.NewEncoder(os.Stdout)
+	var writeMu sync.Mutex
+	send := func(msg map[string]interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		msg["jsonrpc"] = "2.0"
+		_ = out.Encode(msg)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg struct {
+			ID     json.RawMessage        `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || msg.Method == "" {
+			continue
+		}
+		reply := func(result interface{}) {
+			send(map[string]interface{}{"id": msg.ID, "result": result})
+		}
+		switch msg.Method {
+		case "initialize":
+			version := ProtocolVersion
+			if override := os.Getenv("FRACTALBOT_TEST_PLUGIN_VERSION"); override != "" {
+				version = override
+			}
+			reply(map[string]interface{}{"protocolVersion": version, "version": "test", "capabilities": []string{"test"}})
+		case "channel.start":
+			reply(map[string]interface{}{})
+			send(map[string]interface{}{"id": "inbound-1", "method": "channel.inbound", "params": map[string]interface{}{
+				"data": map[string]interface{}{"text": "hi", "chat_id": "42"},
+			}})
+		case "channel.stop":
+			reply(map[string]interface{}{})
+		case "channel.send":
+			reply(map[string]interface{}{"channel_id": msg.Params["to"], "message_ts": "1"})
+		case "runtime.route":
+			reply(map[string]interface{}{"reply": fmt.Sprintf("%v:%v", msg.Params["agent"], msg.Params["text"])})
+		case "runtime.dispatch":
+			reply(map[string]interface{}{"status": "delivered", "envelope_id": msg.Params["job_id"], "reply": "HEARTBEAT_OK"})
+		case "crash":
+			os.Exit(3)
+		case "shutdown":
+			return
+		default:
+			send(map[string]interface{}{"id": msg.ID, "error": map[string]interface{}{"code": -32601, "message": "unknown"}})
+		}
+	}
+}
+
+func testPluginConfig(name string, env map[string]string) config.PluginConfig {
+	merged := map[string]string{testPluginEnv: "1"}
+	for key, value := range env {
+		merged[key] = value
+	}
+	return config.PluginConfig{
+		Name:    name,
+		Enabled: true,
+		Command: os.Args[0],
+		Env:     merged,
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+type recordingHandler struct {
+	mu       sync.Mutex
+	messages []*protocol.Message
+}
+
+func (h *recordingHandler) HandleIncoming(ctx context.Context, msg *protocol.Message) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, msg)
+	return "ok", nil
+}
+
+func (h *recordingHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.messages)
+}
+
+func TestRuntimePluginRoutesAndDispatches(t *testing.T) {
+	cfg := testPluginConfig("echoRuntime", nil)
+	cfg.DefaultAgent = "main"
+	cfg.AllowedAgents = []string{"main", "helper"}
+	host := NewHost(nil, &config.AgentsConfig{Plugins: []config.PluginConfig{cfg}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	host.Start(ctx)
+	defer host.Stop(context.Background())
+
+	adapter := host.runtimes[0]
+	waitFor(t, "plugin running", adapter.process.Running)
+
+	reply, err := adapter.Route(ctx, agentruntime.RouteRequest{Text: "hello"})
+	if err != nil || reply != "main:hello" {
+		t.Fatalf("route reply=%q err=%v", reply, err)
+	}
+	if _, err := adapter.Route(ctx, agentruntime.RouteRequest{Text: "hello", Agent: "other"}); err == nil {
+		t.Fatal("expected allowlist rejection")
+	}
+
+	result := adapter.DispatchRuntime(ctx, agentruntime.DispatchRequest{Agent: "helper", Text: "wake", JobID: "job-1"})
+	if result.Status != "delivered" || result.EnvelopeID != "job-1" || result.Reply != agentruntime.HeartbeatOK || result.Runtime != "echoRuntime" {
+		t.Fatalf("unexpected dispatch result: %#v", result)
+	}
+
+	status := host.Status()
+	if len(status) != 1 || !status[0].Healthy || status[0].Kind != KindRuntime || status[0].Version != "test" {
+		t.Fatalf("unexpected status: %#v", status)
+	}
+}
+
+func TestChannelPluginForwardsInboundAndSends(t *testing.T) {
+	host := NewHost(&config.ChannelsConfig{Plugins: []config.PluginConfig{testPluginConfig("fakechat", nil)}}, nil)
+	manager := channels.NewManager(&config.ChannelsConfig{}, nil)
+	if err := host.RegisterChannels(manager.Registry()); err != nil {
+		t.Fatalf("register channels: %v", err)
+	}
+	handler := &recordingHandler{}
+	manager.SetHandler(handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	host.Start(ctx)
+	defer host.Stop(context.Background())
+	if err := manager.Start(ctx); err != nil {
+		t.Fatalf("start channels: %v", err)
+	}
+	defer manager.Stop()
+
+	channel := manager.Get("fakechat")
+	if channel == nil {
+		t.Fatal("expected plugin channel to be registered")
+	}
+	waitFor(t, "inbound message", func() bool { return handler.count() == 1 })
+	data := handler.messages[0].Data.(map[string]interface{})
+	if data["channel"] != "fakechat" || data["text"] != "hi" {
+		t.Fatalf("unexpected inbound data: %#v", data)
+	}
+	if !channel.IsRunning() {
+		t.Fatal("expected plugin channel to be running")
+	}
+
+	result, err := channel.Send(ctx, channels.OutboundMessage{To: "42", Text: "reply"})
+	if err != nil || result.ChannelID != "42" {
+		t.Fatalf("send result=%#v err=%v", result, err)
+	}
+}
+
+func TestPluginRestartsAfterCrash(t *testing.T) {
+	host := NewHost(&config.ChannelsConfig{Plugins: []config.PluginConfig{testPluginConfig("crashy", nil)}}, nil)
+	adapter := host.channels[0]
+	handler := &recordingHandler{}
+	adapter.SetHandler(handler)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	host.Start(ctx)
+	defer host.Stop(context.Background())
+
+	if err := adapter.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	waitFor(t, "first start", func() bool { return handler.count() == 1 })
+
+	_ = adapter.process.Call(ctx, "crash", nil, nil)
+	waitFor(t, "restart", func() bool {
+		status := adapter.Status()
+		return status.Restarts >= 1 && status.State == StateRunning
+	})
+	// The channel was started before the crash, so it is resumed.
+	waitFor(t, "resumed channel", func() bool { return handler.count() == 2 })
+	if status := adapter.Status(); status.LastExitAt == "" {
+		t.Fatalf("expected last exit time, got %#v", status)
+	}
+}
+
+func TestPluginRejectsProtocolMismatch(t *testing.T) {
+	cfg := testPluginConfig("old", map[string]string{"FRACTALBOT_TEST_PLUGIN_VERSION": "fractalbot.plugin.v0"})
+	host := NewHost(nil, &config.AgentsConfig{Plugins: []config.PluginConfig{cfg}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	host.Start(ctx)
+	defer host.Stop(context.Background())
+
+	adapter := host.runtimes[0]
+	waitFor(t, "backoff", func() bool { return adapter.Status().State == StateBackoff })
+	if status := adapter.Status(); !strings.Contains(status.LastError, "unsupported protocol version") || status.Healthy {
+		t.Fatalf("unexpected status: %#v", status)
+	}
+	if _, err := adapter.Route(ctx, agentruntime.RouteRequest{Text: "x", Agent: "a"}); err == nil {
+		t.Fatal("expected route to fail while plugin is down")
+	}
+}
+
+func TestDisabledPluginIsNotStarted(t *testing.T) {
+	cfg := testPluginConfig("off", nil)
+	cfg.Enabled = false
+	host := NewHost(nil, &config.AgentsConfig{Plugins: []config.PluginConfig{cfg}})
+	host.Start(context.Background())
+	if status := host.Status(); len(status) != 1 || status[0].State != StateDisabled {
+		t.Fatalf("unexpected status: %#v", status)
+	}
+	if err := host.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+}
@@ -0,0 +1,404 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+const (
+	defaultRestartBackoffSeconds    = 1
+	defaultMaxRestartBackoffSeconds = 60
+	defaultCallTimeout              = 30 * time.Second
+	initializeTimeout               = 10 * time.Second
+	stopGracePeriod                 = 3 * time.Second
+
+	// A plugin that stays up this long has its restart backoff reset.
+	stableRunDuration = time.Minute
+)
+
+// backoffUnit scales the configured restart backoff seconds.
+var backoffUnit = time.Second
+
+// Plugin process states reported in Status.
+const (
+	StateStopped  = "stopped"
+	StateStarting = "starting"
+	StateRunning  = "running"
+	StateBackoff  = "backoff"
+	StateDisabled = "disabled"
+)
+
+// Kinds of plugins.
+const (
+	KindChannel = "channel"
+	KindRuntime = "runtime"
+)
+
+// Status reports the supervision state of one plugin.
+type Status struct {
+	Name          string   `json:"name"`
+	Kind          string   `json:"kind"`
+	State         string   `json:"state"`
+	Healthy       bool     `json:"healthy"`
+	PID           int      `json:"pid,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	Capabilities  []string `json:"capabilities,omitempty"`
+	Restarts      int      `json:"restarts"`
+	LastStartedAt string   `json:"last_started_at,omitempty"`
+	LastExitAt    string   `json:"last_exit_at,omitempty"`
+	NextRestartAt string   `json:"next_restart_at,omitempty"`
+	LastError     string   `json:"last_error,omitempty"`
+}
+
+type initializeParams struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Name            string                 `json:"name"`
+	Kind            string                 `json:"kind"`
+	Settings        map[string]interface{} `json:"settings,omitempty"`
+}
+
+type initializeResult struct {
+	ProtocolVersion string   `json:"protocolVersion"`
+	Name            string   `json:"name,omitempty"`
+	Version         string   `json:"version,omitempty"`
+	Capabilities    []string `json:"capabilities,omitempty"`
+}
+
+// Process supervises one plugin executable and restarts it with exponential
+// backoff when it exits.
+type Process struct {
+	cfg     config.PluginConfig
+	kind    string
+	handler RequestHandler
+
+	// onReady runs after every successful initialize, including restarts.
+	onReady func(ctx context.Context)
+
+	mu            sync.RWMutex
+	conn          *Conn
+	state         string
+	pid           int
+	info          initializeResult
+	restarts      int
+	lastStartedAt time.Time
+	lastExitAt    time.Time
+	nextRestartAt time.Time
+	lastError     string
+
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopping bool
+}
+
+func newProcess(cfg config.PluginConfig, kind string, handler RequestHandler) *Process {
+	state := StateStopped
+	if !cfg.Enabled {
+		state = StateDisabled
+	}
+	return &Process{cfg: cfg, kind: kind, handler: handler, state: state}
+}
+
+// Name returns the configured plugin name.
+func (p *Process) Name() string {
+	return strings.TrimSpace(p.cfg.Name)
+}
+
+// Start launches the supervisor. It returns immediately; the plugin becomes
+// available once it answers initialize.
+func (p *Process) Start(ctx context.Context) {
+	if !p.cfg.Enabled {
+		return
+	}
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.mu.Unlock()
+		return
+	}
+	superviseCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	p.stopping = false
+	done := p.done
+	p.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		p.supervise(superviseCtx)
+	}()
+}
+
+// Stop asks the plugin to shut down, then kills it after a grace period.
+func (p *Process) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	cancel := p.cancel
+	done := p.done
+	conn := p.conn
+	p.cancel = nil
+	p.stopping = true
+	p.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	if conn != nil {
+		_ = conn.Notify("shutdown", nil)
+	}
+	grace := time.NewTimer(stopGracePeriod)
+	defer grace.Stop()
+	select {
+	case <-done:
+	case <-grace.C:
+	case <-ctx.Done():
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Call invokes method on the running plugin.
+func (p *Process) Call(ctx context.Context, method string, params, result interface{}) error {
+	p.mu.RLock()
+	conn := p.conn
+	state := p.state
+	p.mu.RUnlock()
+	if conn == nil || state != StateRunning {
+		return fmt.Errorf("plugin %s is not running", p.Name())
+	}
+	callCtx, cancel := context.WithTimeout(ctx, p.callTimeout())
+	defer cancel()
+	if err := conn.Call(callCtx, method, params, result); err != nil {
+		return fmt.Errorf("plugin %s: %s: %w", p.Name(), method, err)
+	}
+	return nil
+}
+
+// Running reports whether the plugin answered initialize and is still up.
+func (p *Process) Running() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.state == StateRunning
+}
+
+// Status returns a snapshot of the supervision state.
+func (p *Process) Status() Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	status := Status{
+		Name:          p.Name(),
+		Kind:          p.kind,
+		State:         p.state,
+		Healthy:       p.state == StateRunning,
+		PID:           p.pid,
+		Version:       p.info.Version,
+		Restarts:      p.restarts,
+		LastStartedAt: formatTime(p.lastStartedAt),
+		LastExitAt:    formatTime(p.lastExitAt),
+		LastError:     p.lastError,
+	}
+	if p.state == StateBackoff {
+		status.NextRestartAt = formatTime(p.nextRestartAt)
+	}
+	if len(p.info.Capabilities) > 0 {
+		status.Capabilities = append([]string(nil), p.info.Capabilities...)
+	}
+	return status
+}
+
+func (p *Process) supervise(ctx context.Context) {
+	backoff := p.restartBackoff()
+	for {
+		startedAt := time.Now()
+		err := p.runOnce(ctx)
+		if ctx.Err() != nil || p.isStopping() {
+			p.setStopped()
+			return
+		}
+		if time.Since(startedAt) >= stableRunDuration {
+			backoff = p.restartBackoff()
+		}
+		if err == nil {
+			err = errors.New("exited")
+		}
+		log.Printf("plugin %s: %v; restarting in %s", p.Name(), err, backoff)
+
+		p.mu.Lock()
+		p.state = StateBackoff
+		p.lastError = err.Error()
+		p.nextRestartAt = time.Now().Add(backoff)
+		p.mu.Unlock()
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			p.setStopped()
+			return
+		case <-timer.C:
+		}
+
+		p.mu.Lock()
+		p.restarts++
+		p.mu.Unlock()
+		backoff *= 2
+		if limit := p.maxRestartBackoff(); backoff > limit {
+			backoff = limit
+		}
+	}
+}
+
+func (p *Process) runOnce(ctx context.Context) error {
+	p.mu.Lock()
+	p.state = StateStarting
+	p.mu.Unlock()
+
+	cmd := exec.CommandContext(ctx, p.cfg.Command, p.cfg.Args...)
+	cmd.Dir = strings.TrimSpace(p.cfg.Dir)
+	cmd.Env = os.Environ()
+	for key, value := range p.cfg.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	go p.logStderr(stderr)
+
+	conn := NewConn(stdout, stdin, p.handler)
+	go func() {
+		_ = conn.Serve(ctx)
+	}()
+
+	var info initializeResult
+	initCtx, cancel := context.WithTimeout(ctx, initializeTimeout)
+	err = conn.Call(initCtx, "initialize", initializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Name:            p.Name(),
+		Kind:            p.kind,
+		Settings:        p.cfg.Settings,
+	}, &info)
+	cancel()
+	if err == nil && info.ProtocolVersion != ProtocolVersion {
+		err = fmt.Errorf("unsupported protocol version %q", info.ProtocolVersion)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		p.recordExit()
+		return fmt.Errorf("initialize: %w", err)
+	}
+
+	p.mu.Lock()
+	p.conn = conn
+	p.state = StateRunning
+	p.pid = cmd.Process.Pid
+	p.info = info
+	p.lastStartedAt = time.Now().UTC()
+	p.lastError = ""
+	onReady := p.onReady
+	p.mu.Unlock()
+
+	if onReady != nil {
+		go onReady(ctx)
+	}
+
+	// Drain stdout before Wait closes the pipe.
+	<-conn.Done()
+	err = cmd.Wait()
+	_ = stdin.Close()
+	p.recordExit()
+	return err
+}
+
+func (p *Process) recordExit() {
+	p.mu.Lock()
+	p.conn = nil
+	p.pid = 0
+	p.lastExitAt = time.Now().UTC()
+	p.mu.Unlock()
+}
+
+func (p *Process) isStopping() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.stopping
+}
+
+func (p *Process) setStopped() {
+	p.mu.Lock()
+	p.state = StateStopped
+	p.conn = nil
+	p.pid = 0
+	p.mu.Unlock()
+}
+
+func (p *Process) setError(err error) {
+	if err == nil {
+		return
+	}
+	p.mu.Lock()
+	p.lastError = err.Error()
+	p.mu.Unlock()
+}
+
+func (p *Process) logStderr(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		log.Printf("plugin %s: %s", p.Name(), scanner.Text())
+	}
+}
+
+func (p *Process) restartBackoff() time.Duration {
+	seconds := defaultRestartBackoffSeconds
+	if p.cfg.RestartBackoffSeconds > 0 {
+		seconds = p.cfg.RestartBackoffSeconds
+	}
+	return time.Duration(seconds) * backoffUnit
+}
+
+func (p *Process) maxRestartBackoff() time.Duration {
+	seconds := defaultMaxRestartBackoffSeconds
+	if p.cfg.MaxRestartBackoffSeconds > 0 {
+		seconds = p.cfg.MaxRestartBackoffSeconds
+	}
+	return time.Duration(seconds) * backoffUnit
+}
+
+func (p *Process) callTimeout() time.Duration {
+	if p.cfg.CallTimeoutSeconds > 0 {
+		return time.Duration(p.cfg.CallTimeoutSeconds) * time.Second
+	}
+	return defaultCallTimeout
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
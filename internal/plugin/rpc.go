@@ -0,0 +1,235 @@
+// Package plugin hosts out-of-process channel and Agent Runtime adapters.
+//
+// A plugin is an executable that exchanges newline-delimited JSON-RPC 2.0
+// messages with FractalBot over stdin and stdout. Stderr is copied to the
+// gateway log. The host calls initialize first and rejects plugins that do not
+// answer with ProtocolVersion.
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// ProtocolVersion is the plugin protocol spoken by this host.
+const ProtocolVersion = "fractalbot.plugin.v1"
+
+const maxMessageBytes = 4 << 20
+
+// ErrConnClosed is returned by calls on a connection whose peer went away.
+var ErrConnClosed = errors.New("plugin connection closed")
+
+// RPCError is a JSON-RPC error object.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("plugin error %d: %s", e.Code, e.Message)
+}
+
+const (
+	codeMethodNotFound = -32601
+	codeInternalError  = -32603
+)
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RequestHandler serves requests and notifications sent by the plugin. The
+// result is ignored for notifications.
+type RequestHandler func(ctx context.Context, method string, params json.RawMessage) (interface{}, error)
+
+// Conn is one JSON-RPC session over a plugin's stdio.
+type Conn struct {
+	reader  io.Reader
+	writer  io.Writer
+	handler RequestHandler
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]chan rpcMessage
+	closed  bool
+	done    chan struct{}
+	err     error
+}
+
+// NewConn creates a connection. Serve must run for calls to complete.
+func NewConn(r io.Reader, w io.Writer, handler RequestHandler) *Conn {
+	return &Conn{
+		reader:  r,
+		writer:  w,
+		handler: handler,
+		pending: make(map[string]chan rpcMessage),
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve reads messages until the reader fails or is closed.
+func (c *Conn) Serve(ctx context.Context) error {
+	scanner := bufio.NewScanner(c.reader)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+		if msg.Method != "" {
+			go c.serveRequest(ctx, msg)
+			continue
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[string(msg.ID)]
+		delete(c.pending, string(msg.ID))
+		c.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	c.close(err)
+	return err
+}
+
+// Done is closed when the connection stops serving.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Call sends a request and decodes the result into result when non-nil.
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrConnClosed
+	}
+	c.nextID++
+	id := json.RawMessage(strconv.FormatUint(c.nextID, 10))
+	ch := make(chan rpcMessage, 1)
+	c.pending[string(id)] = ch
+	c.mu.Unlock()
+
+	if err := c.write(rpcMessage{ID: id, Method: method}, params); err != nil {
+		c.forget(id)
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil && len(msg.Result) > 0 {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				return fmt.Errorf("decode %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-c.done:
+		return ErrConnClosed
+	case <-ctx.Done():
+		c.forget(id)
+		return ctx.Err()
+	}
+}
+
+// Notify sends a notification without waiting for a reply.
+func (c *Conn) Notify(method string, params interface{}) error {
+	return c.write(rpcMessage{Method: method}, params)
+}
+
+func (c *Conn) serveRequest(ctx context.Context, msg rpcMessage) {
+	var (
+		result interface{}
+		err    error
+	)
+	if c.handler == nil {
+		err = &RPCError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	} else {
+		result, err = c.handler(ctx, msg.Method, msg.Params)
+	}
+	if len(msg.ID) == 0 {
+		return
+	}
+	reply := rpcMessage{ID: msg.ID}
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: codeInternalError, Message: err.Error()}
+		}
+		reply.Error = rpcErr
+		_ = c.write(reply, nil)
+		return
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	_ = c.write(reply, result)
+}
+
+func (c *Conn) write(msg rpcMessage, payload interface{}) error {
+	msg.JSONRPC = "2.0"
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		if msg.Method != "" {
+			msg.Params = raw
+		} else {
+			msg.Result = raw
+		}
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.writer.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", msg.Method, err)
+	}
+	return nil
+}
+
+func (c *Conn) forget(id json.RawMessage) {
+	c.mu.Lock()
+	delete(c.pending, string(id))
+	c.mu.Unlock()
+}
+
+func (c *Conn) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+	c.pending = make(map[string]chan rpcMessage)
+	close(c.done)
+}
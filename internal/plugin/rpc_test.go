@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func newConnPair(serverHandler RequestHandler) (*Conn, *Conn, func()) {
+	clientRead, serverWrite := io.Pipe()
+	serverRead, clientWrite := io.Pipe()
+	client := NewConn(clientRead, clientWrite, nil)
+	server := NewConn(serverRead, serverWrite, serverHandler)
+	ctx, cancel := context.WithCancel(context.Background())
+	go client.Serve(ctx)
+	go server.Serve(ctx)
+	return client, server, func() {
+		cancel()
+		clientWrite.Close()
+		serverWrite.Close()
+	}
+}
+
+func TestConnCallRoundTrip(t *testing.T) {
+	client, _, closeAll := newConnPair(func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		if method != "echo" {
+			return nil, &RPCError{Code: codeMethodNotFound, Message: "unknown"}
+		}
+		var in map[string]string
+		_ = json.Unmarshal(params, &in)
+		return map[string]string{"echo": in["text"]}, nil
+	})
+	defer closeAll()
+
+	var out map[string]string
+	if err := client.Call(context.Background(), "echo", map[string]string{"text": "hi"}, &out); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if out["echo"] != "hi" {
+		t.Fatalf("unexpected result: %#v", out)
+	}
+
+	err := client.Call(context.Background(), "missing", nil, nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeMethodNotFound {
+		t.Fatalf("expected method not found, got %v", err)
+	}
+}
+
+func TestConnCallFailsWhenPeerCloses(t *testing.T) {
+	block := make(chan struct{})
+	client, _, closeAll := newConnPair(func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		<-block
+		return nil, nil
+	})
+	defer close(block)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- client.Call(context.Background(), "slow", nil, nil)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	closeAll()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrConnClosed) {
+			t.Fatalf("expected ErrConnClosed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("call did not return after close")
+	}
+}
@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fractalmind-ai/fractalbot/internal/agentruntime"
+	"github.com/fractalmind-ai/fractalbot/internal/channels"
+	"github.com/fractalmind-ai/fractalbot/internal/config"
+)
+
+type runtimeRouteParams struct {
+	Text  string                 `json:"text"`
+	Agent string                 `json:"agent"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+type runtimeRouteResult struct {
+	Reply string `json:"reply,omitempty"`
+}
+
+type runtimeDispatchParams struct {
+	Agent        string   `json:"agent"`
+	Text         string   `json:"text"`
+	Source       string   `json:"source,omitempty"`
+	JobID        string   `json:"job_id,omitempty"`
+	RunID        string   `json:"run_id,omitempty"`
+	ScheduledAt  string   `json:"scheduled_at,omitempty"`
+	ExpiresAt    string   `json:"expires_at,omitempty"`
+	CoalesceKey  string   `json:"coalesce_key,omitempty"`
+	CronProfiles []string `json:"cron_profiles,omitempty"`
+}
+
+type runtimeDispatchResult struct {
+	Status     string `json:"status"`
+	EnvelopeID string `json:"envelope_id,omitempty"`
+	InboxPath  string `json:"inbox_path,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Reply      string `json:"reply,omitempty"`
+}
+
+// RuntimeAdapter exposes a runtime plugin as an agentruntime.Runtime. The
+// plugin receives runtime.route for channel messages and runtime.dispatch for
+// heartbeat wakeups.
+type RuntimeAdapter struct {
+	cfg     config.PluginConfig
+	process *Process
+}
+
+func newRuntimeAdapter(cfg config.PluginConfig) *RuntimeAdapter {
+	return &RuntimeAdapter{cfg: cfg, process: newProcess(cfg, KindRuntime, nil)}
+}
+
+// Name returns the plugin name, which is also the runtime name.
+func (r *RuntimeAdapter) Name() string {
+	return r.process.Name()
+}
+
+// Enabled reports whether the plugin is enabled in config.
+func (r *RuntimeAdapter) Enabled() bool {
+	return r.cfg.Enabled
+}
+
+// DefaultAgent returns agents.plugins[].defaultAgent.
+func (r *RuntimeAdapter) DefaultAgent() string {
+	return strings.TrimSpace(r.cfg.DefaultAgent)
+}
+
+// Route delivers a channel message to the plugin and returns its reply.
+func (r *RuntimeAdapter) Route(ctx context.Context, request agentruntime.RouteRequest) (string, error) {
+	agentName, err := r.resolveAgent(request.Agent)
+	if err != nil {
+		return "", err
+	}
+	var result runtimeRouteResult
+	if err := r.process.Call(ctx, "runtime.route", runtimeRouteParams{
+		Text:  request.Text,
+		Agent: agentName,
+		Data:  request.Data,
+	}, &result); err != nil {
+		return "", err
+	}
+	return result.Reply, nil
+}
+
+// DispatchRuntime delivers a heartbeat wakeup to the plugin.
+func (r *RuntimeAdapter) DispatchRuntime(ctx context.Context, request agentruntime.DispatchRequest) agentruntime.DispatchResult {
+	result := agentruntime.DispatchResult{Runtime: r.Name(), Agent: request.Agent}
+	agentName, err := r.resolveAgent(request.Agent)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	result.Agent = agentName
+
+	var reply runtimeDispatchResult
+	err = r.process.Call(ctx, "runtime.dispatch", runtimeDispatchParams{
+		Agent:        agentName,
+		Text:         request.Text,
+		Source:       request.Source,
+		JobID:        request.JobID,
+		RunID:        request.RunID,
+		ScheduledAt:  formatRFC3339Nano(request.ScheduledAt),
+		ExpiresAt:    formatRFC3339Nano(request.ExpiresAt),
+		CoalesceKey:  request.CoalesceKey,
+		CronProfiles: request.CronProfiles,
+	}, &reply)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = reply.Status
+	if result.Status == "" {
+		result.Status = "delivered"
+	}
+	result.EnvelopeID = reply.EnvelopeID
+	result.InboxPath = reply.InboxPath
+	result.Error = reply.Error
+	result.Reply = reply.Reply
+	return result
+}
+
+// RuntimeStatus implements agentruntime.StatusProvider.
+func (r *RuntimeAdapter) RuntimeStatus() interface{} {
+	return r.process.Status()
+}
+
+// Status returns the plugin supervision status.
+func (r *RuntimeAdapter) Status() Status {
+	return r.process.Status()
+}
+
+func (r *RuntimeAdapter) resolveAgent(agentName string) (string, error) {
+	name := strings.TrimSpace(agentName)
+	if name == "" {
+		name = r.DefaultAgent()
+	}
+	if name == "" {
+		return "", errors.New("agent name is required")
+	}
+	if err := channels.ValidateAgentName(name); err != nil {
+		return "", err
+	}
+	allowlist := channels.NewAgentAllowlist(r.cfg.AllowedAgents)
+	if err := allowlist.Validate(name, r.cfg.DefaultAgent); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func formatRFC3339Nano(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339Nano)
+}
+
+var (
+	_ agentruntime.Runtime        = (*RuntimeAdapter)(nil)
+	_ agentruntime.StatusProvider = (*RuntimeAdapter)(nil)
+)
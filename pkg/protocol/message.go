@@ -1,5 +1,7 @@
 package protocol
 
+import "time"
+
 // MessageKind defines type of message
 type MessageKind string
 
@@ -47,6 +49,11 @@ type AgentInfo struct {
 	Name   string `json:"name"`
 	Status string `json:"status"`
 	Model  string `json:"model,omitempty"`
+	// Runtime is the Agent Runtime that serves the agent.
+	Runtime string `json:"runtime,omitempty"`
+	// Detail describes the agent's target, such as a conversation title.
+	Detail       string     `json:"detail,omitempty"`
+	LastActivity *time.Time `json:"last_activity,omitempty"`
 }
 
 // ChannelInfo contains information about a channel